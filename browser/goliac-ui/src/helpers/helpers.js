@@ -40,10 +40,27 @@ function indexBy (arr, prop) {
     }
   }
   
+  // the Github organization browsed (when Goliac manages several organizations)
+  const ORGANIZATION_KEY = 'goliac-organization'
+
+  function getOrganization () {
+    return window.localStorage.getItem(ORGANIZATION_KEY) || ''
+  }
+
+  function setOrganization (organization) {
+    if (organization) {
+      window.localStorage.setItem(ORGANIZATION_KEY, organization)
+    } else {
+      window.localStorage.removeItem(ORGANIZATION_KEY)
+    }
+  }
+
   export default {
     indexBy,
     pluck,
     sum,
     get,
-    handleErr
+    handleErr,
+    getOrganization,
+    setOrganization
   }
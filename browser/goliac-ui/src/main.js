@@ -3,6 +3,20 @@ import App from './App.vue'
 
 import router from './router'
 import installElementPlus from './plugins/element'
+import Axios from 'axios'
+import constants from '@/constants'
+import helpers from '@/helpers/helpers'
+
+const { API_URL } = constants
+
+// scope the REST API calls to the selected organization (/api/v1/orgs/<organization>/...)
+Axios.interceptors.request.use(config => {
+  const organization = helpers.getOrganization()
+  if (organization && config.url.startsWith(`${API_URL}/`)) {
+    config.url = `${API_URL}/orgs/${encodeURIComponent(organization)}` + config.url.substring(API_URL.length)
+  }
+  return config
+})
 
 const app = createApp(App)
 installElementPlus(app)
@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -10,6 +12,7 @@ import (
 	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/goliac-project/goliac/internal"
 	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/engine"
 	"github.com/goliac-project/goliac/internal/notification"
 	"github.com/goliac-project/goliac/internal/observability"
 	"github.com/schollz/progressbar/v3"
@@ -23,9 +26,15 @@ var dryrunParameter bool
 var forceParameter bool
 var repositoryParameter string
 var branchParameter string
+var showRepositoryParameter string
 var noProgressbar bool
 var goliacAdminTeamnameParameter string
 var usersOnly bool
+var ownerTeamParameter string
+var formatParameter string
+var sourceParameter string
+var outputParameter string
+var onlyParameter []string
 
 type ProgressBar struct {
 	bar *progressbar.ProgressBar
@@ -69,12 +78,50 @@ func (p *ProgressBar) LoadingAsset(entity string, nb int) {
 	p.bar.Add(nb)
 }
 
+/*
+printAccessExplanation prints every path granting access to a repository to a user
+*/
+func printAccessExplanation(explanation *engine.AccessExplanation) {
+	user := explanation.Githubid
+	if explanation.Username != "" {
+		user = fmt.Sprintf("%s (%s)", explanation.Username, explanation.Githubid)
+	}
+	fmt.Printf("%s on %s\n", user, explanation.Repository)
+
+	printPaths := func(title string, paths *engine.AccessPaths) {
+		permission := paths.Permission
+		if permission == "" {
+			permission = "no access"
+		}
+		fmt.Printf("%s: %s\n", title, permission)
+		for _, r := range paths.Reasons {
+			fmt.Printf("  - %s\n", r)
+		}
+		for _, b := range paths.Bypasses {
+			fmt.Printf("  - %s\n", b)
+		}
+	}
+	printPaths("teams repository", explanation.Local)
+	printPaths("Github", explanation.Remote)
+
+	if len(explanation.Unmanaged) > 0 || len(explanation.UnmanagedBypasses) > 0 {
+		fmt.Printf("not managed by Goliac:\n")
+		for _, r := range explanation.Unmanaged {
+			fmt.Printf("  - %s\n", r)
+		}
+		for _, b := range explanation.UnmanagedBypasses {
+			fmt.Printf("  - %s\n", b)
+		}
+	}
+}
+
 func main() {
 	verifyCmd := &cobra.Command{
-		Use:   "verify <path>",
+		Use:   "verify <path> [--show-repository reponame]",
 		Short: "Verify the validity of IAC directory structure",
-		Long:  `Verify the validity of IAC directory structure`,
-		Args:  cobra.MatchAll(cobra.MinimumNArgs(1), cobra.OnlyValidArgs),
+		Long: `Verify the validity of IAC directory structure
+show-repository: print the effective manifest of a repository (with the team's repository_defaults applied)`,
+		Args: cobra.MatchAll(cobra.MinimumNArgs(1), cobra.OnlyValidArgs),
 		Run: func(cmd *cobra.Command, args []string) {
 			path := args[0]
 			goliac, err := internal.NewGoliacLightImpl()
@@ -99,16 +146,25 @@ func main() {
 			for _, info := range logsCollector.Logs {
 				logrus.WithFields(info.Fields).Logf(info.LogLevel, info.Format, info.Args...)
 			}
+			if showRepositoryParameter != "" {
+				manifest, err := goliac.EffectiveRepository(showRepositoryParameter)
+				if err != nil {
+					logrus.Fatalf("failed to show repository: %s", err)
+				}
+				fmt.Print(manifest)
+			}
 		},
 	}
+	verifyCmd.Flags().StringVarP(&showRepositoryParameter, "show-repository", "s", "", "print the effective manifest of a repository")
 
 	planCmd := &cobra.Command{
-		Use:   "plan [--repository https_team_repository_url] [--branch branch]",
+		Use:   "plan [--repository https_team_repository_url] [--branch branch] [--only repo:<name>|team:<name>|ruleset:<name>]",
 		Short: "Check the validity of IAC directory structure against a Github organization",
 		Long: `Check the validity of IAC directory structure against a Github organization.
 repository: a remote repository in the form https://github.com/...
 repository can be passed by parameter or by defining GOLIAC_SERVER_GIT_REPOSITORY env variable
-branch can be passed by parameter or by defining GOLIAC_SERVER_GIT_BRANCH env variable`,
+branch can be passed by parameter or by defining GOLIAC_SERVER_GIT_BRANCH env variable
+only (repeatable) restricts the reconciliation to a repository, a team or a ruleset`,
 		Run: func(cmd *cobra.Command, args []string) {
 			repo := repositoryParameter
 			branch := branchParameter
@@ -148,7 +204,7 @@ branch can be passed by parameter or by defining GOLIAC_SERVER_GIT_BRANCH env va
 			fs := osfs.New("/")
 
 			logsCollector := observability.NewLogCollection()
-			goliac.Apply(ctx, logsCollector, fs, true, repo, branch)
+			goliac.Apply(ctx, logsCollector, fs, true, repo, branch, onlyParameter)
 			if span != nil {
 				span.End()
 				config.ShutdownTraceProvider()
@@ -174,14 +230,16 @@ branch can be passed by parameter or by defining GOLIAC_SERVER_GIT_BRANCH env va
 	planCmd.Flags().StringVarP(&repositoryParameter, "repository", "r", config.Config.ServerGitRepository, "repository (default env variable GOLIAC_SERVER_GIT_REPOSITORY)")
 	planCmd.Flags().StringVarP(&branchParameter, "branch", "b", config.Config.ServerGitBranch, "branch (default env variable GOLIAC_SERVER_GIT_BRANCH)")
 	planCmd.Flags().BoolVarP(&noProgressbar, "noprogressbar", "p", false, "display a progress bar")
+	planCmd.Flags().StringArrayVarP(&onlyParameter, "only", "o", []string{}, "only reconcile this resource (repo:<name>, team:<name> or ruleset:<name>)")
 
 	applyCmd := &cobra.Command{
-		Use:   "apply [--repository https_team_repository_url] [--branch branch]",
+		Use:   "apply [--repository https_team_repository_url] [--branch branch] [--only repo:<name>|team:<name>|ruleset:<name>]",
 		Short: "Verify and apply a IAC directory structure to a Github organization",
 		Long: `Apply a IAC directory structure to a Github organization.
 repository: a remote repository in the form https://github.com/...
 repository can be passed by parameter or by defining GOLIAC_SERVER_GIT_REPOSITORY env variable
-branch can be passed by parameter or by defining GOLIAC_SERVER_GIT_BRANCH env variable`,
+branch can be passed by parameter or by defining GOLIAC_SERVER_GIT_BRANCH env variable
+only (repeatable) restricts the reconciliation to a repository, a team or a ruleset`,
 		Run: func(cmd *cobra.Command, args []string) {
 			repo := repositoryParameter
 			branch := branchParameter
@@ -218,7 +276,7 @@ branch can be passed by parameter or by defining GOLIAC_SERVER_GIT_BRANCH env va
 
 			fs := osfs.New("/")
 			logsCollector := observability.NewLogCollection()
-			goliac.Apply(ctx, logsCollector, fs, false, repo, branch)
+			goliac.Apply(ctx, logsCollector, fs, false, repo, branch, onlyParameter)
 			if span != nil {
 				span.End()
 				config.ShutdownTraceProvider()
@@ -243,6 +301,7 @@ branch can be passed by parameter or by defining GOLIAC_SERVER_GIT_BRANCH env va
 	applyCmd.Flags().StringVarP(&repositoryParameter, "repository", "r", config.Config.ServerGitRepository, "repository (default env variable GOLIAC_SERVER_GIT_REPOSITORY)")
 	applyCmd.Flags().StringVarP(&branchParameter, "branch", "b", config.Config.ServerGitBranch, "branch (default env variable GOLIAC_SERVER_GIT_BRANCH)")
 	applyCmd.Flags().BoolVarP(&noProgressbar, "noprogressbar", "p", false, "display a progress bar")
+	applyCmd.Flags().StringArrayVarP(&onlyParameter, "only", "o", []string{}, "only reconcile this resource (repo:<name>, team:<name> or ruleset:<name>)")
 
 	postSyncUsersCmd := &cobra.Command{
 		Use:   "syncusers [--repository https_team_repository_url] [--branch branch] [--dryrun] [--force]",
@@ -302,6 +361,312 @@ branch can be passed by parameter or by defining GOLIAC_SERVER_GIT_BRANCH env va
 	postSyncUsersCmd.Flags().BoolVarP(&dryrunParameter, "dryrun", "d", false, "dryrun mode")
 	postSyncUsersCmd.Flags().BoolVarP(&forceParameter, "force", "f", false, "force mode")
 
+	adoptCmd := &cobra.Command{
+		Use:   "adopt <kind:name>... [--repository https_team_repository_url] [--branch branch] [--team owner_team]",
+		Short: "Import unmanaged Github resources via a Pull Request",
+		Long: `This command will generate the manifests of unmanaged resources
+ (the ones reported by plan/apply) and open a Pull Request on the teams repository.
+ resources are in the form repo:<name>, team:<name>, user:<githubid> or ruleset:<name>
+ team: the team owning adopted repositories that have no Goliac team with write access (default the admin team)
+ repository can be passed by parameter or by defining GOLIAC_SERVER_GIT_REPOSITORY env variable
+ branch can be passed by parameter or by defining GOLIAC_SERVER_GIT_BRANCH env variable`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			repo := repositoryParameter
+			branch := branchParameter
+
+			if repo == "" {
+				repo = config.Config.ServerGitRepository
+			}
+			if branch == "" {
+				branch = config.Config.ServerGitBranch
+			}
+			if repo == "" || branch == "" {
+				logrus.Fatalf("missing arguments, try --help")
+			}
+
+			goliac, err := internal.NewGoliacImpl()
+			if err != nil {
+				logrus.Fatalf("failed to create goliac: %s", err)
+			}
+			ctx := context.Background()
+			var span trace.Span
+			if config.Config.OpenTelemetryEnabled {
+				tracer := otel.Tracer("goliac")
+				ctx, span = tracer.Start(ctx, "adopt")
+			}
+
+			fs := osfs.New("/")
+			logsCollector := observability.NewLogCollection()
+			prUrl := goliac.Adopt(ctx, logsCollector, fs, "", args, ownerTeamParameter, repo, branch)
+			if span != nil {
+				span.End()
+				config.ShutdownTraceProvider()
+			}
+			if logsCollector.HasWarns() {
+				logrus.Warnf("Warnings:")
+				for _, err := range logsCollector.Warns {
+					logrus.Warnf("- %s", err)
+				}
+			}
+			for _, info := range logsCollector.Logs {
+				logrus.WithFields(info.Fields).Logf(info.LogLevel, info.Format, info.Args...)
+			}
+			if logsCollector.HasErrors() {
+				logrus.Errorf("Failed to adopt:")
+				for _, err := range logsCollector.Errors {
+					logrus.Errorf("- %s", err)
+				}
+				os.Exit(1)
+			}
+			fmt.Printf("Pull request created: %s\n", prUrl)
+		},
+	}
+	adoptCmd.Flags().StringVarP(&repositoryParameter, "repository", "r", config.Config.ServerGitRepository, "repository (default env variable GOLIAC_SERVER_GIT_REPOSITORY)")
+	adoptCmd.Flags().StringVarP(&branchParameter, "branch", "b", config.Config.ServerGitBranch, "branch (default env variable GOLIAC_SERVER_GIT_BRANCH)")
+	adoptCmd.Flags().StringVarP(&ownerTeamParameter, "team", "t", "", "owner team of orphan repositories (default the admin team)")
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate the teams repository definitions",
+	}
+	migrateBranchProtectionsCmd := &cobra.Command{
+		Use:   "branch-protections [--repository https_team_repository_url] [--branch branch] [--dryrun]",
+		Short: "Convert branch protections into rulesets via a Pull Request",
+		Long: `This command will convert the repositories branch_protections into
+ equivalent rulesets, and open a Pull Request on the teams repository.
+ Settings without ruleset equivalent are reported as warnings.
+ repository can be passed by parameter or by defining GOLIAC_SERVER_GIT_REPOSITORY env variable
+ branch can be passed by parameter or by defining GOLIAC_SERVER_GIT_BRANCH env variable`,
+		Run: func(cmd *cobra.Command, args []string) {
+			repo := repositoryParameter
+			branch := branchParameter
+
+			if repo == "" {
+				repo = config.Config.ServerGitRepository
+			}
+			if branch == "" {
+				branch = config.Config.ServerGitBranch
+			}
+			if repo == "" || branch == "" {
+				logrus.Fatalf("missing arguments, try --help")
+			}
+
+			goliac, err := internal.NewGoliacImpl()
+			if err != nil {
+				logrus.Fatalf("failed to create goliac: %s", err)
+			}
+			ctx := context.Background()
+			var span trace.Span
+			if config.Config.OpenTelemetryEnabled {
+				tracer := otel.Tracer("goliac")
+				ctx, span = tracer.Start(ctx, "migrate_branch_protections")
+			}
+
+			fs := osfs.New("/")
+			logsCollector := observability.NewLogCollection()
+			prUrl := goliac.MigrateBranchProtections(ctx, logsCollector, fs, repo, branch, dryrunParameter)
+			if span != nil {
+				span.End()
+				config.ShutdownTraceProvider()
+			}
+			if logsCollector.HasWarns() {
+				logrus.Warnf("Warnings:")
+				for _, err := range logsCollector.Warns {
+					logrus.Warnf("- %s", err)
+				}
+			}
+			for _, info := range logsCollector.Logs {
+				logrus.WithFields(info.Fields).Logf(info.LogLevel, info.Format, info.Args...)
+			}
+			if logsCollector.HasErrors() {
+				logrus.Errorf("Failed to migrate branch protections:")
+				for _, err := range logsCollector.Errors {
+					logrus.Errorf("- %s", err)
+				}
+				os.Exit(1)
+			}
+			if prUrl != "" {
+				fmt.Printf("Pull request created: %s\n", prUrl)
+			}
+		},
+	}
+	migrateBranchProtectionsCmd.Flags().StringVarP(&repositoryParameter, "repository", "r", config.Config.ServerGitRepository, "repository (default env variable GOLIAC_SERVER_GIT_REPOSITORY)")
+	migrateBranchProtectionsCmd.Flags().StringVarP(&branchParameter, "branch", "b", config.Config.ServerGitBranch, "branch (default env variable GOLIAC_SERVER_GIT_BRANCH)")
+	migrateBranchProtectionsCmd.Flags().BoolVarP(&dryrunParameter, "dryrun", "d", false, "dryrun mode")
+	migrateCmd.AddCommand(migrateBranchProtectionsCmd)
+
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Generate reports on the Github organization",
+	}
+	reportAccessCmd := &cobra.Command{
+		Use:   "access [--repository https_team_repository_url] [--branch branch] [--format csv|json] [--source local|remote] [--output file]",
+		Short: "Report who has access to which repository (access review)",
+		Long: `This command will resolve the effective permission of each user on each repository
+ (via teams and their parent teams, "-goliac-owners" teams, the everyone team,
+ collaborators and organization admins), with the reasons granting it.
+ source: local (the teams repository, desired state) or remote (the Github organization, actual state)
+ repository can be passed by parameter or by defining GOLIAC_SERVER_GIT_REPOSITORY env variable
+ branch can be passed by parameter or by defining GOLIAC_SERVER_GIT_BRANCH env variable`,
+		Run: func(cmd *cobra.Command, args []string) {
+			repo := repositoryParameter
+			branch := branchParameter
+
+			if repo == "" {
+				repo = config.Config.ServerGitRepository
+			}
+			if branch == "" {
+				branch = config.Config.ServerGitBranch
+			}
+			if repo == "" || branch == "" {
+				logrus.Fatalf("missing arguments, try --help")
+			}
+			if formatParameter != "csv" && formatParameter != "json" {
+				logrus.Fatalf("unknown format %s (expected csv or json)", formatParameter)
+			}
+
+			goliac, err := internal.NewGoliacImpl()
+			if err != nil {
+				logrus.Fatalf("failed to create goliac: %s", err)
+			}
+			ctx := context.Background()
+			var span trace.Span
+			if config.Config.OpenTelemetryEnabled {
+				tracer := otel.Tracer("goliac")
+				ctx, span = tracer.Start(ctx, "report_access")
+			}
+
+			fs := osfs.New("/")
+			logsCollector := observability.NewLogCollection()
+			report := goliac.AccessReport(ctx, logsCollector, fs, repo, branch, sourceParameter)
+			if span != nil {
+				span.End()
+				config.ShutdownTraceProvider()
+			}
+			if logsCollector.HasWarns() {
+				logrus.Warnf("Warnings:")
+				for _, err := range logsCollector.Warns {
+					logrus.Warnf("- %s", err)
+				}
+			}
+			if logsCollector.HasErrors() {
+				logrus.Errorf("Failed to generate the access report:")
+				for _, err := range logsCollector.Errors {
+					logrus.Errorf("- %s", err)
+				}
+				os.Exit(1)
+			}
+
+			var out io.Writer = os.Stdout
+			if outputParameter != "" {
+				f, err := os.Create(outputParameter)
+				if err != nil {
+					logrus.Fatalf("failed to create %s: %s", outputParameter, err)
+				}
+				defer f.Close()
+				out = f
+			}
+			if formatParameter == "csv" {
+				err = engine.WriteAccessReportCSV(out, report)
+			} else {
+				encoder := json.NewEncoder(out)
+				encoder.SetIndent("", "  ")
+				err = encoder.Encode(report)
+			}
+			if err != nil {
+				logrus.Fatalf("failed to write the access report: %s", err)
+			}
+		},
+	}
+	reportAccessCmd.Flags().StringVarP(&repositoryParameter, "repository", "r", config.Config.ServerGitRepository, "repository (default env variable GOLIAC_SERVER_GIT_REPOSITORY)")
+	reportAccessCmd.Flags().StringVarP(&branchParameter, "branch", "b", config.Config.ServerGitBranch, "branch (default env variable GOLIAC_SERVER_GIT_BRANCH)")
+	reportAccessCmd.Flags().StringVarP(&formatParameter, "format", "f", "csv", "output format (csv or json)")
+	reportAccessCmd.Flags().StringVarP(&sourceParameter, "source", "s", "local", "local (teams repository) or remote (Github organization)")
+	reportAccessCmd.Flags().StringVarP(&outputParameter, "output", "o", "", "output file (default stdout)")
+	reportCmd.AddCommand(reportAccessCmd)
+
+	explainCmd := &cobra.Command{
+		Use:   "explain user <user> repo <repository> [--repository https_team_repository_url] [--branch branch] [--format text|json]",
+		Short: "Explain why a user has access to a repository",
+		Long: `This command will print every path granting access to a repository to a user
+ (teams and their parent teams, "-goliac-owners" teams, the everyone team, collaborators,
+ organization admins, and the rulesets/branch protections the user can bypass),
+ as defined in the teams repository and as currently in Github, and the Github grants
+ that are not managed by Goliac.
+ user can be a github login or a Goliac username
+ repository can be passed by parameter or by defining GOLIAC_SERVER_GIT_REPOSITORY env variable
+ branch can be passed by parameter or by defining GOLIAC_SERVER_GIT_BRANCH env variable`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 4 || args[0] != "user" || args[2] != "repo" {
+				return fmt.Errorf("expected: explain user <user> repo <repository>")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			repo := repositoryParameter
+			branch := branchParameter
+
+			if repo == "" {
+				repo = config.Config.ServerGitRepository
+			}
+			if branch == "" {
+				branch = config.Config.ServerGitBranch
+			}
+			if repo == "" || branch == "" {
+				logrus.Fatalf("missing arguments, try --help")
+			}
+			if formatParameter != "text" && formatParameter != "json" {
+				logrus.Fatalf("unknown format %s (expected text or json)", formatParameter)
+			}
+
+			goliac, err := internal.NewGoliacImpl()
+			if err != nil {
+				logrus.Fatalf("failed to create goliac: %s", err)
+			}
+			ctx := context.Background()
+			var span trace.Span
+			if config.Config.OpenTelemetryEnabled {
+				tracer := otel.Tracer("goliac")
+				ctx, span = tracer.Start(ctx, "explain")
+			}
+
+			fs := osfs.New("/")
+			logsCollector := observability.NewLogCollection()
+			explanation := goliac.ExplainAccess(ctx, logsCollector, fs, repo, branch, args[1], args[3])
+			if span != nil {
+				span.End()
+				config.ShutdownTraceProvider()
+			}
+			if logsCollector.HasWarns() {
+				logrus.Warnf("Warnings:")
+				for _, err := range logsCollector.Warns {
+					logrus.Warnf("- %s", err)
+				}
+			}
+			if logsCollector.HasErrors() {
+				logrus.Errorf("Failed to explain the access:")
+				for _, err := range logsCollector.Errors {
+					logrus.Errorf("- %s", err)
+				}
+				os.Exit(1)
+			}
+
+			if formatParameter == "json" {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(explanation); err != nil {
+					logrus.Fatalf("failed to write the explanation: %s", err)
+				}
+				return
+			}
+			printAccessExplanation(explanation)
+		},
+	}
+	explainCmd.Flags().StringVarP(&repositoryParameter, "repository", "r", config.Config.ServerGitRepository, "repository (default env variable GOLIAC_SERVER_GIT_REPOSITORY)")
+	explainCmd.Flags().StringVarP(&branchParameter, "branch", "b", config.Config.ServerGitBranch, "branch (default env variable GOLIAC_SERVER_GIT_BRANCH)")
+	explainCmd.Flags().StringVarP(&formatParameter, "format", "f", "text", "output format (text or json)")
+
 	scaffoldcmd := &cobra.Command{
 		Use:   "scaffold <directory> [--adminteam goliac_admin_team_name] [--users-only]",
 		Short: "Will create a base directory based on your current Github organization",
@@ -395,11 +760,17 @@ Now you can push this directory as a new repository to Github, like:
 		Short: "This will start the application in server mode",
 		Long: `This will start the application in server mode, which will
 apply periodically (env:GOLIAC_SERVER_APPLY_INTERVAL)
-any changes from the teams Git repository to Github.`,
+any changes from the teams Git repository to Github.
+Several organizations (and their enterprise) can be managed by
+the same server (env:GOLIAC_SERVER_ORGANIZATIONS_FILE).`,
 		Run: func(cmd *cobra.Command, args []string) {
-			goliac, err := internal.NewGoliacImpl()
+			organizations, err := internal.GetOrganizationsConfig()
 			if err != nil {
-				logrus.Fatalf("failed to create goliac: %s", err)
+				logrus.Fatalf("failed to load the organizations: %s", err)
+			}
+			enterprise, err := internal.GetEnterpriseConfig()
+			if err != nil {
+				logrus.Fatalf("failed to load the enterprise: %s", err)
 			}
 			notificationService := notification.NewNullNotificationService()
 			if config.Config.SlackToken != "" && config.Config.SlackChannel != "" {
@@ -407,7 +778,10 @@ any changes from the teams Git repository to Github.`,
 				notificationService = slackService
 			}
 
-			server := internal.NewGoliacServer(goliac, notificationService)
+			server, err := internal.NewGoliacMultiOrgServer(organizations, enterprise, notificationService)
+			if err != nil {
+				logrus.Fatalf("failed to create goliac: %s", err)
+			}
 			server.Serve()
 		},
 	}
@@ -432,6 +806,10 @@ Either local directory, or remote git repository`,
 	rootCmd.AddCommand(planCmd)
 	rootCmd.AddCommand(applyCmd)
 	rootCmd.AddCommand(postSyncUsersCmd)
+	rootCmd.AddCommand(adoptCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(explainCmd)
 	rootCmd.AddCommand(scaffoldcmd)
 	rootCmd.AddCommand(servecmd)
 	rootCmd.AddCommand(versioncmd)
@@ -0,0 +1,307 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/engine"
+	"github.com/goliac-project/goliac/internal/entity"
+	"github.com/goliac-project/goliac/internal/observability"
+)
+
+const (
+	ADOPT_KIND_REPOSITORY = "repo"
+	ADOPT_KIND_TEAM       = "team"
+	ADOPT_KIND_USER       = "user"
+	ADOPT_KIND_RULESET    = "ruleset"
+)
+
+/*
+ * AdoptResource is an unmanaged Github resource that we want to bring
+ * under Goliac management, expressed as <kind>:<name>
+ * (repo:<name>, team:<name>, user:<githubid>, ruleset:<name>)
+ */
+type AdoptResource struct {
+	Kind string
+	Name string
+}
+
+func ParseAdoptResource(resource string) (*AdoptResource, error) {
+	parts := strings.SplitN(resource, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("invalid resource %s: expected <kind>:<name>", resource)
+	}
+	switch parts[0] {
+	case ADOPT_KIND_REPOSITORY, ADOPT_KIND_TEAM, ADOPT_KIND_USER, ADOPT_KIND_RULESET:
+		return &AdoptResource{Kind: parts[0], Name: parts[1]}, nil
+	}
+	return nil, fmt.Errorf("invalid resource kind %s: expected one of repo, team, user, ruleset", parts[0])
+}
+
+/*
+ * localTeamDirectory returns the directory (teams/<parent>/<team>) of a team
+ * defined in the teams repository
+ */
+func localTeamDirectory(lTeams map[string]*entity.Team, teamname string) string {
+	lTeam := lTeams[teamname]
+	directoryPath := teamname
+	for lTeam != nil && lTeam.ParentTeam != nil {
+		directoryPath = path.Join(*lTeam.ParentTeam, directoryPath)
+		lTeam = lTeams[*lTeam.ParentTeam]
+	}
+	return path.Join("teams", directoryPath)
+}
+
+/*
+ * generateAdoptManifests reuses the scaffold logic to generate the manifests
+ * of (currently unmanaged) Github resources.
+ * It returns a map[<filepath>]<manifest> ready to be committed into the teams repository.
+ * If a repository has no ADMIN/WRITE team managed by Goliac, it will be owned by ownerTeam.
+ */
+func generateAdoptManifests(ctx context.Context, remote engine.GoliacRemote, local engine.GoliacLocalResources, resources []*AdoptResource, ownerTeam string, logsCollector *observability.LogCollection) map[string]interface{} {
+	manifests := make(map[string]interface{})
+
+	rTeams := remote.Teams(ctx, false)
+	teamsSlugByName := remote.TeamSlugByName(ctx)
+	teamsNameBySlug := make(map[string]string)
+	for name, slug := range teamsSlugByName {
+		teamsNameBySlug[slug] = name
+	}
+	teamIds := make(map[int]*engine.GithubTeam)
+	for _, t := range rTeams {
+		teamIds[t.Id] = t
+	}
+
+	// usermap is a map[<githubid>]<username>
+	usermap := make(map[string]string)
+	for username, u := range local.Users() {
+		usermap[u.Spec.GithubID] = username
+	}
+
+	// the teams (name) adopted in this batch, and their directory
+	adoptedTeams := make(map[string]string)
+
+	// the teams (name) requested in this batch, a nested team can be adopted with its parent team
+	requestedTeams := make(map[string]bool)
+	for _, res := range resources {
+		if res.Kind != ADOPT_KIND_TEAM {
+			continue
+		}
+		if rTeam := rTeams[res.Name]; rTeam != nil {
+			requestedTeams[rTeam.Name] = true
+		} else if rTeam := rTeams[teamsSlugByName[res.Name]]; rTeam != nil {
+			requestedTeams[rTeam.Name] = true
+		}
+	}
+
+	// returns the directory of a team to adopt: under the directory of its parent team,
+	// that must be managed by Goliac, or adopted in this batch
+	var adoptedTeamDirectory func(rTeam *engine.GithubTeam, depth int) (string, error)
+	adoptedTeamDirectory = func(rTeam *engine.GithubTeam, depth int) (string, error) {
+		if rTeam.ParentTeam == nil {
+			return path.Join("teams", rTeam.Name), nil
+		}
+		parent, ok := teamIds[*rTeam.ParentTeam]
+		if !ok || depth > 100 {
+			return "", fmt.Errorf("not able to find back the parent team (id %d) of team %s", *rTeam.ParentTeam, rTeam.Name)
+		}
+		if local.Teams()[parent.Name] != nil {
+			return path.Join(localTeamDirectory(local.Teams(), parent.Name), rTeam.Name), nil
+		}
+		if requestedTeams[parent.Name] {
+			parentDirectory, err := adoptedTeamDirectory(parent, depth+1)
+			if err != nil {
+				return "", err
+			}
+			return path.Join(parentDirectory, rTeam.Name), nil
+		}
+		return "", fmt.Errorf("the parent team %s of team %s is not managed by Goliac: adopt the parent team first (or together, with team:%s)", parent.Name, rTeam.Name, parent.Name)
+	}
+
+	// first pass: users and teams, because repositories may rely on them
+	for _, res := range resources {
+		if res.Kind != ADOPT_KIND_USER {
+			continue
+		}
+		if _, ok := remote.Users(ctx)[res.Name]; !ok {
+			logsCollector.AddError(fmt.Errorf("user %s not found in the Github organization", res.Name))
+			continue
+		}
+		if _, ok := usermap[res.Name]; ok {
+			logsCollector.AddError(fmt.Errorf("user %s is already managed by Goliac", res.Name))
+			continue
+		}
+		user := entity.User{}
+		user.ApiVersion = "v1"
+		user.Kind = "User"
+		user.Name = res.Name
+		user.Spec.GithubID = res.Name
+		usermap[res.Name] = res.Name
+		manifests[path.Join("users", "org", res.Name+".yaml")] = &user
+	}
+
+	for _, res := range resources {
+		if res.Kind != ADOPT_KIND_TEAM {
+			continue
+		}
+		rTeam := rTeams[res.Name]
+		if rTeam == nil {
+			rTeam = rTeams[teamsSlugByName[res.Name]]
+		}
+		if rTeam == nil {
+			logsCollector.AddError(fmt.Errorf("team %s not found in the Github organization", res.Name))
+			continue
+		}
+		if strings.HasSuffix(rTeam.Slug, config.Config.GoliacTeamOwnerSuffix) {
+			logsCollector.AddError(fmt.Errorf("team %s is an owners team, it cannot be adopted", res.Name))
+			continue
+		}
+		if local.Teams()[rTeam.Name] != nil {
+			logsCollector.AddError(fmt.Errorf("team %s is already managed by Goliac", rTeam.Name))
+			continue
+		}
+
+		for _, m := range append(append([]string{}, rTeam.Maintainers...), rTeam.Members...) {
+			if _, ok := usermap[m]; !ok {
+				logsCollector.AddWarn(fmt.Errorf("user %s (member of team %s) is not managed by Goliac and will not be added to the team (adopt it with user:%s)", m, rTeam.Name, m))
+			}
+		}
+		lTeam := scaffoldTeam(rTeam, rTeam.Name, usermap)
+		lTeam.Spec.Owners = removeEmptyStrings(lTeam.Spec.Owners)
+		lTeam.Spec.Members = removeEmptyStrings(lTeam.Spec.Members)
+
+		teamDirectory, err := adoptedTeamDirectory(rTeam, 0)
+		if err != nil {
+			logsCollector.AddError(err)
+			continue
+		}
+		adoptedTeams[rTeam.Name] = teamDirectory
+		manifests[path.Join(teamDirectory, "team.yaml")] = &lTeam
+	}
+
+	// returns the directory of a team managed (or about to be managed) by Goliac
+	teamDirectory := func(teamname string) (string, bool) {
+		if local.Teams()[teamname] != nil {
+			return localTeamDirectory(local.Teams(), teamname), true
+		}
+		if dir, ok := adoptedTeams[teamname]; ok {
+			return dir, true
+		}
+		return "", false
+	}
+
+	// second pass: repositories and rulesets
+	teamsRepositories := remote.TeamRepositories(ctx)
+	teamSlugs := make([]string, 0, len(teamsRepositories))
+	for slug := range teamsRepositories {
+		teamSlugs = append(teamSlugs, slug)
+	}
+	sort.Strings(teamSlugs)
+
+	for _, res := range resources {
+		switch res.Kind {
+		case ADOPT_KIND_REPOSITORY:
+			rRepo := remote.Repositories(ctx)[res.Name]
+			if rRepo == nil {
+				logsCollector.AddError(fmt.Errorf("repository %s not found in the Github organization", res.Name))
+				continue
+			}
+			if local.Repositories()[res.Name] != nil {
+				logsCollector.AddError(fmt.Errorf("repository %s is already managed by Goliac", res.Name))
+				continue
+			}
+
+			// looking for the owner (ADMIN first, then WRITE) and the writers/readers
+			owner := ""
+			writers := []string{}
+			readers := []string{}
+			for _, permission := range []string{"ADMIN", "WRITE"} {
+				for _, slug := range teamSlugs {
+					tr, ok := teamsRepositories[slug][res.Name]
+					if !ok || tr.Permission != permission {
+						continue
+					}
+					teamname := teamsNameBySlug[slug]
+					if _, managed := teamDirectory(teamname); !managed {
+						if !strings.HasSuffix(slug, config.Config.GoliacTeamOwnerSuffix) {
+							logsCollector.AddWarn(fmt.Errorf("team %s (%s on repository %s) is not managed by Goliac and will be ignored", teamname, permission, res.Name))
+						}
+						continue
+					}
+					if owner == "" {
+						owner = teamname
+					}
+					writers = append(writers, teamname)
+				}
+			}
+			for _, slug := range teamSlugs {
+				tr, ok := teamsRepositories[slug][res.Name]
+				if !ok || tr.Permission == "ADMIN" || tr.Permission == "WRITE" {
+					continue
+				}
+				teamname := teamsNameBySlug[slug]
+				if _, managed := teamDirectory(teamname); !managed {
+					logsCollector.AddWarn(fmt.Errorf("team %s (%s on repository %s) is not managed by Goliac and will be ignored", teamname, tr.Permission, res.Name))
+					continue
+				}
+				readers = append(readers, teamname)
+			}
+			if owner == "" {
+				owner = ownerTeam
+			}
+			ownerDirectory, managed := teamDirectory(owner)
+			if !managed {
+				logsCollector.AddError(fmt.Errorf("owner team %s of repository %s is not managed by Goliac", owner, res.Name))
+				continue
+			}
+
+			ownerSlug := teamsSlugByName[owner]
+			if ownerSlug == "" {
+				ownerSlug = owner
+			}
+			teams := map[string]*engine.GithubTeam{ownerSlug: {Name: owner, Slug: ownerSlug}}
+			lRepo := scaffoldRepository(res.Name, rRepo, ownerSlug, writers, readers, teams, teamsSlugByName, teamsNameBySlug, usermap)
+
+			if lRepo.Archived {
+				manifests[path.Join("archived", res.Name+".yaml")] = &lRepo
+			} else {
+				manifests[path.Join(ownerDirectory, res.Name+".yaml")] = &lRepo
+			}
+
+		case ADOPT_KIND_RULESET:
+			rRuleset := remote.RuleSets(ctx)[res.Name]
+			if rRuleset == nil {
+				logsCollector.AddError(fmt.Errorf("ruleset %s not found in the Github organization", res.Name))
+				continue
+			}
+			if local.RuleSets()[res.Name] != nil {
+				logsCollector.AddError(fmt.Errorf("ruleset %s is already managed by Goliac", res.Name))
+				continue
+			}
+			lRuleset := entity.RuleSet{}
+			lRuleset.ApiVersion = "v1"
+			lRuleset.Kind = "Ruleset"
+			lRuleset.Name = res.Name
+			lRuleset.Spec.Repositories.Included = rRuleset.Repositories
+			lRuleset.Spec.Ruleset = scaffoldRuleSetDefinition(rRuleset, teamsNameBySlug)
+			manifests[path.Join("rulesets", res.Name+".yaml")] = &lRuleset
+			logsCollector.AddInfo(map[string]interface{}{"command": "adopt"}, "ruleset %s: add it to the rulesets list of goliac.yaml to enforce it", res.Name)
+		}
+	}
+
+	return manifests
+}
+
+func removeEmptyStrings(in []string) []string {
+	out := []string{}
+	for _, s := range in {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
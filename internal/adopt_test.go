@@ -0,0 +1,223 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/engine"
+	"github.com/goliac-project/goliac/internal/entity"
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+func fixtureAdoptLocal() *GoliacLocalMock {
+	l := &GoliacLocalMock{
+		teams:         make(map[string]*entity.Team),
+		repositories:  make(map[string]*entity.Repository),
+		users:         make(map[string]*entity.User),
+		externalUsers: make(map[string]*entity.User),
+		rulesets:      make(map[string]*entity.RuleSet),
+		workflows:     make(map[string]*entity.Workflow),
+		repoconfig:    &config.RepositoryConfig{},
+	}
+
+	user1 := entity.User{}
+	user1.Name = "user1"
+	user1.Spec.GithubID = "githubid1"
+	l.users["user1"] = &user1
+
+	admin := entity.Team{}
+	admin.Name = "admin"
+	admin.Spec.Owners = []string{"user1"}
+	l.teams["admin"] = &admin
+
+	return l
+}
+
+func TestParseAdoptResource(t *testing.T) {
+	t.Run("happy path", func(t *testing.T) {
+		res, err := ParseAdoptResource("repo:repo1")
+		assert.Nil(t, err)
+		assert.Equal(t, "repo", res.Kind)
+		assert.Equal(t, "repo1", res.Name)
+	})
+
+	t.Run("not happy path: unknown kind", func(t *testing.T) {
+		_, err := ParseAdoptResource("project:foo")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("not happy path: missing name", func(t *testing.T) {
+		_, err := ParseAdoptResource("repo:")
+		assert.NotNil(t, err)
+		_, err = ParseAdoptResource("repo1")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGenerateAdoptManifests(t *testing.T) {
+	t.Run("happy path: adopt a user", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		manifests := generateAdoptManifests(context.TODO(), NewScaffoldGoliacRemoteMock(), fixtureAdoptLocal(), []*AdoptResource{{Kind: "user", Name: "githubid2"}}, "admin", logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 1, len(manifests))
+		user := manifests["users/org/githubid2.yaml"].(*entity.User)
+		assert.Equal(t, "githubid2", user.Name)
+		assert.Equal(t, "githubid2", user.Spec.GithubID)
+	})
+
+	t.Run("happy path: adopt a team with one of its members", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		manifests := generateAdoptManifests(context.TODO(), NewScaffoldGoliacRemoteMock(), fixtureAdoptLocal(), []*AdoptResource{{Kind: "team", Name: "regular"}, {Kind: "user", Name: "githubid2"}}, "admin", logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		// githubid3 is not managed
+		assert.Equal(t, 1, len(logsCollector.Warns))
+		assert.Equal(t, 2, len(manifests))
+		team := manifests["teams/regular/team.yaml"].(*entity.Team)
+		assert.Equal(t, "regular", team.Name)
+		assert.Equal(t, []string{"githubid2"}, team.Spec.Owners)
+	})
+
+	t.Run("happy path: adopt a repository with a team adopted at the same time", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		manifests := generateAdoptManifests(context.TODO(), NewScaffoldGoliacRemoteMock(), fixtureAdoptLocal(), []*AdoptResource{{Kind: "repo", Name: "repo1"}, {Kind: "team", Name: "regular"}}, "admin", logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		repo := manifests["teams/regular/repo1.yaml"].(*entity.Repository)
+		assert.NotNil(t, repo)
+		assert.Equal(t, "repo1", repo.Name)
+		assert.Equal(t, 0, len(repo.Spec.Writers))
+	})
+
+	t.Run("happy path: orphan repository goes to the owner team", func(t *testing.T) {
+		remote := NewScaffoldGoliacRemoteMock()
+		local := fixtureAdoptLocal()
+		delete(local.teams, "admin")
+		regular := entity.Team{}
+		regular.Name = "regular"
+		local.teams["regular"] = &regular
+		local.teams["other"] = &entity.Team{Entity: entity.Entity{Name: "other"}}
+
+		logsCollector := observability.NewLogCollection()
+		// repo2: admin (WRITE, not managed), regular (READ)
+		manifests := generateAdoptManifests(context.TODO(), remote, local, []*AdoptResource{{Kind: "repo", Name: "repo2"}}, "other", logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 1, len(logsCollector.Warns))
+		repo := manifests["teams/other/repo2.yaml"].(*entity.Repository)
+		assert.NotNil(t, repo)
+		assert.Equal(t, []string{"regular"}, repo.Spec.Readers)
+	})
+
+	t.Run("happy path: archived repository", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		manifests := generateAdoptManifests(context.TODO(), NewScaffoldGoliacRemoteMock(), fixtureAdoptLocal(), []*AdoptResource{{Kind: "repo", Name: "archived_repo"}}, "admin", logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		repo := manifests["archived/archived_repo.yaml"].(*entity.Repository)
+		assert.NotNil(t, repo)
+		assert.True(t, repo.Archived)
+	})
+
+	t.Run("happy path: adopt a ruleset", func(t *testing.T) {
+		remote := NewScaffoldGoliacRemoteMock().(*ScaffoldGoliacRemoteMock)
+		remote.rulesets = map[string]*engine.GithubRuleSet{
+			"protect": {
+				Name:        "protect",
+				Enforcement: "active",
+				BypassTeams: map[string]string{"admin": "always"},
+				OnInclude:   []string{"~DEFAULT_BRANCH"},
+				Rules: map[string]entity.RuleSetParameters{
+					"required_signatures": {},
+				},
+				Repositories: []string{"repo1"},
+			},
+		}
+
+		logsCollector := observability.NewLogCollection()
+		manifests := generateAdoptManifests(context.TODO(), remote, fixtureAdoptLocal(), []*AdoptResource{{Kind: "ruleset", Name: "protect"}}, "admin", logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		ruleset := manifests["rulesets/protect.yaml"].(*entity.RuleSet)
+		assert.Equal(t, "Ruleset", ruleset.Kind)
+		assert.Equal(t, []string{"repo1"}, ruleset.Spec.Repositories.Included)
+		assert.Equal(t, "active", ruleset.Spec.Ruleset.Enforcement)
+		assert.Equal(t, "admin", ruleset.Spec.Ruleset.BypassTeams[0].TeamName)
+		assert.Equal(t, "required_signatures", ruleset.Spec.Ruleset.Rules[0].Ruletype)
+	})
+
+	t.Run("not happy path: already managed resources", func(t *testing.T) {
+		local := fixtureAdoptLocal()
+		repo1 := entity.Repository{}
+		repo1.Name = "repo1"
+		local.repositories["repo1"] = &repo1
+
+		logsCollector := observability.NewLogCollection()
+		generateAdoptManifests(context.TODO(), NewScaffoldGoliacRemoteMock(), local, []*AdoptResource{{Kind: "repo", Name: "repo1"}, {Kind: "user", Name: "githubid1"}, {Kind: "team", Name: "admin"}}, "admin", logsCollector)
+
+		assert.Equal(t, 3, len(logsCollector.Errors))
+	})
+
+	t.Run("not happy path: unknown resources", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		generateAdoptManifests(context.TODO(), NewScaffoldGoliacRemoteMock(), fixtureAdoptLocal(), []*AdoptResource{{Kind: "repo", Name: "unknown"}, {Kind: "user", Name: "unknown"}, {Kind: "team", Name: "unknown"}, {Kind: "ruleset", Name: "unknown"}}, "admin", logsCollector)
+
+		assert.Equal(t, 4, len(logsCollector.Errors))
+	})
+
+	t.Run("happy path: adopt a nested team", func(t *testing.T) {
+		nestedRemote := func() engine.GoliacRemote {
+			remote := NewScaffoldGoliacRemoteMock()
+			parentId := 10
+			teams := remote.(*ScaffoldGoliacRemoteMock).teams
+			teams["parent"] = &engine.GithubTeam{Name: "parent", Slug: "parent", Id: 10}
+			teams["child"] = &engine.GithubTeam{Name: "child", Slug: "child", Id: 11, ParentTeam: &parentId}
+			return remote
+		}
+
+		// with its parent team adopted at the same time
+		logsCollector := observability.NewLogCollection()
+		manifests := generateAdoptManifests(context.TODO(), nestedRemote(), fixtureAdoptLocal(), []*AdoptResource{{Kind: "team", Name: "child"}, {Kind: "team", Name: "parent"}}, "admin", logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.NotNil(t, manifests["teams/parent/team.yaml"])
+		assert.NotNil(t, manifests["teams/parent/child/team.yaml"])
+
+		// with its parent team already managed (in its own directory)
+		local := fixtureAdoptLocal()
+		admin := "admin"
+		parent := entity.Team{}
+		parent.Name = "parent"
+		parent.ParentTeam = &admin
+		local.teams["parent"] = &parent
+		logsCollector = observability.NewLogCollection()
+		manifests = generateAdoptManifests(context.TODO(), nestedRemote(), local, []*AdoptResource{{Kind: "team", Name: "child"}}, "admin", logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 1, len(manifests))
+		assert.NotNil(t, manifests["teams/admin/parent/child/team.yaml"])
+	})
+
+	t.Run("not happy path: nested team without its parent team", func(t *testing.T) {
+		remote := NewScaffoldGoliacRemoteMock()
+		parentId := 10
+		teams := remote.(*ScaffoldGoliacRemoteMock).teams
+		teams["parent"] = &engine.GithubTeam{Name: "parent", Slug: "parent", Id: 10}
+		teams["child"] = &engine.GithubTeam{Name: "child", Slug: "child", Id: 11, ParentTeam: &parentId}
+
+		logsCollector := observability.NewLogCollection()
+		manifests := generateAdoptManifests(context.TODO(), remote, fixtureAdoptLocal(), []*AdoptResource{{Kind: "team", Name: "child"}}, "admin", logsCollector)
+
+		assert.Equal(t, 1, len(logsCollector.Errors))
+		assert.Contains(t, logsCollector.Errors[0].Error(), "adopt the parent team first")
+		assert.Equal(t, 0, len(manifests))
+	})
+
+	t.Run("not happy path: owner team not managed", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		generateAdoptManifests(context.TODO(), NewScaffoldGoliacRemoteMock(), fixtureAdoptLocal(), []*AdoptResource{{Kind: "repo", Name: "repo1"}}, "unknown", logsCollector)
+
+		assert.True(t, logsCollector.HasErrors())
+	})
+}
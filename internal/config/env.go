@@ -21,12 +21,41 @@ var Config = struct {
 
 	GithubConcurrentThreads int64 `env:"GOLIAC_GITHUB_CONCURRENT_THREADS" envDefault:"5"`
 	GithubCacheTTL          int64 `env:"GOLIAC_GITHUB_CACHE_TTL" envDefault:"86400"`
+	// if set, the Github remote state and the REST responses ETags are persisted in this directory (to warm start after a restart)
+	GithubCacheDir string `env:"GOLIAC_GITHUB_CACHE_DIR" envDefault:""`
+	// maximum number of REST responses kept in the ETag cache (the least recently used are evicted)
+	GithubETagCacheMaxEntries int `env:"GOLIAC_GITHUB_ETAG_CACHE_MAX_ENTRIES" envDefault:"20000"`
 
 	ServerApplyInterval int64  `env:"GOLIAC_SERVER_APPLY_INTERVAL" envDefault:"600"`
 	ServerGitRepository string `env:"GOLIAC_SERVER_GIT_REPOSITORY" envDefault:""`
 	ServerGitBranch     string `env:"GOLIAC_SERVER_GIT_BRANCH" envDefault:"main"`
+	// if set, a YAML file listing the Github organizations (and their teams repository) managed by the server
+	// (instead of GOLIAC_GITHUB_APP_ORGANIZATION, GOLIAC_SERVER_GIT_REPOSITORY and GOLIAC_SERVER_GIT_BRANCH)
+	ServerOrganizationsFile string `env:"GOLIAC_SERVER_ORGANIZATIONS_FILE" envDefault:""`
 	// the name of the CI validating each PR on the teams repsotiry. See scaffold.go for the Github action
 	ServerGitBranchProtectionRequiredCheck string `env:"GOLIAC_SERVER_PR_REQUIRED_CHECK" envDefault:"validate"`
+	// to comment (and update the comment of) each PR on the teams repository with the plan of the changes (needs the Github webhook)
+	// opt-in: it needs the Issues read/write (and Pull requests read) Github App permissions
+	ServerPullRequestPlanComment bool `env:"GOLIAC_SERVER_PR_PLAN_COMMENT" envDefault:"false"`
+	// to request the review of the teams owning the files changed by each PR on the teams repository (needs the Github webhook)
+	// opt-in: it needs the Pull requests and Issues read/write Github App permissions
+	ServerPullRequestReviewers bool `env:"GOLIAC_SERVER_PR_REQUEST_REVIEWERS" envDefault:"false"`
+	// to apply only the resources changed by a push on the teams repository (needs the Github webhook), the periodic apply still reconciles everything
+	// opt-in: the scope of a push doesn't cover every dependency of the changed resources yet
+	ServerTargetedApply bool `env:"GOLIAC_SERVER_TARGETED_APPLY" envDefault:"false"`
+
+	// UI sessions: the cookies are signed and encrypted with the first key, the other keys are still accepted (keys rotation)
+	// if no key is set, random keys are generated (the sessions don't survive a restart, and are not shared between replicas)
+	ServerSessionKeys   []string `env:"GOLIAC_SERVER_SESSION_KEYS" envDefault:"" envSeparator:","`
+	ServerSessionMaxAge int      `env:"GOLIAC_SERVER_SESSION_MAX_AGE" envDefault:"28800"`
+	// true or false (if not set: true, unless GOLIAC_GITHUB_APP_CALLBACK_URL is a http url)
+	ServerSessionSecure string `env:"GOLIAC_SERVER_SESSION_SECURE" envDefault:""`
+	// lax, strict or none
+	ServerSessionSameSite string `env:"GOLIAC_SERVER_SESSION_SAMESITE" envDefault:"lax"`
+	// if set, the sessions are stored (server side) in this directory, and the cookies only contain the session id
+	ServerSessionStoreDir string `env:"GOLIAC_SERVER_SESSION_STORE_DIR" envDefault:""`
+	// how often (seconds) the organization membership of a logged user is checked again
+	ServerSessionMembershipCheckInterval int64 `env:"GOLIAC_SERVER_SESSION_MEMBERSHIP_CHECK_INTERVAL" envDefault:"300"`
 
 	// MaxChangesetsOverride - override the max changesets limitation from the repository config
 	MaxChangesetsOverride bool `env:"GOLIAC_MAX_CHANGESETS_OVERRIDE" envDefault:"false"`
@@ -60,6 +89,15 @@ var Config = struct {
 	// API path => localhost:18000/foo/api/v1"
 	WebPrefix string `env:"GOLIAC_WEB_PREFIX" envDefault:""`
 
+	// scim usersync plugin credentials (a bearer token, or an OAuth client-credentials client id/secret)
+	UserSyncScimToken        string `env:"GOLIAC_USERSYNC_SCIM_TOKEN" envDefault:""`
+	UserSyncScimClientID     string `env:"GOLIAC_USERSYNC_SCIM_CLIENT_ID" envDefault:""`
+	UserSyncScimClientSecret string `env:"GOLIAC_USERSYNC_SCIM_CLIENT_SECRET" envDefault:""`
+
+	// ldap usersync plugin bind credentials
+	UserSyncLdapBindDN       string `env:"GOLIAC_USERSYNC_LDAP_BIND_DN" envDefault:""`
+	UserSyncLdapBindPassword string `env:"GOLIAC_USERSYNC_LDAP_BIND_PASSWORD" envDefault:""`
+
 	// to receive slack notifications on errors
 	SlackToken   string `env:"GOLIAC_SLACK_TOKEN" envDefault:""`
 	SlackChannel string `env:"GOLIAC_SLACK_CHANNEL" envDefault:""`
@@ -69,6 +107,8 @@ var Config = struct {
 	GithubWebhookDedicatedHost string `env:"GOLIAC_GITHUB_WEBHOOK_HOST" envDefault:"localhost"`
 	GithubWebhookDedicatedPort int    `env:"GOLIAC_GITHUB_WEBHOOK_PORT" envDefault:"18001"`
 	GithubWebhookPath          string `env:"GOLIAC_GITHUB_WEBHOOK_PATH" envDefault:"/webhook"`
+	// apply as soon as an organization event reports a change made outside of Goliac
+	GithubWebhookReconcile bool `env:"GOLIAC_GITHUB_WEBHOOK_RECONCILE" envDefault:"false"`
 
 	OpenTelemetryEnabled      bool   `env:"GOLIAC_OPENTELEMETRY_ENABLED" envDefault:"false"`
 	OpenTelemetryGrpcEndpoint string `env:"GOLIAC_OPENTELEMETRY_GRPC_ENDPOINT" envDefault:"localhost:4317"`
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"fmt"
+
 	"gopkg.in/yaml.v3"
 )
 
@@ -21,23 +23,72 @@ type GoliacFeatures struct {
 	ManageOrgCustomProperties   bool `yaml:"manage_org_custom_properties"`
 }
 
+// UserSyncScim configures the scim usersync plugin (see goliac.yaml `usersync.scim`).
+// The credentials are not part of the teams repository, but environment variables.
+type UserSyncScim struct {
+	Url               string   `yaml:"url"`                // SCIM 2.0 base url (the /Users endpoint is appended)
+	GithubIdAttribute string   `yaml:"githubid_attribute"` // attribute holding the Github username (default userName)
+	Filter            string   `yaml:"filter"`             // optional SCIM filter (like 'userType eq "Employee"')
+	TokenUrl          string   `yaml:"token_url"`          // OAuth client-credentials token url (else a bearer token is used)
+	Scopes            []string `yaml:"scopes"`             // OAuth client-credentials scopes
+}
+
+// UserSyncLdap configures the ldap usersync plugin (see goliac.yaml `usersync.ldap`).
+// The bind credentials are not part of the teams repository, but environment variables.
+type UserSyncLdap struct {
+	Url                  string `yaml:"url"`                    // ldaps://host:636 or ldap://host:389
+	StartTLS             bool   `yaml:"start_tls"`              // upgrade a ldap:// connection with StartTLS
+	BaseDN               string `yaml:"base_dn"`                // where to search the users
+	UserFilter           string `yaml:"user_filter"`            // default (objectClass=person)
+	UsernameAttribute    string `yaml:"username_attribute"`     // attribute holding the Goliac username (default uid)
+	GithubIdAttribute    string `yaml:"githubid_attribute"`     // attribute holding the Github username
+	GroupMemberAttribute string `yaml:"group_member_attribute"` // group attribute listing its members (default member)
+	EmailAttribute       string `yaml:"email_attribute"`        // attribute holding the user email (default mail)
+	FullNameAttribute    string `yaml:"fullname_attribute"`     // attribute holding the user full name (default displayName)
+	ManagerAttribute     string `yaml:"manager_attribute"`      // attribute holding the user manager DN (default manager)
+}
+
+// RepositorySettings are repository settings set at the organization level
+// (see goliac.yaml `repository_defaults` and `repository_enforced`). Unset values are ignored.
+type RepositorySettings struct {
+	Visibility          string `yaml:"visibility,omitempty"`
+	DefaultBranchName   string `yaml:"default_branch,omitempty"`
+	AllowAutoMerge      *bool  `yaml:"allow_auto_merge,omitempty"`
+	AllowSquashMerge    *bool  `yaml:"allow_squash_merge,omitempty"`
+	AllowRebaseMerge    *bool  `yaml:"allow_rebase_merge,omitempty"`
+	AllowMergeCommit    *bool  `yaml:"allow_merge_commit,omitempty"`
+	DeleteBranchOnMerge *bool  `yaml:"delete_branch_on_merge,omitempty"`
+	AllowUpdateBranch   *bool  `yaml:"allow_update_branch,omitempty"`
+}
+
 type RepositoryConfig struct {
 	AdminTeam           string `yaml:"admin_team"`
 	EveryoneTeamEnabled bool   `yaml:"everyone_team_enabled"`
+	// usernames of the organization owners (admin role). If empty, the org roles are not managed
+	OrgAdmins []string `yaml:"org_admins"`
 
 	Rulesets                []string
 	MaxChangesets           int `yaml:"max_changesets"`
 	GithubConcurrentThreads int `yaml:"github_concurrent_threads"`
 	UserSync                struct {
-		Plugin string `yaml:"plugin"`
-		Path   string `yaml:"path"`
+		Plugin string            `yaml:"plugin"`
+		Path   string            `yaml:"path"`
+		Args   []string          `yaml:"args"`   // external plugin: arguments of the usersync.path binary
+		Config map[string]string `yaml:"config"` // external plugin: settings sent to the plugin
+		// users removed from the usersync source are first suspended for this number of days (0: removed immediately)
+		OffboardingGraceDays int `yaml:"offboarding_grace_days"`
+		// a sync removing (or suspending) more than this percentage of the users is aborted (default: 50, 0: no limit)
+		MaxRemovalPercent int          `yaml:"max_removal_percent"`
+		Scim              UserSyncScim `yaml:"scim"`
+		Ldap              UserSyncLdap `yaml:"ldap"`
 	}
 	ArchiveOnDelete       bool `yaml:"archive_on_delete"`
 	DestructiveOperations struct {
-		AllowDestructiveRepositories bool `yaml:"repositories"`
-		AllowDestructiveTeams        bool `yaml:"teams"`
-		AllowDestructiveUsers        bool `yaml:"users"`
-		AllowDestructiveRulesets     bool `yaml:"rulesets"`
+		AllowDestructiveRepositories    bool `yaml:"repositories"`
+		AllowDestructiveTeams           bool `yaml:"teams"`
+		AllowDestructiveUsers           bool `yaml:"users"`
+		AllowDestructiveRulesets        bool `yaml:"rulesets"`
+		AllowDestructiveRepositoryRoles bool `yaml:"repository_roles"`
 	} `yaml:"destructive_operations"`
 
 	VisibilityRules struct {
@@ -45,6 +96,11 @@ type RepositoryConfig struct {
 		ForbidPublicRepositoriesExclusions []string `yaml:"forbid_public_repositories_exclusions"`
 	} `yaml:"visibility_rules"`
 
+	// replace the built-in repositories default values (a repository definition still wins)
+	RepositoryDefaults RepositorySettings `yaml:"repository_defaults"`
+	// applied over any repository definition
+	RepositoryEnforced RepositorySettings `yaml:"repository_enforced"`
+
 	Workflows           []string                `yaml:"workflows"`
 	OrgCustomProperties []*GithubCustomProperty `yaml:"org_custom_properties"`
 	Features            GoliacFeatures          `yaml:"features"`
@@ -58,6 +114,7 @@ func (rc *RepositoryConfig) UnmarshalYAML(value *yaml.Node) error {
 	x.MaxChangesets = 50
 	x.GithubConcurrentThreads = 4
 	x.UserSync.Plugin = "noop"
+	x.UserSync.MaxRemovalPercent = 50
 	x.ArchiveOnDelete = true
 	x.Features.ManageGithubEnvAndVariables = true
 	x.Features.ManageGithubAutolinks = true
@@ -67,6 +124,12 @@ func (rc *RepositoryConfig) UnmarshalYAML(value *yaml.Node) error {
 		return err
 	}
 
+	for section, settings := range map[string]RepositorySettings{"repository_defaults": x.RepositoryDefaults, "repository_enforced": x.RepositoryEnforced} {
+		if settings.Visibility != "" && settings.Visibility != "public" && settings.Visibility != "private" && settings.Visibility != "internal" {
+			return fmt.Errorf("invalid %s.visibility: %s (must be public, private or internal)", section, settings.Visibility)
+		}
+	}
+
 	// add org_actors systematically to the org_custom_properties
 	for _, orgProperty := range x.OrgCustomProperties {
 		if orgProperty.ValuesEditableBy == "" {
@@ -0,0 +1,160 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	AccessBypassRuleset             = "ruleset"
+	AccessBypassOrganizationRuleset = "organization_ruleset"
+	AccessBypassBranchProtection    = "branch_protection"
+)
+
+/*
+ * AccessBypass is a ruleset (or branch protection) a user can bypass on a repository
+ */
+type AccessBypass struct {
+	Kind string `json:"kind"`           // ruleset, organization_ruleset, branch_protection
+	Name string `json:"name"`           // the ruleset name, or the branch protection pattern
+	Team string `json:"team,omitempty"` // the team slug allowed to bypass (empty if the user is allowed directly)
+	Via  string `json:"via,omitempty"`  // the (child) team slug the user is a member of
+	Mode string `json:"mode"`           // always, pull_request
+}
+
+func (b AccessBypass) String() string {
+	who := "user"
+	if b.Via != "" {
+		who = fmt.Sprintf("member of team %s (child of team %s)", b.Via, b.Team)
+	} else if b.Team != "" {
+		who = fmt.Sprintf("member of team %s", b.Team)
+	}
+	switch b.Kind {
+	case AccessBypassOrganizationRuleset:
+		return fmt.Sprintf("%s can bypass the organization ruleset %s (%s)", who, b.Name, b.Mode)
+	case AccessBypassBranchProtection:
+		return fmt.Sprintf("%s can bypass the pull request reviews of the branch protection %s", who, b.Name)
+	}
+	return fmt.Sprintf("%s can bypass the ruleset %s (%s)", who, b.Name, b.Mode)
+}
+
+/*
+ * AccessPaths are all the paths granting access to a repository to a user
+ */
+type AccessPaths struct {
+	Permission string         `json:"permission"` // empty if no access
+	Reasons    []AccessReason `json:"reasons"`
+	Bypasses   []AccessBypass `json:"bypasses"`
+}
+
+/*
+ * AccessExplanation explains why a user has access to a repository,
+ * as defined in the teams repository (Local) and as currently in Github (Remote)
+ */
+type AccessExplanation struct {
+	Githubid          string         `json:"githubid"`
+	Username          string         `json:"username,omitempty"`
+	Repository        string         `json:"repository"`
+	Local             *AccessPaths   `json:"local"`
+	Remote            *AccessPaths   `json:"remote"`
+	Unmanaged         []AccessReason `json:"unmanaged"`          // the remote grants not defined in the teams repository
+	UnmanagedBypasses []AccessBypass `json:"unmanaged_bypasses"` // the remote bypasses not defined in the teams repository
+}
+
+/*
+ * ExplainAccessPaths returns all the paths granting access to reponame to the user
+ * (a github login or a Goliac username), see ComputeAccessReport,
+ * and the rulesets/branch protections the user can bypass on the repository.
+ * It returns the githubid of the user
+ */
+func ExplainAccessPaths(datasource GoliacReconciliatorDatasource, collaborators map[string]map[string]string, usernames map[string]string, user string, reponame string) (string, *AccessPaths, error) {
+	resolver, err := newAccessResolver(datasource, usernames)
+	if err != nil {
+		return "", nil, err
+	}
+	githubid := resolver.resolve(user)
+
+	paths := &AccessPaths{
+		Reasons:  []AccessReason{},
+		Bypasses: []AccessBypass{},
+	}
+	if grant, ok := resolver.repositoryGrants(reponame, collaborators)[githubid]; ok {
+		paths.Permission = grant.Permission
+		paths.Reasons = grant.Reasons
+	}
+
+	repo, ok := resolver.repositories[reponame]
+	if !ok {
+		return githubid, paths, nil
+	}
+
+	addTeamBypasses := func(kind, name, teamslug, mode string) {
+		resolver.teamMemberships(teamslug, func(m string, via string) {
+			bypass := AccessBypass{Kind: kind, Name: name, Team: teamslug, Via: via, Mode: mode}
+			if m == githubid && !slices.Contains(paths.Bypasses, bypass) {
+				paths.Bypasses = append(paths.Bypasses, bypass)
+			}
+		})
+	}
+
+	for _, name := range sortedKeys(repo.Rulesets) {
+		rs := repo.Rulesets[name]
+		for _, teamslug := range sortedKeys(rs.BypassTeams) {
+			addTeamBypasses(AccessBypassRuleset, name, teamslug, rs.BypassTeams[teamslug])
+		}
+	}
+
+	rulesets, err := datasource.RuleSets()
+	if err != nil {
+		return "", nil, fmt.Errorf("not able to get the rulesets: %v", err)
+	}
+	for _, name := range sortedKeys(rulesets) {
+		rs := rulesets[name]
+		if !slices.Contains(rs.Repositories, reponame) {
+			continue
+		}
+		for _, teamslug := range sortedKeys(rs.BypassTeams) {
+			addTeamBypasses(AccessBypassOrganizationRuleset, name, teamslug, rs.BypassTeams[teamslug])
+		}
+	}
+
+	for _, pattern := range sortedKeys(repo.BranchProtections) {
+		for _, node := range repo.BranchProtections[pattern].BypassPullRequestAllowances.Nodes {
+			if node.Actor.UserLogin != "" && node.Actor.UserLogin == githubid {
+				paths.Bypasses = append(paths.Bypasses, AccessBypass{Kind: AccessBypassBranchProtection, Name: pattern, Mode: "pull_request"})
+			}
+			if node.Actor.TeamSlug != "" {
+				addTeamBypasses(AccessBypassBranchProtection, pattern, node.Actor.TeamSlug, "pull_request")
+			}
+		}
+	}
+
+	return githubid, paths, nil
+}
+
+/*
+ * NewAccessExplanation compares the local (teams repository) and remote (Github)
+ * access paths, to report the remote grants and bypasses Goliac doesn't manage
+ */
+func NewAccessExplanation(githubid, username, reponame string, local *AccessPaths, remote *AccessPaths) *AccessExplanation {
+	explanation := &AccessExplanation{
+		Githubid:          githubid,
+		Username:          username,
+		Repository:        reponame,
+		Local:             local,
+		Remote:            remote,
+		Unmanaged:         []AccessReason{},
+		UnmanagedBypasses: []AccessBypass{},
+	}
+	for _, r := range remote.Reasons {
+		if !slices.Contains(local.Reasons, r) {
+			explanation.Unmanaged = append(explanation.Unmanaged, r)
+		}
+	}
+	for _, b := range remote.Bypasses {
+		if !slices.Contains(local.Bypasses, b) {
+			explanation.UnmanagedBypasses = append(explanation.UnmanagedBypasses, b)
+		}
+	}
+	return explanation
+}
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainAccess(t *testing.T) {
+	usernames := map[string]string{"alice-gh": "alice", "bob-gh": "bob", "carol-gh": "carol"}
+
+	t.Run("happy path: explain the access of a user", func(t *testing.T) {
+		datasource := fixtureAccessReportDatasource()
+		bp := &GithubBranchProtection{Pattern: "main"}
+		node := BypassPullRequestAllowanceNode{}
+		node.Actor.TeamSlug = "platform"
+		bp.BypassPullRequestAllowances.Nodes = append(bp.BypassPullRequestAllowances.Nodes, node)
+		datasource.repos["repo1"].BranchProtections = map[string]*GithubBranchProtection{"main": bp}
+		datasource.repos["repo1"].Rulesets = map[string]*GithubRuleSet{
+			"protect": {Name: "protect", BypassTeams: map[string]string{"sre": "always"}},
+		}
+		datasource.rulesets = map[string]*GithubRuleSet{
+			"org-default": {Name: "org-default", BypassTeams: map[string]string{"platform": "pull_request"}, Repositories: []string{"repo1"}},
+			"org-other":   {Name: "org-other", BypassTeams: map[string]string{"platform": "always"}, Repositories: []string{"repo2"}},
+		}
+
+		// by Goliac username
+		githubid, paths, err := ExplainAccessPaths(datasource, nil, usernames, "bob", "repo1")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "bob-gh", githubid)
+		assert.Equal(t, "write", paths.Permission)
+		assert.Equal(t, []AccessReason{
+			{Kind: AccessReasonEveryone, Team: "everyone", Permission: "read"},
+			{Kind: AccessReasonParentTeam, Team: "platform", Via: "sre", Permission: "write"},
+		}, paths.Reasons)
+		assert.Equal(t, []AccessBypass{
+			{Kind: AccessBypassRuleset, Name: "protect", Team: "sre", Mode: "always"},
+			{Kind: AccessBypassOrganizationRuleset, Name: "org-default", Team: "platform", Via: "sre", Mode: "pull_request"},
+			{Kind: AccessBypassBranchProtection, Name: "main", Team: "platform", Via: "sre", Mode: "pull_request"},
+		}, paths.Bypasses)
+		assert.Equal(t, "member of team sre (child of team platform) can bypass the organization ruleset org-default (pull_request)", paths.Bypasses[1].String())
+	})
+
+	t.Run("happy path: no access", func(t *testing.T) {
+		githubid, paths, err := ExplainAccessPaths(fixtureAccessReportDatasource(), nil, usernames, "carol-gh", "teams")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "carol-gh", githubid)
+		assert.Equal(t, "", paths.Permission)
+		assert.Equal(t, 0, len(paths.Reasons))
+		assert.Equal(t, 0, len(paths.Bypasses))
+
+		// unknown repository
+		_, paths, err = ExplainAccessPaths(fixtureAccessReportDatasource(), nil, usernames, "carol-gh", "unknown")
+		assert.Nil(t, err)
+		assert.Equal(t, "", paths.Permission)
+	})
+
+	t.Run("happy path: remote grants not managed by Goliac", func(t *testing.T) {
+		_, local, err := ExplainAccessPaths(fixtureAccessReportDatasource(), nil, usernames, "alice-gh", "repo1")
+		assert.Nil(t, err)
+		_, remote, err := ExplainAccessPaths(fixtureAccessReportDatasource(), map[string]map[string]string{"repo1": {"alice-gh": "ADMIN"}}, usernames, "alice-gh", "repo1")
+		assert.Nil(t, err)
+
+		explanation := NewAccessExplanation("alice-gh", "alice", "repo1", local, remote)
+
+		assert.Equal(t, "write", explanation.Local.Permission)
+		assert.Equal(t, "admin", explanation.Remote.Permission)
+		assert.Equal(t, []AccessReason{{Kind: AccessReasonCollaborator, Permission: "admin"}}, explanation.Unmanaged)
+		assert.Equal(t, 0, len(explanation.UnmanagedBypasses))
+	})
+}
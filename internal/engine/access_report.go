@@ -0,0 +1,329 @@
+package engine
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/goliac-project/goliac/internal/config"
+)
+
+const (
+	AccessReasonTeam                 = "team"
+	AccessReasonOwnersTeam           = "owners_team"
+	AccessReasonParentTeam           = "parent_team"
+	AccessReasonEveryone             = "everyone"
+	AccessReasonExternalCollaborator = "external_collaborator"
+	AccessReasonCollaborator         = "collaborator"
+	AccessReasonOrganizationAdmin    = "organization_admin"
+)
+
+/*
+ * AccessGrant is the effective permission of a user on a repository,
+ * and all the reasons granting it
+ */
+type AccessGrant struct {
+	Githubid   string         `json:"githubid"`
+	Username   string         `json:"username,omitempty"` // the Goliac username (if known)
+	Repository string         `json:"repository"`
+	Permission string         `json:"permission"` // read, triage, write, maintain, admin or a custom repository role
+	Reasons    []AccessReason `json:"reasons"`
+}
+
+/*
+ * AccessReason is one path granting a permission on a repository
+ */
+type AccessReason struct {
+	Kind       string `json:"kind"`           // team, owners_team, parent_team, everyone, external_collaborator, collaborator, organization_admin
+	Team       string `json:"team,omitempty"` // the team slug having the permission on the repository
+	Via        string `json:"via,omitempty"`  // for parent_team: the (child) team slug the user is a member of
+	Permission string `json:"permission"`
+}
+
+func (r AccessReason) String() string {
+	switch r.Kind {
+	case AccessReasonTeam:
+		return fmt.Sprintf("member of team %s which is %s", r.Team, r.Permission)
+	case AccessReasonOwnersTeam:
+		return fmt.Sprintf("owner via team %s which is %s", r.Team, r.Permission)
+	case AccessReasonParentTeam:
+		return fmt.Sprintf("member of team %s (child of team %s) which is %s", r.Via, r.Team, r.Permission)
+	case AccessReasonEveryone:
+		return fmt.Sprintf("member of the everyone team which is %s", r.Permission)
+	case AccessReasonExternalCollaborator:
+		return fmt.Sprintf("external collaborator with %s", r.Permission)
+	case AccessReasonCollaborator:
+		return fmt.Sprintf("collaborator with %s", r.Permission)
+	case AccessReasonOrganizationAdmin:
+		return "organization admin"
+	}
+	return fmt.Sprintf("%s %s", r.Kind, r.Permission)
+}
+
+// permission rank of the Github base roles
+var accessPermissionRanks = map[string]int{
+	"read":     1,
+	"triage":   2,
+	"write":    3,
+	"maintain": 4,
+	"admin":    5,
+}
+
+/*
+ * ComputeAccessReport resolves the effective permission of each user on each repository:
+ * - via the teams having access to the repository (and their child teams, that inherit the access)
+ * - via the "-goliac-owners" and the "everyone" teams
+ * - as (external) collaborator of the repository
+ * - as organization admin (admin of every repository)
+ *
+ * collaborators is [repository][githubid]permission, the direct collaborators of the repositories.
+ * If nil, the external collaborators of the datasource repositories are used.
+ * usernames is [githubid]username, used to fill the Goliac usernames
+ * (and to resolve team members defined by username)
+ *
+ * The grants are sorted by repository, then by githubid
+ */
+func ComputeAccessReport(datasource GoliacReconciliatorDatasource, collaborators map[string]map[string]string, usernames map[string]string) ([]*AccessGrant, error) {
+	resolver, err := newAccessResolver(datasource, usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	report := []*AccessGrant{}
+	for _, reponame := range sortedKeys(resolver.repositories) {
+		grants := resolver.repositoryGrants(reponame, collaborators)
+		for _, githubid := range sortedKeys(grants) {
+			report = append(report, grants[githubid])
+		}
+	}
+	return report, nil
+}
+
+/*
+ * accessResolver resolves the teams members (githubids), including the
+ * members of the child teams, of a datasource
+ */
+type accessResolver struct {
+	users        map[string]string // [githubid]role
+	repositories map[string]*GithubRepoComparable
+	roles        map[string]*GithubRepositoryRole
+	usernames    map[string]string   // [githubid]username
+	githubids    map[string]string   // [username]githubid
+	teamMembers  map[string][]string // [teamslug]members githubids
+	teamChildren map[string][]string // [teamslug]child teams slugs
+}
+
+func newAccessResolver(datasource GoliacReconciliatorDatasource, usernames map[string]string) (*accessResolver, error) {
+	teams, _, err := datasource.Teams()
+	if err != nil {
+		return nil, fmt.Errorf("not able to get the teams: %v", err)
+	}
+	repositories, _, err := datasource.Repositories()
+	if err != nil {
+		return nil, fmt.Errorf("not able to get the repositories: %v", err)
+	}
+	roles, err := datasource.RepositoryRoles()
+	if err != nil {
+		return nil, fmt.Errorf("not able to get the repository roles: %v", err)
+	}
+
+	r := &accessResolver{
+		users:        datasource.Users(),
+		repositories: repositories,
+		roles:        roles,
+		usernames:    usernames,
+		githubids:    make(map[string]string),
+		teamMembers:  make(map[string][]string),
+		teamChildren: make(map[string][]string),
+	}
+	for githubid, username := range usernames {
+		r.githubids[username] = githubid
+	}
+
+	for teamslug, team := range teams {
+		members := []string{}
+		for _, m := range append(slices.Clone(team.Members), team.Maintainers...) {
+			githubid := r.resolve(m)
+			if !slices.Contains(members, githubid) {
+				members = append(members, githubid)
+			}
+		}
+		r.teamMembers[teamslug] = members
+		if team.ParentTeam != nil {
+			r.teamChildren[*team.ParentTeam] = append(r.teamChildren[*team.ParentTeam], teamslug)
+		}
+	}
+	return r, nil
+}
+
+/*
+ * resolve returns the githubid of a github login or of a Goliac username
+ */
+func (r *accessResolver) resolve(user string) string {
+	if _, ok := r.users[user]; ok {
+		return user
+	}
+	if githubid, ok := r.githubids[user]; ok {
+		return githubid
+	}
+	return user
+}
+
+/*
+ * descendants returns the (recursive) child teams of a team
+ */
+func (r *accessResolver) descendants(teamslug string) []string {
+	visited := map[string]bool{teamslug: true}
+	result := []string{}
+	queue := slices.Clone(r.teamChildren[teamslug])
+	for len(queue) > 0 {
+		child := queue[0]
+		queue = queue[1:]
+		if visited[child] {
+			continue
+		}
+		visited[child] = true
+		result = append(result, child)
+		queue = append(queue, r.teamChildren[child]...)
+	}
+	return result
+}
+
+/*
+ * teamMemberships returns the githubids of the members of a team, and
+ * for each member of a child team, the child team the member belongs to
+ */
+func (r *accessResolver) teamMemberships(teamslug string, visit func(githubid string, via string)) {
+	for _, m := range r.teamMembers[teamslug] {
+		visit(m, "")
+	}
+	for _, child := range r.descendants(teamslug) {
+		for _, m := range r.teamMembers[child] {
+			visit(m, child)
+		}
+	}
+}
+
+/*
+ * repositoryGrants returns the grants ([githubid]grant) of a repository
+ */
+func (r *accessResolver) repositoryGrants(reponame string, collaborators map[string]map[string]string) map[string]*AccessGrant {
+	grants := make(map[string]*AccessGrant)
+	repo, ok := r.repositories[reponame]
+	if !ok {
+		return grants
+	}
+
+	addGrant := func(githubid string, reason AccessReason) {
+		grant, ok := grants[githubid]
+		if !ok {
+			grant = &AccessGrant{
+				Githubid:   githubid,
+				Username:   r.usernames[githubid],
+				Repository: reponame,
+				Reasons:    []AccessReason{},
+			}
+			grants[githubid] = grant
+		}
+		if slices.Contains(grant.Reasons, reason) {
+			return
+		}
+		grant.Reasons = append(grant.Reasons, reason)
+		if grant.Permission == "" || accessPermissionRank(reason.Permission, r.roles) > accessPermissionRank(grant.Permission, r.roles) {
+			grant.Permission = reason.Permission
+		}
+	}
+	addTeamGrant := func(teamslug, permission string) {
+		kind := AccessReasonTeam
+		if teamslug == "everyone" {
+			kind = AccessReasonEveryone
+		} else if strings.HasSuffix(teamslug, config.Config.GoliacTeamOwnerSuffix) {
+			kind = AccessReasonOwnersTeam
+		}
+		r.teamMemberships(teamslug, func(githubid string, via string) {
+			if via == "" {
+				addGrant(githubid, AccessReason{Kind: kind, Team: teamslug, Permission: permission})
+			} else {
+				addGrant(githubid, AccessReason{Kind: AccessReasonParentTeam, Team: teamslug, Via: via, Permission: permission})
+			}
+		})
+	}
+
+	for _, t := range repo.Readers {
+		addTeamGrant(t, "read")
+	}
+	for _, t := range repo.Triagers {
+		addTeamGrant(t, "triage")
+	}
+	for _, t := range repo.Writers {
+		addTeamGrant(t, "write")
+	}
+	for _, t := range repo.Maintainers {
+		addTeamGrant(t, "maintain")
+	}
+	for _, t := range sortedKeys(repo.CustomRoles) {
+		addTeamGrant(t, repo.CustomRoles[t])
+	}
+
+	if collaborators == nil {
+		for _, c := range repo.ExternalUserReaders {
+			addGrant(c, AccessReason{Kind: AccessReasonExternalCollaborator, Permission: "read"})
+		}
+		for _, c := range repo.ExternalUserWriters {
+			addGrant(c, AccessReason{Kind: AccessReasonExternalCollaborator, Permission: "write"})
+		}
+	} else {
+		for _, c := range sortedKeys(collaborators[reponame]) {
+			kind := AccessReasonExternalCollaborator
+			if _, ok := r.users[c]; ok {
+				kind = AccessReasonCollaborator
+			}
+			addGrant(c, AccessReason{Kind: kind, Permission: strings.ToLower(collaborators[reponame][c])})
+		}
+	}
+
+	for _, githubid := range sortedKeys(r.users) {
+		if r.users[githubid] == "ADMIN" {
+			addGrant(githubid, AccessReason{Kind: AccessReasonOrganizationAdmin, Permission: "admin"})
+		}
+	}
+	return grants
+}
+
+/*
+ * accessPermissionRank returns the rank of a permission
+ * (a custom repository role is ranked as its base role)
+ */
+func accessPermissionRank(permission string, roles map[string]*GithubRepositoryRole) int {
+	if rank, ok := accessPermissionRanks[permission]; ok {
+		return rank
+	}
+	if role, ok := roles[permission]; ok {
+		return accessPermissionRanks[role.BaseRole]
+	}
+	return 0
+}
+
+/*
+ * WriteAccessReportCSV writes the access report as CSV
+ * (githubid, username, repository, permission, reasons)
+ */
+func WriteAccessReportCSV(w io.Writer, report []*AccessGrant) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"githubid", "username", "repository", "permission", "reasons"}); err != nil {
+		return err
+	}
+	for _, grant := range report {
+		reasons := make([]string, 0, len(grant.Reasons))
+		for _, r := range grant.Reasons {
+			reasons = append(reasons, r.String())
+		}
+		if err := writer.Write([]string{grant.Githubid, grant.Username, grant.Repository, grant.Permission, strings.Join(reasons, "; ")}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
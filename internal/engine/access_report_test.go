@@ -0,0 +1,127 @@
+package engine
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fixtureAccessReportDatasource() *ScopeDatasourceMock {
+	parent := "platform"
+	return &ScopeDatasourceMock{
+		users: map[string]string{
+			"alice-gh": "MEMBER",
+			"bob-gh":   "MEMBER",
+			"carol-gh": "MEMBER",
+			"admin-gh": "ADMIN",
+		},
+		teams: map[string]*GithubTeamComparable{
+			"platform":               {Name: "platform", Slug: "platform", Members: []string{"alice-gh"}},
+			"platform-goliac-owners": {Name: "platform-goliac-owners", Slug: "platform-goliac-owners", Members: []string{"alice-gh"}},
+			"sre":                    {Name: "sre", Slug: "sre", Members: []string{"bob-gh"}, ParentTeam: &parent},
+			"auditors":               {Name: "auditors", Slug: "auditors", Maintainers: []string{"carol-gh"}},
+			// the local everyone team members are usernames
+			"everyone": {Name: "everyone", Slug: "everyone", Members: []string{"alice", "bob", "carol"}},
+		},
+		repos: map[string]*GithubRepoComparable{
+			"repo1": {
+				Writers:             []string{"platform"},
+				Readers:             []string{"everyone"},
+				CustomRoles:         map[string]string{"auditors": "auditor"},
+				ExternalUserWriters: []string{"outsider-gh"},
+			},
+			"teams": {Writers: []string{"platform-goliac-owners"}},
+		},
+		roles: map[string]*GithubRepositoryRole{
+			"auditor": {Name: "auditor", BaseRole: "triage"},
+		},
+	}
+}
+
+func TestComputeAccessReport(t *testing.T) {
+	usernames := map[string]string{"alice-gh": "alice", "bob-gh": "bob", "carol-gh": "carol"}
+
+	t.Run("happy path: local access report", func(t *testing.T) {
+		report, err := ComputeAccessReport(fixtureAccessReportDatasource(), nil, usernames)
+
+		assert.Nil(t, err)
+		grants := make(map[string]*AccessGrant)
+		for _, g := range report {
+			grants[g.Repository+"/"+g.Githubid] = g
+		}
+		assert.Equal(t, 7, len(report))
+		// sorted by repository, then githubid
+		assert.Equal(t, "repo1", report[0].Repository)
+		assert.Equal(t, "admin-gh", report[0].Githubid)
+
+		alice := grants["repo1/alice-gh"]
+		assert.Equal(t, "alice", alice.Username)
+		assert.Equal(t, "write", alice.Permission)
+		assert.Equal(t, []AccessReason{
+			{Kind: AccessReasonEveryone, Team: "everyone", Permission: "read"},
+			{Kind: AccessReasonTeam, Team: "platform", Permission: "write"},
+		}, alice.Reasons)
+
+		// inherited from the parent team
+		bob := grants["repo1/bob-gh"]
+		assert.Equal(t, "write", bob.Permission)
+		assert.Equal(t, AccessReason{Kind: AccessReasonParentTeam, Team: "platform", Via: "sre", Permission: "write"}, bob.Reasons[1])
+		assert.Equal(t, "member of team sre (child of team platform) which is write", bob.Reasons[1].String())
+
+		// custom role ranked as its base role
+		carol := grants["repo1/carol-gh"]
+		assert.Equal(t, "auditor", carol.Permission)
+
+		outsider := grants["repo1/outsider-gh"]
+		assert.Equal(t, "write", outsider.Permission)
+		assert.Equal(t, AccessReasonExternalCollaborator, outsider.Reasons[0].Kind)
+
+		admin := grants["teams/admin-gh"]
+		assert.Equal(t, "admin", admin.Permission)
+		assert.Equal(t, AccessReasonOrganizationAdmin, admin.Reasons[0].Kind)
+
+		owner := grants["teams/alice-gh"]
+		assert.Equal(t, AccessReasonOwnersTeam, owner.Reasons[0].Kind)
+	})
+
+	t.Run("happy path: remote collaborators", func(t *testing.T) {
+		collaborators := map[string]map[string]string{
+			"repo1": {"bob-gh": "ADMIN", "outsider-gh": "READ"},
+		}
+
+		report, err := ComputeAccessReport(fixtureAccessReportDatasource(), collaborators, usernames)
+
+		assert.Nil(t, err)
+		grants := make(map[string]*AccessGrant)
+		for _, g := range report {
+			grants[g.Repository+"/"+g.Githubid] = g
+		}
+		assert.Equal(t, "admin", grants["repo1/bob-gh"].Permission)
+		assert.Contains(t, grants["repo1/bob-gh"].Reasons, AccessReason{Kind: AccessReasonCollaborator, Permission: "admin"})
+		assert.Equal(t, "read", grants["repo1/outsider-gh"].Permission)
+		assert.Equal(t, []AccessReason{{Kind: AccessReasonExternalCollaborator, Permission: "read"}}, grants["repo1/outsider-gh"].Reasons)
+	})
+
+	t.Run("happy path: csv export", func(t *testing.T) {
+		report := []*AccessGrant{
+			{
+				Githubid:   "alice-gh",
+				Username:   "alice",
+				Repository: "repo1",
+				Permission: "write",
+				Reasons: []AccessReason{
+					{Kind: AccessReasonEveryone, Team: "everyone", Permission: "read"},
+					{Kind: AccessReasonTeam, Team: "platform", Permission: "write"},
+				},
+			},
+		}
+		var buf bytes.Buffer
+
+		err := WriteAccessReportCSV(&buf, report)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "githubid,username,repository,permission,reasons\n"+
+			"alice-gh,alice,repo1,write,member of the everyone team which is read; member of team platform which is write\n", buf.String())
+	})
+}
@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/goliac-project/goliac/internal/observability"
+)
+
+/*
+ * EnterpriseDefinition is the expected state of a Github enterprise
+ * (see the enterprise section of GOLIAC_SERVER_ORGANIZATIONS_FILE)
+ */
+type EnterpriseDefinition struct {
+	Organizations         []string // organizations that must exist in the enterprise
+	BillingEmail          string   // used to create the missing organizations
+	Admins                []string // github logins, admins of the created organizations
+	Teams                 map[string]*EnterpriseTeamDefinition
+	AllowDestructiveTeams bool // delete the enterprise teams not defined
+}
+
+type EnterpriseTeamDefinition struct {
+	Name          string
+	Members       []string // github logins
+	Organizations []string // organizations the team is assigned to
+}
+
+/*
+ * ReconcileEnterprise creates the missing organizations, and creates/updates/deletes
+ * the enterprise teams (members and organizations assignments)
+ */
+func ReconcileEnterprise(ctx context.Context, logsCollector *observability.LogCollection, remote GoliacRemoteEnterprise, definition *EnterpriseDefinition, dryrun bool) {
+	ReconcileEnterpriseOrganizations(ctx, logsCollector, remote, definition, dryrun)
+	ReconcileEnterpriseTeams(ctx, logsCollector, remote, definition, dryrun)
+}
+
+/*
+ * ReconcileEnterpriseOrganizations creates the missing organizations
+ */
+func ReconcileEnterpriseOrganizations(ctx context.Context, logsCollector *observability.LogCollection, remote GoliacRemoteEnterprise, definition *EnterpriseDefinition, dryrun bool) {
+	existingOrganizations := make(map[string]bool)
+	for _, o := range remote.Organizations() {
+		existingOrganizations[strings.ToLower(o)] = true
+	}
+	for _, o := range definition.Organizations {
+		if existingOrganizations[strings.ToLower(o)] {
+			continue
+		}
+		if definition.BillingEmail == "" || len(definition.Admins) == 0 {
+			logsCollector.AddError(fmt.Errorf("organization %s doesn't exist in the enterprise (set billing_email and admins to create it)", o))
+			continue
+		}
+		logsCollector.AddInfo(map[string]interface{}{"dryrun": dryrun, "command": "create_organization"}, "organization: %s, admins: %s", o, strings.Join(definition.Admins, ","))
+		remote.CreateOrganization(ctx, logsCollector, dryrun, o, definition.BillingEmail, definition.Admins)
+	}
+}
+
+/*
+ * ReconcileEnterpriseTeams creates/updates/deletes the enterprise teams
+ * (members and organizations assignments)
+ */
+func ReconcileEnterpriseTeams(ctx context.Context, logsCollector *observability.LogCollection, remote GoliacRemoteEnterprise, definition *EnterpriseDefinition, dryrun bool) {
+	members := make(map[string]bool)
+	for login := range remote.Members() {
+		members[strings.ToLower(login)] = true
+	}
+	rTeams := remote.Teams()
+
+	for _, teamname := range sortedKeys(definition.Teams) {
+		team := definition.Teams[teamname]
+		rTeam, ok := rTeams[teamname]
+		if !ok {
+			logsCollector.AddInfo(map[string]interface{}{"dryrun": dryrun, "command": "create_enterprise_team"}, "teamname: %s", teamname)
+			remote.CreateTeam(ctx, logsCollector, dryrun, teamname)
+			if rTeam, ok = remote.Teams()[teamname]; !ok {
+				continue
+			}
+		}
+
+		expectedMembers := []string{}
+		for _, m := range team.Members {
+			if !members[strings.ToLower(m)] {
+				logsCollector.AddWarn(fmt.Errorf("enterprise team %s: %s is not a member of the enterprise", teamname, m))
+				continue
+			}
+			expectedMembers = append(expectedMembers, m)
+		}
+		toAdd, toRemove := diffLists(expectedMembers, rTeam.Members)
+		for _, m := range toAdd {
+			logsCollector.AddInfo(map[string]interface{}{"dryrun": dryrun, "command": "update_enterprise_team_add_member"}, "teamname: %s, ghuserid: %s", teamname, m)
+			remote.AddTeamMember(ctx, logsCollector, dryrun, teamname, m)
+		}
+		for _, m := range toRemove {
+			logsCollector.AddInfo(map[string]interface{}{"dryrun": dryrun, "command": "update_enterprise_team_remove_member"}, "teamname: %s, ghuserid: %s", teamname, m)
+			remote.RemoveTeamMember(ctx, logsCollector, dryrun, teamname, m)
+		}
+
+		toAdd, toRemove = diffLists(team.Organizations, rTeam.Organizations)
+		for _, o := range toAdd {
+			logsCollector.AddInfo(map[string]interface{}{"dryrun": dryrun, "command": "update_enterprise_team_add_organization"}, "teamname: %s, organization: %s", teamname, o)
+			remote.AddTeamOrganization(ctx, logsCollector, dryrun, teamname, o)
+		}
+		for _, o := range toRemove {
+			logsCollector.AddInfo(map[string]interface{}{"dryrun": dryrun, "command": "update_enterprise_team_remove_organization"}, "teamname: %s, organization: %s", teamname, o)
+			remote.RemoveTeamOrganization(ctx, logsCollector, dryrun, teamname, o)
+		}
+	}
+
+	for _, teamname := range sortedKeys(rTeams) {
+		if _, ok := definition.Teams[teamname]; ok {
+			continue
+		}
+		if !definition.AllowDestructiveTeams {
+			logsCollector.AddWarn(fmt.Errorf("enterprise team %s is not managed by Goliac", teamname))
+			continue
+		}
+		logsCollector.AddInfo(map[string]interface{}{"dryrun": dryrun, "command": "delete_enterprise_team"}, "teamname: %s", teamname)
+		remote.DeleteTeam(ctx, logsCollector, dryrun, teamname)
+	}
+}
+
+/*
+diffLists returns the (case insensitive) elements to add to current,
+and the ones to remove from current, to get expected
+*/
+func diffLists(expected []string, current []string) ([]string, []string) {
+	contains := func(list []string, s string) bool {
+		return slices.ContainsFunc(list, func(e string) bool { return strings.EqualFold(e, s) })
+	}
+	toAdd := []string{}
+	for _, e := range expected {
+		if !contains(current, e) && !contains(toAdd, e) {
+			toAdd = append(toAdd, e)
+		}
+	}
+	toRemove := []string{}
+	for _, c := range current {
+		if !contains(expected, c) {
+			toRemove = append(toRemove, c)
+		}
+	}
+	return toAdd, toRemove
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
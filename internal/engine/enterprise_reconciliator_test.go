@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileEnterprise(t *testing.T) {
+	loadRemote := func(t *testing.T) (*EnterpriseMockClient, *GoliacRemoteEnterpriseImpl) {
+		client := &EnterpriseMockClient{}
+		remote := NewGoliacRemoteEnterpriseImpl(client, "myenterprise")
+		assert.Nil(t, remote.Load(context.TODO()))
+		return client, remote
+	}
+
+	t.Run("happy path: nothing to do", func(t *testing.T) {
+		client, remote := loadRemote(t)
+		logsCollector := observability.NewLogCollection()
+
+		ReconcileEnterprise(context.TODO(), logsCollector, remote, &EnterpriseDefinition{
+			Organizations: []string{"prod", "OSS"},
+			Teams: map[string]*EnterpriseTeamDefinition{
+				"platform": {Name: "platform", Members: []string{"alice", "bob"}, Organizations: []string{"prod"}},
+			},
+		}, false)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.False(t, logsCollector.HasWarns())
+		assert.Equal(t, 0, len(client.mutations))
+	})
+
+	t.Run("happy path: create the organization and update the teams", func(t *testing.T) {
+		client, remote := loadRemote(t)
+		logsCollector := observability.NewLogCollection()
+
+		ReconcileEnterprise(context.TODO(), logsCollector, remote, &EnterpriseDefinition{
+			Organizations: []string{"prod", "oss", "neworg"},
+			BillingEmail:  "billing@company.com",
+			Admins:        []string{"alice"},
+			Teams: map[string]*EnterpriseTeamDefinition{
+				"platform": {Name: "platform", Members: []string{"alice", "unknown"}, Organizations: []string{"oss"}},
+				"newteam":  {Name: "newteam", Members: []string{"bob"}, Organizations: []string{"prod", "oss"}},
+			},
+		}, false)
+
+		assert.False(t, logsCollector.HasErrors())
+		// unknown is not a member of the enterprise
+		assert.True(t, logsCollector.HasWarns())
+		assert.Equal(t, []string{
+			"createEnterpriseOrganization neworg [alice]",
+			"POST /enterprises/myenterprise/teams",
+			"PUT /enterprises/myenterprise/teams/ent:newteam/memberships/bob",
+			"PUT /enterprises/myenterprise/teams/ent:newteam/organizations/prod",
+			"PUT /enterprises/myenterprise/teams/ent:newteam/organizations/oss",
+			"DELETE /enterprises/myenterprise/teams/ent:platform/memberships/bob",
+			"PUT /enterprises/myenterprise/teams/ent:platform/organizations/oss",
+			"DELETE /enterprises/myenterprise/teams/ent:platform/organizations/prod",
+		}, client.mutations)
+	})
+
+	t.Run("happy path: dryrun", func(t *testing.T) {
+		client, remote := loadRemote(t)
+		logsCollector := observability.NewLogCollection()
+
+		ReconcileEnterprise(context.TODO(), logsCollector, remote, &EnterpriseDefinition{
+			Organizations: []string{"prod"},
+			Teams: map[string]*EnterpriseTeamDefinition{
+				"newteam": {Name: "newteam", Members: []string{"bob"}, Organizations: []string{"prod"}},
+			},
+			AllowDestructiveTeams: true,
+		}, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(client.mutations))
+		assert.Equal(t, 1, len(remote.Teams()))
+		assert.Equal(t, []string{"bob"}, remote.Teams()["newteam"].Members)
+	})
+
+	t.Run("happy path: unmanaged teams are kept", func(t *testing.T) {
+		client, remote := loadRemote(t)
+		logsCollector := observability.NewLogCollection()
+
+		ReconcileEnterprise(context.TODO(), logsCollector, remote, &EnterpriseDefinition{}, false)
+
+		assert.True(t, logsCollector.HasWarns())
+		assert.Equal(t, 0, len(client.mutations))
+
+		ReconcileEnterprise(context.TODO(), logsCollector, remote, &EnterpriseDefinition{AllowDestructiveTeams: true}, false)
+
+		assert.Equal(t, []string{"DELETE /enterprises/myenterprise/teams/ent:platform"}, client.mutations)
+	})
+
+	t.Run("not happy path: missing organization without billing email", func(t *testing.T) {
+		client, remote := loadRemote(t)
+		logsCollector := observability.NewLogCollection()
+
+		ReconcileEnterprise(context.TODO(), logsCollector, remote, &EnterpriseDefinition{
+			Organizations: []string{"neworg"},
+		}, false)
+
+		assert.True(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(client.mutations))
+	})
+}
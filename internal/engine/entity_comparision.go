@@ -3,7 +3,7 @@ package engine
 import "github.com/goliac-project/goliac/internal/config"
 
 type Comparable interface {
-	*GithubTeamComparable | *GithubRepoComparable | *GithubRuleSet | *GithubBranchProtection | *GithubEnvironment | *GithubAutolink | *config.GithubCustomProperty
+	*GithubTeamComparable | *GithubRepoComparable | *GithubRuleSet | *GithubBranchProtection | *GithubEnvironment | *GithubAutolink | *config.GithubCustomProperty | *GithubRepositoryRole
 }
 
 type CompareEqualAB[A Comparable, B Comparable] func(key string, value1 A, value2 B) bool
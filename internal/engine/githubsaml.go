@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/goliac-project/goliac/internal/config"
 	"github.com/goliac-project/goliac/internal/entity"
@@ -23,6 +26,12 @@ query listSamlUsers($orgLogin: String!, $endCursor: String) {
             guid
             samlIdentity {
               nameId
+              givenName
+              familyName
+              emails {
+                value
+                primary
+              }
             }
             user {
               login
@@ -48,7 +57,13 @@ type GraplQLUsersFromGithubOrgSaml struct {
 						Node struct {
 							Guid         string
 							SamlIdentity struct {
-								NameId string
+								NameId     string
+								GivenName  string
+								FamilyName string
+								Emails     []struct {
+									Value   string
+									Primary bool
+								}
 							}
 							User struct {
 								Login string
@@ -79,11 +94,11 @@ type GraplQLUsersFromGithubOrgSaml struct {
 /*
  * This function works only for Github organization that have the Entreprise plan ANAD use SAML integration
  */
-func LoadUsersFromGithubOrgSaml(ctx context.Context, client github.GitHubClient, feedback observability.RemoteObservability) (map[string]*entity.User, error) {
+func LoadUsersFromGithubOrgSaml(ctx context.Context, client github.GitHubClient, orgname string, feedback observability.RemoteObservability) (map[string]*entity.User, error) {
 	users := make(map[string]*entity.User)
 
 	variables := make(map[string]interface{})
-	variables["orgLogin"] = config.Config.GithubAppOrganization
+	variables["orgLogin"] = orgname
 	variables["endCursor"] = nil
 
 	hasNextPage := true
@@ -118,6 +133,15 @@ func LoadUsersFromGithubOrgSaml(ctx context.Context, client github.GitHubClient,
 			user.Kind = "User"
 			user.Name = c.Node.SamlIdentity.NameId
 			user.Spec.GithubID = c.Node.User.Login
+			user.Spec.FullName = strings.TrimSpace(c.Node.SamlIdentity.GivenName + " " + c.Node.SamlIdentity.FamilyName)
+			for _, email := range c.Node.SamlIdentity.Emails {
+				if email.Primary || user.Spec.Email == "" {
+					user.Spec.Email = email.Value
+				}
+			}
+			if user.Spec.Email == "" && strings.Contains(c.Node.SamlIdentity.NameId, "@") {
+				user.Spec.Email = c.Node.SamlIdentity.NameId
+			}
 
 			users[c.Node.SamlIdentity.NameId] = user
 		}
@@ -142,7 +166,7 @@ func LoadUsersFromGithubOrgSaml(ctx context.Context, client github.GitHubClient,
 /*
 Listing only pending invitations for the organization for login (and not email)
 */
-func LoadGithubLoginPendingInvitations(ctx context.Context, client github.GitHubClient) (map[string]bool, error) {
+func LoadGithubLoginPendingInvitations(ctx context.Context, client github.GitHubClient, orgname string) (map[string]bool, error) {
 
 	logrus.Debug("loading pending invitations")
 	type PendingInvitation struct {
@@ -150,7 +174,7 @@ func LoadGithubLoginPendingInvitations(ctx context.Context, client github.GitHub
 		Email string
 	}
 	// https://docs.github.com/en/rest/orgs/members?apiVersion=2022-11-28#list-pending-organization-invitations
-	body, err := client.CallRestAPI(ctx, fmt.Sprintf("/orgs/%s/invitations", config.Config.GithubAppOrganization),
+	body, err := client.CallRestAPI(ctx, fmt.Sprintf("/orgs/%s/invitations", orgname),
 		"",
 		"GET",
 		nil,
@@ -175,3 +199,141 @@ func LoadGithubLoginPendingInvitations(ctx context.Context, client github.GitHub
 
 	return users, nil
 }
+
+/*
+TeamSyncGroupsCache keeps the IdP group mappings of the Github teams between
+two syncs: listing them costs one API call per team, and they rarely change.
+The mappings are reloaded every GOLIAC_GITHUB_CACHE_TTL seconds.
+*/
+type TeamSyncGroupsCache struct {
+	mutex       sync.Mutex
+	groupsTeams map[string][]string // [group name or id][]team slug
+	ttlExpire   time.Time
+}
+
+func NewTeamSyncGroupsCache() *TeamSyncGroupsCache {
+	return &TeamSyncGroupsCache{
+		ttlExpire: time.Now(),
+	}
+}
+
+/*
+LoadTeamSyncGroupsMembers returns the Github logins member of the IdP groups
+([group][]githubid), based on the team synchronization of Github Enterprise:
+a Github team mapped to an IdP group (by group name or id) has the group
+members as members. Groups not mapped to any team are missing from the result.
+The team mappings are taken from the cache (if any) while it is fresh, and
+the members of a team are listed once, even if it is mapped to several groups.
+*/
+func LoadTeamSyncGroupsMembers(ctx context.Context, client github.GitHubClient, orgname string, groups []string, cache *TeamSyncGroupsCache) (map[string][]string, error) {
+	var groupsTeams map[string][]string
+	if cache != nil {
+		cache.mutex.Lock()
+		defer cache.mutex.Unlock()
+		if time.Now().Before(cache.ttlExpire) {
+			groupsTeams = cache.groupsTeams
+		}
+	}
+	if groupsTeams == nil {
+		var err error
+		groupsTeams, err = loadTeamSyncGroupsTeams(ctx, client, orgname)
+		if err != nil {
+			return nil, err
+		}
+		if cache != nil {
+			cache.groupsTeams = groupsTeams
+			cache.ttlExpire = time.Now().Add(time.Duration(config.Config.GithubCacheTTL) * time.Second)
+		}
+	}
+
+	teamsMembers := make(map[string][]string)
+	members := make(map[string][]string)
+	for _, group := range groups {
+		slugs, ok := groupsTeams[group]
+		if !ok {
+			continue
+		}
+		if _, ok := members[group]; ok {
+			continue
+		}
+		members[group] = []string{}
+		for _, slug := range slugs {
+			logins, ok := teamsMembers[slug]
+			if !ok {
+				var err error
+				logins, err = listRestAttributeValues(ctx, client, fmt.Sprintf("/orgs/%s/teams/%s/members", orgname, slug), "login")
+				if err != nil {
+					return nil, fmt.Errorf("not able to list the members of team %s: %v", slug, err)
+				}
+				teamsMembers[slug] = logins
+			}
+			members[group] = append(members[group], logins...)
+		}
+	}
+	return members, nil
+}
+
+/*
+loadTeamSyncGroupsTeams returns the Github teams mapped to each IdP group
+([group name or id][]team slug)
+*/
+func loadTeamSyncGroupsTeams(ctx context.Context, client github.GitHubClient, orgname string) (map[string][]string, error) {
+	type TeamSyncGroup struct {
+		GroupId   string `json:"group_id"`
+		GroupName string `json:"group_name"`
+	}
+	type TeamSyncGroupMappings struct {
+		Groups []TeamSyncGroup `json:"groups"`
+	}
+
+	// https://docs.github.com/en/enterprise-cloud@latest/rest/teams/team-sync#list-idp-groups-for-a-team
+	slugs, err := listRestAttributeValues(ctx, client, fmt.Sprintf("/orgs/%s/teams", orgname), "slug")
+	if err != nil {
+		return nil, fmt.Errorf("not able to list teams: %v", err)
+	}
+	groupsTeams := make(map[string][]string)
+	for _, slug := range slugs {
+		body, err := client.CallRestAPI(ctx, fmt.Sprintf("/orgs/%s/teams/%s/team-sync/group-mappings", orgname, slug), "", "GET", nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("not able to list the IdP groups of team %s: %v", slug, err)
+		}
+		var mappings TeamSyncGroupMappings
+		if err := json.Unmarshal(body, &mappings); err != nil {
+			return nil, fmt.Errorf("not able to list the IdP groups of team %s: %v", slug, err)
+		}
+		for _, group := range mappings.Groups {
+			groupsTeams[group.GroupName] = append(groupsTeams[group.GroupName], slug)
+			if group.GroupId != group.GroupName {
+				groupsTeams[group.GroupId] = append(groupsTeams[group.GroupId], slug)
+			}
+		}
+	}
+	return groupsTeams, nil
+}
+
+/*
+listRestAttributeValues pages through a REST endpoint returning an array of objects,
+and returns the given (string) attribute of each object
+*/
+func listRestAttributeValues(ctx context.Context, client github.GitHubClient, endpoint string, attribute string) ([]string, error) {
+	values := []string{}
+	for page := 1; page <= 100; page++ {
+		body, err := client.CallRestAPI(ctx, endpoint, fmt.Sprintf("page=%d&per_page=100", page), "GET", nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		var objects []map[string]interface{}
+		if err := json.Unmarshal(body, &objects); err != nil {
+			return nil, err
+		}
+		for _, object := range objects {
+			if value, ok := object[attribute].(string); ok {
+				values = append(values, value)
+			}
+		}
+		if len(objects) < 100 {
+			break
+		}
+	}
+	return values, nil
+}
@@ -2,7 +2,9 @@ package engine
 
 import (
 	"context"
+	"fmt"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -39,7 +41,13 @@ func (c *GithubSamlGitHubClient) QueryGraphQLAPI(ctx context.Context, query stri
 									"node": {
 										"guid": "guid1",
 										"samlIdentity": {
-											"nameId": "username1"
+											"nameId": "username1",
+											"givenName": "User",
+											"familyName": "One",
+											"emails": [
+												{"value": "username1@other.com", "primary": false},
+												{"value": "username1@company.com", "primary": true}
+											]
 										},
 										"user": {
 											"login": "githubid1"
@@ -50,7 +58,7 @@ func (c *GithubSamlGitHubClient) QueryGraphQLAPI(ctx context.Context, query stri
 									"node": {
 										"guid": "guid2",
 										"samlIdentity": {
-											"nameId": "username2"
+											"nameId": "username2@company.com"
 										},
 										"user": {
 											"login": "githubid2"
@@ -113,8 +121,75 @@ func TestLoadUsersFromGithubOrgSaml(t *testing.T) {
 	t.Run("happy path: load users from Enterprise Github", func(t *testing.T) {
 		client := NewGithubSamlGitHubClient()
 		ctx := context.TODO()
-		users, err := LoadUsersFromGithubOrgSaml(ctx, client, nil)
+		users, err := LoadUsersFromGithubOrgSaml(ctx, client, "myorg", nil)
 		assert.Nil(t, err)
 		assert.Equal(t, 4, len(users))
+		assert.Equal(t, "username1@company.com", users["username1"].Spec.Email)
+		assert.Equal(t, "User One", users["username1"].Spec.FullName)
+		assert.Equal(t, "username2@company.com", users["username2@company.com"].Spec.Email)
+		assert.Equal(t, "", users["username3"].Spec.Email)
+	})
+}
+
+type TeamSyncGitHubClient struct {
+	GithubSamlGitHubClient
+	calls map[string]int
+}
+
+func (c *TeamSyncGitHubClient) CallRestAPI(ctx context.Context, endpoint, parameters, method string, body map[string]interface{}, githubToken *string) ([]byte, error) {
+	if c.calls == nil {
+		c.calls = make(map[string]int)
+	}
+	c.calls[endpoint]++
+	switch {
+	case strings.HasSuffix(endpoint, "/teams"):
+		return []byte(`[{"slug": "team1"}, {"slug": "team2"}, {"slug": "team3"}]`), nil
+	case strings.HasSuffix(endpoint, "/team1/team-sync/group-mappings"):
+		return []byte(`{"groups": [{"group_id": "123", "group_name": "Engineering"}]}`), nil
+	case strings.HasSuffix(endpoint, "/team2/team-sync/group-mappings"):
+		return []byte(`{"groups": [{"group_id": "456", "group_name": "Sales"}]}`), nil
+	case strings.HasSuffix(endpoint, "/team-sync/group-mappings"):
+		return []byte(`{"groups": []}`), nil
+	case strings.HasSuffix(endpoint, "/team1/members"):
+		return []byte(`[{"login": "githubid1"}, {"login": "githubid2"}]`), nil
+	case strings.HasSuffix(endpoint, "/team2/members"):
+		return []byte(`[{"login": "githubid3"}]`), nil
+	}
+	return nil, fmt.Errorf("unexpected endpoint %s", endpoint)
+}
+
+func TestLoadTeamSyncGroupsMembers(t *testing.T) {
+	t.Run("happy path: groups mapped to teams", func(t *testing.T) {
+		client := &TeamSyncGitHubClient{}
+		members, err := LoadTeamSyncGroupsMembers(context.TODO(), client, "myorg", []string{"Engineering", "456", "Unknown"}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(members))
+		assert.Equal(t, []string{"githubid1", "githubid2"}, members["Engineering"])
+		assert.Equal(t, []string{"githubid3"}, members["456"])
+	})
+
+	t.Run("happy path: team members listed once", func(t *testing.T) {
+		client := &TeamSyncGitHubClient{}
+		members, err := LoadTeamSyncGroupsMembers(context.TODO(), client, "myorg", []string{"Engineering", "123"}, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"githubid1", "githubid2"}, members["Engineering"])
+		assert.Equal(t, []string{"githubid1", "githubid2"}, members["123"])
+		assert.Equal(t, 1, client.calls["/orgs/myorg/teams/team1/members"])
+	})
+
+	t.Run("happy path: team mappings taken from the cache", func(t *testing.T) {
+		client := &TeamSyncGitHubClient{}
+		cache := NewTeamSyncGroupsCache()
+		members, err := LoadTeamSyncGroupsMembers(context.TODO(), client, "myorg", []string{"Engineering"}, cache)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"githubid1", "githubid2"}, members["Engineering"])
+
+		members, err = LoadTeamSyncGroupsMembers(context.TODO(), client, "myorg", []string{"Sales"}, cache)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"githubid3"}, members["Sales"])
+
+		assert.Equal(t, 1, client.calls["/orgs/myorg/teams"])
+		assert.Equal(t, 1, client.calls["/orgs/myorg/teams/team1/team-sync/group-mappings"])
+		assert.Equal(t, 1, client.calls["/orgs/myorg/teams/team3/team-sync/group-mappings"])
 	})
 }
@@ -3,6 +3,8 @@ package engine
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"sort"
 	"strings"
 
@@ -113,6 +115,15 @@ func (r *GoliacReconciliatorImpl) Reconciliate(ctx context.Context, logsCollecto
 		return nil, nil, nil, err
 	}
 
+	if isEnterprise {
+		// custom repository roles must exist before being assigned to the repositories' teams
+		err = r.reconciliateRepositoryRoles(ctx, logsCollector, local, rremote, dryrun)
+		if err != nil {
+			r.Rollback(ctx, logsCollector, dryrun, err)
+			return nil, nil, nil, err
+		}
+	}
+
 	reposToArchive, reposToRename, err := r.reconciliateRepositories(ctx, logsCollector, local, rremote, dryrun, manageGithubVariables, manageGithubAutolinks, manageOrgCustomProperties)
 	if err != nil {
 		r.Rollback(ctx, logsCollector, dryrun, err)
@@ -127,7 +138,8 @@ func (r *GoliacReconciliatorImpl) Reconciliate(ctx context.Context, logsCollecto
 		}
 	}
 
-	if manageOrgCustomProperties {
+	// the organization custom properties definitions are not part of a targeted apply
+	if _, scoped := local.(*GoliacReconciliatorDatasourceScoped); manageOrgCustomProperties && !scoped {
 		err = r.reconciliateOrgCustomProperties(ctx, logsCollector, rremote, r.repoconfig, dryrun)
 		if err != nil {
 			r.Rollback(ctx, logsCollector, dryrun, err)
@@ -149,7 +161,7 @@ func (r *GoliacReconciliatorImpl) reconciliateUsers(ctx context.Context, logsCol
 		rUsers[rUser] = membership
 	}
 
-	for _, lUser := range local.Users() {
+	for lUser := range local.Users() {
 		_, ok := rUsers[lUser]
 		if !ok {
 			// deal with non existing remote user
@@ -164,9 +176,59 @@ func (r *GoliacReconciliatorImpl) reconciliateUsers(ctx context.Context, logsCol
 		// DELETE User
 		r.RemoveUserFromOrg(ctx, logsCollector, dryrun, remote, rUser)
 	}
+
+	// a targeted apply only sees the owners inside its scope
+	if _, scoped := local.(*GoliacReconciliatorDatasourceScoped); len(r.repoconfig.OrgAdmins) > 0 && !scoped {
+		r.reconciliateUsersOrgRole(ctx, logsCollector, local, remote, dryrun)
+	}
 	return nil
 }
 
+/*
+ * reconciliateUsersOrgRole promotes the org_admins users to organization owners
+ * and demotes the other (managed) owners to members.
+ * The promotions are done first, and the last organization owner is never demoted
+ */
+func (r *GoliacReconciliatorImpl) reconciliateUsersOrgRole(ctx context.Context, logsCollector *observability.LogCollection, local GoliacReconciliatorDatasource, remote *MutableGoliacRemoteImpl, dryrun bool) {
+	lUsers := local.Users()
+	rUsers := remote.Users()
+
+	toPromote := []string{}
+	toDemote := []string{}
+	for lUser, lRole := range lUsers {
+		rRole, ok := rUsers[lUser]
+		if !ok || rRole == lRole {
+			continue
+		}
+		if lRole == "ADMIN" {
+			toPromote = append(toPromote, lUser)
+		} else {
+			toDemote = append(toDemote, lUser)
+		}
+	}
+	sort.Strings(toPromote)
+	sort.Strings(toDemote)
+
+	for _, user := range toPromote {
+		r.UpdateUserOrgRole(ctx, logsCollector, dryrun, remote, user, "admin")
+	}
+
+	nbAdmins := 0
+	for _, role := range remote.Users() {
+		if role == "ADMIN" {
+			nbAdmins++
+		}
+	}
+	for _, user := range toDemote {
+		if nbAdmins <= 1 {
+			logsCollector.AddError(fmt.Errorf("not demoting %s: it is the last organization owner", user))
+			continue
+		}
+		r.UpdateUserOrgRole(ctx, logsCollector, dryrun, remote, user, "member")
+		nbAdmins--
+	}
+}
+
 type GithubTeamComparable struct {
 	Name              string
 	Slug              string
@@ -340,9 +402,12 @@ type GithubRepoComparable struct {
 	BoolProperties             map[string]bool
 	Writers                    []string
 	Readers                    []string
-	ExternalUserReaders        []string // githubids
-	ExternalUserWriters        []string // githubids
-	InternalUsers              []string // githubids
+	Maintainers                []string
+	Triagers                   []string
+	CustomRoles                map[string]string // [teamslug]custom repository role name
+	ExternalUserReaders        []string          // githubids
+	ExternalUserWriters        []string          // githubids
+	InternalUsers              []string          // githubids
 	Rulesets                   map[string]*GithubRuleSet
 	BranchProtections          map[string]*GithubBranchProtection
 	DefaultBranchName          string
@@ -359,6 +424,52 @@ type GithubRepoComparable struct {
 	// not comparable
 	IsFork   bool
 	ForkFrom string
+	Enforced []EnforcedSetting // definition values overridden by the goliac.yaml repository_enforced settings
+}
+
+/*
+ * EnforcedSetting is a repository definition value overridden by
+ * the goliac.yaml repository_enforced settings
+ */
+type EnforcedSetting struct {
+	Name       string      // visibility, default_branch, or a boolean property (like allow_merge_commit)
+	Value      interface{} // the enforced value
+	Definition interface{} // the repository definition value
+}
+
+func (e EnforcedSetting) String() string {
+	return fmt.Sprintf("%s: %v (instead of %v)", e.Name, e.Value, e.Definition)
+}
+
+/*
+ * drifted returns true if the (remote) repository doesn't have the enforced value yet
+ */
+func (e EnforcedSetting) drifted(rRepo *GithubRepoComparable) bool {
+	if rRepo == nil {
+		return true
+	}
+	switch e.Name {
+	case "visibility":
+		return rRepo.Visibility != e.Value
+	case "default_branch":
+		return rRepo.DefaultBranchName != e.Value
+	default:
+		return rRepo.BoolProperties[e.Name] != e.Value
+	}
+}
+
+/*
+ * repositoryTeamHasAccess returns true if the team has any permission on the repository
+ */
+func repositoryTeamHasAccess(repo *GithubRepoComparable, teamslug string) bool {
+	if slices.Contains(repo.Readers, teamslug) ||
+		slices.Contains(repo.Writers, teamslug) ||
+		slices.Contains(repo.Maintainers, teamslug) ||
+		slices.Contains(repo.Triagers, teamslug) {
+		return true
+	}
+	_, ok := repo.CustomRoles[teamslug]
+	return ok
 }
 
 type GithubEnvironment struct {
@@ -415,6 +526,23 @@ func (r *GoliacReconciliatorImpl) reconciliateRepositories(
 	// let's get the remote now
 	rRepos := remote.Repositories()
 
+	// let's report the definition values overridden by the repository_enforced settings
+	// (only when they are about to be applied, not at every run)
+	enforcedRepos := make([]string, 0, len(lRepos))
+	for reponame, lRepo := range lRepos {
+		if len(lRepo.Enforced) > 0 {
+			enforcedRepos = append(enforcedRepos, reponame)
+		}
+	}
+	sort.Strings(enforcedRepos)
+	for _, reponame := range enforcedRepos {
+		for _, enforced := range lRepos[reponame].Enforced {
+			if enforced.drifted(rRepos[reponame]) {
+				logsCollector.AddWarn(fmt.Errorf("repository %s: enforced %s (goliac.yaml repository_enforced)", reponame, enforced))
+			}
+		}
+	}
+
 	// Rename GitHub repos whose name matches a local repo name case-insensitively
 	// but not exactly, so the subsequent CompareEntities diff works correctly.
 	lowerToRemote := make(map[string]string, len(rRepos))
@@ -628,6 +756,18 @@ func (r *GoliacReconciliatorImpl) reconciliateRepositories(
 			return false
 		}
 
+		if res, _, _ := entity.StringArrayEquivalent(lRepo.Maintainers, rRepo.Maintainers); !res {
+			return false
+		}
+
+		if res, _, _ := entity.StringArrayEquivalent(lRepo.Triagers, rRepo.Triagers); !res {
+			return false
+		}
+
+		if !maps.Equal(lRepo.CustomRoles, rRepo.CustomRoles) {
+			return false
+		}
+
 		if len(rRepo.InternalUsers) != 0 {
 			return false
 		}
@@ -703,6 +843,41 @@ func (r *GoliacReconciliatorImpl) reconciliateRepositories(
 			}
 		}
 
+		if res, maintainToRemove, maintainToAdd := entity.StringArrayEquivalent(lRepo.Maintainers, rRepo.Maintainers); !res {
+			for _, teamSlug := range maintainToAdd {
+				r.UpdateRepositoryAddTeamAccess(ctx, logsCollector, dryrun, remote, reponame, teamSlug, "maintain")
+			}
+			for _, teamSlug := range maintainToRemove {
+				// the team may just have moved to another permission
+				if !repositoryTeamHasAccess(lRepo, teamSlug) {
+					r.UpdateRepositoryRemoveTeamAccess(ctx, logsCollector, dryrun, remote, reponame, teamSlug)
+				}
+			}
+		}
+
+		if res, triageToRemove, triageToAdd := entity.StringArrayEquivalent(lRepo.Triagers, rRepo.Triagers); !res {
+			for _, teamSlug := range triageToAdd {
+				r.UpdateRepositoryAddTeamAccess(ctx, logsCollector, dryrun, remote, reponame, teamSlug, "triage")
+			}
+			for _, teamSlug := range triageToRemove {
+				if !repositoryTeamHasAccess(lRepo, teamSlug) {
+					r.UpdateRepositoryRemoveTeamAccess(ctx, logsCollector, dryrun, remote, reponame, teamSlug)
+				}
+			}
+		}
+
+		// custom repository roles
+		for _, teamSlug := range slices.Sorted(maps.Keys(lRepo.CustomRoles)) {
+			if rRepo.CustomRoles[teamSlug] != lRepo.CustomRoles[teamSlug] {
+				r.UpdateRepositoryAddTeamAccess(ctx, logsCollector, dryrun, remote, reponame, teamSlug, lRepo.CustomRoles[teamSlug])
+			}
+		}
+		for _, teamSlug := range slices.Sorted(maps.Keys(rRepo.CustomRoles)) {
+			if !repositoryTeamHasAccess(lRepo, teamSlug) {
+				r.UpdateRepositoryRemoveTeamAccess(ctx, logsCollector, dryrun, remote, reponame, teamSlug)
+			}
+		}
+
 		// internal users
 		for _, internalUser := range rRepo.InternalUsers {
 			r.UpdateRepositoryRemoveInternalUser(ctx, logsCollector, dryrun, remote, reponame, internalUser)
@@ -833,6 +1008,16 @@ func (r *GoliacReconciliatorImpl) reconciliateRepositories(
 			} else {
 				r.CreateRepository(ctx, logsCollector, dryrun, remote, reponame, reponame, lRepo.Visibility, lRepo.Writers, lRepo.Readers, lRepo.BoolProperties, lRepo.DefaultBranchName, "")
 			}
+			// the other permissions are not part of the repository creation
+			for _, teamSlug := range lRepo.Maintainers {
+				r.UpdateRepositoryAddTeamAccess(ctx, logsCollector, dryrun, remote, reponame, teamSlug, "maintain")
+			}
+			for _, teamSlug := range lRepo.Triagers {
+				r.UpdateRepositoryAddTeamAccess(ctx, logsCollector, dryrun, remote, reponame, teamSlug, "triage")
+			}
+			for _, teamSlug := range slices.Sorted(maps.Keys(lRepo.CustomRoles)) {
+				r.UpdateRepositoryAddTeamAccess(ctx, logsCollector, dryrun, remote, reponame, teamSlug, lRepo.CustomRoles[teamSlug])
+			}
 			if !lRepo.BoolProperties["archived"] {
 				r.reconciliateRepositoryGithubPages(ctx, logsCollector, dryrun, remote, reponame, lRepo.GithubPages, nil)
 			}
@@ -1059,6 +1244,51 @@ func (r *GoliacReconciliatorImpl) reconciliateRulesets(ctx context.Context, logs
 	return nil
 }
 
+func compareRepositoryRoles(rolename string, lRole *GithubRepositoryRole, rRole *GithubRepositoryRole) bool {
+	if lRole.Description != rRole.Description {
+		return false
+	}
+	if lRole.BaseRole != rRole.BaseRole {
+		return false
+	}
+	if res, _, _ := entity.StringArrayEquivalent(lRole.Permissions, rRole.Permissions); !res {
+		return false
+	}
+	return true
+}
+
+func (r *GoliacReconciliatorImpl) reconciliateRepositoryRoles(ctx context.Context, logsCollector *observability.LogCollection, local GoliacReconciliatorDatasource, remote *MutableGoliacRemoteImpl, dryrun bool) error {
+	lRoles, err := local.RepositoryRoles()
+	if err != nil {
+		return err
+	}
+
+	// prepare remote comparable
+	rRoles := remote.RepositoryRoles()
+
+	// prepare the diff computation
+
+	onAdded := func(rolename string, lRole *GithubRepositoryRole, rRole *GithubRepositoryRole) {
+		// CREATE repository role
+		r.AddRepositoryRole(ctx, logsCollector, dryrun, lRole)
+	}
+
+	onRemoved := func(rolename string, lRole *GithubRepositoryRole, rRole *GithubRepositoryRole) {
+		// DELETE repository role
+		r.DeleteRepositoryRole(ctx, logsCollector, dryrun, rRole)
+	}
+
+	onChanged := func(rolename string, lRole *GithubRepositoryRole, rRole *GithubRepositoryRole) {
+		// UPDATE repository role
+		lRole.Id = rRole.Id
+		r.UpdateRepositoryRole(ctx, logsCollector, dryrun, lRole)
+	}
+
+	CompareEntities(lRoles, rRoles, compareRepositoryRoles, onAdded, onRemoved, onChanged)
+
+	return nil
+}
+
 func compareOrgCustomProperties(propertyName string, lProperty *config.GithubCustomProperty, rProperty *config.GithubCustomProperty) bool {
 	if lProperty.PropertyName != rProperty.PropertyName {
 		return false
@@ -1135,6 +1365,14 @@ func (r *GoliacReconciliatorImpl) RemoveUserFromOrg(ctx context.Context, logsCol
 	}
 }
 
+func (r *GoliacReconciliatorImpl) UpdateUserOrgRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, remote *MutableGoliacRemoteImpl, ghuserid string, role string) {
+	logsCollector.AddInfo(map[string]interface{}{"dryrun": dryrun, "command": "update_user_org_role"}, "ghuserid: %s, role: %s", ghuserid, role)
+	remote.UpdateUserOrgRole(ghuserid, role)
+	if r.executor != nil {
+		r.executor.UpdateUserOrgRole(ctx, logsCollector, dryrun, ghuserid, role)
+	}
+}
+
 func (r *GoliacReconciliatorImpl) CreateTeam(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, remote *MutableGoliacRemoteImpl, teamname string, description string, parentTeam *int, members []string) {
 	parentTeamId := "nil"
 	if parentTeam != nil {
@@ -1479,6 +1717,27 @@ func (r *GoliacReconciliatorImpl) DeleteRepositoryGithubPages(ctx context.Contex
 	}
 }
 
+func (r *GoliacReconciliatorImpl) AddRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, role *GithubRepositoryRole) {
+	logsCollector.AddInfo(map[string]interface{}{"dryrun": dryrun, "command": "add_repository_role"}, "repository role: %s, base_role: %s, permissions: %s", role.Name, role.BaseRole, strings.Join(role.Permissions, ","))
+	if r.executor != nil {
+		r.executor.AddRepositoryRole(ctx, logsCollector, dryrun, role)
+	}
+}
+func (r *GoliacReconciliatorImpl) UpdateRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, role *GithubRepositoryRole) {
+	logsCollector.AddInfo(map[string]interface{}{"dryrun": dryrun, "command": "update_repository_role"}, "repository role: %s (id: %d), base_role: %s, permissions: %s", role.Name, role.Id, role.BaseRole, strings.Join(role.Permissions, ","))
+	if r.executor != nil {
+		r.executor.UpdateRepositoryRole(ctx, logsCollector, dryrun, role)
+	}
+}
+func (r *GoliacReconciliatorImpl) DeleteRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, role *GithubRepositoryRole) {
+	if r.repoconfig.DestructiveOperations.AllowDestructiveRepositoryRoles {
+		logsCollector.AddInfo(map[string]interface{}{"dryrun": dryrun, "command": "delete_repository_role"}, "repository role: %s (id: %d)", role.Name, role.Id)
+		if r.executor != nil {
+			r.executor.DeleteRepositoryRole(ctx, logsCollector, dryrun, role.Id)
+		}
+	}
+}
+
 func (r *GoliacReconciliatorImpl) CreateOrUpdateOrgCustomProperty(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, remote *MutableGoliacRemoteImpl, property *config.GithubCustomProperty) {
 	logsCollector.AddInfo(map[string]interface{}{"dryrun": dryrun, "command": "create_or_update_org_custom_property"}, "property: %s, value_type: %s", property.PropertyName, property.ValueType)
 	remote.CreateOrUpdateOrgCustomProperty(property)
@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/goliac-project/goliac/internal/config"
@@ -16,6 +17,7 @@ type GoliacReconciliatorDatasource interface {
 	Teams() (map[string]*GithubTeamComparable, map[string]bool, error)          // team, externallyManaged, error
 	Repositories() (map[string]*GithubRepoComparable, map[string]string, error) // repo, renameTo, error
 	RuleSets() (map[string]*GithubRuleSet, error)
+	RepositoryRoles() (map[string]*GithubRepositoryRole, error) // key is the role name
 	OrgCustomProperties(ctx context.Context) map[string]*config.GithubCustomProperty
 }
 
@@ -30,6 +32,7 @@ type GoliacReconciliatorDatasourceLocal struct {
 	teamsDefaultBranch  string
 	reconciliatorFilter *ReconciliatorFilterImpl
 	githubAppSlug       string
+	organization        string // used to generate the CODEOWNERS files
 }
 
 func NewGoliacReconciliatorDatasourceLocal(
@@ -39,8 +42,25 @@ func NewGoliacReconciliatorDatasourceLocal(
 	isEnterprise bool,
 	conf *config.RepositoryConfig,
 	githubAppSlug string,
+) GoliacReconciliatorDatasource {
+	return NewGoliacReconciliatorDatasourceLocalForOrganization(config.Config.GithubAppOrganization, local, teamsreponame, teamsDefaultBranch, isEnterprise, conf, githubAppSlug)
+}
+
+/*
+ * NewGoliacReconciliatorDatasourceLocalForOrganization is NewGoliacReconciliatorDatasourceLocal
+ * for a given Github organization (when a Goliac server manages several organizations)
+ */
+func NewGoliacReconciliatorDatasourceLocalForOrganization(
+	organization string,
+	local GoliacLocal,
+	teamsreponame string,
+	teamsDefaultBranch string,
+	isEnterprise bool,
+	conf *config.RepositoryConfig,
+	githubAppSlug string,
 ) GoliacReconciliatorDatasource {
 	return &GoliacReconciliatorDatasourceLocal{
+		organization:        organization,
 		local:               local,
 		conf:                conf,
 		teamsreponame:       teamsreponame,
@@ -51,9 +71,20 @@ func NewGoliacReconciliatorDatasourceLocal(
 }
 
 func (d *GoliacReconciliatorDatasourceLocal) Users() map[string]string {
+	admins := make(map[string]bool)
+	if d.conf != nil {
+		for _, admin := range d.conf.OrgAdmins {
+			admins[admin] = true
+		}
+	}
+
 	users := make(map[string]string)
-	for _, user := range d.local.Users() {
-		users[user.Spec.GithubID] = user.Spec.GithubID
+	for username, user := range d.local.Users() {
+		if admins[username] && !user.IsSuspended() {
+			users[user.Spec.GithubID] = "ADMIN"
+		} else {
+			users[user.Spec.GithubID] = "MEMBER"
+		}
 	}
 	return users
 }
@@ -75,13 +106,14 @@ func (d *GoliacReconciliatorDatasourceLocal) Teams() (map[string]*GithubTeamComp
 		members := []string{}
 		membersOwners := []string{}
 		// teamvalue.Spec.Members are not github id
+		// suspended (offboarded) users are removed from the teams
 		for _, m := range teamvalue.Spec.Members {
-			if u, ok := lUsers[m]; ok {
+			if u, ok := lUsers[m]; ok && !u.IsSuspended() {
 				members = append(members, u.Spec.GithubID)
 			}
 		}
 		for _, m := range teamvalue.Spec.Owners {
-			if u, ok := lUsers[m]; ok {
+			if u, ok := lUsers[m]; ok && !u.IsSuspended() {
 				members = append(members, u.Spec.GithubID)
 				membersOwners = append(membersOwners, u.Spec.GithubID)
 			}
@@ -116,7 +148,10 @@ func (d *GoliacReconciliatorDatasourceLocal) Teams() (map[string]*GithubTeamComp
 			Slug:    "everyone",
 			Members: []string{},
 		}
-		for u := range d.local.Users() {
+		for u, user := range d.local.Users() {
+			if user.IsSuspended() {
+				continue
+			}
 			everyone.Members = append(everyone.Members, u)
 		}
 		slugTeams["everyone"] = &everyone
@@ -180,8 +215,16 @@ func (d *GoliacReconciliatorDatasourceLocal) Repositories() (map[string]*GithubR
 	}
 
 	for reponame, lRepo := range localRepositories {
+		maintainers := make([]string, 0)
+		for _, m := range lRepo.Spec.Maintainers {
+			maintainers = append(maintainers, slug.Make(m))
+		}
 		writers := make([]string, 0)
 		for _, w := range lRepo.Spec.Writers {
+			// a maintainer is not a writer
+			if slices.Contains(maintainers, slug.Make(w)) {
+				continue
+			}
 			writers = append(writers, slug.Make(w))
 		}
 		// add the team owner's name ;-)
@@ -195,10 +238,19 @@ func (d *GoliacReconciliatorDatasourceLocal) Repositories() (map[string]*GithubR
 					break
 				}
 			}
-			if !alreadyAdded {
+			if !alreadyAdded && !slices.Contains(maintainers, slugOwner) {
 				writers = append(writers, slugOwner)
 			}
 		}
+		triagers := make([]string, 0)
+		for _, t := range lRepo.Spec.Triagers {
+			// a triager already writer or maintainer keeps the highest permission
+			slugTriager := slug.Make(t)
+			if slices.Contains(writers, slugTriager) || slices.Contains(maintainers, slugTriager) {
+				continue
+			}
+			triagers = append(triagers, slugTriager)
+		}
 		readers := make([]string, 0)
 		for _, r := range lRepo.Spec.Readers {
 			// checking if the reader was already added as a writer
@@ -210,10 +262,19 @@ func (d *GoliacReconciliatorDatasourceLocal) Repositories() (map[string]*GithubR
 					break
 				}
 			}
+			if slices.Contains(maintainers, slugReader) || slices.Contains(triagers, slugReader) {
+				alreadyAdded = true
+			}
 			if !alreadyAdded {
 				readers = append(readers, slugReader)
 			}
 		}
+		customRoles := make(map[string]string)
+		for role, teams := range lRepo.Spec.Roles {
+			for _, t := range teams {
+				customRoles[slug.Make(t)] = role
+			}
+		}
 
 		// special case for the Goliac "teams" repo
 		if reponame == d.teamsreponame {
@@ -357,7 +418,7 @@ func (d *GoliacReconciliatorDatasourceLocal) Repositories() (map[string]*GithubR
 		}
 
 		// Generate CODEOWNERS content if codeowners entries are defined
-		codeownersContent := lRepo.GenerateCodeownersContent(config.Config.GithubAppOrganization)
+		codeownersContent := lRepo.GenerateCodeownersContent(d.organization)
 
 		lRepos[utils.GithubAnsiString(reponame)] = d.reconciliatorFilter.RepositoryFilter(reponame, &GithubRepoComparable{
 			BoolProperties: map[string]bool{
@@ -372,6 +433,9 @@ func (d *GoliacReconciliatorDatasourceLocal) Repositories() (map[string]*GithubR
 			Visibility:                 lRepo.Spec.Visibility,
 			Readers:                    readers,
 			Writers:                    writers,
+			Maintainers:                maintainers,
+			Triagers:                   triagers,
+			CustomRoles:                customRoles,
 			ExternalUserReaders:        eReaders,
 			ExternalUserWriters:        eWriters,
 			InternalUsers:              []string{},
@@ -512,6 +576,21 @@ func ensureGoliacAppBypassOnRuleset(rs *GithubRuleSet, appSlug string) {
 	rs.BypassApps[appSlug] = githubWorkflowAppBypassMode
 }
 
+func (d *GoliacReconciliatorDatasourceLocal) RepositoryRoles() (map[string]*GithubRepositoryRole, error) {
+	lRoles := make(map[string]*GithubRepositoryRole)
+	for name, role := range d.local.RepositoryRoles() {
+		permissions := make([]string, len(role.Spec.Permissions))
+		copy(permissions, role.Spec.Permissions)
+		lRoles[name] = &GithubRepositoryRole{
+			Name:        name,
+			Description: role.Spec.Description,
+			BaseRole:    role.Spec.BaseRole,
+			Permissions: permissions,
+		}
+	}
+	return lRoles, nil
+}
+
 func (d *GoliacReconciliatorDatasourceLocal) OrgCustomProperties(ctx context.Context) map[string]*config.GithubCustomProperty {
 	localProps := make(map[string]*config.GithubCustomProperty)
 	for _, prop := range d.conf.OrgCustomProperties {
@@ -600,6 +679,9 @@ func (d *GoliacReconciliatorDatasourceRemote) Repositories() (map[string]*Github
 			BoolProperties:             map[string]bool{},
 			Writers:                    []string{},
 			Readers:                    []string{},
+			Maintainers:                []string{},
+			Triagers:                   []string{},
+			CustomRoles:                map[string]string{},
 			ExternalUserReaders:        []string{},
 			ExternalUserWriters:        []string{},
 			InternalUsers:              []string{},
@@ -649,9 +731,16 @@ func (d *GoliacReconciliatorDatasourceRemote) Repositories() (map[string]*Github
 	for t, repos := range d.remote.TeamRepositories(context.Background()) {
 		for r, p := range repos {
 			if rr, ok := rRepos[r]; ok {
-				if p.Permission == "WRITE" {
+				switch {
+				case p.RoleName != "":
+					rr.CustomRoles[t] = p.RoleName
+				case p.Permission == "WRITE":
 					rr.Writers = append(rr.Writers, t)
-				} else {
+				case p.Permission == "MAINTAIN":
+					rr.Maintainers = append(rr.Maintainers, t)
+				case p.Permission == "TRIAGE":
+					rr.Triagers = append(rr.Triagers, t)
+				default:
 					rr.Readers = append(rr.Readers, t)
 				}
 			}
@@ -664,6 +753,10 @@ func (d *GoliacReconciliatorDatasourceRemote) RuleSets() (map[string]*GithubRule
 	return d.remote.RuleSets(context.Background()), nil
 }
 
+func (d *GoliacReconciliatorDatasourceRemote) RepositoryRoles() (map[string]*GithubRepositoryRole, error) {
+	return d.remote.RepositoryRoles(context.Background()), nil
+}
+
 func (d *GoliacReconciliatorDatasourceRemote) OrgCustomProperties(ctx context.Context) map[string]*config.GithubCustomProperty {
 	return d.remote.OrgCustomProperties(ctx)
 }
@@ -183,3 +183,67 @@ func TestGoliacReconciliatorDatasourceLocalCodeownersBypassInjection_RulesetWith
 	rs := r.Rulesets["myruleset"]
 	assert.Equal(t, githubWorkflowAppBypassMode, rs.BypassApps["goliac-app"])
 }
+
+func TestGoliacReconciliatorDatasourceLocalSuspendedUsers(t *testing.T) {
+	alice := &entity.User{}
+	alice.Name = "alice"
+	alice.Spec.GithubID = "alice-gh"
+	bob := &entity.User{}
+	bob.Name = "bob"
+	bob.Spec.GithubID = "bob-gh"
+	bob.Spec.Status = entity.USER_STATUS_SUSPENDED
+	bob.Spec.RemovalDate = "2030-01-01"
+
+	team := &entity.Team{}
+	team.Name = "team1"
+	team.Spec.Owners = []string{"alice", "bob"}
+	team.Spec.Members = []string{"bob"}
+
+	local := &GoliacLocalImpl{
+		teams:         map[string]*entity.Team{"team1": team},
+		repositories:  map[string]*entity.Repository{},
+		users:         map[string]*entity.User{"alice": alice, "bob": bob},
+		externalUsers: map[string]*entity.User{},
+		rulesets:      map[string]*entity.RuleSet{},
+		repoconfig:    &config.RepositoryConfig{},
+	}
+
+	repoconf := &config.RepositoryConfig{AdminTeam: "admin", EveryoneTeamEnabled: true}
+	d := NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, repoconf, "goliac-app")
+
+	// suspended users stay org members
+	users := d.Users()
+	assert.Equal(t, 2, len(users))
+
+	// but are removed from the teams
+	teams, _, err := d.Teams()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice-gh"}, teams["team1"].Members)
+	assert.Equal(t, []string{"alice-gh"}, teams["team1"+config.Config.GoliacTeamOwnerSuffix].Members)
+	assert.Equal(t, []string{"alice"}, teams["everyone"].Members)
+}
+
+func TestGoliacReconciliatorDatasourceLocalForOrganizationCodeowners(t *testing.T) {
+	local := &GoliacLocalImpl{
+		teams:         map[string]*entity.Team{},
+		repositories:  map[string]*entity.Repository{},
+		users:         map[string]*entity.User{},
+		externalUsers: map[string]*entity.User{},
+		rulesets:      map[string]*entity.RuleSet{},
+		workflows:     map[string]*entity.Workflow{},
+		repoconfig:    &config.RepositoryConfig{},
+	}
+
+	repo := &entity.Repository{}
+	repo.Spec.Codeowners = []entity.RepositoryCodeownersEntry{
+		{Pattern: "*", Owners: []string{"team1"}},
+	}
+	local.repositories["myrepo"] = repo
+
+	repoconf := &config.RepositoryConfig{AdminTeam: "admin"}
+	d := NewGoliacReconciliatorDatasourceLocalForOrganization("oss", local, "teams", "main", true, repoconf, "goliac-app")
+
+	repos, _, err := d.Repositories()
+	assert.NoError(t, err)
+	assert.Contains(t, repos["myrepo"].Codeowners, "* @oss/team1")
+}
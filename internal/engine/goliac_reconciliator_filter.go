@@ -14,6 +14,7 @@ type ReconciliatorFilterImpl struct {
 	isEnterprise                   bool
 	ForbidPublicRepositories       bool
 	ForbidPulicRepostitoriesExcept []*regexp.Regexp
+	Enforced                       config.RepositorySettings
 }
 
 func NewReconciliatorFilter(isEnterprise bool, config *config.RepositoryConfig) *ReconciliatorFilterImpl {
@@ -28,10 +29,57 @@ func NewReconciliatorFilter(isEnterprise bool, config *config.RepositoryConfig)
 		isEnterprise:                   isEnterprise,
 		ForbidPublicRepositories:       config.VisibilityRules.ForbidPublicRepositories,
 		ForbidPulicRepostitoriesExcept: exclude,
+		Enforced:                       config.RepositoryEnforced,
+	}
+}
+
+/*
+ * enforce applies the repository_enforced settings over the repository definition
+ * and keeps track of the overridden values (repo.Enforced)
+ */
+func (r *ReconciliatorFilterImpl) enforce(repo *GithubRepoComparable) {
+	if repo.BoolProperties["archived"] {
+		return
+	}
+
+	if r.Enforced.Visibility != "" && repo.Visibility != r.Enforced.Visibility {
+		repo.Enforced = append(repo.Enforced, EnforcedSetting{Name: "visibility", Value: r.Enforced.Visibility, Definition: repo.Visibility})
+		repo.Visibility = r.Enforced.Visibility
+	}
+
+	// a repository without default branch doesn't manage it: nothing to enforce
+	if r.Enforced.DefaultBranchName != "" && repo.DefaultBranchName != "" && repo.DefaultBranchName != r.Enforced.DefaultBranchName {
+		repo.Enforced = append(repo.Enforced, EnforcedSetting{Name: "default_branch", Value: r.Enforced.DefaultBranchName, Definition: repo.DefaultBranchName})
+		repo.DefaultBranchName = r.Enforced.DefaultBranchName
+	}
+
+	for _, property := range []struct {
+		name     string
+		enforced *bool
+	}{
+		{"allow_auto_merge", r.Enforced.AllowAutoMerge},
+		{"allow_squash_merge", r.Enforced.AllowSquashMerge},
+		{"allow_rebase_merge", r.Enforced.AllowRebaseMerge},
+		{"allow_merge_commit", r.Enforced.AllowMergeCommit},
+		{"delete_branch_on_merge", r.Enforced.DeleteBranchOnMerge},
+		{"allow_update_branch", r.Enforced.AllowUpdateBranch},
+	} {
+		if property.enforced == nil {
+			continue
+		}
+		if repo.BoolProperties == nil {
+			repo.BoolProperties = map[string]bool{}
+		}
+		if repo.BoolProperties[property.name] != *property.enforced {
+			repo.Enforced = append(repo.Enforced, EnforcedSetting{Name: property.name, Value: *property.enforced, Definition: repo.BoolProperties[property.name]})
+			repo.BoolProperties[property.name] = *property.enforced
+		}
 	}
 }
 
 func (r *ReconciliatorFilterImpl) RepositoryFilter(reponame string, repo *GithubRepoComparable) *GithubRepoComparable {
+	r.enforce(repo)
+
 	if !r.isEnterprise {
 		if repo.Visibility == "internal" {
 			repo.Visibility = "private"
@@ -79,4 +79,73 @@ func TestReconciliatorFilterImpl_RepositoryFilter(t *testing.T) {
 		repo = filter.RepositoryFilter("repo2", repo)
 		assert.Equal(t, "private", repo.Visibility)
 	})
+
+	t.Run("happy path: repository_enforced", func(t *testing.T) {
+		config := &config.RepositoryConfig{}
+		enabled := true
+		disabled := false
+		config.RepositoryEnforced.DeleteBranchOnMerge = &enabled
+		config.RepositoryEnforced.AllowMergeCommit = &disabled
+		config.RepositoryEnforced.DefaultBranchName = "main"
+
+		filter := NewReconciliatorFilter(true, config)
+		repo := &GithubRepoComparable{
+			Visibility: "private",
+			BoolProperties: map[string]bool{
+				"delete_branch_on_merge": false,
+				"allow_merge_commit":     true,
+				"allow_squash_merge":     true,
+			},
+			DefaultBranchName: "master",
+		}
+		repo = filter.RepositoryFilter("repo", repo)
+		assert.Equal(t, true, repo.BoolProperties["delete_branch_on_merge"])
+		assert.Equal(t, false, repo.BoolProperties["allow_merge_commit"])
+		assert.Equal(t, true, repo.BoolProperties["allow_squash_merge"])
+		assert.Equal(t, "main", repo.DefaultBranchName)
+		assert.Equal(t, []EnforcedSetting{
+			{Name: "default_branch", Value: "main", Definition: "master"},
+			{Name: "allow_merge_commit", Value: false, Definition: true},
+			{Name: "delete_branch_on_merge", Value: true, Definition: false},
+		}, repo.Enforced)
+		assert.Equal(t, "allow_merge_commit: false (instead of true)", repo.Enforced[1].String())
+	})
+
+	t.Run("happy path: repository_enforced already respected", func(t *testing.T) {
+		config := &config.RepositoryConfig{}
+		enabled := true
+		config.RepositoryEnforced.DeleteBranchOnMerge = &enabled
+		config.RepositoryEnforced.DefaultBranchName = "main"
+
+		filter := NewReconciliatorFilter(true, config)
+		repo := &GithubRepoComparable{
+			Visibility: "private",
+			BoolProperties: map[string]bool{
+				"delete_branch_on_merge": true,
+			},
+		}
+		repo = filter.RepositoryFilter("repo", repo)
+		assert.Equal(t, true, repo.BoolProperties["delete_branch_on_merge"])
+		// no default branch in the definition: not managed, so not enforced
+		assert.Equal(t, "", repo.DefaultBranchName)
+		assert.Equal(t, 0, len(repo.Enforced))
+	})
+
+	t.Run("happy path: repository_enforced is not applied to archived repositories", func(t *testing.T) {
+		config := &config.RepositoryConfig{}
+		enabled := true
+		config.RepositoryEnforced.DeleteBranchOnMerge = &enabled
+
+		filter := NewReconciliatorFilter(true, config)
+		repo := &GithubRepoComparable{
+			Visibility: "private",
+			BoolProperties: map[string]bool{
+				"archived":               true,
+				"delete_branch_on_merge": false,
+			},
+		}
+		repo = filter.RepositoryFilter("repo", repo)
+		assert.Equal(t, false, repo.BoolProperties["delete_branch_on_merge"])
+		assert.Equal(t, 0, len(repo.Enforced))
+	})
 }
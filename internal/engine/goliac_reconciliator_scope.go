@@ -0,0 +1,253 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/gosimple/slug"
+)
+
+const (
+	SCOPE_KIND_REPOSITORY = "repo"
+	SCOPE_KIND_TEAM       = "team"
+	SCOPE_KIND_RULESET    = "ruleset"
+)
+
+/*
+ * ReconciliatorScope is the set of resources reconciled by a targeted apply
+ * (the requested resources, and the teams/users they depend on)
+ */
+type ReconciliatorScope struct {
+	Users           map[string]bool // githubid
+	Teams           map[string]bool // team slug
+	Repositories    map[string]bool
+	RuleSets        map[string]bool
+	RepositoryRoles map[string]bool
+}
+
+/*
+ * NewReconciliatorScope computes the scope of the <kind>:<name> resources
+ * (repo:<name>, team:<name>, ruleset:<name>) from the local datasource:
+ * - a team comes with its -goliac-owners team, its parent teams and its members
+ * - a repository comes with the teams (and custom repository roles) it grants access to
+ */
+func NewReconciliatorScope(local GoliacReconciliatorDatasource, resources []string) (*ReconciliatorScope, error) {
+	scope := &ReconciliatorScope{
+		Users:           make(map[string]bool),
+		Teams:           make(map[string]bool),
+		Repositories:    make(map[string]bool),
+		RuleSets:        make(map[string]bool),
+		RepositoryRoles: make(map[string]bool),
+	}
+
+	lTeams, _, err := local.Teams()
+	if err != nil {
+		return nil, err
+	}
+	lRepos, toRename, err := local.Repositories()
+	if err != nil {
+		return nil, err
+	}
+
+	var addTeam func(teamslug string)
+	addTeam = func(teamslug string) {
+		if scope.Teams[teamslug] {
+			return
+		}
+		scope.Teams[teamslug] = true
+		// even for a team deleted locally, to delete its owners team too
+		if !strings.HasSuffix(teamslug, config.Config.GoliacTeamOwnerSuffix) {
+			addTeam(teamslug + config.Config.GoliacTeamOwnerSuffix)
+		}
+		lTeam, ok := lTeams[teamslug]
+		if !ok {
+			return
+		}
+		for _, m := range lTeam.Members {
+			scope.Users[m] = true
+		}
+		for _, m := range lTeam.Maintainers {
+			scope.Users[m] = true
+		}
+		if lTeam.ParentTeam != nil {
+			addTeam(*lTeam.ParentTeam)
+		}
+	}
+
+	for _, resource := range resources {
+		parts := strings.SplitN(resource, ":", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid resource %s: expected <kind>:<name>", resource)
+		}
+		name := parts[1]
+		switch parts[0] {
+		case SCOPE_KIND_TEAM:
+			addTeam(slug.Make(name))
+		case SCOPE_KIND_REPOSITORY:
+			scope.Repositories[name] = true
+			if renameTo, ok := toRename[name]; ok {
+				scope.Repositories[renameTo] = true
+			}
+			if lRepo, ok := lRepos[name]; ok {
+				for _, teams := range [][]string{lRepo.Writers, lRepo.Readers, lRepo.Maintainers, lRepo.Triagers} {
+					for _, t := range teams {
+						addTeam(t)
+					}
+				}
+				for t, role := range lRepo.CustomRoles {
+					addTeam(t)
+					scope.RepositoryRoles[role] = true
+				}
+			}
+		case SCOPE_KIND_RULESET:
+			scope.RuleSets[name] = true
+		default:
+			return nil, fmt.Errorf("invalid resource kind %s: expected one of repo, team, ruleset", parts[0])
+		}
+	}
+	return scope, nil
+}
+
+/*
+ * ScopeWithTeamsRepositories adds to the <kind>:<name> resources the repositories
+ * owned by the requested teams and by their nested teams: a team definition
+ * (like its repository_defaults) also changes the repositories it owns
+ */
+func ScopeWithTeamsRepositories(local GoliacLocalResources, resources []string) []string {
+	requested := make(map[string]bool)
+	for _, resource := range resources {
+		parts := strings.SplitN(resource, ":", 2)
+		if len(parts) == 2 && parts[0] == SCOPE_KIND_TEAM {
+			requested[slug.Make(parts[1])] = true
+		}
+	}
+	if len(requested) == 0 {
+		return resources
+	}
+
+	teams := local.Teams()
+	// returns true if the team, or one of its parent teams, is requested
+	isRequested := func(teamname string) bool {
+		for visited := 0; visited <= len(teams); visited++ {
+			if requested[slug.Make(teamname)] {
+				return true
+			}
+			team, ok := teams[teamname]
+			if !ok || team.ParentTeam == nil {
+				return false
+			}
+			teamname = *team.ParentTeam
+		}
+		return false
+	}
+
+	scoped := append([]string{}, resources...)
+	for reponame, repo := range local.Repositories() {
+		if repo.Owner == nil || !isRequested(*repo.Owner) {
+			continue
+		}
+		resource := SCOPE_KIND_REPOSITORY + ":" + reponame
+		if !slices.Contains(scoped, resource) {
+			scoped = append(scoped, resource)
+		}
+	}
+	return scoped
+}
+
+/*
+ * GoliacReconciliatorDatasourceScoped restricts a datasource to the resources
+ * of a ReconciliatorScope. Both the local and the remote datasources must be
+ * scoped, so the resources outside of the scope are seen as unchanged
+ */
+type GoliacReconciliatorDatasourceScoped struct {
+	datasource GoliacReconciliatorDatasource
+	scope      *ReconciliatorScope
+}
+
+func NewGoliacReconciliatorDatasourceScoped(datasource GoliacReconciliatorDatasource, scope *ReconciliatorScope) GoliacReconciliatorDatasource {
+	return &GoliacReconciliatorDatasourceScoped{
+		datasource: datasource,
+		scope:      scope,
+	}
+}
+
+func (d *GoliacReconciliatorDatasourceScoped) Users() map[string]string {
+	users := make(map[string]string)
+	for login, role := range d.datasource.Users() {
+		if d.scope.Users[login] {
+			users[login] = role
+		}
+	}
+	return users
+}
+
+func (d *GoliacReconciliatorDatasourceScoped) Teams() (map[string]*GithubTeamComparable, map[string]bool, error) {
+	teams, externallyManaged, err := d.datasource.Teams()
+	if err != nil {
+		return nil, nil, err
+	}
+	scopedTeams := make(map[string]*GithubTeamComparable)
+	for teamslug, team := range teams {
+		if d.scope.Teams[teamslug] {
+			scopedTeams[teamslug] = team
+		}
+	}
+	return scopedTeams, externallyManaged, nil
+}
+
+func (d *GoliacReconciliatorDatasourceScoped) Repositories() (map[string]*GithubRepoComparable, map[string]string, error) {
+	repos, renameTo, err := d.datasource.Repositories()
+	if err != nil {
+		return nil, nil, err
+	}
+	scopedRepos := make(map[string]*GithubRepoComparable)
+	for reponame, repo := range repos {
+		if d.scope.Repositories[reponame] {
+			scopedRepos[reponame] = repo
+		}
+	}
+	scopedRenameTo := make(map[string]string)
+	for reponame, newname := range renameTo {
+		if d.scope.Repositories[reponame] {
+			scopedRenameTo[reponame] = newname
+		}
+	}
+	return scopedRepos, scopedRenameTo, nil
+}
+
+func (d *GoliacReconciliatorDatasourceScoped) RuleSets() (map[string]*GithubRuleSet, error) {
+	rulesets, err := d.datasource.RuleSets()
+	if err != nil {
+		return nil, err
+	}
+	scopedRulesets := make(map[string]*GithubRuleSet)
+	for name, ruleset := range rulesets {
+		if d.scope.RuleSets[name] {
+			scopedRulesets[name] = ruleset
+		}
+	}
+	return scopedRulesets, nil
+}
+
+func (d *GoliacReconciliatorDatasourceScoped) RepositoryRoles() (map[string]*GithubRepositoryRole, error) {
+	roles, err := d.datasource.RepositoryRoles()
+	if err != nil {
+		return nil, err
+	}
+	scopedRoles := make(map[string]*GithubRepositoryRole)
+	for name, role := range roles {
+		if d.scope.RepositoryRoles[name] {
+			scopedRoles[name] = role
+		}
+	}
+	return scopedRoles, nil
+}
+
+func (d *GoliacReconciliatorDatasourceScoped) OrgCustomProperties(ctx context.Context) map[string]*config.GithubCustomProperty {
+	// the organization custom properties definitions are not reconciled by a targeted apply
+	// but they are needed to compare the repositories custom properties
+	return d.datasource.OrgCustomProperties(ctx)
+}
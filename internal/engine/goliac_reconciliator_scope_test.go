@@ -0,0 +1,191 @@
+package engine
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+type ScopeDatasourceMock struct {
+	users    map[string]string
+	teams    map[string]*GithubTeamComparable
+	repos    map[string]*GithubRepoComparable
+	renameTo map[string]string
+	rulesets map[string]*GithubRuleSet
+	roles    map[string]*GithubRepositoryRole
+}
+
+func (d *ScopeDatasourceMock) Users() map[string]string {
+	return d.users
+}
+func (d *ScopeDatasourceMock) Teams() (map[string]*GithubTeamComparable, map[string]bool, error) {
+	return d.teams, map[string]bool{}, nil
+}
+func (d *ScopeDatasourceMock) Repositories() (map[string]*GithubRepoComparable, map[string]string, error) {
+	return d.repos, d.renameTo, nil
+}
+func (d *ScopeDatasourceMock) RuleSets() (map[string]*GithubRuleSet, error) {
+	return d.rulesets, nil
+}
+func (d *ScopeDatasourceMock) RepositoryRoles() (map[string]*GithubRepositoryRole, error) {
+	return d.roles, nil
+}
+func (d *ScopeDatasourceMock) OrgCustomProperties(ctx context.Context) map[string]*config.GithubCustomProperty {
+	return map[string]*config.GithubCustomProperty{}
+}
+
+func fixtureScopeDatasource() *ScopeDatasourceMock {
+	parent := "parent"
+	return &ScopeDatasourceMock{
+		users: map[string]string{
+			"user1": "MEMBER",
+			"user2": "MEMBER",
+			"user3": "MEMBER",
+			"user4": "MEMBER",
+		},
+		teams: map[string]*GithubTeamComparable{
+			"parent":                 {Name: "parent", Slug: "parent", Members: []string{"user3"}},
+			"parent-goliac-owners":   {Name: "parent-goliac-owners", Slug: "parent-goliac-owners"},
+			"team1":                  {Name: "team1", Slug: "team1", Members: []string{"user1"}, ParentTeam: &parent},
+			"team1-goliac-owners":    {Name: "team1-goliac-owners", Slug: "team1-goliac-owners", Members: []string{"user2"}},
+			"team2":                  {Name: "team2", Slug: "team2", Members: []string{"user4"}},
+			"team2-goliac-owners":    {Name: "team2-goliac-owners", Slug: "team2-goliac-owners"},
+			"auditors":               {Name: "auditors", Slug: "auditors"},
+			"auditors-goliac-owners": {Name: "auditors-goliac-owners", Slug: "auditors-goliac-owners"},
+		},
+		repos: map[string]*GithubRepoComparable{
+			"repo1": {Writers: []string{"team2"}, Readers: []string{}, CustomRoles: map[string]string{"auditors": "auditor"}},
+			"repo2": {Writers: []string{"team1"}, Readers: []string{}},
+		},
+		renameTo: map[string]string{
+			"repo2": "repo3",
+		},
+		rulesets: map[string]*GithubRuleSet{
+			"default": {Name: "default"},
+			"other":   {Name: "other"},
+		},
+		roles: map[string]*GithubRepositoryRole{
+			"auditor": {Name: "auditor"},
+			"other":   {Name: "other"},
+		},
+	}
+}
+
+func TestReconciliatorScope(t *testing.T) {
+	t.Run("happy path: team scope", func(t *testing.T) {
+		scope, err := NewReconciliatorScope(fixtureScopeDatasource(), []string{"team:team1"})
+		assert.Nil(t, err)
+
+		assert.Equal(t, map[string]bool{
+			"team1":                true,
+			"team1-goliac-owners":  true,
+			"parent":               true,
+			"parent-goliac-owners": true,
+		}, scope.Teams)
+		assert.Equal(t, map[string]bool{"user1": true, "user2": true, "user3": true}, scope.Users)
+		assert.Equal(t, 0, len(scope.Repositories))
+		assert.Equal(t, 0, len(scope.RuleSets))
+	})
+
+	t.Run("happy path: repository scope", func(t *testing.T) {
+		scope, err := NewReconciliatorScope(fixtureScopeDatasource(), []string{"repo:repo1", "ruleset:default"})
+		assert.Nil(t, err)
+
+		assert.Equal(t, map[string]bool{"repo1": true}, scope.Repositories)
+		assert.True(t, scope.Teams["team2"])
+		assert.True(t, scope.Teams["auditors"])
+		assert.False(t, scope.Teams["team1"])
+		assert.Equal(t, map[string]bool{"auditor": true}, scope.RepositoryRoles)
+		assert.Equal(t, map[string]bool{"default": true}, scope.RuleSets)
+	})
+
+	t.Run("happy path: renamed repository scope", func(t *testing.T) {
+		scope, err := NewReconciliatorScope(fixtureScopeDatasource(), []string{"repo:repo2"})
+		assert.Nil(t, err)
+
+		assert.Equal(t, map[string]bool{"repo2": true, "repo3": true}, scope.Repositories)
+		assert.True(t, scope.Teams["team1"])
+	})
+
+	t.Run("happy path: deleted team scope", func(t *testing.T) {
+		scope, err := NewReconciliatorScope(fixtureScopeDatasource(), []string{"team:deleted"})
+		assert.Nil(t, err)
+
+		// the owners team is deleted with the team
+		assert.Equal(t, map[string]bool{
+			"deleted":               true,
+			"deleted-goliac-owners": true,
+		}, scope.Teams)
+	})
+
+	t.Run("happy path: team repositories scope", func(t *testing.T) {
+		parent := "parent"
+		team1 := "team1"
+		team2 := "team2"
+		local := &GoliacLocalMock{
+			teams: map[string]*entity.Team{
+				"parent": {},
+				"team1":  {ParentTeam: &parent},
+				"team2":  {},
+			},
+			repos: map[string]*entity.Repository{
+				"repo1":  {Owner: &parent},
+				"repo2":  {Owner: &team1},
+				"repo3":  {Owner: &team2},
+				"shared": {},
+			},
+		}
+
+		only := ScopeWithTeamsRepositories(local, []string{"team:parent", "repo:repo1"})
+		slices.Sort(only)
+		assert.Equal(t, []string{"repo:repo1", "repo:repo2", "team:parent"}, only)
+
+		only = ScopeWithTeamsRepositories(local, []string{"ruleset:default"})
+		assert.Equal(t, []string{"ruleset:default"}, only)
+	})
+
+	t.Run("not happy path: invalid resources", func(t *testing.T) {
+		_, err := NewReconciliatorScope(fixtureScopeDatasource(), []string{"repo1"})
+		assert.NotNil(t, err)
+
+		_, err = NewReconciliatorScope(fixtureScopeDatasource(), []string{"user:user1"})
+		assert.NotNil(t, err)
+
+		_, err = NewReconciliatorScope(fixtureScopeDatasource(), []string{"team:"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("happy path: scoped datasource", func(t *testing.T) {
+		ds := fixtureScopeDatasource()
+		scope, err := NewReconciliatorScope(ds, []string{"repo:repo1", "ruleset:default"})
+		assert.Nil(t, err)
+		scoped := NewGoliacReconciliatorDatasourceScoped(ds, scope)
+
+		assert.Equal(t, map[string]string{"user4": "MEMBER"}, scoped.Users())
+
+		teams, _, err := scoped.Teams()
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(teams))
+		assert.Nil(t, teams["team1"])
+
+		repos, renameTo, err := scoped.Repositories()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(repos))
+		assert.NotNil(t, repos["repo1"])
+		assert.Equal(t, 0, len(renameTo))
+
+		rulesets, err := scoped.RuleSets()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(rulesets))
+		assert.NotNil(t, rulesets["default"])
+
+		roles, err := scoped.RepositoryRoles()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(roles))
+		assert.NotNil(t, roles["auditor"])
+	})
+}
@@ -23,6 +23,7 @@ type GoliacLocalMock struct {
 	repos     map[string]*entity.Repository
 	rulesets  map[string]*entity.RuleSet
 	workflows map[string]*entity.Workflow
+	repoRoles map[string]*entity.RepositoryRole
 }
 
 func (m *GoliacLocalMock) Clone(fs billy.Filesystem, accesstoken, repositoryUrl, branch string) error {
@@ -62,6 +63,9 @@ func (m *GoliacLocalMock) ExternalUsers() map[string]*entity.User {
 func (m *GoliacLocalMock) RuleSets() map[string]*entity.RuleSet {
 	return m.rulesets
 }
+func (m *GoliacLocalMock) RepositoryRoles() map[string]*entity.RepositoryRole {
+	return m.repoRoles
+}
 func (m *GoliacLocalMock) Workflows() map[string]*entity.Workflow {
 	return m.workflows
 }
@@ -79,6 +83,12 @@ func (m *GoliacLocalMock) UpdateRepos(reposToArchiveList []string, reposToRename
 func (m *GoliacLocalMock) UpdateReposViaPullRequest(ctx context.Context, client LocalGithubClient, reposToCreate map[string]*entity.Repository, orgname, reponame, accesstoken, baseBranch, newBranchName string) (*github.PullRequest, error) {
 	return nil, nil
 }
+func (m *GoliacLocalMock) MigrateBranchProtections(logsCollector *observability.LogCollection) map[string]interface{} {
+	return nil
+}
+func (m *GoliacLocalMock) UpdateFilesViaPullRequest(ctx context.Context, client LocalGithubClient, files map[string]interface{}, orgname, reponame, accesstoken, baseBranch, newBranchName, title string) (*github.PullRequest, error) {
+	return nil, nil
+}
 
 func (m *GoliacLocalMock) SyncUsersAndTeams(ctx context.Context, repoconfig *config.RepositoryConfig, plugin UserSyncPlugin, accesstoken string, dryrun bool, force bool, feedback observability.RemoteObservability, logsCollector *observability.LogCollection) bool {
 	return false
@@ -95,6 +105,7 @@ type GoliacRemoteMock struct {
 	rulesets            map[string]*GithubRuleSet
 	appids              map[string]*GithubApp
 	orgCustomProperties map[string]*config.GithubCustomProperty
+	repositoryRoles     map[string]*GithubRepositoryRole
 }
 
 func (m *GoliacRemoteMock) Load(ctx context.Context, continueOnError bool) error {
@@ -107,6 +118,9 @@ func (m *GoliacRemoteMock) FlushCache() {
 }
 func (m *GoliacRemoteMock) FlushCacheUsersTeamsOnly() {
 }
+func (m *GoliacRemoteMock) HandleOrgEvent(ctx context.Context, event *GithubOrgEvent) bool {
+	return false
+}
 func (m *GoliacRemoteMock) RuleSets(ctx context.Context) map[string]*GithubRuleSet {
 	return m.rulesets
 }
@@ -158,6 +172,13 @@ func (m *GoliacRemoteMock) OrgCustomProperties(ctx context.Context) map[string]*
 	return m.orgCustomProperties
 }
 
+func (m *GoliacRemoteMock) RepositoryRoles(ctx context.Context) map[string]*GithubRepositoryRole {
+	if m.repositoryRoles == nil {
+		return make(map[string]*GithubRepositoryRole)
+	}
+	return m.repositoryRoles
+}
+
 func (m *GoliacRemoteMock) GetRepositoryPages(ctx context.Context, repositoryName string) (*GithubPagesRemote, error) {
 	if m.repos == nil {
 		return nil, nil
@@ -183,6 +204,7 @@ func NewMockMappedEntityLazyLoader[T any](entity map[string]T) *MockMappedEntity
 type ReconciliatorListenerRecorder struct {
 	UsersCreated map[string]string
 	UsersRemoved map[string]string
+	UsersOrgRole map[string]string
 
 	TeamsCreated      map[string][]string
 	TeamMemberAdded   map[string][]string
@@ -193,6 +215,7 @@ type ReconciliatorListenerRecorder struct {
 
 	RepositoryCreated                    map[string]bool
 	RepositoryTeamAdded                  map[string][]string
+	RepositoryTeamAddedPermission        map[string]map[string]string // [reponame][teamslug]permission
 	RepositoryTeamUpdated                map[string][]string
 	RepositoryTeamRemoved                map[string][]string
 	RepositoriesDeleted                  map[string]bool
@@ -230,12 +253,17 @@ type ReconciliatorListenerRecorder struct {
 	OrgCustomPropertyCreated map[string]*config.GithubCustomProperty
 	OrgCustomPropertyUpdated map[string]*config.GithubCustomProperty
 	OrgCustomPropertyDeleted map[string]bool
+
+	RepositoryRoleCreated map[string]*GithubRepositoryRole
+	RepositoryRoleUpdated map[string]*GithubRepositoryRole
+	RepositoryRoleDeleted []int
 }
 
 func NewReconciliatorListenerRecorder() *ReconciliatorListenerRecorder {
 	r := ReconciliatorListenerRecorder{
 		UsersCreated:                         make(map[string]string),
 		UsersRemoved:                         make(map[string]string),
+		UsersOrgRole:                         make(map[string]string),
 		TeamsCreated:                         make(map[string][]string),
 		TeamMemberAdded:                      make(map[string][]string),
 		TeamMemberRemoved:                    make(map[string][]string),
@@ -244,6 +272,7 @@ func NewReconciliatorListenerRecorder() *ReconciliatorListenerRecorder {
 		TeamDeleted:                          make(map[string]bool),
 		RepositoryCreated:                    make(map[string]bool),
 		RepositoryTeamAdded:                  make(map[string][]string),
+		RepositoryTeamAddedPermission:        make(map[string]map[string]string),
 		RepositoryTeamUpdated:                make(map[string][]string),
 		RepositoryTeamRemoved:                make(map[string][]string),
 		RepositoriesDeleted:                  make(map[string]bool),
@@ -265,6 +294,9 @@ func NewReconciliatorListenerRecorder() *ReconciliatorListenerRecorder {
 		OrgCustomPropertyCreated:             make(map[string]*config.GithubCustomProperty),
 		OrgCustomPropertyUpdated:             make(map[string]*config.GithubCustomProperty),
 		OrgCustomPropertyDeleted:             make(map[string]bool),
+		RepositoryRoleCreated:                make(map[string]*GithubRepositoryRole),
+		RepositoryRoleUpdated:                make(map[string]*GithubRepositoryRole),
+		RepositoryRoleDeleted:                make([]int, 0),
 		RepositoryEnvironmentCreated:         make(map[string]string),
 		RepositoryEnvironmentDeleted:         make(map[string]string),
 		RepositoryVariableCreated:            make(map[string]string),
@@ -288,6 +320,9 @@ func (r *ReconciliatorListenerRecorder) AddUserToOrg(ctx context.Context, logsCo
 func (r *ReconciliatorListenerRecorder) RemoveUserFromOrg(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, ghuserid string) {
 	r.UsersRemoved[ghuserid] = ghuserid
 }
+func (r *ReconciliatorListenerRecorder) UpdateUserOrgRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, ghuserid string, role string) {
+	r.UsersOrgRole[ghuserid] = role
+}
 func (r *ReconciliatorListenerRecorder) CreateTeam(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, description string, parentTeam *int, members []string) {
 	r.TeamsCreated[teamname] = append(r.TeamsCreated[teamname], members...)
 }
@@ -311,6 +346,10 @@ func (r *ReconciliatorListenerRecorder) CreateRepository(ctx context.Context, lo
 }
 func (r *ReconciliatorListenerRecorder) UpdateRepositoryAddTeamAccess(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, reponame string, teamslug string, permission string) {
 	r.RepositoryTeamAdded[reponame] = append(r.RepositoryTeamAdded[reponame], teamslug)
+	if r.RepositoryTeamAddedPermission[reponame] == nil {
+		r.RepositoryTeamAddedPermission[reponame] = make(map[string]string)
+	}
+	r.RepositoryTeamAddedPermission[reponame][teamslug] = permission
 }
 func (r *ReconciliatorListenerRecorder) UpdateRepositoryUpdateTeamAccess(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, reponame string, teamslug string, permission string) {
 	r.RepositoryTeamUpdated[reponame] = append(r.RepositoryTeamUpdated[reponame], teamslug)
@@ -478,6 +517,15 @@ func (r *ReconciliatorListenerRecorder) CreateOrUpdateOrgCustomProperty(ctx cont
 func (r *ReconciliatorListenerRecorder) DeleteOrgCustomProperty(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, propertyName string) {
 	r.OrgCustomPropertyDeleted[propertyName] = true
 }
+func (r *ReconciliatorListenerRecorder) AddRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, role *GithubRepositoryRole) {
+	r.RepositoryRoleCreated[role.Name] = role
+}
+func (r *ReconciliatorListenerRecorder) UpdateRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, role *GithubRepositoryRole) {
+	r.RepositoryRoleUpdated[role.Name] = role
+}
+func (r *ReconciliatorListenerRecorder) DeleteRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, roleid int) {
+	r.RepositoryRoleDeleted = append(r.RepositoryRoleDeleted, roleid)
+}
 func (r *ReconciliatorListenerRecorder) Begin(logsCollector *observability.LogCollection, dryrun bool) {
 }
 func (r *ReconciliatorListenerRecorder) Rollback(logsCollector *observability.LogCollection, dryrun bool, err error) {
@@ -486,6 +534,108 @@ func (r *ReconciliatorListenerRecorder) Commit(ctx context.Context, logsCollecto
 	return nil
 }
 
+func fixtureOrgRoleUser(name string, githubid string) *entity.User {
+	user := &entity.User{}
+	user.Name = name
+	user.Spec.GithubID = githubid
+	return user
+}
+
+func TestReconciliationUsersOrgRole(t *testing.T) {
+	newRemote := func(users map[string]string) *GoliacRemoteMock {
+		remote := &GoliacRemoteMock{
+			users:      make(map[string]*GithubUser),
+			teams:      make(map[string]*GithubTeam),
+			repos:      make(map[string]*GithubRepository),
+			teamsrepos: make(map[string]map[string]*GithubTeamRepo),
+			rulesets:   make(map[string]*GithubRuleSet),
+			appids:     make(map[string]*GithubApp),
+		}
+		for login, role := range users {
+			remote.users[login] = &GithubUser{Login: login, Role: role}
+		}
+		return remote
+	}
+	newLocal := func() *GoliacLocalMock {
+		return &GoliacLocalMock{
+			users: map[string]*entity.User{
+				"alice": fixtureOrgRoleUser("alice", "alice-gh"),
+				"bob":   fixtureOrgRoleUser("bob", "bob-gh"),
+				"carol": fixtureOrgRoleUser("carol", "carol-gh"),
+			},
+			teams: make(map[string]*entity.Team),
+			repos: make(map[string]*entity.Repository),
+		}
+	}
+
+	t.Run("happy path: org roles not managed without org_admins", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{}
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local := newLocal()
+		remote := newRemote(map[string]string{"alice-gh": "MEMBER", "bob-gh": "ADMIN", "carol-gh": "MEMBER"})
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, ""), NewGoliacReconciliatorDatasourceRemote(remote), true, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(recorder.UsersOrgRole))
+	})
+
+	t.Run("happy path: promote and demote org owners", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{OrgAdmins: []string{"alice", "carol"}}
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local := newLocal()
+		remote := newRemote(map[string]string{"alice-gh": "MEMBER", "bob-gh": "ADMIN"})
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, ""), NewGoliacReconciliatorDatasourceRemote(remote), true, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, "carol-gh", recorder.UsersCreated["carol-gh"])
+		assert.Equal(t, map[string]string{"alice-gh": "admin", "carol-gh": "admin", "bob-gh": "member"}, recorder.UsersOrgRole)
+	})
+
+	t.Run("not happy path: the last org owner is not demoted", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{OrgAdmins: []string{"alice"}}
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local := newLocal()
+		local.users["alice"].Spec.Status = entity.USER_STATUS_SUSPENDED
+		remote := newRemote(map[string]string{"alice-gh": "MEMBER", "bob-gh": "ADMIN", "carol-gh": "MEMBER"})
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, ""), NewGoliacReconciliatorDatasourceRemote(remote), true, false, true, true, true)
+
+		assert.True(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(recorder.UsersOrgRole))
+	})
+
+	t.Run("happy path: org roles not managed by a targeted apply", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{OrgAdmins: []string{"carol"}}
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local := newLocal()
+		remote := newRemote(map[string]string{"alice-gh": "ADMIN", "bob-gh": "ADMIN", "carol-gh": "MEMBER"})
+		// only alice is in the scope: she is not the last organization owner
+		scope := &ReconciliatorScope{Users: map[string]bool{"alice-gh": true}}
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector,
+			NewGoliacReconciliatorDatasourceScoped(NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, ""), scope),
+			NewGoliacReconciliatorDatasourceScoped(NewGoliacReconciliatorDatasourceRemote(remote), scope),
+			true, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(recorder.UsersOrgRole))
+	})
+}
+
 func TestReconciliationTeam(t *testing.T) {
 	t.Run("happy path: new team", func(t *testing.T) {
 		recorder := NewReconciliatorListenerRecorder()
@@ -2861,6 +3011,340 @@ func TestReconciliationRepo(t *testing.T) {
 
 }
 
+/*
+ * fixtureRepoTeamRoles returns a local and remote "myrepo" repository owned by the "existing" team,
+ * with the "maint", "triag" and "sec" teams defined locally and remotely
+ */
+func fixtureRepoTeamRoles() (*GoliacLocalMock, *GoliacRemoteMock) {
+	local := GoliacLocalMock{
+		users: make(map[string]*entity.User),
+		teams: make(map[string]*entity.Team),
+		repos: make(map[string]*entity.Repository),
+	}
+	lRepo := &entity.Repository{}
+	lRepo.Name = "myrepo"
+	lowner := "existing"
+	lRepo.Owner = &lowner
+	local.repos["myrepo"] = lRepo
+
+	remote := GoliacRemoteMock{
+		users:      make(map[string]*GithubUser),
+		teams:      make(map[string]*GithubTeam),
+		repos:      make(map[string]*GithubRepository),
+		teamsrepos: make(map[string]map[string]*GithubTeamRepo),
+		rulesets:   make(map[string]*GithubRuleSet),
+		appids:     make(map[string]*GithubApp),
+	}
+	remote.repos["teams"] = &GithubRepository{
+		Name:           "teams",
+		ExternalUsers:  map[string]string{},
+		BoolProperties: map[string]bool{},
+	}
+	remote.repos["myrepo"] = &GithubRepository{
+		Name:           "myrepo",
+		ExternalUsers:  map[string]string{},
+		BoolProperties: map[string]bool{},
+	}
+
+	for _, teamname := range []string{"existing", "maint", "triag", "sec"} {
+		team := &entity.Team{}
+		team.Name = teamname
+		team.Spec.Owners = []string{"existing_owner"}
+		local.teams[teamname] = team
+
+		remote.teams[teamname] = &GithubTeam{
+			Name:    teamname,
+			Slug:    teamname,
+			Members: []string{"existing_owner"},
+		}
+		remote.teamsrepos[teamname] = make(map[string]*GithubTeamRepo)
+	}
+	remote.teamsrepos["existing"]["myrepo"] = &GithubTeamRepo{
+		Name:       "myrepo",
+		Permission: "WRITE",
+	}
+
+	return &local, &remote
+}
+
+func TestReconciliationRepoTeamRoles(t *testing.T) {
+
+	t.Run("happy path: add maintainers, triagers and custom roles", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{}
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local, remote := fixtureRepoTeamRoles()
+		local.repos["myrepo"].Spec.Maintainers = []string{"maint"}
+		local.repos["myrepo"].Spec.Triagers = []string{"triag"}
+		local.repos["myrepo"].Spec.Roles = map[string][]string{"security-triager": {"sec"}}
+		remote.teamsrepos["maint"]["myrepo"] = &GithubTeamRepo{
+			Name:       "myrepo",
+			Permission: "READ",
+		}
+
+		localDatasource := NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, "")
+		remoteDatasource := NewGoliacReconciliatorDatasourceRemote(remote)
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, localDatasource, remoteDatasource, true, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, map[string]string{"maint": "maintain", "triag": "triage", "sec": "security-triager"}, recorder.RepositoryTeamAddedPermission["myrepo"])
+		// maint was a reader
+		assert.Equal(t, []string{"maint"}, recorder.RepositoryTeamRemoved["myrepo"])
+	})
+
+	t.Run("happy path: nothing to change", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{}
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local, remote := fixtureRepoTeamRoles()
+		local.repos["myrepo"].Spec.Maintainers = []string{"maint"}
+		local.repos["myrepo"].Spec.Triagers = []string{"triag"}
+		local.repos["myrepo"].Spec.Roles = map[string][]string{"security-triager": {"sec"}}
+		remote.teamsrepos["maint"]["myrepo"] = &GithubTeamRepo{
+			Name:       "myrepo",
+			Permission: "MAINTAIN",
+		}
+		remote.teamsrepos["triag"]["myrepo"] = &GithubTeamRepo{
+			Name:       "myrepo",
+			Permission: "TRIAGE",
+		}
+		remote.teamsrepos["sec"]["myrepo"] = &GithubTeamRepo{
+			Name:     "myrepo",
+			RoleName: "security-triager",
+		}
+
+		localDatasource := NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, "")
+		remoteDatasource := NewGoliacReconciliatorDatasourceRemote(remote)
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, localDatasource, remoteDatasource, true, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(recorder.RepositoryTeamAdded["myrepo"]))
+		assert.Equal(t, 0, len(recorder.RepositoryTeamRemoved["myrepo"]))
+	})
+
+	t.Run("happy path: a maintainer becomes a writer", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{}
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local, remote := fixtureRepoTeamRoles()
+		local.repos["myrepo"].Spec.Writers = []string{"maint"}
+		remote.teamsrepos["maint"]["myrepo"] = &GithubTeamRepo{
+			Name:       "myrepo",
+			Permission: "MAINTAIN",
+		}
+
+		localDatasource := NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, "")
+		remoteDatasource := NewGoliacReconciliatorDatasourceRemote(remote)
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, localDatasource, remoteDatasource, true, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, map[string]string{"maint": "push"}, recorder.RepositoryTeamAddedPermission["myrepo"])
+		// the team access must not be removed afterwards
+		assert.Equal(t, 0, len(recorder.RepositoryTeamRemoved["myrepo"]))
+	})
+
+	t.Run("happy path: remove a custom role", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{}
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local, remote := fixtureRepoTeamRoles()
+		remote.teamsrepos["sec"]["myrepo"] = &GithubTeamRepo{
+			Name:     "myrepo",
+			RoleName: "security-triager",
+		}
+
+		localDatasource := NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, "")
+		remoteDatasource := NewGoliacReconciliatorDatasourceRemote(remote)
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, localDatasource, remoteDatasource, true, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(recorder.RepositoryTeamAdded["myrepo"]))
+		assert.Equal(t, []string{"sec"}, recorder.RepositoryTeamRemoved["myrepo"])
+	})
+
+	t.Run("happy path: new repository with maintainers and custom roles", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{}
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local, remote := fixtureRepoTeamRoles()
+		delete(remote.repos, "myrepo")
+		delete(remote.teamsrepos["existing"], "myrepo")
+		local.repos["myrepo"].Spec.Maintainers = []string{"maint"}
+		local.repos["myrepo"].Spec.Roles = map[string][]string{"security-triager": {"sec"}}
+
+		localDatasource := NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, "")
+		remoteDatasource := NewGoliacReconciliatorDatasourceRemote(remote)
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, localDatasource, remoteDatasource, true, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.True(t, recorder.RepositoryCreated["myrepo"])
+		assert.Equal(t, map[string]string{"maint": "maintain", "sec": "security-triager"}, recorder.RepositoryTeamAddedPermission["myrepo"])
+	})
+}
+
+func fixtureRepositoryRoles() (*GoliacLocalMock, *GoliacRemoteMock) {
+	local := GoliacLocalMock{
+		users:     make(map[string]*entity.User),
+		teams:     make(map[string]*entity.Team),
+		repos:     make(map[string]*entity.Repository),
+		repoRoles: make(map[string]*entity.RepositoryRole),
+	}
+	remote := GoliacRemoteMock{
+		users:           make(map[string]*GithubUser),
+		teams:           make(map[string]*GithubTeam),
+		repos:           make(map[string]*GithubRepository),
+		teamsrepos:      make(map[string]map[string]*GithubTeamRepo),
+		rulesets:        make(map[string]*GithubRuleSet),
+		appids:          make(map[string]*GithubApp),
+		repositoryRoles: make(map[string]*GithubRepositoryRole),
+	}
+	remote.repos["teams"] = &GithubRepository{
+		Name:           "teams",
+		ExternalUsers:  map[string]string{},
+		BoolProperties: map[string]bool{},
+	}
+	return &local, &remote
+}
+
+func TestReconciliationRepositoryRoles(t *testing.T) {
+
+	t.Run("happy path: new repository role", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{}
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local, remote := fixtureRepositoryRoles()
+		lRole := &entity.RepositoryRole{}
+		lRole.Name = "security-triager"
+		lRole.Spec.BaseRole = "triage"
+		lRole.Spec.Permissions = []string{"view_dependabot_alerts"}
+		local.repoRoles["security-triager"] = lRole
+
+		localDatasource := NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, "")
+		remoteDatasource := NewGoliacReconciliatorDatasourceRemote(remote)
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, localDatasource, remoteDatasource, true, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 1, len(recorder.RepositoryRoleCreated))
+		assert.Equal(t, "triage", recorder.RepositoryRoleCreated["security-triager"].BaseRole)
+		assert.Equal(t, []string{"view_dependabot_alerts"}, recorder.RepositoryRoleCreated["security-triager"].Permissions)
+		assert.Equal(t, 0, len(recorder.RepositoryRoleUpdated))
+	})
+
+	t.Run("happy path: not enterprise", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{}
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local, remote := fixtureRepositoryRoles()
+		lRole := &entity.RepositoryRole{}
+		lRole.Name = "security-triager"
+		lRole.Spec.BaseRole = "triage"
+		local.repoRoles["security-triager"] = lRole
+
+		localDatasource := NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", false, &repoconf, "")
+		remoteDatasource := NewGoliacReconciliatorDatasourceRemote(remote)
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, localDatasource, remoteDatasource, false, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(recorder.RepositoryRoleCreated))
+	})
+
+	t.Run("happy path: update repository role", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{}
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local, remote := fixtureRepositoryRoles()
+		lRole := &entity.RepositoryRole{}
+		lRole.Name = "security-triager"
+		lRole.Spec.BaseRole = "triage"
+		lRole.Spec.Permissions = []string{"view_dependabot_alerts", "delete_alerts_code_scanning"}
+		local.repoRoles["security-triager"] = lRole
+		remote.repositoryRoles["security-triager"] = &GithubRepositoryRole{
+			Id:          42,
+			Name:        "security-triager",
+			BaseRole:    "triage",
+			Permissions: []string{"view_dependabot_alerts"},
+		}
+
+		localDatasource := NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, "")
+		remoteDatasource := NewGoliacReconciliatorDatasourceRemote(remote)
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, localDatasource, remoteDatasource, true, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(recorder.RepositoryRoleCreated))
+		assert.Equal(t, 1, len(recorder.RepositoryRoleUpdated))
+		assert.Equal(t, 42, recorder.RepositoryRoleUpdated["security-triager"].Id)
+	})
+
+	t.Run("happy path: removed repository role is kept without destructive operations", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{}
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local, remote := fixtureRepositoryRoles()
+		remote.repositoryRoles["security-triager"] = &GithubRepositoryRole{
+			Id:       42,
+			Name:     "security-triager",
+			BaseRole: "triage",
+		}
+
+		localDatasource := NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, "")
+		remoteDatasource := NewGoliacReconciliatorDatasourceRemote(remote)
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, localDatasource, remoteDatasource, true, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(recorder.RepositoryRoleDeleted))
+	})
+
+	t.Run("happy path: delete repository role", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{}
+		repoconf.DestructiveOperations.AllowDestructiveRepositoryRoles = true
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local, remote := fixtureRepositoryRoles()
+		remote.repositoryRoles["security-triager"] = &GithubRepositoryRole{
+			Id:       42,
+			Name:     "security-triager",
+			BaseRole: "triage",
+		}
+
+		localDatasource := NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, "")
+		remoteDatasource := NewGoliacReconciliatorDatasourceRemote(remote)
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, localDatasource, remoteDatasource, true, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, []int{42}, recorder.RepositoryRoleDeleted)
+	})
+}
+
 func TestReconciliationRulesets(t *testing.T) {
 
 	t.Run("happy path: no new ruleset in goliac conf", func(t *testing.T) {
@@ -4113,3 +4597,71 @@ func TestReconciliationCustomProperties(t *testing.T) {
 		assert.Equal(t, 0, len(recorder.OrgCustomPropertyDeleted))
 	})
 }
+
+func TestReconciliationRepositoryEnforced(t *testing.T) {
+
+	t.Run("happy path: enforced value overriding the repository definition", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{}
+		enabled := true
+		repoconf.RepositoryEnforced.DeleteBranchOnMerge = &enabled
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local, remote := fixtureRepoTeamRoles()
+		local.repos["myrepo"].Spec.DeleteBranchOnMerge = false
+
+		localDatasource := NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, "")
+		remoteDatasource := NewGoliacReconciliatorDatasourceRemote(remote)
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, localDatasource, remoteDatasource, true, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.True(t, recorder.RepositoriesUpdateProperty["myrepo"])
+		assert.Equal(t, 1, len(logsCollector.Warns))
+		assert.Equal(t, "repository myrepo: enforced delete_branch_on_merge: true (instead of false) (goliac.yaml repository_enforced)", logsCollector.Warns[0].Error())
+	})
+
+	t.Run("happy path: repository definition already respecting the enforced value", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{}
+		enabled := true
+		repoconf.RepositoryEnforced.DeleteBranchOnMerge = &enabled
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local, remote := fixtureRepoTeamRoles()
+		local.repos["myrepo"].Spec.DeleteBranchOnMerge = true
+		remote.repos["myrepo"].BoolProperties["delete_branch_on_merge"] = true
+
+		localDatasource := NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, "")
+		remoteDatasource := NewGoliacReconciliatorDatasourceRemote(remote)
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, localDatasource, remoteDatasource, true, false, true, true, true)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(logsCollector.Warns))
+	})
+
+	t.Run("happy path: enforced value already applied on Github", func(t *testing.T) {
+		recorder := NewReconciliatorListenerRecorder()
+		repoconf := config.RepositoryConfig{}
+		enabled := true
+		repoconf.RepositoryEnforced.DeleteBranchOnMerge = &enabled
+		r := NewGoliacReconciliatorImpl(false, recorder, &repoconf)
+
+		local, remote := fixtureRepoTeamRoles()
+		local.repos["myrepo"].Spec.DeleteBranchOnMerge = false
+		remote.repos["myrepo"].BoolProperties["delete_branch_on_merge"] = true
+
+		localDatasource := NewGoliacReconciliatorDatasourceLocal(local, "teams", "main", true, &repoconf, "")
+		remoteDatasource := NewGoliacReconciliatorDatasourceRemote(remote)
+
+		logsCollector := observability.NewLogCollection()
+		r.Reconciliate(context.TODO(), logsCollector, localDatasource, remoteDatasource, true, false, true, true, true)
+
+		// no drift: nothing to report
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(logsCollector.Warns))
+	})
+}
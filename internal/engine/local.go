@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -64,6 +66,10 @@ type GoliacLocalGit interface {
 	LoadAndValidateLocal(fs billy.Filesystem, LogCollection *observability.LogCollection)
 
 	UpdateReposViaPullRequest(ctx context.Context, client LocalGithubClient, reposToCreate map[string]*entity.Repository, orgname, reponame, accesstoken, baseBranch, newBranchName string) (*github.PullRequest, error)
+	// convert the repositories branch protections into rulesets, return the migrated repositories [filepath: repository]
+	MigrateBranchProtections(logsCollector *observability.LogCollection) map[string]interface{}
+	// write (or overwrite) the files [filepath: yaml content] in a new branch and open a PR
+	UpdateFilesViaPullRequest(ctx context.Context, client LocalGithubClient, files map[string]interface{}, orgname, reponame, accesstoken, baseBranch, newBranchName, title string) (*github.PullRequest, error)
 }
 
 type GoliacLocalResources interface {
@@ -71,8 +77,9 @@ type GoliacLocalResources interface {
 	Repositories() map[string]*entity.Repository // reponame, repo definition
 	Users() map[string]*entity.User              // github username, user definition
 	ExternalUsers() map[string]*entity.User
-	RuleSets() map[string]*entity.RuleSet   //global rulesets
-	Workflows() map[string]*entity.Workflow // global workflows
+	RuleSets() map[string]*entity.RuleSet               //global rulesets
+	Workflows() map[string]*entity.Workflow             // global workflows
+	RepositoryRoles() map[string]*entity.RepositoryRole // org-wide custom repository roles
 	// RepositoryInWorkflow is true if the repository matches at least one loaded workflow's repository scope.
 	RepositoryInWorkflow(repository string) bool
 	RepoConfig() *config.RepositoryConfig
@@ -85,6 +92,7 @@ type GoliacLocalImpl struct {
 	externalUsers map[string]*entity.User
 	rulesets      map[string]*entity.RuleSet
 	workflows     map[string]*entity.Workflow
+	repoRoles     map[string]*entity.RepositoryRole
 	repoconfig    *config.RepositoryConfig
 	repo          *git.Repository
 }
@@ -97,6 +105,7 @@ func NewGoliacLocalImpl() GoliacLocal {
 		externalUsers: map[string]*entity.User{},
 		rulesets:      map[string]*entity.RuleSet{},
 		workflows:     map[string]*entity.Workflow{},
+		repoRoles:     map[string]*entity.RepositoryRole{},
 		repo:          nil,
 	}
 }
@@ -110,6 +119,7 @@ func NewGoliacLocalImplWithRepo(repo *git.Repository) GoliacLocal {
 		externalUsers: map[string]*entity.User{},
 		rulesets:      map[string]*entity.RuleSet{},
 		workflows:     map[string]*entity.Workflow{},
+		repoRoles:     map[string]*entity.RepositoryRole{},
 		repo:          repo,
 	}
 }
@@ -138,6 +148,10 @@ func (g *GoliacLocalImpl) Workflows() map[string]*entity.Workflow {
 	return g.workflows
 }
 
+func (g *GoliacLocalImpl) RepositoryRoles() map[string]*entity.RepositoryRole {
+	return g.repoRoles
+}
+
 func (g *GoliacLocalImpl) RepositoryInWorkflow(repository string) bool {
 	for _, w := range g.workflows {
 		ok, err := w.AppliesToRepository(repository)
@@ -368,11 +382,39 @@ func (g *GoliacLocalImpl) codeowners_regenerate(adminteam string, githubOrganiza
 }
 
 func (g *GoliacLocalImpl) buildTeamPath(teamname string) string {
-	team := g.teams[teamname]
+	return buildTeamPath(g.teams, teamname)
+}
+
+func buildTeamPath(teams map[string]*entity.Team, teamname string) string {
+	team := teams[teamname]
 	if team.ParentTeam == nil || *team.ParentTeam == "" {
 		return teamname
 	}
-	return g.buildTeamPath(*team.ParentTeam) + "/" + teamname
+	return buildTeamPath(teams, *team.ParentTeam) + "/" + teamname
+}
+
+/*
+ * CodeOwnersTeams returns the teams owning the given paths of the teams repository,
+ * following the rules of the generated CODEOWNERS file (see codeowners_regenerate):
+ * a file directly in a team directory is owned by this team, any other file by the admin team.
+ * It returns a map[teamname][]paths
+ */
+func CodeOwnersTeams(teams map[string]*entity.Team, adminteam string, paths []string) map[string][]string {
+	teamsByDirectory := make(map[string]string)
+	for teamname := range teams {
+		teamsByDirectory[path.Join("teams", buildTeamPath(teams, teamname))] = teamname
+	}
+
+	owners := make(map[string][]string)
+	for _, p := range paths {
+		p = strings.TrimPrefix(p, "/")
+		owner := adminteam
+		if teamname, ok := teamsByDirectory[path.Dir(p)]; ok {
+			owner = teamname
+		}
+		owners[owner] = append(owners[owner], p)
+	}
+	return owners
 }
 
 func (g *GoliacLocalImpl) UpdateRepos(reposToArchiveList []string, reposToRename map[string]*entity.Repository, accesstoken string, branch string, tagname string) error {
@@ -530,6 +572,45 @@ func (g *GoliacLocalImpl) UpdateRepos(reposToArchiveList []string, reposToRename
 }
 
 func (g *GoliacLocalImpl) UpdateReposViaPullRequest(ctx context.Context, client LocalGithubClient, reposToCreate map[string]*entity.Repository, orgname, reponame, accesstoken, baseBranch, newBranchName string) (*github.PullRequest, error) {
+	files := make(map[string]interface{})
+	for directoryPath, repository := range reposToCreate {
+		newRepository := *repository
+		files[filepath.Join(directoryPath, newRepository.Name+".yaml")] = &newRepository
+	}
+	return g.updateFilesViaPullRequest(ctx, client, files, orgname, reponame, accesstoken, baseBranch, newBranchName, "creating repositories", "Creating new repositories")
+}
+
+/*
+ * MigrateBranchProtections reads the cloned repositories definitions and
+ * converts their branch protections into rulesets (without writing them)
+ */
+func (g *GoliacLocalImpl) MigrateBranchProtections(logsCollector *observability.LogCollection) map[string]interface{} {
+	files := make(map[string]interface{})
+	if g.repo == nil {
+		logsCollector.AddError(fmt.Errorf("git repository not cloned"))
+		return files
+	}
+	w, err := g.repo.Worktree()
+	if err != nil {
+		logsCollector.AddError(err)
+		return files
+	}
+
+	for filename, repository := range entity.ReadAndMigrateBranchProtections(w.Filesystem, "archived", "teams", logsCollector) {
+		files[filename] = repository
+	}
+	return files
+}
+
+/*
+ * UpdateFilesViaPullRequest writes (or overwrites) yaml files in a new branch,
+ * pushes it and creates a pull request against baseBranch
+ */
+func (g *GoliacLocalImpl) UpdateFilesViaPullRequest(ctx context.Context, client LocalGithubClient, files map[string]interface{}, orgname, reponame, accesstoken, baseBranch, newBranchName, title string) (*github.PullRequest, error) {
+	return g.updateFilesViaPullRequest(ctx, client, files, orgname, reponame, accesstoken, baseBranch, newBranchName, title, title)
+}
+
+func (g *GoliacLocalImpl) updateFilesViaPullRequest(ctx context.Context, client LocalGithubClient, files map[string]interface{}, orgname, reponame, accesstoken, baseBranch, newBranchName, commitMessage, title string) (*github.PullRequest, error) {
 	if g.repo == nil {
 		return nil, fmt.Errorf("git repository not cloned")
 	}
@@ -548,12 +629,19 @@ func (g *GoliacLocalImpl) UpdateReposViaPullRequest(ctx context.Context, client
 		return nil, err
 	}
 
-	if len(reposToCreate) != 0 {
+	if len(files) != 0 {
 
-		for directoryPath, repository := range reposToCreate {
-			newRepository := *repository
+		// sort the files to have a deterministic commit
+		filenames := make([]string, 0, len(files))
+		for filename := range files {
+			filenames = append(filenames, filename)
+		}
+		sort.Strings(filenames)
 
-			filename := filepath.Join(directoryPath, newRepository.Name+".yaml")
+		for _, filename := range filenames {
+			if err := w.Filesystem.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+				return nil, fmt.Errorf("not able to create directory for %s: %v", filename, err)
+			}
 			file, err := w.Filesystem.Create(filename)
 			if err != nil {
 				return nil, fmt.Errorf("not able to create file %s: %v", filename, err)
@@ -562,7 +650,7 @@ func (g *GoliacLocalImpl) UpdateReposViaPullRequest(ctx context.Context, client
 
 			encoder := yaml.NewEncoder(file)
 			encoder.SetIndent(2)
-			err = encoder.Encode(&newRepository)
+			err = encoder.Encode(files[filename])
 			if err != nil {
 				return nil, fmt.Errorf("not able to write to file %s: %v", filename, err)
 			}
@@ -573,7 +661,7 @@ func (g *GoliacLocalImpl) UpdateReposViaPullRequest(ctx context.Context, client
 			}
 		}
 
-		_, err = w.Commit("creating repositories", &git.CommitOptions{
+		_, err = w.Commit(commitMessage, &git.CommitOptions{
 			Author: &object.Signature{
 				Name:  "Goliac",
 				Email: config.Config.GoliacEmail,
@@ -599,7 +687,7 @@ func (g *GoliacLocalImpl) UpdateReposViaPullRequest(ctx context.Context, client
 		return nil, fmt.Errorf("error pushing to remote: %v", err)
 	}
 
-	return client.CreatePullRequest(ctx, orgname, reponame, baseBranch, newBranchName, "Creating new repositories")
+	return client.CreatePullRequest(ctx, orgname, reponame, baseBranch, newBranchName, title)
 }
 
 /*
@@ -730,8 +818,13 @@ func (g *GoliacLocalImpl) UpdateAndCommitCodeOwners(ctx context.Context, repocon
  * - call the external user sync plugin
  * - collect the difference
  * - returns deleted users, and add/updated users
+ *
+ * With usersync.offboarding_grace_days, a user removed from the usersync source is
+ * first suspended (until its removal date), and deleted once the removal date is reached.
+ * A sync removing (or suspending) more than usersync.max_removal_percent of the users
+ * is aborted (unless forced).
  */
-func syncUsersViaUserPlugin(repoconfig *config.RepositoryConfig, fs billy.Filesystem, userplugin UserSyncPlugin, feedback observability.RemoteObservability, LogCollection *observability.LogCollection) ([]string, []string) {
+func syncUsersViaUserPlugin(repoconfig *config.RepositoryConfig, fs billy.Filesystem, userplugin UserSyncPlugin, force bool, now time.Time, feedback observability.RemoteObservability, LogCollection *observability.LogCollection) ([]string, []string) {
 	usersOrgPath := filepath.Join("users", "org")
 	orgUsers := entity.ReadUserDirectory(fs, usersOrgPath, LogCollection)
 	if LogCollection.HasErrors() {
@@ -744,50 +837,91 @@ func syncUsersViaUserPlugin(repoconfig *config.RepositoryConfig, fs billy.Filesy
 		return nil, nil
 	}
 
-	// write back to disk
-	deletedusers := []string{}
-	updatedusers := []string{}
+	// compute the changes
+	graceDays := repoconfig.UserSync.OffboardingGraceDays
+	toDelete := []string{}
+	toWrite := make(map[string]*entity.User)
+	nbRemoved := 0
 	for username, user := range orgUsers {
-		if newuser, ok := newOrgUsers[username]; !ok {
-			// deleted user
-			deletedusers = append(deletedusers, filepath.Join(usersOrgPath, fmt.Sprintf("%s.yaml", username)))
-			fs.Remove(filepath.Join(usersOrgPath, fmt.Sprintf("%s.yaml", username)))
+		newuser, ok := newOrgUsers[username]
+		delete(newOrgUsers, username)
+		if ok {
+			newuser = mergeUserAttributes(newuser, user)
 		} else {
-			// check if user changed
-			if !newuser.Equals(user) {
-				// changed user
-				file, err := fs.Create(filepath.Join(usersOrgPath, fmt.Sprintf("%s.yaml", username)))
-				if err != nil {
-					LogCollection.AddError(err)
-					return nil, nil
-				}
-				defer file.Close()
-
-				encoder := yaml.NewEncoder(file)
-				encoder.SetIndent(2)
-				err = encoder.Encode(newuser)
-				if err != nil {
-					LogCollection.AddError(err)
-					return nil, nil
-				}
-				updatedusers = append(updatedusers, filepath.Join(usersOrgPath, fmt.Sprintf("%s.yaml", username)))
+			switch {
+			case graceDays > 0 && !user.IsSuspended():
+				// offboarding: suspended until the end of the grace period
+				suspended := *user
+				suspended.Spec.Status = entity.USER_STATUS_SUSPENDED
+				suspended.Spec.RemovalDate = now.AddDate(0, 0, graceDays).Format(entity.USER_REMOVAL_DATE_FORMAT)
+				newuser = &suspended
+				nbRemoved++
+			case graceDays > 0 && !user.RemovalDue(now):
+				// still in its grace period
+				continue
+			default:
+				// deleted user
+				toDelete = append(toDelete, username)
+				nbRemoved++
+				continue
 			}
-
-			delete(newOrgUsers, username)
+		}
+		if newuser.RemovalDue(now) {
+			toDelete = append(toDelete, username)
+			continue
+		}
+		// check if user changed
+		if !newuser.Equals(user) {
+			toWrite[username] = newuser
 		}
 	}
 	for username, user := range newOrgUsers {
 		// new user
+		toWrite[username] = user
+	}
+
+	// check if we remove too many users (an usersync source outage?)
+	maxRemovalPercent := repoconfig.UserSync.MaxRemovalPercent
+	if !force && maxRemovalPercent > 0 && len(orgUsers) > 0 && nbRemoved*100 > maxRemovalPercent*len(orgUsers) {
+		LogCollection.AddError(fmt.Errorf("the users sync would remove %d users out of %d (more than %d%%): aborting. Please check the usersync source, or increase usersync.max_removal_percent in goliac.yaml", nbRemoved, len(orgUsers), maxRemovalPercent))
+		return nil, nil
+	}
+
+	// write back to disk
+	deletedusers := []string{}
+	updatedusers := []string{}
+	sort.Strings(toDelete)
+	for _, username := range toDelete {
+		deletedusers = append(deletedusers, filepath.Join(usersOrgPath, fmt.Sprintf("%s.yaml", username)))
+		fs.Remove(filepath.Join(usersOrgPath, fmt.Sprintf("%s.yaml", username)))
+	}
+	// updated users first, then the new ones
+	usernames := make([]string, 0, len(toWrite))
+	for username := range toWrite {
+		if _, ok := orgUsers[username]; ok {
+			usernames = append(usernames, username)
+		}
+	}
+	sort.Strings(usernames)
+	nbUpdated := len(usernames)
+	for username := range toWrite {
+		if _, ok := orgUsers[username]; !ok {
+			usernames = append(usernames, username)
+		}
+	}
+	sort.Strings(usernames[nbUpdated:])
+	for _, username := range usernames {
 		file, err := fs.Create(filepath.Join(usersOrgPath, fmt.Sprintf("%s.yaml", username)))
 		if err != nil {
 			LogCollection.AddError(err)
 			return nil, nil
 		}
-		defer file.Close()
-
 		encoder := yaml.NewEncoder(file)
 		encoder.SetIndent(2)
-		err = encoder.Encode(user)
+		err = encoder.Encode(toWrite[username])
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
 		if err != nil {
 			LogCollection.AddError(err)
 			return nil, nil
@@ -797,6 +931,33 @@ func syncUsersViaUserPlugin(repoconfig *config.RepositoryConfig, fs billy.Filesy
 	return deletedusers, updatedusers
 }
 
+/**
+ * mergeUserAttributes returns the user given by the usersync plugin, with the
+ * optional attributes (email, full name, manager, labels) the plugin doesn't
+ * provide taken from the current user definition (that may have been written by hand).
+ * A manual suspension (without removal date, unlike the offboarding ones) is kept
+ * unless the plugin reports a status itself.
+ */
+func mergeUserAttributes(newuser *entity.User, user *entity.User) *entity.User {
+	merged := *newuser
+	if merged.Spec.Email == "" {
+		merged.Spec.Email = user.Spec.Email
+	}
+	if merged.Spec.FullName == "" {
+		merged.Spec.FullName = user.Spec.FullName
+	}
+	if merged.Spec.Manager == "" {
+		merged.Spec.Manager = user.Spec.Manager
+	}
+	if len(merged.Spec.Labels) == 0 {
+		merged.Spec.Labels = user.Spec.Labels
+	}
+	if merged.Spec.Status == "" && user.IsSuspended() && user.Spec.RemovalDate == "" {
+		merged.Spec.Status = user.Spec.Status
+	}
+	return &merged
+}
+
 // return true if some changes were done
 func (g *GoliacLocalImpl) SyncUsersAndTeams(ctx context.Context, repoconfig *config.RepositoryConfig, userplugin UserSyncPlugin, accesstoken string, dryrun bool, force bool, feedback observability.RemoteObservability, logsCollector *observability.LogCollection) bool {
 	var childSpan trace.Span
@@ -819,7 +980,7 @@ func (g *GoliacLocalImpl) SyncUsersAndTeams(ctx context.Context, repoconfig *con
 	//
 
 	// Parse all the users in the <orgDirectory>/org-users directory
-	deletedusers, addedusers := syncUsersViaUserPlugin(repoconfig, w.Filesystem, userplugin, feedback, logsCollector)
+	deletedusers, addedusers := syncUsersViaUserPlugin(repoconfig, w.Filesystem, userplugin, force, time.Now(), feedback, logsCollector)
 	if logsCollector.HasErrors() {
 		return false
 	}
@@ -833,11 +994,30 @@ func (g *GoliacLocalImpl) SyncUsersAndTeams(ctx context.Context, repoconfig *con
 		return false
 	}
 
+	// teams synced from a group (spec.sync_from, spec.sync_owners_from, spec.ldapGroup)
+	groupteamschanged, err := entity.ReadAndSyncTeamGroupDirectory(w.Filesystem, "teams", g.users, func(groups []string) (map[string][]string, error) {
+		groupplugin, ok := userplugin.(UserSyncGroupPlugin)
+		if !ok {
+			logsCollector.AddWarn(fmt.Errorf("the usersync plugin %s cannot sync teams from groups (%s)", repoconfig.UserSync.Plugin, strings.Join(groups, ", ")))
+			return map[string][]string{}, nil
+		}
+		return groupplugin.GroupsMembers(repoconfig, groups, logsCollector)
+	})
+	if err != nil {
+		logsCollector.AddError(err)
+		return false
+	}
+
 	teamschanged, err := entity.ReadAndAdjustTeamDirectory(w.Filesystem, "teams", g.users)
 	if err != nil {
 		logsCollector.AddError(err)
 		return false
 	}
+	for _, t := range groupteamschanged {
+		if !slices.Contains(teamschanged, t) {
+			teamschanged = append(teamschanged, t)
+		}
+	}
 
 	//
 	// let's update repositories
@@ -973,6 +1153,25 @@ func (g *GoliacLocalImpl) loadUsers(fs billy.Filesystem, LogCollection *observab
 		g.users[k] = v
 	}
 
+	for _, user := range g.users {
+		if user.Spec.Manager != "" {
+			if _, ok := g.users[user.Spec.Manager]; !ok {
+				LogCollection.AddWarn(fmt.Errorf("manager %s of user %s doesn't exist", user.Spec.Manager, user.Name))
+			}
+		}
+	}
+
+	if g.repoconfig != nil {
+		for _, admin := range g.repoconfig.OrgAdmins {
+			user, ok := g.users[admin]
+			if !ok {
+				LogCollection.AddError(fmt.Errorf("org_admins: user %s doesn't exist", admin))
+			} else if user.IsSuspended() {
+				LogCollection.AddWarn(fmt.Errorf("org_admins: user %s is suspended and will not be an organization owner", admin))
+			}
+		}
+	}
+
 	// Parse all the users in the <orgDirectory>/external-users directory
 	externalUsers := entity.ReadUserDirectory(fs, filepath.Join("users", "external"), LogCollection)
 	g.externalUsers = externalUsers
@@ -1002,7 +1201,7 @@ func (g *GoliacLocalImpl) LoadAndValidateLocal(fs billy.Filesystem, LogCollectio
 	g.teams = teams
 
 	// Parse all repositories in the <orgDirectory>/teams/<teamname> directories
-	repos := entity.ReadRepositories(fs, "archived", "teams", g.teams, g.externalUsers, g.users, g.repoconfig.OrgCustomProperties, LogCollection)
+	repos := entity.ReadRepositories(fs, "archived", "teams", g.teams, g.externalUsers, g.users, g.repoconfig.OrgCustomProperties, &g.repoconfig.RepositoryDefaults, LogCollection)
 	g.repositories = repos
 
 	repoNames := make([]string, 0, len(g.repositories))
@@ -1012,6 +1211,16 @@ func (g *GoliacLocalImpl) LoadAndValidateLocal(fs billy.Filesystem, LogCollectio
 	rulesets := entity.ReadRuleSetDirectory(fs, "rulesets", repoNames, LogCollection)
 	g.rulesets = rulesets
 
+	// Parse all the custom repository roles in the <orgDirectory>/repository-roles directory
+	g.repoRoles = entity.ReadRepositoryRoleDirectory(fs, "repository-roles", LogCollection)
+	for reponame, repo := range g.repositories {
+		for role := range repo.Spec.Roles {
+			if _, ok := g.repoRoles[role]; !ok {
+				LogCollection.AddError(fmt.Errorf("invalid role: %s doesn't exist in repository %s (not defined in repository-roles)", role, reponame))
+			}
+		}
+	}
+
 	workflows := entity.ReadWorkflowDirectory(fs, "workflows", LogCollection)
 	g.workflows = make(map[string]*entity.Workflow)
 	for _, v := range repoconfig.Workflows {
@@ -1020,10 +1229,15 @@ func (g *GoliacLocalImpl) LoadAndValidateLocal(fs billy.Filesystem, LogCollectio
 		}
 	}
 
+	// evaluate the organization policies (policies/ directory)
+	policies := entity.ReadPolicyDirectory(fs, "policies", LogCollection)
+	entity.EvaluatePolicies(policies, g.teams, g.repositories, g.users, g.externalUsers, g.rulesets, g.workflows, LogCollection)
+
 	logrus.Debugf("Nb local users: %d", len(g.users))
 	logrus.Debugf("Nb local external users: %d", len(g.externalUsers))
 	logrus.Debugf("Nb local teams: %d", len(g.teams))
 	logrus.Debugf("Nb local repositories: %d", len(g.repositories))
 	logrus.Debugf("Nb local rulesets: %d", len(g.rulesets))
 	logrus.Debugf("Nb local workflows: %d", len(g.workflows))
+	logrus.Debugf("Nb local repository roles: %d", len(g.repoRoles))
 }
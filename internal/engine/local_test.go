@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/goliac-project/goliac/internal/utils"
 	"github.com/google/go-github/v55/github"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
 )
 
 func createBasicStructure(fs billy.Filesystem) error {
@@ -104,6 +106,104 @@ func TestRepository(t *testing.T) {
 		assert.False(t, logsCollector.HasWarns())
 	})
 
+	t.Run("happy path: org_admins", func(t *testing.T) {
+		fs := memfs.New()
+		createBasicStructure(fs)
+		utils.WriteFile(fs, "goliac.yaml", []byte(`
+org_admins:
+- user1
+`), 0644)
+		g := NewGoliacLocalImpl()
+		logsCollector := observability.NewLogCollection()
+		g.LoadAndValidateLocal(fs, logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, []string{"user1"}, g.RepoConfig().OrgAdmins)
+	})
+
+	t.Run("not happy path: unknown org_admins user", func(t *testing.T) {
+		fs := memfs.New()
+		createBasicStructure(fs)
+		utils.WriteFile(fs, "goliac.yaml", []byte(`
+org_admins:
+- user1
+- unknown
+`), 0644)
+		g := NewGoliacLocalImpl()
+		logsCollector := observability.NewLogCollection()
+		g.LoadAndValidateLocal(fs, logsCollector)
+
+		assert.True(t, logsCollector.HasErrors())
+	})
+
+	t.Run("happy path: repository roles", func(t *testing.T) {
+		fs := memfs.New()
+		createBasicStructure(fs)
+		utils.WriteFile(fs, "repository-roles/security-triager.yaml", []byte(`
+apiVersion: v1
+kind: RepositoryRole
+name: security-triager
+spec:
+  base_role: triage
+  permissions:
+  - view_dependabot_alerts
+`), 0644)
+		utils.WriteFile(fs, "teams/team2/team.yaml", []byte(`
+apiVersion: v1
+kind: Team
+name: team2
+spec:
+  owners:
+  - user1
+  - user2
+`), 0644)
+		utils.WriteFile(fs, "teams/team1/repo2.yaml", []byte(`
+apiVersion: v1
+kind: Repository
+name: repo2
+spec:
+  roles:
+    security-triager:
+    - team2
+`), 0644)
+		g := NewGoliacLocalImpl()
+		logsCollector := observability.NewLogCollection()
+		g.LoadAndValidateLocal(fs, logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 1, len(g.RepositoryRoles()))
+		assert.Equal(t, map[string][]string{"security-triager": {"team2"}}, g.Repositories()["repo2"].Spec.Roles)
+		assert.Equal(t, "triage", g.RepositoryRoles()["security-triager"].Spec.BaseRole)
+	})
+
+	t.Run("not happy path: undefined repository role", func(t *testing.T) {
+		fs := memfs.New()
+		createBasicStructure(fs)
+		utils.WriteFile(fs, "teams/team2/team.yaml", []byte(`
+apiVersion: v1
+kind: Team
+name: team2
+spec:
+  owners:
+  - user1
+  - user2
+`), 0644)
+		utils.WriteFile(fs, "teams/team1/repo2.yaml", []byte(`
+apiVersion: v1
+kind: Repository
+name: repo2
+spec:
+  roles:
+    security-triager:
+    - team2
+`), 0644)
+		g := NewGoliacLocalImpl()
+		logsCollector := observability.NewLogCollection()
+		g.LoadAndValidateLocal(fs, logsCollector)
+
+		assert.True(t, logsCollector.HasErrors())
+	})
+
 	t.Run("happy path: local repository", func(t *testing.T) {
 		fs := memfs.New()
 		storer := memory.NewStorage()
@@ -172,6 +272,26 @@ func (p *ScrambleUserSync) UpdateUsers(repoconfig *config.RepositoryConfig, fs b
 	return users
 }
 
+// ListUserSync returns the userN users (with the githubN github id)
+type ListUserSync struct {
+	usernames []string
+	statuses  map[string]string // optional status reported per user
+}
+
+func (p *ListUserSync) UpdateUsers(repoconfig *config.RepositoryConfig, fs billy.Filesystem, orguserdirrectorypath string, feedback observability.RemoteObservability, logsCollector *observability.LogCollection) map[string]*entity.User {
+	users := make(map[string]*entity.User)
+	for _, name := range p.usernames {
+		user := &entity.User{}
+		user.ApiVersion = "v1"
+		user.Kind = "User"
+		user.Name = name
+		user.Spec.GithubID = strings.Replace(name, "user", "github", 1)
+		user.Spec.Status = p.statuses[name]
+		users[name] = user
+	}
+	return users
+}
+
 type ErroreUserSync struct {
 }
 
@@ -198,7 +318,7 @@ func TestSyncUsersViaUserPlugin(t *testing.T) {
 		createBasicStructure(fs)
 
 		logsCollector := observability.NewLogCollection()
-		removed, added := syncUsersViaUserPlugin(&config.RepositoryConfig{}, fs, &UserSyncPluginNoop{}, nil, logsCollector)
+		removed, added := syncUsersViaUserPlugin(&config.RepositoryConfig{}, fs, &UserSyncPluginNoop{}, false, time.Now(), nil, logsCollector)
 
 		assert.False(t, logsCollector.HasErrors())
 		assert.Equal(t, 0, len(removed))
@@ -210,7 +330,7 @@ func TestSyncUsersViaUserPlugin(t *testing.T) {
 		createBasicStructure(fs)
 
 		logsCollector := observability.NewLogCollection()
-		removed, added := syncUsersViaUserPlugin(&config.RepositoryConfig{}, fs, &ScrambleUserSync{}, nil, logsCollector)
+		removed, added := syncUsersViaUserPlugin(&config.RepositoryConfig{}, fs, &ScrambleUserSync{}, false, time.Now(), nil, logsCollector)
 
 		assert.False(t, logsCollector.HasErrors())
 		assert.Equal(t, 1, len(removed))
@@ -218,15 +338,175 @@ func TestSyncUsersViaUserPlugin(t *testing.T) {
 		assert.Equal(t, "users/org/user1.yaml", added[0])
 		assert.Equal(t, "users/org/foobar.yaml", added[1])
 	})
+	t.Run("happy path: hand-written attributes kept", func(t *testing.T) {
+		fs := memfs.New()
+		createBasicStructure(fs)
+		err := utils.WriteFile(fs, "users/org/user1.yaml", []byte(`
+apiVersion: v1
+kind: User
+name: user1
+spec:
+  githubID: github1
+  manager: user2
+  labels:
+    cost-center: rd
+`), 0644)
+		assert.Nil(t, err)
+
+		// noop sync: nothing changes
+		logsCollector := observability.NewLogCollection()
+		removed, added := syncUsersViaUserPlugin(&config.RepositoryConfig{}, fs, &UserSyncPluginNoop{}, false, time.Now(), nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(removed))
+		assert.Equal(t, 0, len(added))
+
+		// a sync without manager and labels (like fromgithubsaml): nothing changes
+		removed, added = syncUsersViaUserPlugin(&config.RepositoryConfig{}, fs, &ListUserSync{usernames: []string{"user1", "user2"}}, false, time.Now(), nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(removed))
+		assert.Equal(t, 0, len(added))
+		user1, err := entity.NewUser(fs, "users/org/user1.yaml")
+		assert.Nil(t, err)
+		assert.Equal(t, "user2", user1.Spec.Manager)
+		assert.Equal(t, map[string]string{"cost-center": "rd"}, user1.Spec.Labels)
+	})
+
 	t.Run("not happy path: dealing with usersync error", func(t *testing.T) {
 		fs := memfs.New()
 		createBasicStructure(fs)
 
 		logsCollector := observability.NewLogCollection()
-		syncUsersViaUserPlugin(&config.RepositoryConfig{}, fs, &ErroreUserSync{}, nil, logsCollector)
+		syncUsersViaUserPlugin(&config.RepositoryConfig{}, fs, &ErroreUserSync{}, false, time.Now(), nil, logsCollector)
 
 		assert.True(t, logsCollector.HasErrors())
 	})
+
+	t.Run("happy path: offboarding grace period", func(t *testing.T) {
+		fs := memfs.New()
+		createBasicStructure(fs)
+		repoconfig := &config.RepositoryConfig{}
+		repoconfig.UserSync.OffboardingGraceDays = 30
+		now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+		// user2 disappears: suspended (and not deleted)
+		userplugin := &ListUserSync{usernames: []string{"user1"}}
+		logsCollector := observability.NewLogCollection()
+		removed, added := syncUsersViaUserPlugin(repoconfig, fs, userplugin, false, now, nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(removed))
+		assert.Equal(t, []string{"users/org/user2.yaml"}, added)
+		user2, err := entity.NewUser(fs, "users/org/user2.yaml")
+		assert.Nil(t, err)
+		assert.True(t, user2.IsSuspended())
+		assert.Equal(t, "2025-03-31", user2.Spec.RemovalDate)
+
+		// during the grace period: no change
+		removed, added = syncUsersViaUserPlugin(repoconfig, fs, userplugin, false, now.AddDate(0, 0, 10), nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(removed))
+		assert.Equal(t, 0, len(added))
+
+		// at the end of the grace period: deleted
+		removed, _ = syncUsersViaUserPlugin(repoconfig, fs, userplugin, false, now.AddDate(0, 0, 30), nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, []string{"users/org/user2.yaml"}, removed)
+	})
+
+	t.Run("happy path: suspended user back in the usersync source", func(t *testing.T) {
+		fs := memfs.New()
+		createBasicStructure(fs)
+		err := utils.WriteFile(fs, "users/org/user2.yaml", []byte(`
+apiVersion: v1
+kind: User
+name: user2
+spec:
+  githubID: github2
+  status: suspended
+  removal_date: "2025-03-31"
+`), 0644)
+		assert.Nil(t, err)
+		repoconfig := &config.RepositoryConfig{}
+		repoconfig.UserSync.OffboardingGraceDays = 30
+
+		logsCollector := observability.NewLogCollection()
+		removed, added := syncUsersViaUserPlugin(repoconfig, fs, &ListUserSync{usernames: []string{"user1", "user2"}}, false, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(removed))
+		assert.Equal(t, []string{"users/org/user2.yaml"}, added)
+		user2, err := entity.NewUser(fs, "users/org/user2.yaml")
+		assert.Nil(t, err)
+		assert.False(t, user2.IsSuspended())
+	})
+
+	t.Run("happy path: manually suspended user kept suspended", func(t *testing.T) {
+		fs := memfs.New()
+		createBasicStructure(fs)
+		err := utils.WriteFile(fs, "users/org/user2.yaml", []byte(`
+apiVersion: v1
+kind: User
+name: user2
+spec:
+  githubID: github2
+  status: suspended
+`), 0644)
+		assert.Nil(t, err)
+
+		// the plugin doesn't report a status: the suspension is kept
+		logsCollector := observability.NewLogCollection()
+		removed, added := syncUsersViaUserPlugin(&config.RepositoryConfig{}, fs, &ListUserSync{usernames: []string{"user1", "user2"}}, false, time.Now(), nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(removed))
+		assert.Equal(t, 0, len(added))
+		user2, err := entity.NewUser(fs, "users/org/user2.yaml")
+		assert.Nil(t, err)
+		assert.True(t, user2.IsSuspended())
+
+		// the plugin reports the user as active: reactivated
+		removed, added = syncUsersViaUserPlugin(&config.RepositoryConfig{}, fs, &ListUserSync{usernames: []string{"user1", "user2"}, statuses: map[string]string{"user2": entity.USER_STATUS_ACTIVE}}, false, time.Now(), nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(removed))
+		assert.Equal(t, []string{"users/org/user2.yaml"}, added)
+		user2, err = entity.NewUser(fs, "users/org/user2.yaml")
+		assert.Nil(t, err)
+		assert.False(t, user2.IsSuspended())
+	})
+
+	t.Run("not happy path: too many users removed", func(t *testing.T) {
+		fs := memfs.New()
+		createBasicStructure(fs)
+		repoconfig := &config.RepositoryConfig{}
+		repoconfig.UserSync.MaxRemovalPercent = 40
+
+		// 1 user out of 2 removed
+		logsCollector := observability.NewLogCollection()
+		removed, added := syncUsersViaUserPlugin(repoconfig, fs, &ScrambleUserSync{}, false, time.Now(), nil, logsCollector)
+		assert.True(t, logsCollector.HasErrors())
+		assert.Nil(t, removed)
+		assert.Nil(t, added)
+		exist, _ := utils.Exists(fs, "users/org/user2.yaml")
+		assert.True(t, exist)
+
+		// unless forced
+		logsCollector = observability.NewLogCollection()
+		removed, _ = syncUsersViaUserPlugin(repoconfig, fs, &ScrambleUserSync{}, true, time.Now(), nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 1, len(removed))
+	})
+
+	t.Run("not happy path: all users removed with the default guard", func(t *testing.T) {
+		fs := memfs.New()
+		createBasicStructure(fs)
+		var repoconfig config.RepositoryConfig
+		err := yaml.Unmarshal([]byte("usersync:\n  plugin: noop\n"), &repoconfig)
+		assert.Nil(t, err)
+		assert.Equal(t, 50, repoconfig.UserSync.MaxRemovalPercent)
+
+		logsCollector := observability.NewLogCollection()
+		removed, added := syncUsersViaUserPlugin(&repoconfig, fs, &ListUserSync{}, false, time.Now(), nil, logsCollector)
+		assert.True(t, logsCollector.HasErrors())
+		assert.Nil(t, removed)
+		assert.Nil(t, added)
+	})
 }
 
 func createEmptyTeamRepo(src billy.Filesystem) (*git.Repository, error) {
@@ -822,6 +1102,43 @@ func TestGoliacLocalImpl(t *testing.T) {
 		assert.NotNil(t, pr)
 	})
 
+	t.Run("UpdateFilesViaPullRequest", func(t *testing.T) {
+		rootfs := memfs.New()
+		src, _ := rootfs.Chroot("/src")
+		target, _ := src.Chroot("/target")
+
+		repo, clonedRepo, err := helperCreateAndClone(rootfs, src, target)
+		assert.Nil(t, err)
+		assert.NotNil(t, repo)
+		assert.NotNil(t, clonedRepo)
+
+		g := GoliacLocalImpl{
+			teams:         map[string]*entity.Team{},
+			repositories:  map[string]*entity.Repository{},
+			users:         map[string]*entity.User{},
+			externalUsers: map[string]*entity.User{},
+			rulesets:      map[string]*entity.RuleSet{},
+			repo:          clonedRepo,
+		}
+
+		localClient := &MockLocalGithubClient{}
+		newuser := entity.User{}
+		newuser.ApiVersion = "v1"
+		newuser.Kind = "User"
+		newuser.Name = "newuser"
+		newuser.Spec.GithubID = "newuser_gh"
+
+		localClient.On("CreatePullRequest", context.TODO(), "a_org", "a_repo", "a_branch", "a_newbranch", "Adopting resources").Return(&github.PullRequest{}, nil)
+		pr, err := g.UpdateFilesViaPullRequest(context.TODO(), localClient, map[string]interface{}{"users/org/newuser.yaml": &newuser}, "a_org", "a_repo", "a_accesstoken", "a_branch", "a_newbranch", "Adopting resources")
+
+		assert.Nil(t, err)
+		assert.NotNil(t, pr)
+
+		content, err := utils.ReadFile(target, "users/org/newuser.yaml")
+		assert.Nil(t, err)
+		assert.Equal(t, "apiVersion: v1\nkind: User\nname: newuser\nspec:\n  githubID: newuser_gh\n", string(content))
+	})
+
 	t.Run("UpdateAndCommitCodeOwners", func(t *testing.T) {
 		rootfs := memfs.New()
 		src, _ := rootfs.Chroot("/src")
@@ -906,6 +1223,83 @@ func TestGoliacLocalImpl(t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, "apiVersion: v1\nkind: User\nname: foobar\nspec:\n  githubID: foobar\n", string(content))
 	})
+
+	t.Run("SyncUsersAndTeams with a group plugin", func(t *testing.T) {
+		rootfs := memfs.New()
+		src, _ := rootfs.Chroot("/src")
+		target, _ := src.Chroot("/target")
+
+		repo, clonedRepo, err := helperCreateAndClone(rootfs, src, target)
+		assert.Nil(t, err)
+		assert.NotNil(t, repo)
+
+		g := GoliacLocalImpl{
+			teams:         map[string]*entity.Team{},
+			repositories:  map[string]*entity.Repository{},
+			users:         map[string]*entity.User{},
+			externalUsers: map[string]*entity.User{},
+			rulesets:      map[string]*entity.RuleSet{},
+			repo:          clonedRepo,
+		}
+
+		wt, err := clonedRepo.Worktree()
+		assert.Nil(t, err)
+		goliacConfig, err := g.loadRepoConfig(wt.Filesystem)
+		assert.Nil(t, err)
+
+		err = utils.WriteFile(wt.Filesystem, "teams/ldapteam/team.yaml", []byte(`apiVersion: v1
+kind: Team
+name: ldapteam
+spec:
+  ldapGroup: cn=ldapteam,ou=groups,dc=company,dc=com
+  owners:
+    - admin
+`), 0644)
+		assert.Nil(t, err)
+
+		mockUserPlugin := &UserSyncGroupPluginMock{}
+
+		logsCollector := observability.NewLogCollection()
+		change := g.SyncUsersAndTeams(context.TODO(), goliacConfig, mockUserPlugin, "none", false, false, nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.True(t, change)
+
+		content, err := utils.ReadFile(target, "teams/ldapteam/team.yaml")
+		assert.Nil(t, err)
+		assert.Equal(t, "apiVersion: v1\nkind: Team\nname: ldapteam\nspec:\n  ldapGroup: cn=ldapteam,ou=groups,dc=company,dc=com\n  owners:\n    - admin\n  members:\n    - foobar\n", string(content))
+	})
+}
+
+func TestCodeOwnersTeams(t *testing.T) {
+	t.Run("happy path: teams owning the paths", func(t *testing.T) {
+		adminTeam := entity.Team{}
+		adminTeam.Name = "github-admins"
+		team1 := entity.Team{}
+		team1.Name = "team1"
+		subTeam := entity.Team{}
+		subTeam.Name = "subteam"
+		parentTeam := "team1"
+		subTeam.ParentTeam = &parentTeam
+
+		teams := map[string]*entity.Team{
+			"github-admins": &adminTeam,
+			"team1":         &team1,
+			"subteam":       &subTeam,
+		}
+
+		owners := CodeOwnersTeams(teams, "github-admins", []string{
+			"teams/team1/repo1.yaml",
+			"teams/team1/subteam/team.yaml",
+			"/teams/team1/subteam/repo2.yaml",
+			"teams/team1/newteam/team.yaml", // not a team (yet): owned by the admin team
+			"users/org/user1.yaml",
+		})
+
+		assert.Equal(t, 3, len(owners))
+		assert.Equal(t, []string{"teams/team1/repo1.yaml"}, owners["team1"])
+		assert.Equal(t, []string{"teams/team1/subteam/team.yaml", "teams/team1/subteam/repo2.yaml"}, owners["subteam"])
+		assert.Equal(t, []string{"teams/team1/newteam/team.yaml", "users/org/user1.yaml"}, owners["github-admins"])
+	})
 }
 
 func TestGoliacLocalImplRepositoryInWorkflow(t *testing.T) {
@@ -939,3 +1333,13 @@ func (us *UserSyncPluginMock) UpdateUsers(repoconfig *config.RepositoryConfig, f
 
 	return users
 }
+
+type UserSyncGroupPluginMock struct {
+	UserSyncPluginMock
+}
+
+func (us *UserSyncGroupPluginMock) GroupsMembers(repoconfig *config.RepositoryConfig, groups []string, logsCollector *observability.LogCollection) (map[string][]string, error) {
+	return map[string][]string{
+		"cn=ldapteam,ou=groups,dc=company,dc=com": {"admin", "foobar", "unknown"},
+	}, nil
+}
@@ -2,6 +2,8 @@ package engine
 
 import (
 	"context"
+	"slices"
+	"strings"
 
 	"github.com/goliac-project/goliac/internal/config"
 	"github.com/goliac-project/goliac/internal/entity"
@@ -76,6 +78,7 @@ type MutableGoliacRemoteImpl struct {
 	repositories        map[string]*GithubRepoComparable
 	teams               map[string]*GithubTeamComparable
 	rulesets            map[string]*GithubRuleSet
+	repositoryRoles     map[string]*GithubRepositoryRole
 	orgCustomProperties map[string]*config.GithubCustomProperty
 }
 
@@ -129,6 +132,20 @@ func NewMutableGoliacRemoteImpl(ctx context.Context, remote GoliacReconciliatorD
 		copy(ghExternalUsersWriters, v.ExternalUserWriters)
 		rRepositories[k].ExternalUserWriters = ghExternalUsersWriters
 
+		ghMaintainers := make([]string, len(v.Maintainers))
+		copy(ghMaintainers, v.Maintainers)
+		rRepositories[k].Maintainers = ghMaintainers
+
+		ghTriagers := make([]string, len(v.Triagers))
+		copy(ghTriagers, v.Triagers)
+		rRepositories[k].Triagers = ghTriagers
+
+		ghCustomRoles := make(map[string]string)
+		for k, v := range v.CustomRoles {
+			ghCustomRoles[k] = v
+		}
+		rRepositories[k].CustomRoles = ghCustomRoles
+
 		ghInternalUsers := make([]string, len(v.InternalUsers))
 		copy(ghInternalUsers, v.InternalUsers)
 		rRepositories[k].InternalUsers = ghInternalUsers
@@ -168,6 +185,18 @@ func NewMutableGoliacRemoteImpl(ctx context.Context, remote GoliacReconciliatorD
 		rrulesets[k] = &ghRuleset
 	}
 
+	rrepositoryRoles := make(map[string]*GithubRepositoryRole)
+	repositoryRoles, err := remote.RepositoryRoles()
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range repositoryRoles {
+		ghRole := *v
+		ghRole.Permissions = make([]string, len(v.Permissions))
+		copy(ghRole.Permissions, v.Permissions)
+		rrepositoryRoles[k] = &ghRole
+	}
+
 	// Get org custom properties from remote if it implements GoliacRemote
 	rorgCustomProperties := make(map[string]*config.GithubCustomProperty)
 	orgProps := remote.OrgCustomProperties(ctx)
@@ -183,6 +212,7 @@ func NewMutableGoliacRemoteImpl(ctx context.Context, remote GoliacReconciliatorD
 		repositories:        rRepositories,
 		teams:               rTeams,
 		rulesets:            rrulesets,
+		repositoryRoles:     rrepositoryRoles,
 		orgCustomProperties: rorgCustomProperties,
 	}, nil
 }
@@ -202,6 +232,10 @@ func (m *MutableGoliacRemoteImpl) RuleSets() map[string]*GithubRuleSet {
 	return m.rulesets
 }
 
+func (m *MutableGoliacRemoteImpl) RepositoryRoles() map[string]*GithubRepositoryRole {
+	return m.repositoryRoles
+}
+
 func (m *MutableGoliacRemoteImpl) OrgCustomProperties() map[string]*config.GithubCustomProperty {
 	return m.orgCustomProperties
 }
@@ -209,7 +243,12 @@ func (m *MutableGoliacRemoteImpl) OrgCustomProperties() map[string]*config.Githu
 // LISTENER
 
 func (m *MutableGoliacRemoteImpl) AddUserToOrg(ghuserid string) {
-	m.users[ghuserid] = ghuserid
+	m.users[ghuserid] = "MEMBER"
+}
+
+// role can be 'admin' or 'member'
+func (m *MutableGoliacRemoteImpl) UpdateUserOrgRole(ghuserid string, role string) {
+	m.users[ghuserid] = strings.ToUpper(role)
 }
 
 func (m *MutableGoliacRemoteImpl) RemoveUserFromOrg(ghuserid string) {
@@ -298,6 +337,9 @@ func (m *MutableGoliacRemoteImpl) CreateRepository(reponame string, descrition s
 		BoolProperties:      boolProperties,
 		Writers:             writers,
 		Readers:             readers,
+		Maintainers:         []string{},
+		Triagers:            []string{},
+		CustomRoles:         map[string]string{},
 		ExternalUserReaders: []string{},
 		ExternalUserWriters: []string{},
 		InternalUsers:       []string{},
@@ -312,51 +354,55 @@ func (m *MutableGoliacRemoteImpl) CreateRepository(reponame string, descrition s
 }
 func (m *MutableGoliacRemoteImpl) UpdateRepositoryAddTeamAccess(reponame string, teamslug string, permission string) {
 	if r, ok := m.repositories[reponame]; ok {
-		if permission == "pull" {
-			r.Readers = append(r.Readers, teamslug)
-		} else if permission == "push" {
-			r.Writers = append(r.Writers, teamslug)
-		}
+		addRepositoryTeamAccess(r, teamslug, permission)
+	}
+}
+
+/*
+ * addRepositoryTeamAccess adds the team to the repository list matching the permission
+ * (anything else than a Github predefined permission is a custom repository role)
+ */
+func addRepositoryTeamAccess(r *GithubRepoComparable, teamslug string, permission string) {
+	switch permission {
+	case "pull":
+		r.Readers = append(r.Readers, teamslug)
+	case "push":
+		r.Writers = append(r.Writers, teamslug)
+	case "maintain":
+		r.Maintainers = append(r.Maintainers, teamslug)
+	case "triage":
+		r.Triagers = append(r.Triagers, teamslug)
+	case "admin":
+		// admin access is not tracked in the comparable
+	default:
+		if r.CustomRoles == nil {
+			r.CustomRoles = make(map[string]string)
+		}
+		r.CustomRoles[teamslug] = permission
 	}
 }
 
+/*
+ * removeRepositoryTeamAccess removes the team from all the repository permission lists
+ */
+func removeRepositoryTeamAccess(r *GithubRepoComparable, teamslug string) {
+	r.Writers = slices.DeleteFunc(r.Writers, func(t string) bool { return t == teamslug })
+	r.Readers = slices.DeleteFunc(r.Readers, func(t string) bool { return t == teamslug })
+	r.Maintainers = slices.DeleteFunc(r.Maintainers, func(t string) bool { return t == teamslug })
+	r.Triagers = slices.DeleteFunc(r.Triagers, func(t string) bool { return t == teamslug })
+	delete(r.CustomRoles, teamslug)
+}
+
 func (m *MutableGoliacRemoteImpl) UpdateRepositoryUpdateTeamAccess(reponame string, teamslug string, permission string) {
 	if r, ok := m.repositories[reponame]; ok {
-		// remove the team from the readers and writers
-		for i, t := range r.Writers {
-			if t == teamslug {
-				r.Writers = append(r.Writers[:i], r.Writers[i+1:]...)
-				break
-			}
-		}
-		for i, t := range r.Readers {
-			if t == teamslug {
-				r.Readers = append(r.Readers[:i], r.Readers[i+1:]...)
-				break
-			}
-		}
-		// add the team to the readers and writers
-		if permission == "pull" {
-			r.Readers = append(r.Readers, teamslug)
-		} else if permission == "push" {
-			r.Writers = append(r.Writers, teamslug)
-		}
+		// remove the team from the previous permission, and add it back with the new one
+		removeRepositoryTeamAccess(r, teamslug)
+		addRepositoryTeamAccess(r, teamslug, permission)
 	}
 }
 func (m *MutableGoliacRemoteImpl) UpdateRepositoryRemoveTeamAccess(reponame string, teamslug string) {
 	if r, ok := m.repositories[reponame]; ok {
-		for i, t := range r.Writers {
-			if t == teamslug {
-				r.Writers = append(r.Writers[:i], r.Writers[i+1:]...)
-				break
-			}
-		}
-		for i, t := range r.Readers {
-			if t == teamslug {
-				r.Readers = append(r.Readers[:i], r.Readers[i+1:]...)
-				break
-			}
-		}
+		removeRepositoryTeamAccess(r, teamslug)
 	}
 }
 func (m *MutableGoliacRemoteImpl) DeleteRepository(reponame string) {
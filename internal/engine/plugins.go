@@ -12,6 +12,16 @@ type UserSyncPlugin interface {
 	UpdateUsers(repoconfig *config.RepositoryConfig, fs billy.Filesystem, orguserdirrectorypath string, feedback observability.RemoteObservability, logsCollector *observability.LogCollection) map[string]*entity.User
 }
 
+/*
+ * UserSyncGroupPlugin is optionally implemented by a UserSyncPlugin able to resolve
+ * groups (see the team's spec.sync_from, spec.sync_owners_from and spec.ldapGroup attributes).
+ * It returns the usernames member of each group ([group][]username), a group that
+ * cannot be found must be missing from the result (the team is left untouched)
+ */
+type UserSyncGroupPlugin interface {
+	GroupsMembers(repoconfig *config.RepositoryConfig, groups []string, logsCollector *observability.LogCollection) (map[string][]string, error)
+}
+
 var plugins map[string]UserSyncPlugin
 
 func RegisterPlugin(name string, plugin UserSyncPlugin) {
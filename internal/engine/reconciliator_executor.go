@@ -10,6 +10,7 @@ import (
 type ReconciliatorExecutor interface {
 	AddUserToOrg(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, ghuserid string)
 	RemoveUserFromOrg(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, ghuserid string)
+	UpdateUserOrgRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, ghuserid string, role string) // role can be 'admin' or 'member'
 
 	CreateTeam(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, description string, parentTeam *int, members []string)
 	UpdateTeamAddMember(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamslug string, username string, role string)    // role can be 'member' or 'maintainer'
@@ -23,8 +24,8 @@ type ReconciliatorExecutor interface {
 	UpdateRepositoryUpdateProperties(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, reponame string, properties map[string]interface{})
 	UpdateRepositoryCustomProperties(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, reponame string, propertyName string, propertyValue interface{})
 	UpdateRepositoryTopics(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, reponame string, topics []string)
-	UpdateRepositoryAddTeamAccess(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, reponame string, teamslug string, permission string)    // permission can be "pull", "push", or "admin" which correspond to read, write, and admin access.
-	UpdateRepositoryUpdateTeamAccess(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, reponame string, teamslug string, permission string) // permission can be "pull", "push", or "admin" which correspond to read, write, and admin access.
+	UpdateRepositoryAddTeamAccess(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, reponame string, teamslug string, permission string)    // permission can be "pull", "triage", "push", "maintain", "admin" or a custom repository role name
+	UpdateRepositoryUpdateTeamAccess(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, reponame string, teamslug string, permission string) // permission can be "pull", "triage", "push", "maintain", "admin" or a custom repository role name
 	UpdateRepositoryRemoveTeamAccess(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, reponame string, teamslug string)
 	AddRuleset(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, ruleset *GithubRuleSet)
 	UpdateRuleset(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, ruleset *GithubRuleSet)
@@ -73,6 +74,11 @@ type ReconciliatorExecutor interface {
 	CreateOrUpdateOrgCustomProperty(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, property *config.GithubCustomProperty)
 	DeleteOrgCustomProperty(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, propertyName string)
 
+	// Organization custom repository roles management
+	AddRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, role *GithubRepositoryRole)
+	UpdateRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, role *GithubRepositoryRole)
+	DeleteRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, roleid int)
+
 	Begin(logsCollector *observability.LogCollection, dryrun bool)
 	Rollback(logsCollector *observability.LogCollection, dryrun bool, err error)
 	Commit(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool) error
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -45,6 +46,9 @@ type GoliacRemote interface {
 	// Flush only the users, and teams from the cache
 	FlushCacheUsersTeamsOnly()
 
+	// Update the cache from a Github organization webhook event, return true if the event is a change made outside of Goliac
+	HandleOrgEvent(ctx context.Context, event *GithubOrgEvent) bool
+
 	Users(ctx context.Context) map[string]*GithubUser // key is the login, value is the role (MEMBER, ADMIN) + graphqlID
 	TeamSlugByName(ctx context.Context) map[string]string
 	Teams(ctx context.Context, current bool) map[string]*GithubTeam             // the key is the team slug
@@ -64,6 +68,9 @@ type GoliacRemote interface {
 
 	OrgCustomProperties(ctx context.Context) map[string]*config.GithubCustomProperty
 
+	// RepositoryRoles returns the organization custom repository roles (key is the role name)
+	RepositoryRoles(ctx context.Context) map[string]*GithubRepositoryRole
+
 	// GetRepositoryPages returns cached Pages info from the last Load, or fetches GET /repos/{org}/{repo}/pages.
 	GetRepositoryPages(ctx context.Context, repositoryName string) (*GithubPagesRemote, error)
 }
@@ -123,6 +130,15 @@ type GithubApp struct {
 type GithubTeamRepo struct {
 	Name       string // repository name
 	Permission string // possible values: ADMIN, MAINTAIN, WRITE, TRIAGE, READ
+	RoleName   string // custom repository role name (Permission is empty in that case)
+}
+
+type GithubRepositoryRole struct {
+	Id          int
+	Name        string
+	Description string
+	BaseRole    string   // read, triage, write, maintain
+	Permissions []string // fine-grained permissions
 }
 
 // GithubRemoteEnvironment represents a GitHub environment
@@ -166,6 +182,7 @@ type GoliacRemoteImpl struct {
 	rulesets                  map[string]*GithubRuleSet
 	appIds                    map[string]*GithubApp
 	orgCustomProperties       map[string]*config.GithubCustomProperty
+	repositoryRoles           map[string]*GithubRepositoryRole
 	ttlExpireUsers            time.Time
 	ttlExpireRepositories     time.Time
 	ttlExpireTeams            time.Time
@@ -173,14 +190,20 @@ type GoliacRemoteImpl struct {
 	ttlExpireRulesets         time.Time
 	ttlExpireAppIds           time.Time
 	ttlExpireCustomProperties time.Time
+	ttlExpireRepositoryRoles  time.Time
 	isEnterprise              bool
 	feedback                  observability.RemoteObservability
 	loadTeamsMutex            sync.Mutex
 	actionMutex               sync.Mutex // used when an action (like a REST CALL to create a repository) is launched while a load is in progress
 	configGithubOrg           string
+	installationOwnerLogin    string
+	installationOwnerLoaded   bool
 	manageGithubVariables     bool
 	manageGithubAutolinks     bool
 	manageOrgCustomProperties bool
+	stateCache                RemoteStateCache // optional persistent cache (see SetRemoteStateCache)
+	stateCacheRestored        bool
+	stateCacheDirty           bool // the remote state changed (webhook events) since it was persisted
 }
 
 type GHESInfo struct {
@@ -202,6 +225,60 @@ func getGHESVersion(ctx context.Context, client github.GitHubClient) (*GHESInfo,
 	return &info, nil
 }
 
+/*
+ * GHES_FEATURES lists the features needing a minimal GHES version
+ * (feature name: minimal GHES version)
+ */
+var GHES_FEATURES = map[string]string{
+	"rulesets":  "3.11", // organization rulesets
+	"workflows": "3.11", // the PR breaking glass workflows rely on the Goliac app bypassing the rulesets
+}
+
+/*
+ * GetGHESInfo returns the Github Enterprise Server information,
+ * or nil if Goliac is connected to github.com
+ */
+func GetGHESInfo(ctx context.Context, client github.GitHubClient) *GHESInfo {
+	info, err := getGHESVersion(ctx, client)
+	if err != nil || info.InstalledVersion == "" {
+		return nil
+	}
+	return info
+}
+
+/*
+ * Supports returns true if the feature (see GHES_FEATURES) is supported
+ * by this GHES version (always true on github.com, i.e. with a nil GHESInfo)
+ */
+func (i *GHESInfo) Supports(feature string) bool {
+	if i == nil {
+		return true
+	}
+	minVersion, ok := GHES_FEATURES[feature]
+	if !ok {
+		return true
+	}
+	installedVersion, err := version.NewVersion(i.InstalledVersion)
+	if err != nil {
+		return false
+	}
+	return installedVersion.GreaterThanOrEqual(version.Must(version.NewVersion(minVersion)))
+}
+
+/*
+ * UnsupportedFeatures returns the (sorted) features not supported by this GHES version
+ */
+func (i *GHESInfo) UnsupportedFeatures() []string {
+	unsupported := []string{}
+	for feature := range GHES_FEATURES {
+		if !i.Supports(feature) {
+			unsupported = append(unsupported, feature)
+		}
+	}
+	slices.Sort(unsupported)
+	return unsupported
+}
+
 const getAssets = `
 query getAssets($orgLogin: String!) {
 	organization(login: $orgLogin) {
@@ -350,6 +427,7 @@ func NewGoliacRemoteImpl(client github.GitHubClient,
 		rulesets:                  make(map[string]*GithubRuleSet),
 		appIds:                    make(map[string]*GithubApp),
 		orgCustomProperties:       make(map[string]*config.GithubCustomProperty),
+		repositoryRoles:           make(map[string]*GithubRepositoryRole),
 		ttlExpireUsers:            time.Now(),
 		ttlExpireRepositories:     time.Now(),
 		ttlExpireTeams:            time.Now(),
@@ -357,6 +435,7 @@ func NewGoliacRemoteImpl(client github.GitHubClient,
 		ttlExpireRulesets:         time.Now(),
 		ttlExpireAppIds:           time.Now(),
 		ttlExpireCustomProperties: time.Now(),
+		ttlExpireRepositoryRoles:  time.Now(),
 		isEnterprise:              isEnterprise(ctx, configGithubOrg, client),
 		feedback:                  nil,
 		configGithubOrg:           configGithubOrg,
@@ -381,6 +460,7 @@ func (g *GoliacRemoteImpl) IsEnterprise() bool {
 func (g *GoliacRemoteImpl) FlushCacheUsersTeamsOnly() {
 	g.ttlExpireUsers = time.Now()
 	g.ttlExpireTeams = time.Now()
+	g.clearRemoteState()
 }
 
 func (g *GoliacRemoteImpl) FlushCache() {
@@ -391,10 +471,25 @@ func (g *GoliacRemoteImpl) FlushCache() {
 	g.ttlExpireRulesets = time.Now()
 	g.ttlExpireAppIds = time.Now()
 	g.ttlExpireCustomProperties = time.Now()
+	g.ttlExpireRepositoryRoles = time.Now()
+	g.clearRemoteState()
+}
+
+/*
+cacheExpired returns true if a cache (expiring at ttlExpire) must be reloaded,
+and counts the cache hit/miss (cf observability.RemoteCacheRequestsTotal)
+*/
+func (g *GoliacRemoteImpl) cacheExpired(cache string, ttlExpire time.Time) bool {
+	if time.Now().After(ttlExpire) {
+		observability.RemoteCacheRequestsTotal.Inc(g.configGithubOrg, cache, "miss")
+		return true
+	}
+	observability.RemoteCacheRequestsTotal.Inc(g.configGithubOrg, cache, "hit")
+	return false
 }
 
 func (g *GoliacRemoteImpl) RuleSets(ctx context.Context) map[string]*GithubRuleSet {
-	if time.Now().After(g.ttlExpireRulesets) {
+	if g.cacheExpired("rulesets", g.ttlExpireRulesets) {
 		var githubToken *string
 		if config.Config.GithubPersonalAccessToken != "" {
 			githubToken = &config.Config.GithubPersonalAccessToken
@@ -409,7 +504,7 @@ func (g *GoliacRemoteImpl) RuleSets(ctx context.Context) map[string]*GithubRuleS
 }
 
 func (g *GoliacRemoteImpl) AppIds(ctx context.Context) map[string]*GithubApp {
-	if time.Now().After(g.ttlExpireAppIds) {
+	if g.cacheExpired("app_ids", g.ttlExpireAppIds) {
 		appIds, err := g.loadAppIds(ctx)
 		if err == nil {
 			g.appIds = appIds
@@ -434,7 +529,7 @@ func (g *GoliacRemoteImpl) AppIds(ctx context.Context) map[string]*GithubApp {
 }
 
 func (g *GoliacRemoteImpl) Users(ctx context.Context) map[string]*GithubUser {
-	if time.Now().After(g.ttlExpireUsers) {
+	if g.cacheExpired("users", g.ttlExpireUsers) {
 		users, err := g.loadOrgUsers(ctx)
 		if err == nil {
 			g.users = users
@@ -458,7 +553,7 @@ func (g *GoliacRemoteImpl) Users(ctx context.Context) map[string]*GithubUser {
 }
 
 func (g *GoliacRemoteImpl) TeamSlugByName(ctx context.Context) map[string]string {
-	if time.Now().After(g.ttlExpireTeams) {
+	if g.cacheExpired("teams", g.ttlExpireTeams) {
 		teams, teamSlugByName, err := g.loadTeams(ctx)
 		if err == nil {
 			g.teams = teams
@@ -487,7 +582,7 @@ func (g *GoliacRemoteImpl) Teams(ctx context.Context, current bool) map[string]*
 	}
 	g.loadTeamsMutex.Lock()
 	defer g.loadTeamsMutex.Unlock()
-	if time.Now().After(g.ttlExpireTeams) {
+	if g.cacheExpired("teams", g.ttlExpireTeams) {
 		teams, teamSlugByName, err := g.loadTeams(ctx)
 		if err == nil {
 			g.teams = teams
@@ -508,7 +603,7 @@ func (g *GoliacRemoteImpl) Teams(ctx context.Context, current bool) map[string]*
 }
 
 func (g *GoliacRemoteImpl) Repositories(ctx context.Context) map[string]*GithubRepository {
-	if time.Now().After(g.ttlExpireRepositories) {
+	if g.cacheExpired("repositories", g.ttlExpireRepositories) {
 		var githubToken *string
 		if config.Config.GithubPersonalAccessToken != "" {
 			githubToken = &config.Config.GithubPersonalAccessToken
@@ -533,7 +628,7 @@ func (g *GoliacRemoteImpl) Repositories(ctx context.Context) map[string]*GithubR
 }
 
 func (g *GoliacRemoteImpl) TeamRepositories(ctx context.Context) map[string]map[string]*GithubTeamRepo {
-	if time.Now().After(g.ttlExpireTeamsRepos) {
+	if g.cacheExpired("teams_repos", g.ttlExpireTeamsRepos) {
 		repositories := g.Repositories(ctx)
 		teamsrepos, err := g.loadTeamReposConcurrently(ctx, config.Config.GithubConcurrentThreads, repositories)
 		if err == nil {
@@ -1034,6 +1129,47 @@ func (g *GoliacRemoteImpl) loadRepositories(ctx context.Context, githubToken *st
 		}
 	}
 
+	g.setRepositoriesLazyLoaders(repositories)
+
+	if g.manageOrgCustomProperties {
+		// Load custom properties for all repositories
+		customPropsPerRepo, err := g.loadCustomPropertiesConcurrently(ctx, config.Config.GithubConcurrentThreads, repositories)
+		if err != nil {
+			logrus.Warnf("error loading custom properties: %v", err)
+			if retErr == nil {
+				retErr = fmt.Errorf("error loading repository custom properties: %w", err)
+			}
+		} else {
+			for reponame, customProps := range customPropsPerRepo {
+				if repo, ok := repositories[reponame]; ok {
+					repo.CustomProperties = customProps
+				}
+			}
+		}
+	}
+
+	if err := g.loadRepositoryCodeownersConcurrently(ctx, config.Config.GithubConcurrentThreads, repositories); err != nil {
+		logrus.Warnf("error loading CODEOWNERS: %v", err)
+		if retErr == nil {
+			retErr = fmt.Errorf("error loading repository CODEOWNERS: %w", err)
+		}
+	}
+
+	if err := g.loadRepositoryPagesConcurrently(ctx, config.Config.GithubConcurrentThreads, repositories); err != nil {
+		logrus.Warnf("error loading GitHub Pages: %v", err)
+		if retErr == nil {
+			retErr = fmt.Errorf("error loading repository GitHub Pages: %w", err)
+		}
+	}
+
+	return repositories, repositoriesByRefId, retErr
+}
+
+/*
+ * setRepositoriesLazyLoaders sets the (variables, environments, autolinks)
+ * lazy loaders of the repositories
+ */
+func (g *GoliacRemoteImpl) setRepositoriesLazyLoaders(repositories map[string]*GithubRepository) {
 	if g.manageGithubVariables {
 		for reponame, repo := range repositories {
 			repo.RepositoryVariables = NewRemoteLazyLoader[string](func() map[string]string {
@@ -1122,39 +1258,6 @@ func (g *GoliacRemoteImpl) loadRepositories(ctx context.Context, githubToken *st
 			})
 		}
 	}
-
-	if g.manageOrgCustomProperties {
-		// Load custom properties for all repositories
-		customPropsPerRepo, err := g.loadCustomPropertiesConcurrently(ctx, config.Config.GithubConcurrentThreads, repositories)
-		if err != nil {
-			logrus.Warnf("error loading custom properties: %v", err)
-			if retErr == nil {
-				retErr = fmt.Errorf("error loading repository custom properties: %w", err)
-			}
-		} else {
-			for reponame, customProps := range customPropsPerRepo {
-				if repo, ok := repositories[reponame]; ok {
-					repo.CustomProperties = customProps
-				}
-			}
-		}
-	}
-
-	if err := g.loadRepositoryCodeownersConcurrently(ctx, config.Config.GithubConcurrentThreads, repositories); err != nil {
-		logrus.Warnf("error loading CODEOWNERS: %v", err)
-		if retErr == nil {
-			retErr = fmt.Errorf("error loading repository CODEOWNERS: %w", err)
-		}
-	}
-
-	if err := g.loadRepositoryPagesConcurrently(ctx, config.Config.GithubConcurrentThreads, repositories); err != nil {
-		logrus.Warnf("error loading GitHub Pages: %v", err)
-		if retErr == nil {
-			retErr = fmt.Errorf("error loading repository GitHub Pages: %w", err)
-		}
-	}
-
-	return repositories, repositoriesByRefId, retErr
 }
 
 const listAllTeamsInOrg = `
@@ -1294,7 +1397,11 @@ func (g *GoliacRemoteImpl) Load(ctx context.Context, continueOnError bool) error
 	}
 	var retErr error
 
-	if time.Now().After(g.ttlExpireAppIds) {
+	g.restoreRemoteState()
+	reloaded := false
+
+	if g.cacheExpired("app_ids", g.ttlExpireAppIds) {
+		reloaded = true
 		appIds, err := g.loadAppIds(ctx)
 		if err != nil {
 			if !continueOnError {
@@ -1310,7 +1417,8 @@ func (g *GoliacRemoteImpl) Load(ctx context.Context, continueOnError bool) error
 	// the lock is here to avoid multiple calls to loadTeams (and it can be long)
 	// especially coming from the UI
 	g.loadTeamsMutex.Lock()
-	if time.Now().After(g.ttlExpireTeams) {
+	if g.cacheExpired("teams", g.ttlExpireTeams) {
+		reloaded = true
 		teams, teamSlugByName, err := g.loadTeams(ctx)
 		if err != nil {
 			if !continueOnError {
@@ -1326,7 +1434,8 @@ func (g *GoliacRemoteImpl) Load(ctx context.Context, continueOnError bool) error
 	}
 	g.loadTeamsMutex.Unlock()
 
-	if time.Now().After(g.ttlExpireUsers) {
+	if g.cacheExpired("users", g.ttlExpireUsers) {
+		reloaded = true
 		users, err := g.loadOrgUsers(ctx)
 		if err != nil {
 			if !continueOnError {
@@ -1339,7 +1448,8 @@ func (g *GoliacRemoteImpl) Load(ctx context.Context, continueOnError bool) error
 		g.ttlExpireUsers = time.Now().Add(time.Duration(config.Config.GithubCacheTTL) * time.Second)
 	}
 
-	if time.Now().After(g.ttlExpireRepositories) {
+	if g.cacheExpired("repositories", g.ttlExpireRepositories) {
+		reloaded = true
 		var githubToken *string
 		if config.Config.GithubPersonalAccessToken != "" {
 			githubToken = &config.Config.GithubPersonalAccessToken
@@ -1358,7 +1468,8 @@ func (g *GoliacRemoteImpl) Load(ctx context.Context, continueOnError bool) error
 	}
 
 	// let's load the rulesets after the repositories because I need the repository refs
-	if time.Now().After(g.ttlExpireRulesets) {
+	if g.cacheExpired("rulesets", g.ttlExpireRulesets) {
+		reloaded = true
 		var githubToken *string
 		if config.Config.GithubPersonalAccessToken != "" {
 			githubToken = &config.Config.GithubPersonalAccessToken
@@ -1375,7 +1486,8 @@ func (g *GoliacRemoteImpl) Load(ctx context.Context, continueOnError bool) error
 		g.ttlExpireRulesets = time.Now().Add(time.Duration(config.Config.GithubCacheTTL) * time.Second)
 	}
 
-	if time.Now().After(g.ttlExpireTeamsRepos) {
+	if g.cacheExpired("teams_repos", g.ttlExpireTeamsRepos) {
+		reloaded = true
 		teamsrepos, err := g.loadTeamReposConcurrently(ctx, config.Config.GithubConcurrentThreads, g.repositories)
 		if err != nil {
 			if !continueOnError {
@@ -1388,6 +1500,10 @@ func (g *GoliacRemoteImpl) Load(ctx context.Context, continueOnError bool) error
 		g.ttlExpireTeamsRepos = time.Now().Add(time.Duration(config.Config.GithubCacheTTL) * time.Second)
 	}
 
+	if (reloaded || g.remoteStateDirty()) && retErr == nil {
+		g.persistRemoteState()
+	}
+
 	logrus.Debugf("Nb remote users: %d", len(g.users))
 	logrus.Debugf("Nb remote teams: %d", len(g.teams))
 	logrus.Debugf("Nb remote repositories: %d", len(g.repositories))
@@ -1426,9 +1542,40 @@ func (g *GoliacRemoteImpl) loadTeamReposConcurrently(ctx context.Context, maxGor
 type TeamsRepoResponse struct {
 	Name       string `json:"name"`
 	Permission string `json:"permission"`
+	RoleName   string `json:"role_name"`
 	Slug       string `json:"slug"`
 }
 
+/*
+newGithubTeamRepo converts a Github permission (pull, triage, push, maintain, admin)
+or a custom repository role name into a GithubTeamRepo
+*/
+func newGithubTeamRepo(reponame string, permission string, roleName string) *GithubTeamRepo {
+	teamRepo := &GithubTeamRepo{
+		Name: reponame,
+	}
+	switch permission {
+	case "admin":
+		teamRepo.Permission = "ADMIN"
+	case "maintain":
+		teamRepo.Permission = "MAINTAIN"
+	case "push":
+		teamRepo.Permission = "WRITE"
+	case "triage":
+		teamRepo.Permission = "TRIAGE"
+	case "pull":
+		teamRepo.Permission = "READ"
+	default:
+		teamRepo.RoleName = permission
+	}
+	// Github returns the custom role name in role_name
+	if roleName != "" && !slices.Contains(entity.REPOSITORY_ROLE_PREDEFINED, roleName) {
+		teamRepo.Permission = ""
+		teamRepo.RoleName = roleName
+	}
+	return teamRepo
+}
+
 /*
 loadTeamRepos returns
 map[teamSlug]repoinfo
@@ -1458,19 +1605,7 @@ func (g *GoliacRemoteImpl) loadTeamRepos(ctx context.Context, repository *Github
 		}
 
 		for _, t := range teams {
-			permission := ""
-			switch t.Permission {
-			case "admin":
-				permission = "ADMIN"
-			case "push":
-				permission = "WRITE"
-			case "pull":
-				permission = "READ"
-			}
-			teamsrepo[t.Slug] = &GithubTeamRepo{
-				Name:       repository.Name,
-				Permission: permission,
-			}
+			teamsrepo[t.Slug] = newGithubTeamRepo(repository.Name, t.Permission, t.RoleName)
 		}
 
 		page++
@@ -1856,7 +1991,7 @@ func (g *GoliacRemoteImpl) loadCustomPropertiesPerRepository(ctx context.Context
 }
 
 func (g *GoliacRemoteImpl) OrgCustomProperties(ctx context.Context) map[string]*config.GithubCustomProperty {
-	if time.Now().After(g.ttlExpireCustomProperties) {
+	if g.cacheExpired("custom_properties", g.ttlExpireCustomProperties) {
 		customProperties, err := g.loadOrgCustomProperties(ctx)
 		if err == nil {
 			g.orgCustomProperties = customProperties
@@ -1963,6 +2098,153 @@ func (g *GoliacRemoteImpl) DeleteOrgCustomProperty(ctx context.Context, logsColl
 	delete(g.orgCustomProperties, propertyName)
 }
 
+func (g *GoliacRemoteImpl) RepositoryRoles(ctx context.Context) map[string]*GithubRepositoryRole {
+	if g.cacheExpired("repository_roles", g.ttlExpireRepositoryRoles) {
+		repositoryRoles, err := g.loadRepositoryRoles(ctx)
+		if err == nil {
+			g.repositoryRoles = repositoryRoles
+			g.ttlExpireRepositoryRoles = time.Now().Add(time.Duration(config.Config.GithubCacheTTL) * time.Second)
+		}
+	}
+	return g.repositoryRoles
+}
+
+type RepositoryRolesResponse struct {
+	TotalCount  int `json:"total_count"`
+	CustomRoles []struct {
+		Id          int      `json:"id"`
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		BaseRole    string   `json:"base_role"`
+		Permissions []string `json:"permissions"`
+	} `json:"custom_roles"`
+}
+
+func (g *GoliacRemoteImpl) loadRepositoryRoles(ctx context.Context) (map[string]*GithubRepositoryRole, error) {
+	// https://docs.github.com/en/enterprise-cloud@latest/rest/orgs/custom-roles?apiVersion=2022-11-28#list-custom-repository-roles-in-an-organization
+	logrus.Debug("loading repository roles")
+	repositoryRoles := make(map[string]*GithubRepositoryRole)
+
+	data, err := g.client.CallRestAPI(ctx, fmt.Sprintf("/orgs/%s/custom-repository-roles", g.configGithubOrg), "", "GET", nil, nil)
+	if err != nil {
+		// custom repository roles are only available for Github Enterprise
+		if strings.Contains(err.Error(), "404") {
+			return repositoryRoles, nil
+		}
+		return nil, fmt.Errorf("not able to list custom repository roles for org %s: %v", g.configGithubOrg, err)
+	}
+
+	var roles RepositoryRolesResponse
+	err = json.Unmarshal(data, &roles)
+	if err != nil {
+		return nil, fmt.Errorf("not able to unmarshal custom repository roles for org %s: %v", g.configGithubOrg, err)
+	}
+
+	for _, r := range roles.CustomRoles {
+		repositoryRoles[r.Name] = &GithubRepositoryRole{
+			Id:          r.Id,
+			Name:        r.Name,
+			Description: r.Description,
+			BaseRole:    r.BaseRole,
+			Permissions: r.Permissions,
+		}
+	}
+
+	return repositoryRoles, nil
+}
+
+func (g *GoliacRemoteImpl) AddRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, role *GithubRepositoryRole) {
+	// https://docs.github.com/en/enterprise-cloud@latest/rest/orgs/custom-roles?apiVersion=2022-11-28#create-a-custom-repository-role
+	roleCopy := *role
+	if !dryrun {
+		body, err := g.client.CallRestAPI(
+			ctx,
+			fmt.Sprintf("/orgs/%s/custom-repository-roles", g.configGithubOrg),
+			"",
+			"POST",
+			map[string]interface{}{
+				"name":        role.Name,
+				"description": role.Description,
+				"base_role":   role.BaseRole,
+				"permissions": role.Permissions,
+			},
+			nil,
+		)
+		if err != nil {
+			logsCollector.AddError(fmt.Errorf("failed to create repository role %s: %v. %s", role.Name, err, string(body)))
+			return
+		}
+
+		var created struct {
+			Id int `json:"id"`
+		}
+		if err := json.Unmarshal(body, &created); err == nil {
+			roleCopy.Id = created.Id
+		}
+	}
+
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	g.repositoryRoles[role.Name] = &roleCopy
+}
+
+func (g *GoliacRemoteImpl) UpdateRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, role *GithubRepositoryRole) {
+	// https://docs.github.com/en/enterprise-cloud@latest/rest/orgs/custom-roles?apiVersion=2022-11-28#update-a-custom-repository-role
+	if !dryrun {
+		body, err := g.client.CallRestAPI(
+			ctx,
+			fmt.Sprintf("/orgs/%s/custom-repository-roles/%d", g.configGithubOrg, role.Id),
+			"",
+			"PATCH",
+			map[string]interface{}{
+				"name":        role.Name,
+				"description": role.Description,
+				"base_role":   role.BaseRole,
+				"permissions": role.Permissions,
+			},
+			nil,
+		)
+		if err != nil {
+			logsCollector.AddError(fmt.Errorf("failed to update repository role %s: %v. %s", role.Name, err, string(body)))
+			return
+		}
+	}
+
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	roleCopy := *role
+	g.repositoryRoles[role.Name] = &roleCopy
+}
+
+func (g *GoliacRemoteImpl) DeleteRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, roleid int) {
+	// https://docs.github.com/en/enterprise-cloud@latest/rest/orgs/custom-roles?apiVersion=2022-11-28#delete-a-custom-repository-role
+	if !dryrun {
+		body, err := g.client.CallRestAPI(
+			ctx,
+			fmt.Sprintf("/orgs/%s/custom-repository-roles/%d", g.configGithubOrg, roleid),
+			"",
+			"DELETE",
+			nil,
+			nil,
+		)
+		if err != nil {
+			logsCollector.AddError(fmt.Errorf("failed to delete repository role %d: %v. %s", roleid, err, string(body)))
+			return
+		}
+	}
+
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	for name, role := range g.repositoryRoles {
+		if role.Id == roleid {
+			delete(g.repositoryRoles, name)
+		}
+	}
+}
+
 // func (g *GoliacRemoteImpl) loadEnvironmentVariables(ctx context.Context, maxGoroutines int64, repositories map[string]*GithubRepository) (map[string]map[string]map[string]*GithubVariable, error) {
 // 	var childSpan trace.Span
 // 	if config.Config.OpenTelemetryEnabled {
@@ -3636,6 +3918,96 @@ func (g *GoliacRemoteImpl) RemoveUserFromOrg(ctx context.Context, logsCollector
 	delete(g.users, ghuserid)
 }
 
+/*
+ * installationOwner returns the Github login Goliac is acting for:
+ * the owner of the Github App, or the owner of the personal access token.
+ * This user must never be demoted from the organization owners.
+ * A Github App owned by an organization doesn't act for any user (demoting
+ * a user doesn't change the App permissions): no login is returned.
+ */
+func (g *GoliacRemoteImpl) installationOwner(ctx context.Context) (string, error) {
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+	if g.installationOwnerLoaded {
+		return g.installationOwnerLogin, nil
+	}
+
+	var owner struct {
+		Login string `json:"login"`
+		Owner struct {
+			Login string `json:"login"`
+			Type  string `json:"type"`
+		} `json:"owner"`
+	}
+	if g.client.GetAppSlug() != "" {
+		// https://docs.github.com/en/rest/apps/apps?apiVersion=2022-11-28#get-the-authenticated-app
+		jwt, err := g.client.CreateJWT()
+		if err != nil {
+			return "", err
+		}
+		body, err := g.client.CallRestAPI(ctx, "/app", "", "GET", nil, &jwt)
+		if err != nil {
+			return "", fmt.Errorf("not able to get the Github App: %v. %s", err, string(body))
+		}
+		if err := json.Unmarshal(body, &owner); err != nil {
+			return "", err
+		}
+		if owner.Owner.Type == "User" {
+			g.installationOwnerLogin = owner.Owner.Login
+		}
+	} else {
+		// https://docs.github.com/en/rest/users/users?apiVersion=2022-11-28#get-the-authenticated-user
+		body, err := g.client.CallRestAPI(ctx, "/user", "", "GET", nil, nil)
+		if err != nil {
+			return "", fmt.Errorf("not able to get the authenticated user: %v. %s", err, string(body))
+		}
+		if err := json.Unmarshal(body, &owner); err != nil {
+			return "", err
+		}
+		g.installationOwnerLogin = owner.Login
+	}
+	g.installationOwnerLoaded = true
+	return g.installationOwnerLogin, nil
+}
+
+func (g *GoliacRemoteImpl) UpdateUserOrgRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, ghuserid string, role string) {
+	if role == "member" {
+		owner, err := g.installationOwner(ctx)
+		if err != nil {
+			logsCollector.AddError(fmt.Errorf("not demoting %s: not able to check the Goliac installation owner: %v", ghuserid, err))
+			return
+		}
+		if owner != "" && strings.EqualFold(owner, ghuserid) {
+			logsCollector.AddError(fmt.Errorf("not demoting %s: it is the Goliac installation owner", ghuserid))
+			return
+		}
+	}
+
+	// update the membership role
+	// https://docs.github.com/en/rest/orgs/members?apiVersion=2022-11-28#set-organization-membership-for-a-user
+	if !dryrun {
+		body, err := g.client.CallRestAPI(
+			ctx,
+			fmt.Sprintf("/orgs/%s/memberships/%s", g.configGithubOrg, ghuserid),
+			"",
+			"PUT",
+			map[string]interface{}{"role": role},
+			nil,
+		)
+		if err != nil {
+			logsCollector.AddError(fmt.Errorf("failed to update the org role of user %s: %v. %s", ghuserid, err, string(body)))
+			return
+		}
+	}
+
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	if user, ok := g.users[ghuserid]; ok {
+		user.Role = strings.ToUpper(role)
+	}
+}
+
 type CreateTeamResponse struct {
 	ID   int    `json:"id"`
 	Name string `json:"name"`
@@ -4169,14 +4541,7 @@ func (g *GoliacRemoteImpl) UpdateRepositoryAddTeamAccess(ctx context.Context, lo
 	if teamsRepos == nil {
 		teamsRepos = make(map[string]*GithubTeamRepo)
 	}
-	rPermission := "READ"
-	if permission == "push" {
-		rPermission = "WRITE"
-	}
-	teamsRepos[reponame] = &GithubTeamRepo{
-		Name:       reponame,
-		Permission: rPermission,
-	}
+	teamsRepos[reponame] = newGithubTeamRepo(reponame, permission, "")
 	g.teamRepos[teamslug] = teamsRepos
 }
 
@@ -4218,14 +4583,7 @@ func (g *GoliacRemoteImpl) UpdateRepositoryUpdateTeamAccess(ctx context.Context,
 	if teamsRepos == nil {
 		teamsRepos = make(map[string]*GithubTeamRepo)
 	}
-	rPermission := "READ"
-	if permission == "push" {
-		rPermission = "WRITE"
-	}
-	teamsRepos[reponame] = &GithubTeamRepo{
-		Name:       reponame,
-		Permission: rPermission,
-	}
+	teamsRepos[reponame] = newGithubTeamRepo(reponame, permission, "")
 	g.teamRepos[teamslug] = teamsRepos
 }
 
@@ -5249,5 +5607,9 @@ func (g *GoliacRemoteImpl) Begin(logsCollector *observability.LogCollection, dry
 func (g *GoliacRemoteImpl) Rollback(logsCollector *observability.LogCollection, dryrun bool, err error) {
 }
 func (g *GoliacRemoteImpl) Commit(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool) error {
+	if !dryrun {
+		// the in memory cache has been updated with the changes
+		g.persistRemoteState()
+	}
 	return nil
 }
@@ -0,0 +1,511 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/goliac-project/goliac/internal/github"
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/sirupsen/logrus"
+)
+
+/*
+ * GoliacRemoteEnterprise is the (enterprise level) state of a Github enterprise:
+ * its organizations, its members and its enterprise teams
+ */
+type GoliacRemoteEnterprise interface {
+	Load(ctx context.Context) error
+
+	// organizations login
+	Organizations() []string
+	// map[login]*GithubEnterpriseMember
+	Members() map[string]*GithubEnterpriseMember
+	// map[teamname]*GithubEnterpriseTeam
+	Teams() map[string]*GithubEnterpriseTeam
+
+	CreateOrganization(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, login string, billingEmail string, admins []string)
+	CreateTeam(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string)
+	DeleteTeam(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string)
+	AddTeamMember(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, login string)
+	RemoveTeamMember(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, login string)
+	AddTeamOrganization(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, organization string)
+	RemoveTeamOrganization(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, organization string)
+}
+
+type GithubEnterpriseMember struct {
+	Login string
+}
+
+type GithubEnterpriseTeam struct {
+	Id            int
+	Name          string
+	Slug          string
+	Members       []string // github logins
+	Organizations []string // organizations the team is assigned to
+}
+
+type GoliacRemoteEnterpriseImpl struct {
+	client        github.GitHubClient
+	enterprise    string // enterprise slug
+	enterpriseId  string // graphql id
+	organizations []string
+	members       map[string]*GithubEnterpriseMember
+	teams         map[string]*GithubEnterpriseTeam
+	actionMutex   sync.Mutex
+}
+
+func NewGoliacRemoteEnterpriseImpl(client github.GitHubClient, enterprise string) *GoliacRemoteEnterpriseImpl {
+	return &GoliacRemoteEnterpriseImpl{
+		client:        client,
+		enterprise:    enterprise,
+		organizations: []string{},
+		members:       make(map[string]*GithubEnterpriseMember),
+		teams:         make(map[string]*GithubEnterpriseTeam),
+	}
+}
+
+func (g *GoliacRemoteEnterpriseImpl) Organizations() []string {
+	return g.organizations
+}
+
+func (g *GoliacRemoteEnterpriseImpl) Members() map[string]*GithubEnterpriseMember {
+	return g.members
+}
+
+func (g *GoliacRemoteEnterpriseImpl) Teams() map[string]*GithubEnterpriseTeam {
+	return g.teams
+}
+
+/*
+Load (re)loads the organizations, the members and the teams of the enterprise
+*/
+func (g *GoliacRemoteEnterpriseImpl) Load(ctx context.Context) error {
+	enterpriseId, organizations, err := g.loadOrganizations(ctx)
+	if err != nil {
+		return err
+	}
+	members, err := g.loadMembers(ctx)
+	if err != nil {
+		return err
+	}
+	teams, err := g.loadTeams(ctx)
+	if err != nil {
+		return err
+	}
+
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+	g.enterpriseId = enterpriseId
+	g.organizations = organizations
+	g.members = members
+	g.teams = teams
+	return nil
+}
+
+type GraphQLErrors []struct {
+	Path       []interface{} `json:"path"`
+	Extensions struct {
+		Code         string
+		ErrorMessage string
+	} `json:"extensions"`
+	Message string
+}
+
+const listEnterpriseOrganizations = `
+query listEnterpriseOrganizations($enterpriseSlug: String!, $endCursor: String) {
+  enterprise(slug: $enterpriseSlug) {
+    id
+    organizations(first: 100, after: $endCursor) {
+      nodes {
+        login
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+    }
+  }
+}
+`
+
+type GraplQLEnterpriseOrganizations struct {
+	Data struct {
+		Enterprise *struct {
+			Id            string `json:"id"`
+			Organizations struct {
+				Nodes []struct {
+					Login string `json:"login"`
+				} `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   string
+				} `json:"pageInfo"`
+			} `json:"organizations"`
+		} `json:"enterprise"`
+	}
+	Errors GraphQLErrors `json:"errors"`
+}
+
+func (g *GoliacRemoteEnterpriseImpl) loadOrganizations(ctx context.Context) (string, []string, error) {
+	logrus.Debug("loading enterprise organizations")
+	enterpriseId := ""
+	organizations := []string{}
+
+	variables := make(map[string]interface{})
+	variables["enterpriseSlug"] = g.enterprise
+	variables["endCursor"] = nil
+
+	hasNextPage := true
+	count := 0
+	for hasNextPage {
+		data, err := g.client.QueryGraphQLAPI(ctx, listEnterpriseOrganizations, variables, nil)
+		if err != nil {
+			return "", nil, err
+		}
+		var gResult GraplQLEnterpriseOrganizations
+		err = json.Unmarshal(data, &gResult)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(gResult.Errors) > 0 {
+			return "", nil, fmt.Errorf("graphql error on loadOrganizations: %v (%v)", gResult.Errors[0].Message, gResult.Errors[0].Path)
+		}
+		if gResult.Data.Enterprise == nil {
+			return "", nil, fmt.Errorf("enterprise %s not found", g.enterprise)
+		}
+
+		enterpriseId = gResult.Data.Enterprise.Id
+		for _, o := range gResult.Data.Enterprise.Organizations.Nodes {
+			organizations = append(organizations, o.Login)
+		}
+
+		hasNextPage = gResult.Data.Enterprise.Organizations.PageInfo.HasNextPage
+		variables["endCursor"] = gResult.Data.Enterprise.Organizations.PageInfo.EndCursor
+
+		count++
+		// sanity check to avoid loops
+		if count > FORLOOP_STOP {
+			break
+		}
+	}
+
+	slices.Sort(organizations)
+	return enterpriseId, organizations, nil
+}
+
+const listEnterpriseMembers = `
+query listEnterpriseMembers($enterpriseSlug: String!, $endCursor: String) {
+  enterprise(slug: $enterpriseSlug) {
+    members(first: 100, after: $endCursor) {
+      nodes {
+        ... on EnterpriseUserAccount {
+          login
+        }
+        ... on User {
+          login
+        }
+      }
+      pageInfo {
+        hasNextPage
+        endCursor
+      }
+    }
+  }
+}
+`
+
+type GraplQLEnterpriseMembers struct {
+	Data struct {
+		Enterprise *struct {
+			Members struct {
+				Nodes []struct {
+					Login string `json:"login"`
+				} `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   string
+				} `json:"pageInfo"`
+			} `json:"members"`
+		} `json:"enterprise"`
+	}
+	Errors GraphQLErrors `json:"errors"`
+}
+
+func (g *GoliacRemoteEnterpriseImpl) loadMembers(ctx context.Context) (map[string]*GithubEnterpriseMember, error) {
+	logrus.Debug("loading enterprise members")
+	members := make(map[string]*GithubEnterpriseMember)
+
+	variables := make(map[string]interface{})
+	variables["enterpriseSlug"] = g.enterprise
+	variables["endCursor"] = nil
+
+	hasNextPage := true
+	count := 0
+	for hasNextPage {
+		data, err := g.client.QueryGraphQLAPI(ctx, listEnterpriseMembers, variables, nil)
+		if err != nil {
+			return nil, err
+		}
+		var gResult GraplQLEnterpriseMembers
+		err = json.Unmarshal(data, &gResult)
+		if err != nil {
+			return nil, err
+		}
+		if len(gResult.Errors) > 0 {
+			return nil, fmt.Errorf("graphql error on loadMembers: %v (%v)", gResult.Errors[0].Message, gResult.Errors[0].Path)
+		}
+		if gResult.Data.Enterprise == nil {
+			return nil, fmt.Errorf("enterprise %s not found", g.enterprise)
+		}
+
+		for _, m := range gResult.Data.Enterprise.Members.Nodes {
+			if m.Login == "" {
+				continue
+			}
+			members[m.Login] = &GithubEnterpriseMember{Login: m.Login}
+		}
+
+		hasNextPage = gResult.Data.Enterprise.Members.PageInfo.HasNextPage
+		variables["endCursor"] = gResult.Data.Enterprise.Members.PageInfo.EndCursor
+
+		count++
+		// sanity check to avoid loops
+		if count > FORLOOP_STOP {
+			break
+		}
+	}
+
+	return members, nil
+}
+
+type EnterpriseTeamResponse struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+func (g *GoliacRemoteEnterpriseImpl) loadTeams(ctx context.Context) (map[string]*GithubEnterpriseTeam, error) {
+	// https://docs.github.com/en/enterprise-cloud@latest/rest/enterprise-teams/enterprise-teams#list-enterprise-teams
+	logrus.Debug("loading enterprise teams")
+	teams := make(map[string]*GithubEnterpriseTeam)
+
+	var page []EnterpriseTeamResponse
+	for p := 1; p == 1 || len(page) == 100; p++ {
+		data, err := g.client.CallRestAPI(ctx, fmt.Sprintf("/enterprises/%s/teams", g.enterprise), fmt.Sprintf("page=%d&per_page=100", p), "GET", nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("not able to list the teams of the enterprise %s: %v", g.enterprise, err)
+		}
+		page = []EnterpriseTeamResponse{}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("not able to unmarshall the teams of the enterprise %s: %v", g.enterprise, err)
+		}
+		for _, t := range page {
+			teams[t.Name] = &GithubEnterpriseTeam{
+				Id:            t.Id,
+				Name:          t.Name,
+				Slug:          t.Slug,
+				Members:       []string{},
+				Organizations: []string{},
+			}
+		}
+
+		// sanity check to avoid loops
+		if p > FORLOOP_STOP {
+			break
+		}
+	}
+
+	for _, team := range teams {
+		members, err := g.loadTeamLogins(ctx, fmt.Sprintf("/enterprises/%s/teams/%s/memberships", g.enterprise, team.Slug))
+		if err != nil {
+			return nil, fmt.Errorf("not able to list the members of the enterprise team %s: %v", team.Name, err)
+		}
+		team.Members = members
+
+		organizations, err := g.loadTeamLogins(ctx, fmt.Sprintf("/enterprises/%s/teams/%s/organizations", g.enterprise, team.Slug))
+		if err != nil {
+			return nil, fmt.Errorf("not able to list the organizations of the enterprise team %s: %v", team.Name, err)
+		}
+		team.Organizations = organizations
+	}
+
+	return teams, nil
+}
+
+/*
+loadTeamLogins returns the (sorted) logins of a paginated list of users or organizations
+*/
+func (g *GoliacRemoteEnterpriseImpl) loadTeamLogins(ctx context.Context, endpoint string) ([]string, error) {
+	logins := []string{}
+
+	var page []struct {
+		Login string `json:"login"`
+	}
+	for p := 1; p == 1 || len(page) == 100; p++ {
+		data, err := g.client.CallRestAPI(ctx, endpoint, fmt.Sprintf("page=%d&per_page=100", p), "GET", nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		page = nil
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		for _, l := range page {
+			logins = append(logins, l.Login)
+		}
+
+		// sanity check to avoid loops
+		if p > FORLOOP_STOP {
+			break
+		}
+	}
+
+	slices.Sort(logins)
+	return logins, nil
+}
+
+const createEnterpriseOrganization = `
+mutation createEnterpriseOrganization($enterpriseId: ID!, $login: String!, $profileName: String!, $billingEmail: String!, $adminLogins: [String!]!) {
+  createEnterpriseOrganization(input: {enterpriseId: $enterpriseId, login: $login, profileName: $profileName, billingEmail: $billingEmail, adminLogins: $adminLogins}) {
+    organization {
+      login
+    }
+  }
+}
+`
+
+func (g *GoliacRemoteEnterpriseImpl) CreateOrganization(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, login string, billingEmail string, admins []string) {
+	// https://docs.github.com/en/enterprise-cloud@latest/graphql/reference/mutations#createenterpriseorganization
+	if !dryrun {
+		data, err := g.client.QueryGraphQLAPI(ctx, createEnterpriseOrganization, map[string]interface{}{
+			"enterpriseId": g.enterpriseId,
+			"login":        login,
+			"profileName":  login,
+			"billingEmail": billingEmail,
+			"adminLogins":  admins,
+		}, nil)
+		if err != nil {
+			logsCollector.AddError(fmt.Errorf("failed to create the organization %s: %v", login, err))
+			return
+		}
+		var res struct {
+			Errors GraphQLErrors `json:"errors"`
+		}
+		if err := json.Unmarshal(data, &res); err != nil {
+			logsCollector.AddError(fmt.Errorf("failed to create the organization %s: %v", login, err))
+			return
+		}
+		if len(res.Errors) > 0 {
+			logsCollector.AddError(fmt.Errorf("graphql error on createEnterpriseOrganization: %v (%v)", res.Errors[0].Message, res.Errors[0].Path))
+			return
+		}
+	}
+
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	g.organizations = append(g.organizations, login)
+	slices.Sort(g.organizations)
+}
+
+func (g *GoliacRemoteEnterpriseImpl) CreateTeam(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string) {
+	// https://docs.github.com/en/enterprise-cloud@latest/rest/enterprise-teams/enterprise-teams#create-an-enterprise-team
+	team := GithubEnterpriseTeam{
+		Name:          teamname,
+		Members:       []string{},
+		Organizations: []string{},
+	}
+	if !dryrun {
+		body, err := g.client.CallRestAPI(ctx, fmt.Sprintf("/enterprises/%s/teams", g.enterprise), "", "POST", map[string]interface{}{
+			"name":        teamname,
+			"description": "managed by Goliac",
+		}, nil)
+		if err != nil {
+			logsCollector.AddError(fmt.Errorf("failed to create the enterprise team %s: %v. %s", teamname, err, string(body)))
+			return
+		}
+		var created EnterpriseTeamResponse
+		if err := json.Unmarshal(body, &created); err != nil {
+			logsCollector.AddError(fmt.Errorf("failed to unmarshall the enterprise team %s: %v", teamname, err))
+			return
+		}
+		team.Id = created.Id
+		team.Slug = created.Slug
+	}
+
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	g.teams[teamname] = &team
+}
+
+func (g *GoliacRemoteEnterpriseImpl) DeleteTeam(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string) {
+	// https://docs.github.com/en/enterprise-cloud@latest/rest/enterprise-teams/enterprise-teams#delete-an-enterprise-team
+	team, ok := g.teams[teamname]
+	if !ok {
+		return
+	}
+	if !dryrun {
+		body, err := g.client.CallRestAPI(ctx, fmt.Sprintf("/enterprises/%s/teams/%s", g.enterprise, team.Slug), "", "DELETE", nil, nil)
+		if err != nil {
+			logsCollector.AddError(fmt.Errorf("failed to delete the enterprise team %s: %v. %s", teamname, err, string(body)))
+			return
+		}
+	}
+
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	delete(g.teams, teamname)
+}
+
+func (g *GoliacRemoteEnterpriseImpl) AddTeamMember(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, login string) {
+	// https://docs.github.com/en/enterprise-cloud@latest/rest/enterprise-teams/enterprise-team-members#add-team-member
+	g.updateTeamList(ctx, logsCollector, dryrun, teamname, "memberships", login, "PUT", func(team *GithubEnterpriseTeam) *[]string { return &team.Members })
+}
+
+func (g *GoliacRemoteEnterpriseImpl) RemoveTeamMember(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, login string) {
+	// https://docs.github.com/en/enterprise-cloud@latest/rest/enterprise-teams/enterprise-team-members#remove-team-membership
+	g.updateTeamList(ctx, logsCollector, dryrun, teamname, "memberships", login, "DELETE", func(team *GithubEnterpriseTeam) *[]string { return &team.Members })
+}
+
+func (g *GoliacRemoteEnterpriseImpl) AddTeamOrganization(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, organization string) {
+	// https://docs.github.com/en/enterprise-cloud@latest/rest/enterprise-teams/enterprise-team-organizations#add-an-organization-assignment
+	g.updateTeamList(ctx, logsCollector, dryrun, teamname, "organizations", organization, "PUT", func(team *GithubEnterpriseTeam) *[]string { return &team.Organizations })
+}
+
+func (g *GoliacRemoteEnterpriseImpl) RemoveTeamOrganization(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, organization string) {
+	// https://docs.github.com/en/enterprise-cloud@latest/rest/enterprise-teams/enterprise-team-organizations#delete-an-organization-assignment
+	g.updateTeamList(ctx, logsCollector, dryrun, teamname, "organizations", organization, "DELETE", func(team *GithubEnterpriseTeam) *[]string { return &team.Organizations })
+}
+
+/*
+updateTeamList adds (PUT) or removes (DELETE) a member or an organization of an enterprise team
+*/
+func (g *GoliacRemoteEnterpriseImpl) updateTeamList(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, kind string, login string, method string, list func(*GithubEnterpriseTeam) *[]string) {
+	team, ok := g.teams[teamname]
+	if !ok {
+		logsCollector.AddError(fmt.Errorf("enterprise team %s not found", teamname))
+		return
+	}
+	if !dryrun {
+		body, err := g.client.CallRestAPI(ctx, fmt.Sprintf("/enterprises/%s/teams/%s/%s/%s", g.enterprise, team.Slug, kind, login), "", method, nil, nil)
+		if err != nil {
+			logsCollector.AddError(fmt.Errorf("failed to update the %s %s of the enterprise team %s: %v. %s", kind, login, teamname, err, string(body)))
+			return
+		}
+	}
+
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	l := list(team)
+	*l = slices.DeleteFunc(*l, func(s string) bool { return s == login })
+	if method == "PUT" {
+		*l = append(*l, login)
+		slices.Sort(*l)
+	}
+}
@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+// EnterpriseMockClient is a dedicated mock client for the enterprise tests
+type EnterpriseMockClient struct {
+	notFound bool
+
+	// Track the mutations
+	mutations []string
+}
+
+func (m *EnterpriseMockClient) QueryGraphQLAPI(ctx context.Context, query string, variables map[string]interface{}, githubToken *string) ([]byte, error) {
+	if m.notFound {
+		return []byte(`{"data": {"enterprise": null}}`), nil
+	}
+	if strings.Contains(query, "createEnterpriseOrganization") {
+		m.mutations = append(m.mutations, fmt.Sprintf("createEnterpriseOrganization %s %v", variables["login"], variables["adminLogins"]))
+		return []byte(`{"data": {"createEnterpriseOrganization": {"organization": {"login": "neworg"}}}}`), nil
+	}
+	if strings.Contains(query, "listEnterpriseOrganizations") {
+		return []byte(`{"data": {"enterprise": {"id": "E_123", "organizations": {
+  "nodes": [{"login": "oss"}, {"login": "prod"}],
+  "pageInfo": {"hasNextPage": false, "endCursor": null}
+}}}}`), nil
+	}
+	return []byte(`{"data": {"enterprise": {"members": {
+  "nodes": [{"login": "alice"}, {"login": "bob"}, {}],
+  "pageInfo": {"hasNextPage": false, "endCursor": null}
+}}}}`), nil
+}
+
+func (m *EnterpriseMockClient) CallRestAPI(ctx context.Context, endpoint, parameters, method string, body map[string]interface{}, githubToken *string) ([]byte, error) {
+	if method != "GET" {
+		m.mutations = append(m.mutations, method+" "+endpoint)
+		if method == "POST" {
+			return []byte(`{"id": 2, "name": "newteam", "slug": "ent:newteam"}`), nil
+		}
+		return []byte("{}"), nil
+	}
+	switch endpoint {
+	case "/enterprises/myenterprise/teams":
+		return []byte(`[{"id": 1, "name": "platform", "slug": "ent:platform"}]`), nil
+	case "/enterprises/myenterprise/teams/ent:platform/memberships":
+		return []byte(`[{"login": "bob"}, {"login": "alice"}]`), nil
+	case "/enterprises/myenterprise/teams/ent:platform/organizations":
+		return []byte(`[{"login": "prod"}]`), nil
+	}
+	return nil, fmt.Errorf("unexpected endpoint %s", endpoint)
+}
+
+func (m *EnterpriseMockClient) GetAccessToken(ctx context.Context) (string, error) {
+	return "mock-token", nil
+}
+
+func (m *EnterpriseMockClient) CreateJWT() (string, error) {
+	return "mock-jwt", nil
+}
+
+func (m *EnterpriseMockClient) GetAppSlug() string {
+	return "goliac-app"
+}
+
+func TestRemoteEnterprise(t *testing.T) {
+	t.Run("happy path: load the enterprise", func(t *testing.T) {
+		remote := NewGoliacRemoteEnterpriseImpl(&EnterpriseMockClient{}, "myenterprise")
+
+		err := remote.Load(context.TODO())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "E_123", remote.enterpriseId)
+		assert.Equal(t, []string{"oss", "prod"}, remote.Organizations())
+		assert.Equal(t, 2, len(remote.Members()))
+		assert.NotNil(t, remote.Members()["alice"])
+		assert.Equal(t, 1, len(remote.Teams()))
+		assert.Equal(t, "ent:platform", remote.Teams()["platform"].Slug)
+		assert.Equal(t, []string{"alice", "bob"}, remote.Teams()["platform"].Members)
+		assert.Equal(t, []string{"prod"}, remote.Teams()["platform"].Organizations)
+	})
+
+	t.Run("not happy path: unknown enterprise", func(t *testing.T) {
+		remote := NewGoliacRemoteEnterpriseImpl(&EnterpriseMockClient{notFound: true}, "myenterprise")
+
+		err := remote.Load(context.TODO())
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("happy path: update the enterprise", func(t *testing.T) {
+		client := &EnterpriseMockClient{}
+		remote := NewGoliacRemoteEnterpriseImpl(client, "myenterprise")
+		assert.Nil(t, remote.Load(context.TODO()))
+		logsCollector := observability.NewLogCollection()
+
+		remote.CreateOrganization(context.TODO(), logsCollector, false, "neworg", "billing@company.com", []string{"alice"})
+		remote.CreateTeam(context.TODO(), logsCollector, false, "newteam")
+		remote.AddTeamMember(context.TODO(), logsCollector, false, "newteam", "bob")
+		remote.AddTeamOrganization(context.TODO(), logsCollector, false, "newteam", "oss")
+		remote.RemoveTeamMember(context.TODO(), logsCollector, false, "platform", "bob")
+		remote.DeleteTeam(context.TODO(), logsCollector, false, "platform")
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, []string{
+			"createEnterpriseOrganization neworg [alice]",
+			"POST /enterprises/myenterprise/teams",
+			"PUT /enterprises/myenterprise/teams/ent:newteam/memberships/bob",
+			"PUT /enterprises/myenterprise/teams/ent:newteam/organizations/oss",
+			"DELETE /enterprises/myenterprise/teams/ent:platform/memberships/bob",
+			"DELETE /enterprises/myenterprise/teams/ent:platform",
+		}, client.mutations)
+		assert.Equal(t, []string{"neworg", "oss", "prod"}, remote.Organizations())
+		assert.Equal(t, 1, len(remote.Teams()))
+		assert.Equal(t, []string{"bob"}, remote.Teams()["newteam"].Members)
+		assert.Equal(t, []string{"oss"}, remote.Teams()["newteam"].Organizations)
+	})
+
+	t.Run("happy path: dryrun", func(t *testing.T) {
+		client := &EnterpriseMockClient{}
+		remote := NewGoliacRemoteEnterpriseImpl(client, "myenterprise")
+		assert.Nil(t, remote.Load(context.TODO()))
+		logsCollector := observability.NewLogCollection()
+
+		remote.CreateTeam(context.TODO(), logsCollector, true, "newteam")
+		remote.AddTeamMember(context.TODO(), logsCollector, true, "newteam", "bob")
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(client.mutations))
+		assert.Equal(t, []string{"bob"}, remote.Teams()["newteam"].Members)
+	})
+}
@@ -0,0 +1,325 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+/*
+ * GithubOrgEvent is a Github organization webhook event (repository, team,
+ * membership, member, organization, branch_protection_rule, repository_ruleset)
+ * used to keep the remote cache up to date between 2 (TTL based) reloads
+ */
+type GithubOrgEvent struct {
+	Event              string // the X-GitHub-Event header
+	Action             string
+	Sender             string // login of the user (or app) at the origin of the change
+	Repository         string // repository name
+	PreviousRepository string // previous repository name (repository renamed)
+	Team               string // team slug
+	User               string // user login
+	UserGraphqlId      string
+	Role               string // organization role (admin, member)
+	Permission         string // repository collaborator permission (admin, maintain, write, triage, read)
+	RuleName           string // ruleset name, or branch protection rule pattern
+	OrgRuleset         bool   // the ruleset is an organization ruleset
+
+	// repository properties (empty when not part of the event)
+	Visibility     string
+	DefaultBranch  string
+	BoolProperties map[string]bool
+}
+
+/*
+ * HandleOrgEvent updates (or invalidates) the cache entries concerned by
+ * the event. It returns true if the event is a change made outside of Goliac,
+ * that may need to be reconciled
+ */
+func (g *GoliacRemoteImpl) HandleOrgEvent(ctx context.Context, event *GithubOrgEvent) bool {
+	if event.Sender != "" && event.Sender == g.client.GetAppSlug()+"[bot]" {
+		// the cache is already up to date with the Goliac changes
+		return false
+	}
+
+	logrus.Debugf("github %s event (%s) received", event.Event, event.Action)
+
+	changed := false
+	switch event.Event {
+	case "repository":
+		changed = g.handleRepositoryEvent(event)
+	case "team":
+		changed = g.handleTeamEvent(ctx, event)
+	case "membership":
+		changed = g.handleMembershipEvent(ctx, event)
+	case "member":
+		changed = g.handleMemberEvent(event)
+	case "organization":
+		changed = g.handleOrganizationEvent(event)
+	case "branch_protection_rule":
+		changed = g.handleBranchProtectionRuleEvent(event)
+	case "repository_ruleset":
+		changed = g.handleRepositoryRulesetEvent(event)
+	}
+	if changed {
+		// persisted at the next Load (or Commit), not at every event
+		g.actionMutex.Lock()
+		g.stateCacheDirty = true
+		g.actionMutex.Unlock()
+	}
+	return changed
+}
+
+func (g *GoliacRemoteImpl) handleRepositoryEvent(event *GithubOrgEvent) bool {
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	switch event.Action {
+	case "deleted", "transferred":
+		if r, ok := g.repositories[event.Repository]; ok {
+			delete(g.repositoriesByRefId, r.RefId)
+			delete(g.repositories, event.Repository)
+		}
+		for _, repos := range g.teamRepos {
+			delete(repos, event.Repository)
+		}
+		return true
+	case "renamed":
+		if r, ok := g.repositories[event.PreviousRepository]; ok {
+			r.Name = event.Repository
+			delete(g.repositories, event.PreviousRepository)
+			g.repositories[event.Repository] = r
+		}
+		for _, repos := range g.teamRepos {
+			if tr, ok := repos[event.PreviousRepository]; ok {
+				tr.Name = event.Repository
+				delete(repos, event.PreviousRepository)
+				repos[event.Repository] = tr
+			}
+		}
+		return true
+	case "created":
+		// we don't know yet the teams and collaborators of the repository
+		g.ttlExpireRepositories = time.Now()
+		g.ttlExpireTeamsRepos = time.Now()
+		return true
+	}
+
+	// archived, unarchived, edited, privatized, publicized
+	r, ok := g.repositories[event.Repository]
+	if !ok {
+		return false
+	}
+	if event.Visibility != "" {
+		r.Visibility = event.Visibility
+	}
+	if event.DefaultBranch != "" {
+		r.DefaultBranchName = event.DefaultBranch
+	}
+	if r.BoolProperties == nil && len(event.BoolProperties) > 0 {
+		r.BoolProperties = make(map[string]bool)
+	}
+	for k, v := range event.BoolProperties {
+		r.BoolProperties[k] = v
+	}
+	return true
+}
+
+func (g *GoliacRemoteImpl) handleTeamEvent(ctx context.Context, event *GithubOrgEvent) bool {
+	switch event.Action {
+	case "deleted":
+		g.actionMutex.Lock()
+		defer g.actionMutex.Unlock()
+		if t, ok := g.teams[event.Team]; ok {
+			delete(g.teamSlugByName, t.Name)
+			delete(g.teams, event.Team)
+		}
+		delete(g.teamRepos, event.Team)
+		return true
+	case "added_to_repository", "removed_from_repository", "edited":
+		if event.Repository != "" {
+			// reload only the teams of this repository
+			teamsrepo, err := g.loadTeamRepos(ctx, &GithubRepository{Name: event.Repository})
+			g.actionMutex.Lock()
+			defer g.actionMutex.Unlock()
+			if err != nil {
+				logrus.Warnf("not able to reload the teams of the repository %s: %v", event.Repository, err)
+				g.ttlExpireTeamsRepos = time.Now()
+				return true
+			}
+			for slug, repos := range g.teamRepos {
+				if _, ok := teamsrepo[slug]; !ok {
+					delete(repos, event.Repository)
+				}
+			}
+			for slug, tr := range teamsrepo {
+				if _, ok := g.teamRepos[slug]; !ok {
+					g.teamRepos[slug] = make(map[string]*GithubTeamRepo)
+				}
+				g.teamRepos[slug][event.Repository] = tr
+			}
+			return true
+		}
+	}
+
+	// created, or team renamed (the slug changes)
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+	g.ttlExpireTeams = time.Now()
+	g.ttlExpireTeamsRepos = time.Now()
+	return true
+}
+
+func (g *GoliacRemoteImpl) handleMembershipEvent(ctx context.Context, event *GithubOrgEvent) bool {
+	g.actionMutex.Lock()
+	t, ok := g.teams[event.Team]
+	g.actionMutex.Unlock()
+	if !ok {
+		return false
+	}
+
+	// reload only the members of this team
+	team := &GithubTeam{
+		Name:        t.Name,
+		Id:          t.Id,
+		GraphqlId:   t.GraphqlId,
+		Slug:        t.Slug,
+		Members:     []string{},
+		Maintainers: []string{},
+		ParentTeam:  t.ParentTeam,
+	}
+	err := g.loadTeamsMembers(ctx, team)
+
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+	if err != nil {
+		logrus.Warnf("not able to reload the members of the team %s: %v", event.Team, err)
+		g.ttlExpireTeams = time.Now()
+		return true
+	}
+	g.teams[event.Team] = team
+	return true
+}
+
+func (g *GoliacRemoteImpl) handleMemberEvent(event *GithubOrgEvent) bool {
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	r, ok := g.repositories[event.Repository]
+	if !ok {
+		return false
+	}
+
+	delete(r.ExternalUsers, event.User)
+	delete(r.InternalUsers, event.User)
+	if event.Action == "removed" {
+		return true
+	}
+	if event.Permission == "" {
+		// we don't know the new permission
+		g.ttlExpireRepositories = time.Now()
+		return true
+	}
+	permission := strings.ToUpper(event.Permission)
+	switch permission {
+	case "PUSH":
+		permission = "WRITE"
+	case "PULL":
+		permission = "READ"
+	}
+	if _, isMember := g.users[event.User]; isMember {
+		if r.InternalUsers == nil {
+			r.InternalUsers = make(map[string]string)
+		}
+		r.InternalUsers[event.User] = permission
+	} else {
+		if r.ExternalUsers == nil {
+			r.ExternalUsers = make(map[string]string)
+		}
+		r.ExternalUsers[event.User] = permission
+	}
+	return true
+}
+
+func (g *GoliacRemoteImpl) handleOrganizationEvent(event *GithubOrgEvent) bool {
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	switch event.Action {
+	case "member_added":
+		role := "MEMBER"
+		if event.Role == "admin" {
+			role = "ADMIN"
+		}
+		g.users[event.User] = &GithubUser{
+			Login:     event.User,
+			GraphqlId: event.UserGraphqlId,
+			Role:      role,
+		}
+		return true
+	case "member_removed":
+		delete(g.users, event.User)
+		// Github removes the user from the teams too
+		for _, t := range g.teams {
+			t.Members = removeLogin(t.Members, event.User)
+			t.Maintainers = removeLogin(t.Maintainers, event.User)
+		}
+		return true
+	}
+	return false
+}
+
+func (g *GoliacRemoteImpl) handleBranchProtectionRuleEvent(event *GithubOrgEvent) bool {
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	r, ok := g.repositories[event.Repository]
+	if !ok {
+		return false
+	}
+	if event.Action == "deleted" {
+		delete(r.BranchProtections, event.RuleName)
+		return true
+	}
+	// the branch protections are loaded with the repositories
+	g.ttlExpireRepositories = time.Now()
+	return true
+}
+
+func (g *GoliacRemoteImpl) handleRepositoryRulesetEvent(event *GithubOrgEvent) bool {
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	if event.OrgRuleset {
+		if event.Action == "deleted" {
+			delete(g.rulesets, event.RuleName)
+		} else {
+			g.ttlExpireRulesets = time.Now()
+		}
+		return true
+	}
+
+	r, ok := g.repositories[event.Repository]
+	if !ok {
+		return false
+	}
+	if event.Action == "deleted" {
+		delete(r.RuleSets, event.RuleName)
+		return true
+	}
+	// the repositories rulesets are loaded with the repositories
+	g.ttlExpireRepositories = time.Now()
+	return true
+}
+
+func removeLogin(logins []string, login string) []string {
+	result := make([]string, 0, len(logins))
+	for _, l := range logins {
+		if l != login {
+			result = append(result, l)
+		}
+	}
+	return result
+}
@@ -0,0 +1,301 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// OrgEventMockClient is a dedicated mock client for the organization events tests
+type OrgEventMockClient struct {
+	endpoints []string
+}
+
+func (m *OrgEventMockClient) QueryGraphQLAPI(ctx context.Context, query string, variables map[string]interface{}, githubToken *string) ([]byte, error) {
+	return []byte(`{
+  "data": {
+    "organization": {
+      "team": {
+        "members": {
+          "edges": [
+            { "node": { "login": "user1" }, "role": "MAINTAINER" },
+            { "node": { "login": "user3" }, "role": "MEMBER" }
+          ],
+          "pageInfo": { "hasNextPage": false, "endCursor": null }
+        }
+      }
+    }
+  }
+}`), nil
+}
+
+func (m *OrgEventMockClient) CallRestAPI(ctx context.Context, endpoint, parameters, method string, body map[string]interface{}, githubToken *string) ([]byte, error) {
+	if endpoint == "/api/v3" {
+		// GHES version check done by NewGoliacRemoteImpl
+		return []byte("{}"), nil
+	}
+	m.endpoints = append(m.endpoints, endpoint)
+	return []byte(`[{"slug": "team2", "permission": "push", "role_name": "write"}]`), nil
+}
+
+func (m *OrgEventMockClient) GetAccessToken(ctx context.Context) (string, error) {
+	return "mock-token", nil
+}
+
+func (m *OrgEventMockClient) CreateJWT() (string, error) {
+	return "mock-jwt", nil
+}
+
+func (m *OrgEventMockClient) GetAppSlug() string {
+	return "goliac-app"
+}
+
+func fixtureRemoteForOrgEvents() (*GoliacRemoteImpl, *OrgEventMockClient) {
+	mockClient := &OrgEventMockClient{}
+	remoteImpl := NewGoliacRemoteImpl(mockClient, "myorg", true, true, true)
+	expire := time.Now().Add(time.Hour)
+
+	remoteImpl.users = map[string]*GithubUser{
+		"user1": {Login: "user1", Role: "MEMBER"},
+		"user2": {Login: "user2", Role: "MEMBER"},
+	}
+	remoteImpl.teams = map[string]*GithubTeam{
+		"team1": {Name: "team1", Slug: "team1", Members: []string{"user2"}, Maintainers: []string{"user1"}},
+	}
+	remoteImpl.teamSlugByName = map[string]string{"team1": "team1"}
+	remoteImpl.repositories = map[string]*GithubRepository{
+		"repo1": {
+			Name:              "repo1",
+			RefId:             "R_1",
+			Visibility:        "private",
+			BoolProperties:    map[string]bool{"archived": false},
+			ExternalUsers:     map[string]string{},
+			InternalUsers:     map[string]string{"user2": "READ"},
+			BranchProtections: map[string]*GithubBranchProtection{"main": {Pattern: "main"}},
+			RuleSets:          map[string]*GithubRuleSet{"repo-ruleset": {Name: "repo-ruleset"}},
+		},
+	}
+	remoteImpl.repositoriesByRefId = map[string]*GithubRepository{"R_1": remoteImpl.repositories["repo1"]}
+	remoteImpl.teamRepos = map[string]map[string]*GithubTeamRepo{
+		"team1": {"repo1": {Name: "repo1", Permission: "WRITE"}},
+	}
+	remoteImpl.rulesets = map[string]*GithubRuleSet{"default": {Name: "default"}}
+	remoteImpl.ttlExpireUsers = expire
+	remoteImpl.ttlExpireTeams = expire
+	remoteImpl.ttlExpireRepositories = expire
+	remoteImpl.ttlExpireTeamsRepos = expire
+	remoteImpl.ttlExpireRulesets = expire
+	return remoteImpl, mockClient
+}
+
+func TestHandleOrgEvent(t *testing.T) {
+	t.Run("happy path: Goliac changes are ignored", func(t *testing.T) {
+		remoteImpl, _ := fixtureRemoteForOrgEvents()
+
+		changed := remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:      "repository",
+			Action:     "deleted",
+			Sender:     "goliac-app[bot]",
+			Repository: "repo1",
+		})
+
+		assert.False(t, changed)
+		assert.NotNil(t, remoteImpl.repositories["repo1"])
+	})
+
+	t.Run("happy path: repository deleted", func(t *testing.T) {
+		remoteImpl, _ := fixtureRemoteForOrgEvents()
+
+		changed := remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:      "repository",
+			Action:     "deleted",
+			Sender:     "user1",
+			Repository: "repo1",
+		})
+
+		assert.True(t, changed)
+		assert.Nil(t, remoteImpl.repositories["repo1"])
+		assert.Nil(t, remoteImpl.repositoriesByRefId["R_1"])
+		assert.Equal(t, 0, len(remoteImpl.teamRepos["team1"]))
+	})
+
+	t.Run("happy path: repository renamed", func(t *testing.T) {
+		remoteImpl, _ := fixtureRemoteForOrgEvents()
+
+		changed := remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:              "repository",
+			Action:             "renamed",
+			Repository:         "repo2",
+			PreviousRepository: "repo1",
+		})
+
+		assert.True(t, changed)
+		assert.Nil(t, remoteImpl.repositories["repo1"])
+		assert.Equal(t, "repo2", remoteImpl.repositories["repo2"].Name)
+		assert.Equal(t, "repo2", remoteImpl.teamRepos["team1"]["repo2"].Name)
+	})
+
+	t.Run("happy path: repository edited", func(t *testing.T) {
+		remoteImpl, _ := fixtureRemoteForOrgEvents()
+
+		changed := remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:          "repository",
+			Action:         "publicized",
+			Repository:     "repo1",
+			Visibility:     "public",
+			BoolProperties: map[string]bool{"archived": true},
+		})
+
+		assert.True(t, changed)
+		assert.Equal(t, "public", remoteImpl.repositories["repo1"].Visibility)
+		assert.Equal(t, true, remoteImpl.repositories["repo1"].BoolProperties["archived"])
+		// no reload needed
+		assert.True(t, remoteImpl.ttlExpireRepositories.After(time.Now()))
+	})
+
+	t.Run("happy path: repository created", func(t *testing.T) {
+		remoteImpl, _ := fixtureRemoteForOrgEvents()
+
+		changed := remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:      "repository",
+			Action:     "created",
+			Repository: "repo3",
+		})
+
+		assert.True(t, changed)
+		assert.False(t, remoteImpl.ttlExpireRepositories.After(time.Now()))
+	})
+
+	t.Run("happy path: team added to a repository", func(t *testing.T) {
+		remoteImpl, mockClient := fixtureRemoteForOrgEvents()
+
+		changed := remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:      "team",
+			Action:     "added_to_repository",
+			Team:       "team2",
+			Repository: "repo1",
+		})
+
+		assert.True(t, changed)
+		assert.Equal(t, []string{"/repos/myorg/repo1/teams"}, mockClient.endpoints)
+		assert.Equal(t, "WRITE", remoteImpl.teamRepos["team2"]["repo1"].Permission)
+		// team1 is not a team of the repository anymore
+		assert.Nil(t, remoteImpl.teamRepos["team1"]["repo1"])
+	})
+
+	t.Run("happy path: team deleted", func(t *testing.T) {
+		remoteImpl, _ := fixtureRemoteForOrgEvents()
+
+		changed := remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:  "team",
+			Action: "deleted",
+			Team:   "team1",
+		})
+
+		assert.True(t, changed)
+		assert.Nil(t, remoteImpl.teams["team1"])
+		assert.Equal(t, "", remoteImpl.teamSlugByName["team1"])
+		assert.Nil(t, remoteImpl.teamRepos["team1"])
+	})
+
+	t.Run("happy path: team membership", func(t *testing.T) {
+		remoteImpl, _ := fixtureRemoteForOrgEvents()
+
+		changed := remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:  "membership",
+			Action: "added",
+			Team:   "team1",
+			User:   "user3",
+		})
+
+		assert.True(t, changed)
+		assert.Equal(t, []string{"user3"}, remoteImpl.teams["team1"].Members)
+		assert.Equal(t, []string{"user1"}, remoteImpl.teams["team1"].Maintainers)
+	})
+
+	t.Run("happy path: repository collaborator", func(t *testing.T) {
+		remoteImpl, _ := fixtureRemoteForOrgEvents()
+
+		changed := remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:      "member",
+			Action:     "added",
+			Repository: "repo1",
+			User:       "outside",
+			Permission: "write",
+		})
+		assert.True(t, changed)
+		assert.Equal(t, "WRITE", remoteImpl.repositories["repo1"].ExternalUsers["outside"])
+
+		changed = remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:      "member",
+			Action:     "removed",
+			Repository: "repo1",
+			User:       "user2",
+		})
+		assert.True(t, changed)
+		assert.Equal(t, 0, len(remoteImpl.repositories["repo1"].InternalUsers))
+	})
+
+	t.Run("happy path: organization members", func(t *testing.T) {
+		remoteImpl, _ := fixtureRemoteForOrgEvents()
+
+		changed := remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:         "organization",
+			Action:        "member_added",
+			User:          "user3",
+			UserGraphqlId: "U_3",
+			Role:          "admin",
+		})
+		assert.True(t, changed)
+		assert.Equal(t, "ADMIN", remoteImpl.users["user3"].Role)
+		assert.Equal(t, "U_3", remoteImpl.users["user3"].GraphqlId)
+
+		changed = remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:  "organization",
+			Action: "member_removed",
+			User:   "user1",
+		})
+		assert.True(t, changed)
+		assert.Nil(t, remoteImpl.users["user1"])
+		assert.Equal(t, 0, len(remoteImpl.teams["team1"].Maintainers))
+	})
+
+	t.Run("happy path: branch protection and rulesets", func(t *testing.T) {
+		remoteImpl, _ := fixtureRemoteForOrgEvents()
+
+		remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:      "branch_protection_rule",
+			Action:     "deleted",
+			Repository: "repo1",
+			RuleName:   "main",
+		})
+		assert.Equal(t, 0, len(remoteImpl.repositories["repo1"].BranchProtections))
+
+		remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:      "repository_ruleset",
+			Action:     "deleted",
+			Repository: "repo1",
+			RuleName:   "repo-ruleset",
+		})
+		assert.Equal(t, 0, len(remoteImpl.repositories["repo1"].RuleSets))
+
+		remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:      "repository_ruleset",
+			Action:     "deleted",
+			RuleName:   "default",
+			OrgRuleset: true,
+		})
+		assert.Equal(t, 0, len(remoteImpl.rulesets))
+
+		// an edited organization ruleset is reloaded
+		remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:      "repository_ruleset",
+			Action:     "edited",
+			RuleName:   "other",
+			OrgRuleset: true,
+		})
+		assert.False(t, remoteImpl.ttlExpireRulesets.After(time.Now()))
+	})
+}
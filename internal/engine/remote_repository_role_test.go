@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+// RepositoryRoleMockClient is a dedicated mock client for the custom repository roles tests
+type RepositoryRoleMockClient struct {
+	notFound bool
+
+	// Track REST API calls
+	endpoints  []string
+	lastMethod string
+	lastBody   map[string]interface{}
+}
+
+func (m *RepositoryRoleMockClient) QueryGraphQLAPI(ctx context.Context, query string, variables map[string]interface{}, githubToken *string) ([]byte, error) {
+	return []byte("{}"), nil
+}
+
+func (m *RepositoryRoleMockClient) CallRestAPI(ctx context.Context, endpoint, parameters, method string, body map[string]interface{}, githubToken *string) ([]byte, error) {
+	if endpoint == "/api/v3" {
+		// GHES version check done by NewGoliacRemoteImpl
+		return []byte("{}"), nil
+	}
+	m.endpoints = append(m.endpoints, endpoint)
+	m.lastMethod = method
+	m.lastBody = body
+
+	if m.notFound {
+		return nil, fmt.Errorf("unexpected status: 404 Not Found")
+	}
+	switch method {
+	case "GET":
+		return []byte(`{
+  "total_count": 1,
+  "custom_roles": [
+    {
+      "id": 8030,
+      "name": "security-triager",
+      "description": "triage + security alerts",
+      "base_role": "triage",
+      "permissions": ["view_dependabot_alerts"]
+    }
+  ]
+}`), nil
+	case "POST":
+		return []byte(`{"id": 8031, "name": "release-manager"}`), nil
+	}
+	return []byte("{}"), nil
+}
+
+func (m *RepositoryRoleMockClient) GetAccessToken(ctx context.Context) (string, error) {
+	return "mock-token", nil
+}
+
+func (m *RepositoryRoleMockClient) CreateJWT() (string, error) {
+	return "mock-jwt", nil
+}
+
+func (m *RepositoryRoleMockClient) GetAppSlug() string {
+	return "goliac-app"
+}
+
+func TestRepositoryRoles(t *testing.T) {
+	t.Run("happy path: load the custom repository roles", func(t *testing.T) {
+		mockClient := &RepositoryRoleMockClient{}
+		remoteImpl := NewGoliacRemoteImpl(mockClient, "myorg", true, true, true)
+
+		roles := remoteImpl.RepositoryRoles(context.TODO())
+
+		assert.Equal(t, []string{"/orgs/myorg/custom-repository-roles"}, mockClient.endpoints)
+		assert.Equal(t, 1, len(roles))
+		assert.Equal(t, 8030, roles["security-triager"].Id)
+		assert.Equal(t, "triage", roles["security-triager"].BaseRole)
+		assert.Equal(t, []string{"view_dependabot_alerts"}, roles["security-triager"].Permissions)
+
+		// cached
+		remoteImpl.RepositoryRoles(context.TODO())
+		assert.Equal(t, 1, len(mockClient.endpoints))
+	})
+
+	t.Run("happy path: custom repository roles not available", func(t *testing.T) {
+		mockClient := &RepositoryRoleMockClient{notFound: true}
+		remoteImpl := NewGoliacRemoteImpl(mockClient, "myorg", true, true, true)
+
+		roles := remoteImpl.RepositoryRoles(context.TODO())
+
+		assert.NotNil(t, roles)
+		assert.Equal(t, 0, len(roles))
+	})
+
+	t.Run("happy path: create a custom repository role", func(t *testing.T) {
+		mockClient := &RepositoryRoleMockClient{}
+		remoteImpl := NewGoliacRemoteImpl(mockClient, "myorg", true, true, true)
+
+		logsCollector := observability.NewLogCollection()
+		remoteImpl.AddRepositoryRole(context.TODO(), logsCollector, false, &GithubRepositoryRole{
+			Name:        "release-manager",
+			BaseRole:    "write",
+			Permissions: []string{"bypass_branch_protection"},
+		})
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, []string{"/orgs/myorg/custom-repository-roles"}, mockClient.endpoints)
+		assert.Equal(t, "POST", mockClient.lastMethod)
+		assert.Equal(t, "write", mockClient.lastBody["base_role"])
+		assert.Equal(t, 8031, remoteImpl.repositoryRoles["release-manager"].Id)
+	})
+
+	t.Run("happy path: update a custom repository role", func(t *testing.T) {
+		mockClient := &RepositoryRoleMockClient{}
+		remoteImpl := NewGoliacRemoteImpl(mockClient, "myorg", true, true, true)
+
+		logsCollector := observability.NewLogCollection()
+		remoteImpl.UpdateRepositoryRole(context.TODO(), logsCollector, false, &GithubRepositoryRole{
+			Id:       8030,
+			Name:     "security-triager",
+			BaseRole: "read",
+		})
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, []string{"/orgs/myorg/custom-repository-roles/8030"}, mockClient.endpoints)
+		assert.Equal(t, "PATCH", mockClient.lastMethod)
+		assert.Equal(t, "read", remoteImpl.repositoryRoles["security-triager"].BaseRole)
+	})
+
+	t.Run("happy path: delete a custom repository role", func(t *testing.T) {
+		mockClient := &RepositoryRoleMockClient{}
+		remoteImpl := NewGoliacRemoteImpl(mockClient, "myorg", true, true, true)
+		remoteImpl.repositoryRoles["security-triager"] = &GithubRepositoryRole{Id: 8030, Name: "security-triager"}
+
+		logsCollector := observability.NewLogCollection()
+		remoteImpl.DeleteRepositoryRole(context.TODO(), logsCollector, false, 8030)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, []string{"/orgs/myorg/custom-repository-roles/8030"}, mockClient.endpoints)
+		assert.Equal(t, "DELETE", mockClient.lastMethod)
+		assert.Equal(t, 0, len(remoteImpl.repositoryRoles))
+	})
+
+	t.Run("happy path: dryrun", func(t *testing.T) {
+		mockClient := &RepositoryRoleMockClient{}
+		remoteImpl := NewGoliacRemoteImpl(mockClient, "myorg", true, true, true)
+
+		logsCollector := observability.NewLogCollection()
+		remoteImpl.AddRepositoryRole(context.TODO(), logsCollector, true, &GithubRepositoryRole{
+			Name:     "release-manager",
+			BaseRole: "write",
+		})
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(mockClient.endpoints))
+		assert.NotNil(t, remoteImpl.repositoryRoles["release-manager"])
+	})
+}
+
+func TestNewGithubTeamRepo(t *testing.T) {
+	t.Run("happy path: predefined permissions", func(t *testing.T) {
+		assert.Equal(t, "ADMIN", newGithubTeamRepo("repo", "admin", "admin").Permission)
+		assert.Equal(t, "MAINTAIN", newGithubTeamRepo("repo", "maintain", "maintain").Permission)
+		assert.Equal(t, "WRITE", newGithubTeamRepo("repo", "push", "write").Permission)
+		assert.Equal(t, "TRIAGE", newGithubTeamRepo("repo", "triage", "triage").Permission)
+		assert.Equal(t, "READ", newGithubTeamRepo("repo", "pull", "read").Permission)
+		assert.Equal(t, "", newGithubTeamRepo("repo", "pull", "read").RoleName)
+	})
+
+	t.Run("happy path: custom repository role", func(t *testing.T) {
+		// Github returns the base role permission, and the custom role in role_name
+		teamRepo := newGithubTeamRepo("repo", "triage", "security-triager")
+		assert.Equal(t, "", teamRepo.Permission)
+		assert.Equal(t, "security-triager", teamRepo.RoleName)
+
+		// when the custom role is set through the permission (cache update)
+		teamRepo = newGithubTeamRepo("repo", "release-manager", "")
+		assert.Equal(t, "", teamRepo.Permission)
+		assert.Equal(t, "release-manager", teamRepo.RoleName)
+	})
+}
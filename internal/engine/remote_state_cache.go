@@ -0,0 +1,331 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/gosimple/slug"
+	"github.com/sirupsen/logrus"
+)
+
+/*
+ * RemoteStateCache persists the Github remote state loaded by GoliacRemoteImpl,
+ * to warm start Goliac after a restart (instead of reloading the whole organization).
+ * FileRemoteStateCache is the default backend, but another one (like a database)
+ * can be plugged with GoliacRemoteImpl.SetRemoteStateCache
+ */
+type RemoteStateCache interface {
+	Load(key string, value interface{}) error // returns an error if the key is not found
+	Store(key string, value interface{}) error
+	Clear(key string) error
+}
+
+/*
+ * FileRemoteStateCache is a RemoteStateCache storing one json file per key
+ */
+type FileRemoteStateCache struct {
+	dirname string
+}
+
+func NewFileRemoteStateCache(dirname string) (*FileRemoteStateCache, error) {
+	if err := os.MkdirAll(dirname, 0700); err != nil {
+		return nil, err
+	}
+	return &FileRemoteStateCache{
+		dirname: dirname,
+	}, nil
+}
+
+func (c *FileRemoteStateCache) filename(key string) string {
+	return filepath.Join(c.dirname, slug.Make(key)+".json")
+}
+
+func (c *FileRemoteStateCache) Load(key string, value interface{}) error {
+	content, err := os.ReadFile(c.filename(key))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, value)
+}
+
+func (c *FileRemoteStateCache) Store(key string, value interface{}) error {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	// write then rename, to never read a partially written state
+	tmpfile, err := os.CreateTemp(c.dirname, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmpfile.Write(content)
+	if err1 := tmpfile.Close(); err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Rename(tmpfile.Name(), c.filename(key))
+	}
+	if err != nil {
+		os.Remove(tmpfile.Name())
+	}
+	return err
+}
+
+func (c *FileRemoteStateCache) Clear(key string) error {
+	err := os.Remove(c.filename(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+/*
+ * persistedGithubRepository is the persisted version of a GithubRepository:
+ * the lazy loaders (Environments, RepositoryVariables, Autolinks) are not
+ * persisted, they are set back when the state is restored
+ */
+type persistedGithubRepository struct {
+	Name                       string
+	Id                         int
+	RefId                      string
+	Visibility                 string
+	BoolProperties             map[string]bool
+	ExternalUsers              map[string]string
+	InternalUsers              map[string]string
+	RuleSets                   map[string]*GithubRuleSet
+	BranchProtections          map[string]*GithubBranchProtection
+	DefaultBranchName          string
+	IsFork                     bool
+	DefaultMergeCommitMessage  string
+	DefaultSquashCommitMessage string
+	CustomProperties           map[string]interface{}
+	Topics                     []string
+	CodeownersContent          string
+	CodeownersSHA              string
+	GithubPages                *GithubPagesRemote
+}
+
+func newPersistedGithubRepository(r *GithubRepository) *persistedGithubRepository {
+	return &persistedGithubRepository{
+		Name:                       r.Name,
+		Id:                         r.Id,
+		RefId:                      r.RefId,
+		Visibility:                 r.Visibility,
+		BoolProperties:             r.BoolProperties,
+		ExternalUsers:              r.ExternalUsers,
+		InternalUsers:              r.InternalUsers,
+		RuleSets:                   r.RuleSets,
+		BranchProtections:          r.BranchProtections,
+		DefaultBranchName:          r.DefaultBranchName,
+		IsFork:                     r.IsFork,
+		DefaultMergeCommitMessage:  r.DefaultMergeCommitMessage,
+		DefaultSquashCommitMessage: r.DefaultSquashCommitMessage,
+		CustomProperties:           r.CustomProperties,
+		Topics:                     r.Topics,
+		CodeownersContent:          r.CodeownersContent,
+		CodeownersSHA:              r.CodeownersSHA,
+		GithubPages:                r.GithubPages,
+	}
+}
+
+/*
+ * toGithubRepository returns the GithubRepository, without its lazy loaders
+ */
+func (r *persistedGithubRepository) toGithubRepository() *GithubRepository {
+	return &GithubRepository{
+		Name:                       r.Name,
+		Id:                         r.Id,
+		RefId:                      r.RefId,
+		Visibility:                 r.Visibility,
+		BoolProperties:             r.BoolProperties,
+		ExternalUsers:              r.ExternalUsers,
+		InternalUsers:              r.InternalUsers,
+		RuleSets:                   r.RuleSets,
+		BranchProtections:          r.BranchProtections,
+		DefaultBranchName:          r.DefaultBranchName,
+		IsFork:                     r.IsFork,
+		DefaultMergeCommitMessage:  r.DefaultMergeCommitMessage,
+		DefaultSquashCommitMessage: r.DefaultSquashCommitMessage,
+		CustomProperties:           r.CustomProperties,
+		Topics:                     r.Topics,
+		CodeownersContent:          r.CodeownersContent,
+		CodeownersSHA:              r.CodeownersSHA,
+		GithubPages:                r.GithubPages,
+	}
+}
+
+/*
+ * remoteState is the persisted version of the GoliacRemoteImpl cache
+ * (with the cache expiration of each asset)
+ */
+type remoteState struct {
+	GoliacVersion      string // the state is discarded when Goliac is upgraded
+	Users              map[string]*GithubUser
+	UsersExpire        time.Time
+	Teams              map[string]*GithubTeam
+	TeamSlugByName     map[string]string
+	TeamsExpire        time.Time
+	Repositories       map[string]*persistedGithubRepository
+	RepositoriesExpire time.Time
+	TeamRepos          map[string]map[string]*GithubTeamRepo
+	TeamReposExpire    time.Time
+	Rulesets           map[string]*GithubRuleSet
+	RulesetsExpire     time.Time
+	AppIds             map[string]*GithubApp
+	AppIdsExpire       time.Time
+}
+
+func (g *GoliacRemoteImpl) remoteStateKey() string {
+	return "remote-" + g.configGithubOrg
+}
+
+/*
+ * SetRemoteStateCache plugs a persistent cache: the remote state is restored
+ * from it at the next Load (for the assets not expired yet), and saved after each
+ * Load reloading assets (or following webhook events changes) and each (not dryrun) Commit
+ */
+func (g *GoliacRemoteImpl) SetRemoteStateCache(cache RemoteStateCache) {
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+	g.stateCache = cache
+	g.stateCacheRestored = false
+}
+
+/*
+ * restoreRemoteState restores (once) the assets not expired from the persistent cache
+ */
+func (g *GoliacRemoteImpl) restoreRemoteState() {
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	if g.stateCache == nil || g.stateCacheRestored {
+		return
+	}
+	g.stateCacheRestored = true
+
+	var state remoteState
+	if err := g.stateCache.Load(g.remoteStateKey(), &state); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			logrus.Debugf("no remote state to restore: %v", err)
+		} else {
+			logrus.Warnf("not able to restore the remote state: %v", err)
+		}
+		return
+	}
+	if state.GoliacVersion != config.GoliacBuildVersion {
+		logrus.Debugf("remote state discarded (saved by Goliac %s)", state.GoliacVersion)
+		return
+	}
+
+	now := time.Now()
+	if now.Before(state.UsersExpire) && state.Users != nil {
+		g.users = state.Users
+		g.ttlExpireUsers = state.UsersExpire
+	}
+	if now.Before(state.TeamsExpire) && state.Teams != nil && state.TeamSlugByName != nil {
+		g.teams = state.Teams
+		g.teamSlugByName = state.TeamSlugByName
+		g.ttlExpireTeams = state.TeamsExpire
+	}
+	if now.Before(state.RepositoriesExpire) && state.Repositories != nil {
+		repositories := make(map[string]*GithubRepository)
+		repositoriesByRefId := make(map[string]*GithubRepository)
+		for name, r := range state.Repositories {
+			repo := r.toGithubRepository()
+			// json doesn't keep the []string type of the multi_select custom properties
+			for k, v := range repo.CustomProperties {
+				if values, ok := v.([]interface{}); ok {
+					strValues := make([]string, 0, len(values))
+					for _, value := range values {
+						if s, ok := value.(string); ok {
+							strValues = append(strValues, s)
+						}
+					}
+					repo.CustomProperties[k] = strValues
+				}
+			}
+			repositories[name] = repo
+			repositoriesByRefId[repo.RefId] = repo
+		}
+		g.setRepositoriesLazyLoaders(repositories)
+		g.repositories = repositories
+		g.repositoriesByRefId = repositoriesByRefId
+		g.ttlExpireRepositories = state.RepositoriesExpire
+	}
+	if now.Before(state.TeamReposExpire) && state.TeamRepos != nil {
+		g.teamRepos = state.TeamRepos
+		g.ttlExpireTeamsRepos = state.TeamReposExpire
+	}
+	if now.Before(state.RulesetsExpire) && state.Rulesets != nil {
+		g.rulesets = state.Rulesets
+		g.ttlExpireRulesets = state.RulesetsExpire
+	}
+	if now.Before(state.AppIdsExpire) && state.AppIds != nil {
+		g.appIds = state.AppIds
+		g.ttlExpireAppIds = state.AppIdsExpire
+	}
+	logrus.Debugf("remote state restored from the persistent cache")
+}
+
+/*
+ * persistRemoteState saves the current remote state into the persistent cache
+ */
+func (g *GoliacRemoteImpl) persistRemoteState() {
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	if g.stateCache == nil {
+		return
+	}
+	g.stateCacheDirty = false
+
+	repositories := make(map[string]*persistedGithubRepository)
+	for name, repo := range g.repositories {
+		repositories[name] = newPersistedGithubRepository(repo)
+	}
+
+	state := remoteState{
+		GoliacVersion:      config.GoliacBuildVersion,
+		Users:              g.users,
+		UsersExpire:        g.ttlExpireUsers,
+		Teams:              g.teams,
+		TeamSlugByName:     g.teamSlugByName,
+		TeamsExpire:        g.ttlExpireTeams,
+		Repositories:       repositories,
+		RepositoriesExpire: g.ttlExpireRepositories,
+		TeamRepos:          g.teamRepos,
+		TeamReposExpire:    g.ttlExpireTeamsRepos,
+		Rulesets:           g.rulesets,
+		RulesetsExpire:     g.ttlExpireRulesets,
+		AppIds:             g.appIds,
+		AppIdsExpire:       g.ttlExpireAppIds,
+	}
+	if err := g.stateCache.Store(g.remoteStateKey(), &state); err != nil {
+		logrus.Warnf("not able to persist the remote state: %v", err)
+	}
+}
+
+/*
+ * remoteStateDirty returns true if the remote state changed since it was persisted
+ */
+func (g *GoliacRemoteImpl) remoteStateDirty() bool {
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+	return g.stateCacheDirty
+}
+
+/*
+ * clearRemoteState removes the persisted remote state (when the cache is flushed)
+ */
+func (g *GoliacRemoteImpl) clearRemoteState() {
+	if g.stateCache == nil {
+		return
+	}
+	if err := g.stateCache.Clear(g.remoteStateKey()); err != nil {
+		logrus.Warnf("not able to clear the persisted remote state: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func fixtureRemoteWithState(cache RemoteStateCache) *GoliacRemoteImpl {
+	remoteImpl := NewGoliacRemoteImpl(&RepositoryRoleMockClient{}, "myorg", true, true, true)
+	remoteImpl.SetRemoteStateCache(cache)
+	return remoteImpl
+}
+
+func TestRemoteStateCache(t *testing.T) {
+	t.Run("happy path: persist and restore the remote state", func(t *testing.T) {
+		cache, err := NewFileRemoteStateCache(t.TempDir())
+		assert.Nil(t, err)
+
+		remoteImpl := fixtureRemoteWithState(cache)
+		expire := time.Now().Add(time.Hour)
+		remoteImpl.users = map[string]*GithubUser{
+			"user1": {Login: "user1", Role: "MEMBER"},
+		}
+		remoteImpl.ttlExpireUsers = expire
+		remoteImpl.teams = map[string]*GithubTeam{
+			"team1": {Name: "team1", Id: 1, Slug: "team1", Members: []string{"user1"}},
+		}
+		remoteImpl.teamSlugByName = map[string]string{"team1": "team1"}
+		remoteImpl.ttlExpireTeams = expire
+		remoteImpl.repositories = map[string]*GithubRepository{
+			"repo1": {
+				Name:             "repo1",
+				RefId:            "R_1",
+				Visibility:       "private",
+				BoolProperties:   map[string]bool{"archived": false},
+				CustomProperties: map[string]interface{}{"tier": "gold", "domains": []string{"a", "b"}},
+			},
+		}
+		remoteImpl.ttlExpireRepositories = expire
+		// the repositories are persisted with their lazy loaders set (like after a Load)
+		remoteImpl.setRepositoriesLazyLoaders(remoteImpl.repositories)
+		assert.NotNil(t, remoteImpl.repositories["repo1"].Environments)
+		remoteImpl.persistRemoteState()
+
+		restored := fixtureRemoteWithState(cache)
+		restored.restoreRemoteState()
+
+		assert.Equal(t, "MEMBER", restored.users["user1"].Role)
+		assert.Equal(t, []string{"user1"}, restored.teams["team1"].Members)
+		assert.Equal(t, "team1", restored.teamSlugByName["team1"])
+		assert.Equal(t, "private", restored.repositories["repo1"].Visibility)
+		assert.Equal(t, []string{"a", "b"}, restored.repositories["repo1"].CustomProperties["domains"])
+		assert.Equal(t, "repo1", restored.repositoriesByRefId["R_1"].Name)
+		assert.NotNil(t, restored.repositories["repo1"].Environments)
+		assert.NotNil(t, restored.repositories["repo1"].RepositoryVariables)
+		assert.NotNil(t, restored.repositories["repo1"].Autolinks)
+		assert.True(t, restored.ttlExpireRepositories.After(time.Now()))
+	})
+
+	t.Run("happy path: expired assets are not restored", func(t *testing.T) {
+		cache, err := NewFileRemoteStateCache(t.TempDir())
+		assert.Nil(t, err)
+
+		remoteImpl := fixtureRemoteWithState(cache)
+		remoteImpl.users = map[string]*GithubUser{
+			"user1": {Login: "user1", Role: "MEMBER"},
+		}
+		remoteImpl.ttlExpireUsers = time.Now().Add(-time.Minute)
+		remoteImpl.persistRemoteState()
+
+		restored := fixtureRemoteWithState(cache)
+		restored.restoreRemoteState()
+
+		assert.Equal(t, 0, len(restored.users))
+	})
+
+	t.Run("happy path: state saved by another Goliac version is discarded", func(t *testing.T) {
+		cache, err := NewFileRemoteStateCache(t.TempDir())
+		assert.Nil(t, err)
+
+		err = cache.Store("remote-myorg", &remoteState{
+			GoliacVersion: config.GoliacBuildVersion + "-old",
+			Users: map[string]*GithubUser{
+				"user1": {Login: "user1", Role: "MEMBER"},
+			},
+			UsersExpire: time.Now().Add(time.Hour),
+		})
+		assert.Nil(t, err)
+
+		restored := fixtureRemoteWithState(cache)
+		restored.restoreRemoteState()
+
+		assert.Equal(t, 0, len(restored.users))
+	})
+
+	t.Run("happy path: webhook events changes are persisted at the next Load", func(t *testing.T) {
+		cache, err := NewFileRemoteStateCache(t.TempDir())
+		assert.Nil(t, err)
+
+		remoteImpl, _ := fixtureRemoteForOrgEvents()
+		remoteImpl.ttlExpireAppIds = time.Now().Add(time.Hour)
+		remoteImpl.SetRemoteStateCache(cache)
+		remoteImpl.stateCacheRestored = true
+
+		changed := remoteImpl.HandleOrgEvent(context.TODO(), &GithubOrgEvent{
+			Event:      "repository",
+			Action:     "deleted",
+			Sender:     "alice",
+			Repository: "repo1",
+		})
+		assert.True(t, changed)
+
+		// not persisted at each event
+		var state remoteState
+		assert.NotNil(t, cache.Load("remote-myorg", &state))
+
+		err = remoteImpl.Load(context.TODO(), false)
+		assert.Nil(t, err)
+		assert.Nil(t, cache.Load("remote-myorg", &state))
+		assert.Nil(t, state.Repositories["repo1"])
+		assert.NotNil(t, state.Repositories)
+		assert.False(t, remoteImpl.stateCacheDirty)
+	})
+
+	t.Run("happy path: flushing the cache clears the persisted state", func(t *testing.T) {
+		cache, err := NewFileRemoteStateCache(t.TempDir())
+		assert.Nil(t, err)
+
+		remoteImpl := fixtureRemoteWithState(cache)
+		remoteImpl.ttlExpireUsers = time.Now().Add(time.Hour)
+		remoteImpl.persistRemoteState()
+
+		var state remoteState
+		assert.Nil(t, cache.Load("remote-myorg", &state))
+
+		remoteImpl.FlushCache()
+		assert.NotNil(t, cache.Load("remote-myorg", &state))
+	})
+}
@@ -10,9 +10,11 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/goliac-project/goliac/internal/entity"
 	"github.com/goliac-project/goliac/internal/github"
+	"github.com/goliac-project/goliac/internal/observability"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
 
@@ -588,6 +590,19 @@ func (g *GitHubClientIsEnterpriseMock) GetAppSlug() string {
 	return ""
 }
 
+func TestRemoteCacheExpired(t *testing.T) {
+	t.Run("happy path: cache hits and misses are counted", func(t *testing.T) {
+		remoteImpl := &GoliacRemoteImpl{configGithubOrg: "cache-metrics-org"}
+
+		assert.True(t, remoteImpl.cacheExpired("users", time.Now().Add(-time.Second)))
+		assert.False(t, remoteImpl.cacheExpired("users", time.Now().Add(time.Hour)))
+		assert.False(t, remoteImpl.cacheExpired("users", time.Now().Add(time.Hour)))
+
+		assert.Equal(t, float64(1), observability.RemoteCacheRequestsTotal.Value("cache-metrics-org", "users", "miss"))
+		assert.Equal(t, float64(2), observability.RemoteCacheRequestsTotal.Value("cache-metrics-org", "users", "hit"))
+	})
+}
+
 func TestIsEnterprise(t *testing.T) {
 
 	t.Run("test GHES", func(t *testing.T) {
@@ -697,6 +712,39 @@ func TestIsEnterprise(t *testing.T) {
 	})
 }
 
+func TestGetGHESInfo(t *testing.T) {
+
+	t.Run("happy path: GHES features", func(t *testing.T) {
+		mock := &GitHubClientIsEnterpriseMock{
+			results: map[string][]byte{
+				"/api/v3": []byte(`{"github_services_sha": "SOME_SHA_VALUE_HERE","installed_version": "3.10.2"}`),
+			},
+		}
+		info := GetGHESInfo(context.TODO(), mock)
+		assert.NotNil(t, info)
+		assert.Equal(t, "3.10.2", info.InstalledVersion)
+		assert.False(t, info.Supports("rulesets"))
+		assert.True(t, info.Supports("unknown"))
+		assert.Equal(t, []string{"rulesets", "workflows"}, info.UnsupportedFeatures())
+
+		mock.results["/api/v3"] = []byte(`{"installed_version": "3.12.1"}`)
+		info = GetGHESInfo(context.TODO(), mock)
+		assert.True(t, info.Supports("rulesets"))
+		assert.Equal(t, []string{}, info.UnsupportedFeatures())
+	})
+
+	t.Run("happy path: github.com", func(t *testing.T) {
+		mock := &GitHubClientIsEnterpriseMock{
+			results: map[string][]byte{},
+			err:     fmt.Errorf("404 Not Found"),
+		}
+		info := GetGHESInfo(context.TODO(), mock)
+		assert.Nil(t, info)
+		assert.True(t, info.Supports("rulesets"))
+		assert.Equal(t, []string{}, info.UnsupportedFeatures())
+	})
+}
+
 func TestPrepareRuleset(t *testing.T) {
 	t.Run("happy path", func(t *testing.T) {
 		ghClient := MockGithubClient{}
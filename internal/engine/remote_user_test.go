@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+// UpdateUserOrgRoleMockClient is a dedicated mock client for UpdateUserOrgRole tests
+type UpdateUserOrgRoleMockClient struct {
+	appSlug  string
+	appOwner string // the /app response owner (default: owned by the app-owner user)
+
+	// Track REST API calls
+	endpoints  []string
+	lastMethod string
+	lastBody   map[string]interface{}
+	lastToken  *string
+}
+
+func (m *UpdateUserOrgRoleMockClient) QueryGraphQLAPI(ctx context.Context, query string, variables map[string]interface{}, githubToken *string) ([]byte, error) {
+	return []byte("{}"), nil
+}
+
+func (m *UpdateUserOrgRoleMockClient) CallRestAPI(ctx context.Context, endpoint, parameters, method string, body map[string]interface{}, githubToken *string) ([]byte, error) {
+	if endpoint == "/api/v3" {
+		// GHES version check done by NewGoliacRemoteImpl
+		return []byte("{}"), nil
+	}
+	m.endpoints = append(m.endpoints, endpoint)
+	m.lastToken = githubToken
+
+	switch endpoint {
+	case "/app":
+		if m.appOwner != "" {
+			return []byte(m.appOwner), nil
+		}
+		return []byte(`{"slug": "goliac-app", "owner": {"login": "app-owner", "type": "User"}}`), nil
+	case "/user":
+		return []byte(`{"login": "pat-owner"}`), nil
+	}
+	m.lastMethod = method
+	m.lastBody = body
+	return []byte(`{"role": "member"}`), nil
+}
+
+func (m *UpdateUserOrgRoleMockClient) GetAccessToken(ctx context.Context) (string, error) {
+	return "mock-token", nil
+}
+
+func (m *UpdateUserOrgRoleMockClient) CreateJWT() (string, error) {
+	return "mock-jwt", nil
+}
+
+func (m *UpdateUserOrgRoleMockClient) GetAppSlug() string {
+	return m.appSlug
+}
+
+func TestUpdateUserOrgRole(t *testing.T) {
+	t.Run("happy path: promote a user", func(t *testing.T) {
+		mockClient := &UpdateUserOrgRoleMockClient{appSlug: "goliac-app"}
+		remoteImpl := NewGoliacRemoteImpl(mockClient, "myorg", true, true, true)
+		remoteImpl.users = map[string]*GithubUser{"alice": {Login: "alice", Role: "MEMBER"}}
+
+		logsCollector := observability.NewLogCollection()
+		remoteImpl.UpdateUserOrgRole(context.TODO(), logsCollector, false, "alice", "admin")
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, []string{"/orgs/myorg/memberships/alice"}, mockClient.endpoints)
+		assert.Equal(t, "PUT", mockClient.lastMethod)
+		assert.Equal(t, map[string]interface{}{"role": "admin"}, mockClient.lastBody)
+		assert.Equal(t, "ADMIN", remoteImpl.users["alice"].Role)
+	})
+
+	t.Run("happy path: demote a user", func(t *testing.T) {
+		mockClient := &UpdateUserOrgRoleMockClient{appSlug: "goliac-app"}
+		remoteImpl := NewGoliacRemoteImpl(mockClient, "myorg", true, true, true)
+		remoteImpl.users = map[string]*GithubUser{"alice": {Login: "alice", Role: "ADMIN"}}
+
+		logsCollector := observability.NewLogCollection()
+		remoteImpl.UpdateUserOrgRole(context.TODO(), logsCollector, false, "alice", "member")
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, []string{"/app", "/orgs/myorg/memberships/alice"}, mockClient.endpoints)
+		assert.Equal(t, map[string]interface{}{"role": "member"}, mockClient.lastBody)
+		assert.Equal(t, "MEMBER", remoteImpl.users["alice"].Role)
+	})
+
+	t.Run("happy path: dryrun", func(t *testing.T) {
+		mockClient := &UpdateUserOrgRoleMockClient{appSlug: "goliac-app"}
+		remoteImpl := NewGoliacRemoteImpl(mockClient, "myorg", true, true, true)
+		remoteImpl.users = map[string]*GithubUser{"alice": {Login: "alice", Role: "MEMBER"}}
+
+		logsCollector := observability.NewLogCollection()
+		remoteImpl.UpdateUserOrgRole(context.TODO(), logsCollector, true, "alice", "admin")
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(mockClient.endpoints))
+		assert.Equal(t, "ADMIN", remoteImpl.users["alice"].Role)
+	})
+
+	t.Run("not happy path: the Github App owner is not demoted", func(t *testing.T) {
+		mockClient := &UpdateUserOrgRoleMockClient{appSlug: "goliac-app"}
+		remoteImpl := NewGoliacRemoteImpl(mockClient, "myorg", true, true, true)
+		remoteImpl.users = map[string]*GithubUser{"app-owner": {Login: "app-owner", Role: "ADMIN"}}
+
+		logsCollector := observability.NewLogCollection()
+		remoteImpl.UpdateUserOrgRole(context.TODO(), logsCollector, false, "app-owner", "member")
+
+		assert.True(t, logsCollector.HasErrors())
+		assert.Equal(t, []string{"/app"}, mockClient.endpoints)
+		assert.Equal(t, "mock-jwt", *mockClient.lastToken)
+		assert.Equal(t, "ADMIN", remoteImpl.users["app-owner"].Role)
+	})
+
+	t.Run("happy path: a Github App owned by the organization protects no user", func(t *testing.T) {
+		mockClient := &UpdateUserOrgRoleMockClient{
+			appSlug:  "goliac-app",
+			appOwner: `{"slug": "goliac-app", "owner": {"login": "myorg", "type": "Organization"}}`,
+		}
+		remoteImpl := NewGoliacRemoteImpl(mockClient, "myorg", true, true, true)
+		remoteImpl.users = map[string]*GithubUser{
+			"alice": {Login: "alice", Role: "ADMIN"},
+			"myorg": {Login: "myorg", Role: "ADMIN"},
+		}
+
+		logsCollector := observability.NewLogCollection()
+		remoteImpl.UpdateUserOrgRole(context.TODO(), logsCollector, false, "alice", "member")
+		remoteImpl.UpdateUserOrgRole(context.TODO(), logsCollector, false, "myorg", "member")
+
+		assert.False(t, logsCollector.HasErrors())
+		// the Github App is fetched once
+		assert.Equal(t, []string{"/app", "/orgs/myorg/memberships/alice", "/orgs/myorg/memberships/myorg"}, mockClient.endpoints)
+		assert.Equal(t, "MEMBER", remoteImpl.users["alice"].Role)
+	})
+
+	t.Run("not happy path: the personal access token owner is not demoted", func(t *testing.T) {
+		mockClient := &UpdateUserOrgRoleMockClient{}
+		remoteImpl := NewGoliacRemoteImpl(mockClient, "myorg", true, true, true)
+		remoteImpl.users = map[string]*GithubUser{"pat-owner": {Login: "pat-owner", Role: "ADMIN"}}
+
+		logsCollector := observability.NewLogCollection()
+		remoteImpl.UpdateUserOrgRole(context.TODO(), logsCollector, false, "PAT-owner", "member")
+
+		assert.True(t, logsCollector.HasErrors())
+		assert.Equal(t, []string{"/user"}, mockClient.endpoints)
+		assert.Equal(t, "ADMIN", remoteImpl.users["pat-owner"].Role)
+	})
+}
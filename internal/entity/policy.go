@@ -0,0 +1,327 @@
+package entity
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/goliac-project/goliac/internal/utils"
+	"github.com/google/cel-go/cel"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	POLICY_RESOURCE_REPOSITORY   = "repository"
+	POLICY_RESOURCE_TEAM         = "team"
+	POLICY_RESOURCE_USER         = "user"
+	POLICY_RESOURCE_RULESET      = "ruleset"
+	POLICY_RESOURCE_WORKFLOW     = "workflow"
+	POLICY_RESOURCE_ORGANIZATION = "organization"
+
+	POLICY_SEVERITY_ERROR   = "error"
+	POLICY_SEVERITY_WARNING = "warning"
+)
+
+/*
+ * Policy is an organization rule (written as a CEL expression) evaluated
+ * against the loaded manifests. The expression must return true when the
+ * resource is compliant.
+ */
+type Policy struct {
+	Entity `yaml:",inline"`
+	Spec   struct {
+		Description string `yaml:"description,omitempty"`
+		Resource    string `yaml:"resource"`           // repository, team, user, ruleset, workflow, organization
+		Severity    string `yaml:"severity,omitempty"` // error (default), warning
+		Expression  string `yaml:"expression"`
+		Message     string `yaml:"message,omitempty"`
+	} `yaml:"spec"`
+
+	program cel.Program
+}
+
+/*
+ * NewPolicy reads a file and returns a Policy object
+ * The next step is to validate the Policy object using the Validate method
+ */
+func NewPolicy(fs billy.Filesystem, filename string) (*Policy, error) {
+	filecontent, err := utils.ReadFile(fs, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{}
+	err = yaml.Unmarshal(filecontent, policy)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Spec.Severity == "" {
+		policy.Spec.Severity = POLICY_SEVERITY_ERROR
+	}
+
+	return policy, nil
+}
+
+/*
+ * policyEnv returns the CEL environment of a policy:
+ * - the evaluated resource (repository, team, user, ruleset or workflow) as its manifest
+ * - org: all the manifests (teams, repositories, users, external_users, rulesets, workflows) by name
+ */
+func policyEnv() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.OptionalTypes(),
+		cel.Variable(POLICY_RESOURCE_REPOSITORY, cel.DynType),
+		cel.Variable(POLICY_RESOURCE_TEAM, cel.DynType),
+		cel.Variable(POLICY_RESOURCE_USER, cel.DynType),
+		cel.Variable(POLICY_RESOURCE_RULESET, cel.DynType),
+		cel.Variable(POLICY_RESOURCE_WORKFLOW, cel.DynType),
+		cel.Variable("org", cel.MapType(cel.StringType, cel.DynType)),
+	)
+}
+
+/*
+ * Validate checks the policy definition and compiles its expression
+ */
+func (p *Policy) Validate(filename string) error {
+
+	if p.ApiVersion != "v1" {
+		return fmt.Errorf("invalid apiVersion: %s for Policy filename %s", p.ApiVersion, filename)
+	}
+
+	if p.Kind != "Policy" {
+		return fmt.Errorf("invalid kind: %s for Policy filename %s", p.Kind, filename)
+	}
+
+	if p.Name == "" {
+		return fmt.Errorf("metadata.name is empty for Policy filename %s", filename)
+	}
+
+	basename := filepath.Base(filename)
+	if p.Name != basename[:len(basename)-len(filepath.Ext(basename))] {
+		return fmt.Errorf("invalid metadata.name: %s for Policy filename %s", p.Name, filename)
+	}
+
+	switch p.Spec.Resource {
+	case POLICY_RESOURCE_REPOSITORY, POLICY_RESOURCE_TEAM, POLICY_RESOURCE_USER, POLICY_RESOURCE_RULESET, POLICY_RESOURCE_WORKFLOW, POLICY_RESOURCE_ORGANIZATION:
+	default:
+		return fmt.Errorf("invalid spec.resource: %s for Policy filename %s", p.Spec.Resource, filename)
+	}
+
+	if p.Spec.Severity != POLICY_SEVERITY_ERROR && p.Spec.Severity != POLICY_SEVERITY_WARNING {
+		return fmt.Errorf("invalid spec.severity: %s for Policy filename %s (must be error or warning)", p.Spec.Severity, filename)
+	}
+
+	if p.Spec.Expression == "" {
+		return fmt.Errorf("spec.expression is empty for Policy filename %s", filename)
+	}
+
+	env, err := policyEnv()
+	if err != nil {
+		return err
+	}
+	ast, issues := env.Compile(p.Spec.Expression)
+	if issues != nil && issues.Err() != nil {
+		return fmt.Errorf("invalid spec.expression for Policy filename %s: %v", filename, issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return fmt.Errorf("invalid spec.expression for Policy filename %s: must return a bool (not %s)", filename, ast.OutputType())
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return fmt.Errorf("invalid spec.expression for Policy filename %s: %v", filename, err)
+	}
+	p.program = program
+
+	return nil
+}
+
+func ReadPolicyDirectory(fs billy.Filesystem, dirname string, LogCollection *observability.LogCollection) map[string]*Policy {
+	policies := make(map[string]*Policy)
+
+	exist, err := utils.Exists(fs, dirname)
+	if err != nil {
+		LogCollection.AddError(err)
+		return policies
+	}
+	if !exist {
+		return policies
+	}
+
+	// Parse all the policies in the dirname directory
+	entries, err := fs.ReadDir(dirname)
+	if err != nil {
+		LogCollection.AddError(err)
+		return policies
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		// skipping files starting with '.'
+		if e.Name()[0] == '.' {
+			continue
+		}
+		// skipping the non yaml files (README, editor backups, ...)
+		if ext := filepath.Ext(e.Name()); ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+		policy, err := NewPolicy(fs, filepath.Join(dirname, e.Name()))
+		if err != nil {
+			LogCollection.AddError(err)
+		} else {
+			err := policy.Validate(filepath.Join(dirname, e.Name()))
+			if err != nil {
+				LogCollection.AddError(err)
+			} else {
+				policies[policy.Name] = policy
+			}
+		}
+	}
+	return policies
+}
+
+/*
+ * toPolicyDocument converts a manifest into the generic structure
+ * (using the yaml field names) used by the policies
+ */
+func toPolicyDocument(in interface{}) (map[string]interface{}, error) {
+	content, err := yaml.Marshal(in)
+	if err != nil {
+		return nil, err
+	}
+	doc := make(map[string]interface{})
+	err = yaml.Unmarshal(content, &doc)
+	return doc, err
+}
+
+func toPolicyDocuments[T any](entities map[string]*T, adjust func(*T, map[string]interface{})) (map[string]interface{}, error) {
+	docs := make(map[string]interface{})
+	for name, e := range entities {
+		doc, err := toPolicyDocument(e)
+		if err != nil {
+			return nil, err
+		}
+		if adjust != nil {
+			adjust(e, doc)
+		}
+		docs[name] = doc
+	}
+	return docs, nil
+}
+
+/*
+ * EvaluatePolicies evaluates every policy against the loaded manifests,
+ * and reports the violations as errors or warnings (depending on the policy severity).
+ * The owner team of a repository (owner) and the parent of a team (parentTeam) are
+ * added to the manifests since they are implicit.
+ */
+func EvaluatePolicies(policies map[string]*Policy, teams map[string]*Team, repositories map[string]*Repository, users map[string]*User, externalUsers map[string]*User, rulesets map[string]*RuleSet, workflows map[string]*Workflow, LogCollection *observability.LogCollection) {
+	if len(policies) == 0 {
+		return
+	}
+
+	org := make(map[string]interface{})
+	var err error
+	if org["teams"], err = toPolicyDocuments(teams, func(t *Team, doc map[string]interface{}) {
+		if t.ParentTeam != nil {
+			doc["parentTeam"] = *t.ParentTeam
+		}
+	}); err != nil {
+		LogCollection.AddError(fmt.Errorf("not able to build the policies document: %v", err))
+		return
+	}
+	if org["repositories"], err = toPolicyDocuments(repositories, func(r *Repository, doc map[string]interface{}) {
+		if r.Owner != nil {
+			doc["owner"] = *r.Owner
+		}
+	}); err != nil {
+		LogCollection.AddError(fmt.Errorf("not able to build the policies document: %v", err))
+		return
+	}
+	if org["users"], err = toPolicyDocuments(users, nil); err != nil {
+		LogCollection.AddError(fmt.Errorf("not able to build the policies document: %v", err))
+		return
+	}
+	if org["external_users"], err = toPolicyDocuments(externalUsers, nil); err != nil {
+		LogCollection.AddError(fmt.Errorf("not able to build the policies document: %v", err))
+		return
+	}
+	if org["rulesets"], err = toPolicyDocuments(rulesets, nil); err != nil {
+		LogCollection.AddError(fmt.Errorf("not able to build the policies document: %v", err))
+		return
+	}
+	if org["workflows"], err = toPolicyDocuments(workflows, nil); err != nil {
+		LogCollection.AddError(fmt.Errorf("not able to build the policies document: %v", err))
+		return
+	}
+
+	collections := map[string]string{
+		POLICY_RESOURCE_REPOSITORY: "repositories",
+		POLICY_RESOURCE_TEAM:       "teams",
+		POLICY_RESOURCE_USER:       "users",
+		POLICY_RESOURCE_RULESET:    "rulesets",
+		POLICY_RESOURCE_WORKFLOW:   "workflows",
+	}
+
+	policyNames := make([]string, 0, len(policies))
+	for name := range policies {
+		policyNames = append(policyNames, name)
+	}
+	sort.Strings(policyNames)
+
+	for _, policyName := range policyNames {
+		policy := policies[policyName]
+
+		report := func(err error) {
+			if policy.Spec.Severity == POLICY_SEVERITY_WARNING {
+				LogCollection.AddWarn(err)
+			} else {
+				LogCollection.AddError(err)
+			}
+		}
+		message := policy.Spec.Message
+		if message == "" {
+			message = policy.Spec.Description
+		}
+		if message == "" {
+			message = policy.Spec.Expression
+		}
+
+		evaluate := func(input map[string]interface{}, target string) {
+			out, _, err := policy.program.Eval(input)
+			if err != nil {
+				report(fmt.Errorf("policy %s: not able to evaluate %s: %v", policy.Name, target, err))
+				return
+			}
+			compliant, ok := out.Value().(bool)
+			if !ok {
+				report(fmt.Errorf("policy %s: expression must return a bool for %s", policy.Name, target))
+				return
+			}
+			if !compliant {
+				report(fmt.Errorf("policy %s violated by %s: %s", policy.Name, target, message))
+			}
+		}
+
+		if policy.Spec.Resource == POLICY_RESOURCE_ORGANIZATION {
+			evaluate(map[string]interface{}{"org": org}, "the organization")
+			continue
+		}
+
+		resources := org[collections[policy.Spec.Resource]].(map[string]interface{})
+		names := make([]string, 0, len(resources))
+		for name := range resources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			evaluate(map[string]interface{}{
+				policy.Spec.Resource: resources[name],
+				"org":                org,
+			}, fmt.Sprintf("%s %s", policy.Spec.Resource, name))
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/goliac-project/goliac/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy(t *testing.T) {
+	t.Run("happy path: read policies", func(t *testing.T) {
+		fs := memfs.New()
+		fs.MkdirAll("policies", 0755)
+		err := utils.WriteFile(fs, "policies/repository-tier.yaml", []byte(`
+apiVersion: v1
+kind: Policy
+name: repository-tier
+spec:
+  description: every repository needs a tier custom property
+  resource: repository
+  expression: 'has(repository.spec.custom_properties) && "tier" in repository.spec.custom_properties'
+`), 0644)
+		assert.Nil(t, err)
+		err = utils.WriteFile(fs, "policies/team-size.yml", []byte(`
+apiVersion: v1
+kind: Policy
+name: team-size
+spec:
+  resource: team
+  expression: 'size(team.spec.members) < 50'
+`), 0644)
+		assert.Nil(t, err)
+		// not policies
+		err = utils.WriteFile(fs, "policies/README.md", []byte("# Our policies\n"), 0644)
+		assert.Nil(t, err)
+		err = utils.WriteFile(fs, "policies/repository-tier.yaml~", []byte("name: repository-tier\n  wrong"), 0644)
+		assert.Nil(t, err)
+
+		logsCollector := observability.NewLogCollection()
+		policies := ReadPolicyDirectory(fs, "policies", logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 2, len(policies))
+		assert.Equal(t, "error", policies["repository-tier"].Spec.Severity)
+	})
+
+	t.Run("not happy path: invalid policies", func(t *testing.T) {
+		fs := memfs.New()
+		fs.MkdirAll("policies", 0755)
+		err := utils.WriteFile(fs, "policies/wrong-resource.yaml", []byte(`
+apiVersion: v1
+kind: Policy
+name: wrong-resource
+spec:
+  resource: project
+  expression: 'true'
+`), 0644)
+		assert.Nil(t, err)
+		err = utils.WriteFile(fs, "policies/wrong-expression.yaml", []byte(`
+apiVersion: v1
+kind: Policy
+name: wrong-expression
+spec:
+  resource: team
+  expression: 'size(team.spec.members'
+`), 0644)
+		assert.Nil(t, err)
+		err = utils.WriteFile(fs, "policies/not-a-bool.yaml", []byte(`
+apiVersion: v1
+kind: Policy
+name: not-a-bool
+spec:
+  resource: team
+  expression: '"foo"'
+`), 0644)
+		assert.Nil(t, err)
+		err = utils.WriteFile(fs, "policies/wrong-severity.yaml", []byte(`
+apiVersion: v1
+kind: Policy
+name: wrong-severity
+spec:
+  resource: team
+  severity: critical
+  expression: 'true'
+`), 0644)
+		assert.Nil(t, err)
+
+		logsCollector := observability.NewLogCollection()
+		policies := ReadPolicyDirectory(fs, "policies", logsCollector)
+		assert.Equal(t, 4, len(logsCollector.Errors))
+		assert.Equal(t, 0, len(policies))
+	})
+}
+
+func TestEvaluatePolicies(t *testing.T) {
+	fixturePolicy := func(name string, resource string, severity string, expression string) *Policy {
+		p := &Policy{}
+		p.ApiVersion = "v1"
+		p.Kind = "Policy"
+		p.Name = name
+		p.Spec.Resource = resource
+		p.Spec.Severity = severity
+		p.Spec.Expression = expression
+		err := p.Validate("policies/" + name + ".yaml")
+		assert.Nil(t, err)
+		return p
+	}
+
+	team1 := &Team{}
+	team1.Name = "team1"
+	team1.Spec.Owners = []string{"user1"}
+	team1.Spec.Members = []string{"user2", "user3"}
+	team2 := &Team{}
+	team2.Name = "team2"
+	team2.Spec.Owners = []string{"user1"}
+	teams := map[string]*Team{"team1": team1, "team2": team2}
+
+	owner := "team1"
+	repo1 := &Repository{}
+	repo1.Name = "repo1"
+	repo1.Owner = &owner
+	repo1.Spec.Visibility = "private"
+	repo1.Spec.CustomProperties = map[string]interface{}{"tier": "1"}
+	repo2 := &Repository{}
+	repo2.Name = "repo2"
+	repo2.Spec.Visibility = "public"
+	repositories := map[string]*Repository{"repo1": repo1, "repo2": repo2}
+
+	user1 := &User{}
+	user1.Name = "user1"
+	user1.Spec.GithubID = "github1"
+	users := map[string]*User{"user1": user1}
+
+	t.Run("happy path: compliant", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		policies := map[string]*Policy{
+			"team-size": fixturePolicy("team-size", "team", "error", `size(team.spec.?owners.orValue([])) + size(team.spec.?members.orValue([])) <= 3`),
+			"owner":     fixturePolicy("owner", "repository", "error", `repository.name != "repo1" || repository.owner in org.teams`),
+			"users":     fixturePolicy("users", "organization", "error", `size(org.users) > 0`),
+		}
+		EvaluatePolicies(policies, teams, repositories, users, map[string]*User{}, map[string]*RuleSet{}, map[string]*Workflow{}, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.False(t, logsCollector.HasWarns())
+	})
+
+	t.Run("not happy path: violations as errors and warnings", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		policies := map[string]*Policy{
+			"tier":      fixturePolicy("tier", "repository", "error", `has(repository.spec.custom_properties) && "tier" in repository.spec.custom_properties`),
+			"no-public": fixturePolicy("no-public", "repository", "warning", `repository.spec.visibility != "public"`),
+			"team-size": fixturePolicy("team-size", "team", "error", `size(team.spec.?members.orValue([])) < 2`),
+		}
+		EvaluatePolicies(policies, teams, repositories, users, map[string]*User{}, map[string]*RuleSet{}, map[string]*Workflow{}, logsCollector)
+		assert.Equal(t, 2, len(logsCollector.Errors))
+		assert.Equal(t, 1, len(logsCollector.Warns))
+		assert.Contains(t, logsCollector.Errors[0].Error(), "team-size violated by team team1")
+		assert.Contains(t, logsCollector.Errors[1].Error(), "tier violated by repository repo2")
+		assert.Contains(t, logsCollector.Warns[0].Error(), "no-public violated by repository repo2")
+	})
+
+	t.Run("not happy path: evaluation error", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		policies := map[string]*Policy{
+			"tier": fixturePolicy("tier", "repository", "warning", `repository.spec.custom_properties.tier == "1"`),
+		}
+		EvaluatePolicies(policies, teams, repositories, users, map[string]*User{}, map[string]*RuleSet{}, map[string]*Workflow{}, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 1, len(logsCollector.Warns))
+		assert.Contains(t, logsCollector.Warns[0].Error(), "not able to evaluate repository repo2")
+	})
+}
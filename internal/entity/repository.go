@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/go-git/go-billy/v5"
@@ -55,6 +56,9 @@ type Repository struct {
 	Spec   struct {
 		Writers                    []string                     `yaml:"writers,omitempty"`
 		Readers                    []string                     `yaml:"readers,omitempty"`
+		Maintainers                []string                     `yaml:"maintainers,omitempty"`
+		Triagers                   []string                     `yaml:"triagers,omitempty"`
+		Roles                      map[string][]string          `yaml:"roles,omitempty"` // custom repository role (RepositoryRole) name -> teams
 		ExternalUserReaders        []string                     `yaml:"externalUserReaders,omitempty"`
 		ExternalUserWriters        []string                     `yaml:"externalUserWriters,omitempty"`
 		Visibility                 string                       `yaml:"visibility,omitempty"`
@@ -115,6 +119,18 @@ type RepositoryBranchProtection struct {
  * The next step is to validate the Repository object using the Validate method
  */
 func NewRepository(fs billy.Filesystem, filename string) (*Repository, error) {
+	return NewRepositoryWithDefaults(fs, filename, nil)
+}
+
+/*
+ * NewRepositoryWithDefaults reads a file and returns a Repository object,
+ * where the defaults (the organization repository_defaults, then the team's
+ * repository_defaults from the top parent team down to the owner team) are
+ * applied before the repository file.
+ * Values explicitly set in the repository file always win: scalars and lists
+ * are replaced, maps are merged.
+ */
+func NewRepositoryWithDefaults(fs billy.Filesystem, filename string, defaults []*yaml.Node) (*Repository, error) {
 	filecontent, err := utils.ReadFile(fs, filename)
 	if err != nil {
 		return nil, err
@@ -129,6 +145,11 @@ func NewRepository(fs billy.Filesystem, filename string) (*Repository, error) {
 	repository.Spec.AllowMergeCommit = true                        // default allow merge commit
 	repository.Spec.DefaultMergeCommitMessage = "Default message"  // default merge commit message template
 	repository.Spec.DefaultSquashCommitMessage = "Default message" // default squash commit message template
+	for _, d := range defaults {
+		if err := d.Decode(&repository.Spec); err != nil {
+			return nil, fmt.Errorf("not able to apply the team's repository_defaults to %s: %v", filename, err)
+		}
+	}
 	err = yaml.Unmarshal(filecontent, repository)
 	if err != nil {
 		return nil, err
@@ -186,9 +207,20 @@ func NewRepository(fs billy.Filesystem, filename string) (*Repository, error) {
  * - a slice of errors that must stop the validation process
  * - a slice of warning that must not stop the validation process
  */
-func ReadRepositories(fs billy.Filesystem, archivedDirname string, teamDirname string, teams map[string]*Team, externalUsers map[string]*User, users map[string]*User, customProperties []*config.GithubCustomProperty, LogCollection *observability.LogCollection) map[string]*Repository {
+func ReadRepositories(fs billy.Filesystem, archivedDirname string, teamDirname string, teams map[string]*Team, externalUsers map[string]*User, users map[string]*User, customProperties []*config.GithubCustomProperty, repositoryDefaults *config.RepositorySettings, LogCollection *observability.LogCollection) map[string]*Repository {
 	repos := make(map[string]*Repository)
 
+	// the organization repository_defaults (goliac.yaml) replace the built-in default values
+	orgDefaults := []*yaml.Node{}
+	if repositoryDefaults != nil {
+		node := &yaml.Node{}
+		if err := node.Encode(repositoryDefaults); err != nil {
+			LogCollection.AddError(fmt.Errorf("not able to read the repository_defaults: %v", err))
+			return repos
+		}
+		orgDefaults = append(orgDefaults, node)
+	}
+
 	// archived dir
 	exist, err := utils.Exists(fs, archivedDirname)
 	if err != nil {
@@ -214,7 +246,7 @@ func ReadRepositories(fs billy.Filesystem, archivedDirname string, teamDirname s
 				LogCollection.AddWarn(fmt.Errorf("file %s doesn't have a .yaml extension", entry.Name()))
 				continue
 			}
-			repo, err := NewRepository(fs, filepath.Join(archivedDirname, entry.Name()))
+			repo, err := NewRepositoryWithDefaults(fs, filepath.Join(archivedDirname, entry.Name()), orgDefaults)
 			if err != nil {
 				LogCollection.AddError(err)
 			} else {
@@ -246,14 +278,14 @@ func ReadRepositories(fs billy.Filesystem, archivedDirname string, teamDirname s
 
 	for _, team := range entries {
 		if team.IsDir() {
-			recursiveReadRepositories(fs, archivedDirname, filepath.Join(teamDirname, team.Name()), team.Name(), repos, teams, externalUsers, users, customProperties, LogCollection)
+			recursiveReadRepositories(fs, archivedDirname, filepath.Join(teamDirname, team.Name()), team.Name(), repos, teams, externalUsers, users, customProperties, orgDefaults, LogCollection)
 		}
 	}
 
 	return repos
 }
 
-func recursiveReadRepositories(fs billy.Filesystem, archivedDirPath string, teamDirPath string, teamName string, repos map[string]*Repository, teams map[string]*Team, externalUsers map[string]*User, users map[string]*User, customProperties []*config.GithubCustomProperty, LogCollection *observability.LogCollection) {
+func recursiveReadRepositories(fs billy.Filesystem, archivedDirPath string, teamDirPath string, teamName string, repos map[string]*Repository, teams map[string]*Team, externalUsers map[string]*User, users map[string]*User, customProperties []*config.GithubCustomProperty, orgDefaults []*yaml.Node, LogCollection *observability.LogCollection) {
 
 	subentries, err := fs.ReadDir(teamDirPath)
 	if err != nil {
@@ -262,14 +294,15 @@ func recursiveReadRepositories(fs billy.Filesystem, archivedDirPath string, team
 	}
 	for _, sube := range subentries {
 		if sube.IsDir() && sube.Name()[0] != '.' {
-			recursiveReadRepositories(fs, archivedDirPath, filepath.Join(teamDirPath, sube.Name()), sube.Name(), repos, teams, externalUsers, users, customProperties, LogCollection)
+			recursiveReadRepositories(fs, archivedDirPath, filepath.Join(teamDirPath, sube.Name()), sube.Name(), repos, teams, externalUsers, users, customProperties, orgDefaults, LogCollection)
 		}
 		if !sube.IsDir() && sube.Name() != "team.yaml" {
 			if filepath.Ext(sube.Name()) != ".yaml" {
 				LogCollection.AddError(fmt.Errorf("file %s doesn't have a .yaml extension", sube.Name()))
 				continue
 			}
-			repo, err := NewRepository(fs, filepath.Join(teamDirPath, sube.Name()))
+			defaults := append(slices.Clone(orgDefaults), teamRepositoryDefaults(teams, teamName)...)
+			repo, err := NewRepositoryWithDefaults(fs, filepath.Join(teamDirPath, sube.Name()), defaults)
 			if err != nil {
 				LogCollection.AddError(err)
 			} else {
@@ -295,6 +328,25 @@ func recursiveReadRepositories(fs billy.Filesystem, archivedDirPath string, team
 	}
 }
 
+/*
+ * teamRepositoryDefaults returns the repository_defaults inherited by the
+ * repositories of a team: from the top parent team down to the team itself
+ */
+func teamRepositoryDefaults(teams map[string]*Team, teamName string) []*yaml.Node {
+	defaults := []*yaml.Node{}
+	team := teams[teamName]
+	for team != nil {
+		if !team.Spec.RepositoryDefaults.IsZero() {
+			defaults = append([]*yaml.Node{&team.Spec.RepositoryDefaults}, defaults...)
+		}
+		if team.ParentTeam == nil {
+			break
+		}
+		team = teams[*team.ParentTeam]
+	}
+	return defaults
+}
+
 func (r *Repository) Validate(filename string, teams map[string]*Team, externalUsers map[string]*User, users map[string]*User, customProperties []*config.GithubCustomProperty) error {
 
 	if r.ApiVersion != "v1" {
@@ -329,6 +381,31 @@ func (r *Repository) Validate(filename string, teams map[string]*Team, externalU
 			return fmt.Errorf("invalid reader: %s doesn't exist (check repository filename %s)", reader, filename)
 		}
 	}
+	for _, maintainer := range r.Spec.Maintainers {
+		if _, ok := teams[maintainer]; !ok {
+			return fmt.Errorf("invalid maintainer: %s doesn't exist (check repository filename %s)", maintainer, filename)
+		}
+	}
+	for _, triager := range r.Spec.Triagers {
+		if _, ok := teams[triager]; !ok {
+			return fmt.Errorf("invalid triager: %s doesn't exist (check repository filename %s)", triager, filename)
+		}
+	}
+	roleTeams := make(map[string]string)
+	for role, roleMembers := range r.Spec.Roles {
+		for _, team := range roleMembers {
+			if _, ok := teams[team]; !ok {
+				return fmt.Errorf("invalid %s role team: %s doesn't exist (check repository filename %s)", role, team, filename)
+			}
+			if other, ok := roleTeams[team]; ok {
+				return fmt.Errorf("invalid roles: team %s has the %s and %s roles (check repository filename %s)", team, other, role, filename)
+			}
+			if (r.Owner != nil && *r.Owner == team) || slices.Contains(r.Spec.Writers, team) || slices.Contains(r.Spec.Readers, team) || slices.Contains(r.Spec.Maintainers, team) || slices.Contains(r.Spec.Triagers, team) {
+				return fmt.Errorf("invalid roles: team %s has the %s role but also another access (check repository filename %s)", team, role, filename)
+			}
+			roleTeams[team] = role
+		}
+	}
 	writersSet := make(map[string]struct{}, len(r.Spec.Writers))
 	for _, writer := range r.Spec.Writers {
 		writersSet[writer] = struct{}{}
@@ -821,3 +898,286 @@ func (r *Repository) Update(fs billy.Filesystem, filename string, allUsers map[s
 	}
 	return changed, err
 }
+
+/*
+ * BranchProtectionToRuleSets converts a (legacy) branch protection into equivalent rulesets.
+ * A ruleset bypass applies to all its rules, while a branch protection bypass only applies
+ * to the pull request requirement: so if bypass actors are defined, the pull_request rule
+ * goes into a dedicated ruleset (<name>-pull-request).
+ * It returns the rulesets and the settings that have no ruleset equivalent.
+ */
+func BranchProtectionToRuleSets(name string, bp *RepositoryBranchProtection) ([]RepositoryRuleSet, []error) {
+	type rule = struct {
+		Ruletype   string
+		Parameters RuleSetParameters `yaml:"parameters,omitempty"`
+	}
+	newRuleset := func(name string) *RepositoryRuleSet {
+		ruleset := RepositoryRuleSet{Name: name}
+		ruleset.Enforcement = "active"
+		ruleset.Conditions.Include = []string{bp.Pattern}
+		return &ruleset
+	}
+	warnings := []error{}
+
+	ruleset := newRuleset(name)
+	if bp.RequiresStatusChecks {
+		if len(bp.RequiredStatusCheckContexts) == 0 {
+			warnings = append(warnings, fmt.Errorf("branch protection %s: requires_status_checks without required_status_check_contexts has no ruleset equivalent", bp.Pattern))
+		} else {
+			r := rule{Ruletype: "required_status_checks"}
+			r.Parameters.RequiredStatusChecks = bp.RequiredStatusCheckContexts
+			r.Parameters.StrictRequiredStatusChecksPolicy = bp.RequiresStrictStatusChecks
+			ruleset.Rules = append(ruleset.Rules, r)
+		}
+	}
+	if bp.RequiresCommitSignatures {
+		ruleset.Rules = append(ruleset.Rules, rule{Ruletype: "required_signatures"})
+	}
+	if bp.RequiresLinearHistory {
+		ruleset.Rules = append(ruleset.Rules, rule{Ruletype: "required_linear_history"})
+	}
+	if !bp.AllowsForcePushes {
+		ruleset.Rules = append(ruleset.Rules, rule{Ruletype: "non_fast_forward"})
+	}
+	if !bp.AllowsDeletions {
+		ruleset.Rules = append(ruleset.Rules, rule{Ruletype: "deletion"})
+	}
+
+	if len(bp.BypassPullRequestUsers) > 0 {
+		warnings = append(warnings, fmt.Errorf("branch protection %s: bypass_pullrequest_users (%s) has no ruleset equivalent (only teams and apps can bypass a ruleset)", bp.Pattern, strings.Join(bp.BypassPullRequestUsers, ",")))
+	}
+	hasBypass := len(bp.BypassPullRequestTeams) > 0 || len(bp.BypassPullRequestApps) > 0
+
+	var prRuleset *RepositoryRuleSet
+	if bp.RequiresApprovingReviews || bp.RequiresConversationResolution {
+		pr := rule{Ruletype: "pull_request"}
+		if bp.RequiresApprovingReviews {
+			pr.Parameters.RequiredApprovingReviewCount = bp.RequiredApprovingReviewCount
+			pr.Parameters.DismissStaleReviewsOnPush = bp.DismissesStaleReviews
+			pr.Parameters.RequireCodeOwnerReview = bp.RequiresCodeOwnerReviews
+			pr.Parameters.RequireLastPushApproval = bp.RequireLastPushApproval
+		}
+		pr.Parameters.RequiredReviewThreadResolution = bp.RequiresConversationResolution
+		pr.Parameters.AllowedMergeMethods = []string{"MERGE", "SQUASH", "REBASE"}
+
+		if hasBypass {
+			prRuleset = newRuleset(name + "-pull-request")
+			for _, team := range bp.BypassPullRequestTeams {
+				prRuleset.BypassTeams = append(prRuleset.BypassTeams, struct {
+					TeamName string
+					Mode     string
+				}{TeamName: team, Mode: "always"})
+			}
+			for _, app := range bp.BypassPullRequestApps {
+				prRuleset.BypassApps = append(prRuleset.BypassApps, struct {
+					AppName string
+					Mode    string
+				}{AppName: app, Mode: "always"})
+			}
+			prRuleset.Rules = append(prRuleset.Rules, pr)
+		} else {
+			ruleset.Rules = append(ruleset.Rules, pr)
+		}
+	} else if hasBypass {
+		warnings = append(warnings, fmt.Errorf("branch protection %s: bypass_pullrequest_teams and bypass_pullrequest_apps are ignored since no pull request is required", bp.Pattern))
+	}
+
+	rulesets := []RepositoryRuleSet{}
+	if len(ruleset.Rules) > 0 {
+		rulesets = append(rulesets, *ruleset)
+	}
+	if prRuleset != nil {
+		rulesets = append(rulesets, *prRuleset)
+	}
+	return rulesets, warnings
+}
+
+/*
+ * MigrateBranchProtections replaces the repository's branch protections by equivalent rulesets
+ * (see BranchProtectionToRuleSets). It returns the settings that have no ruleset equivalent.
+ */
+func (r *Repository) MigrateBranchProtections() []error {
+	warnings := []error{}
+
+	existingNames := make(map[string]bool)
+	for _, rs := range r.Spec.Rulesets {
+		existingNames[rs.Name] = true
+	}
+
+	for i := range r.Spec.BranchProtections {
+		bp := &r.Spec.BranchProtections[i]
+
+		// find a uniq ruleset name
+		basename := "branch-protection-" + strings.Trim(regexp.MustCompile(`[^a-zA-Z0-9_.-]+`).ReplaceAllString(bp.Pattern, "-"), "-")
+		name := basename
+		for n := 2; existingNames[name] || existingNames[name+"-pull-request"]; n++ {
+			name = fmt.Sprintf("%s-%d", basename, n)
+		}
+
+		rulesets, bpWarnings := BranchProtectionToRuleSets(name, bp)
+		for _, w := range bpWarnings {
+			warnings = append(warnings, fmt.Errorf("repository %s: %v", r.Name, w))
+		}
+		for _, rs := range rulesets {
+			existingNames[rs.Name] = true
+			r.Spec.Rulesets = append(r.Spec.Rulesets, rs)
+		}
+	}
+	r.Spec.BranchProtections = nil
+
+	return warnings
+}
+
+/*
+ * migrateBranchProtectionsNode edits a repository definition (yaml document node):
+ * spec.branch_protections is removed and the rulesets are appended to spec.rulesets.
+ * The rest of the document (other settings, comments) is left untouched
+ */
+func migrateBranchProtectionsNode(document *yaml.Node, rulesets []RepositoryRuleSet) error {
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("not a yaml mapping document")
+	}
+	root := document.Content[0]
+
+	var spec *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "spec" {
+			spec = root.Content[i+1]
+		}
+	}
+	if spec == nil || spec.Kind != yaml.MappingNode {
+		return fmt.Errorf("spec not found")
+	}
+
+	var rulesetsNode yaml.Node
+	if err := rulesetsNode.Encode(rulesets); err != nil {
+		return err
+	}
+
+	content := []*yaml.Node{}
+	position := -1
+	var existingRulesets *yaml.Node
+	for i := 0; i+1 < len(spec.Content); i += 2 {
+		switch spec.Content[i].Value {
+		case "branch_protections":
+			position = len(content)
+			continue
+		case "rulesets":
+			existingRulesets = spec.Content[i+1]
+		}
+		content = append(content, spec.Content[i], spec.Content[i+1])
+	}
+
+	if len(rulesets) > 0 {
+		if existingRulesets != nil && existingRulesets.Kind == yaml.SequenceNode {
+			existingRulesets.Content = append(existingRulesets.Content, rulesetsNode.Content...)
+			existingRulesets.Style = 0
+		} else if existingRulesets != nil {
+			*existingRulesets = rulesetsNode
+		} else {
+			if position < 0 {
+				position = len(content)
+			}
+			key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "rulesets"}
+			content = append(content[:position], append([]*yaml.Node{key, &rulesetsNode}, content[position:]...)...)
+		}
+	}
+	spec.Content = content
+	return nil
+}
+
+/*
+ * ReadAndMigrateBranchProtections reads all repositories (archived and teams directories)
+ * defining branch protections, and migrates them to rulesets.
+ * It returns the migrated repositories definitions (yaml documents) per filename: only
+ * the branch_protections and rulesets are changed, to keep the other settings (even the
+ * ones set to their default value) and the comments as they are.
+ */
+func ReadAndMigrateBranchProtections(fs billy.Filesystem, archivedDirname string, teamDirname string, LogCollection *observability.LogCollection) map[string]*yaml.Node {
+	migrated := make(map[string]*yaml.Node)
+
+	migrate := func(filename string) {
+		repo, err := NewRepository(fs, filename)
+		if err != nil {
+			LogCollection.AddError(err)
+			return
+		}
+		if len(repo.Spec.BranchProtections) == 0 {
+			return
+		}
+		nbRulesets := len(repo.Spec.Rulesets)
+		for _, w := range repo.MigrateBranchProtections() {
+			LogCollection.AddWarn(w)
+		}
+
+		content, err := utils.ReadFile(fs, filename)
+		if err != nil {
+			LogCollection.AddError(err)
+			return
+		}
+		document := &yaml.Node{}
+		if err := yaml.Unmarshal(content, document); err != nil {
+			LogCollection.AddError(fmt.Errorf("not able to read %s: %v", filename, err))
+			return
+		}
+		if err := migrateBranchProtectionsNode(document, repo.Spec.Rulesets[nbRulesets:]); err != nil {
+			LogCollection.AddError(fmt.Errorf("not able to migrate %s: %v", filename, err))
+			return
+		}
+		migrated[filename] = document
+	}
+
+	// archived dir
+	exist, err := utils.Exists(fs, archivedDirname)
+	if err != nil {
+		LogCollection.AddError(err)
+		return migrated
+	}
+	if exist {
+		entries, err := fs.ReadDir(archivedDirname)
+		if err != nil {
+			LogCollection.AddError(err)
+			return migrated
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || entry.Name()[0] == '.' || filepath.Ext(entry.Name()) != ".yaml" {
+				continue
+			}
+			migrate(filepath.Join(archivedDirname, entry.Name()))
+		}
+	}
+
+	// regular teams dir
+	exist, err = utils.Exists(fs, teamDirname)
+	if err != nil {
+		LogCollection.AddError(err)
+		return migrated
+	}
+	if !exist {
+		return migrated
+	}
+	var recursiveMigrate func(dirname string)
+	recursiveMigrate = func(dirname string) {
+		entries, err := fs.ReadDir(dirname)
+		if err != nil {
+			LogCollection.AddError(err)
+			return
+		}
+		for _, entry := range entries {
+			if entry.Name()[0] == '.' {
+				continue
+			}
+			if entry.IsDir() {
+				recursiveMigrate(filepath.Join(dirname, entry.Name()))
+				continue
+			}
+			if entry.Name() == "team.yaml" || filepath.Ext(entry.Name()) != ".yaml" {
+				continue
+			}
+			migrate(filepath.Join(dirname, entry.Name()))
+		}
+	}
+	recursiveMigrate(teamDirname)
+
+	return migrated
+}
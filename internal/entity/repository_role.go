@@ -0,0 +1,126 @@
+package entity
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/goliac-project/goliac/internal/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// the base roles a custom repository role can extend
+var REPOSITORY_ROLE_BASE_ROLES = []string{"read", "triage", "write", "maintain"}
+
+// the Github predefined repository roles (and their permission aliases)
+var REPOSITORY_ROLE_PREDEFINED = []string{"read", "pull", "triage", "write", "push", "maintain", "admin"}
+
+/*
+ * RepositoryRole is an organization-wide custom repository role,
+ * that can be assigned to teams in the repositories (spec.roles)
+ */
+type RepositoryRole struct {
+	Entity `yaml:",inline"`
+	Spec   struct {
+		Description string   `yaml:"description,omitempty"`
+		BaseRole    string   `yaml:"base_role"`             // read, triage, write, maintain
+		Permissions []string `yaml:"permissions,omitempty"` // fine-grained permissions (like delete_alerts_code_scanning)
+	} `yaml:"spec"`
+}
+
+/*
+ * NewRepositoryRole reads a file and returns a RepositoryRole object
+ * The next step is to validate the RepositoryRole object using the Validate method
+ */
+func NewRepositoryRole(fs billy.Filesystem, filename string) (*RepositoryRole, error) {
+	filecontent, err := utils.ReadFile(fs, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &RepositoryRole{}
+	err = yaml.Unmarshal(filecontent, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+/*
+ * ReadRepositoryRoleDirectory reads all the files in the dirname directory and returns
+ * a map of RepositoryRole objects
+ */
+func ReadRepositoryRoleDirectory(fs billy.Filesystem, dirname string, LogCollection *observability.LogCollection) map[string]*RepositoryRole {
+	roles := make(map[string]*RepositoryRole)
+
+	exist, err := utils.Exists(fs, dirname)
+	if err != nil {
+		LogCollection.AddError(err)
+		return roles
+	}
+	if !exist {
+		return roles
+	}
+
+	// Parse all the repository roles in the dirname directory
+	entries, err := fs.ReadDir(dirname)
+	if err != nil {
+		LogCollection.AddError(err)
+		return roles
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		// skipping files starting with '.'
+		if e.Name()[0] == '.' {
+			continue
+		}
+		role, err := NewRepositoryRole(fs, filepath.Join(dirname, e.Name()))
+		if err != nil {
+			LogCollection.AddError(err)
+		} else {
+			err := role.Validate(filepath.Join(dirname, e.Name()))
+			if err != nil {
+				LogCollection.AddError(err)
+			} else {
+				roles[role.Name] = role
+			}
+		}
+	}
+	return roles
+}
+
+func (r *RepositoryRole) Validate(filename string) error {
+
+	if r.ApiVersion != "v1" {
+		return fmt.Errorf("invalid apiVersion: %s for RepositoryRole filename %s", r.ApiVersion, filename)
+	}
+
+	if r.Kind != "RepositoryRole" {
+		return fmt.Errorf("invalid kind: %s for RepositoryRole filename %s", r.Kind, filename)
+	}
+
+	if r.Name == "" {
+		return fmt.Errorf("metadata.name is empty for RepositoryRole filename %s", filename)
+	}
+
+	basename := filepath.Base(filename)
+	if r.Name != basename[:len(basename)-len(filepath.Ext(basename))] {
+		return fmt.Errorf("invalid metadata.name: %s for RepositoryRole filename %s", r.Name, filename)
+	}
+
+	if slices.Contains(REPOSITORY_ROLE_PREDEFINED, r.Name) {
+		return fmt.Errorf("invalid metadata.name: %s is a predefined repository role (RepositoryRole filename %s)", r.Name, filename)
+	}
+
+	if !slices.Contains(REPOSITORY_ROLE_BASE_ROLES, r.Spec.BaseRole) {
+		return fmt.Errorf("invalid spec.base_role: %s for RepositoryRole filename %s (must be read, triage, write or maintain)", r.Spec.BaseRole, filename)
+	}
+
+	return nil
+}
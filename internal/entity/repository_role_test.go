@@ -0,0 +1,112 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/goliac-project/goliac/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func fixtureCreateRepositoryRole(t *testing.T, fs billy.Filesystem) {
+	fs.MkdirAll("repository-roles", 0755)
+	err := utils.WriteFile(fs, "repository-roles/security-triager.yaml", []byte(`
+apiVersion: v1
+kind: RepositoryRole
+name: security-triager
+spec:
+  description: triage + security alerts
+  base_role: triage
+  permissions:
+    - delete_alerts_code_scanning
+    - view_dependabot_alerts
+`), 0644)
+	assert.Nil(t, err)
+
+	err = utils.WriteFile(fs, "repository-roles/release-manager.yaml", []byte(`
+apiVersion: v1
+kind: RepositoryRole
+name: release-manager
+spec:
+  base_role: write
+`), 0644)
+	assert.Nil(t, err)
+}
+
+func TestRepositoryRole(t *testing.T) {
+
+	t.Run("happy path", func(t *testing.T) {
+		fs := memfs.New()
+		fixtureCreateRepositoryRole(t, fs)
+
+		logsCollector := observability.NewLogCollection()
+		roles := ReadRepositoryRoleDirectory(fs, "repository-roles", logsCollector)
+		assert.Equal(t, false, logsCollector.HasErrors())
+		assert.Equal(t, 2, len(roles))
+		assert.Equal(t, "triage", roles["security-triager"].Spec.BaseRole)
+		assert.Equal(t, []string{"delete_alerts_code_scanning", "view_dependabot_alerts"}, roles["security-triager"].Spec.Permissions)
+		assert.Equal(t, "write", roles["release-manager"].Spec.BaseRole)
+	})
+
+	t.Run("happy path: no repository-roles directory", func(t *testing.T) {
+		fs := memfs.New()
+
+		logsCollector := observability.NewLogCollection()
+		roles := ReadRepositoryRoleDirectory(fs, "repository-roles", logsCollector)
+		assert.Equal(t, false, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(roles))
+	})
+
+	t.Run("not happy path: invalid base role", func(t *testing.T) {
+		fs := memfs.New()
+		err := utils.WriteFile(fs, "repository-roles/superadmin.yaml", []byte(`
+apiVersion: v1
+kind: RepositoryRole
+name: superadmin
+spec:
+  base_role: admin
+`), 0644)
+		assert.Nil(t, err)
+
+		logsCollector := observability.NewLogCollection()
+		roles := ReadRepositoryRoleDirectory(fs, "repository-roles", logsCollector)
+		assert.Equal(t, true, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(roles))
+	})
+
+	t.Run("not happy path: predefined role name", func(t *testing.T) {
+		fs := memfs.New()
+		err := utils.WriteFile(fs, "repository-roles/maintain.yaml", []byte(`
+apiVersion: v1
+kind: RepositoryRole
+name: maintain
+spec:
+  base_role: write
+`), 0644)
+		assert.Nil(t, err)
+
+		logsCollector := observability.NewLogCollection()
+		roles := ReadRepositoryRoleDirectory(fs, "repository-roles", logsCollector)
+		assert.Equal(t, true, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(roles))
+	})
+
+	t.Run("not happy path: name not matching the filename", func(t *testing.T) {
+		fs := memfs.New()
+		err := utils.WriteFile(fs, "repository-roles/role1.yaml", []byte(`
+apiVersion: v1
+kind: RepositoryRole
+name: role2
+spec:
+  base_role: read
+`), 0644)
+		assert.Nil(t, err)
+
+		logsCollector := observability.NewLogCollection()
+		roles := ReadRepositoryRoleDirectory(fs, "repository-roles", logsCollector)
+		assert.Equal(t, true, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(roles))
+	})
+}
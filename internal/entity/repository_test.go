@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/go-git/go-billy/v5"
@@ -10,6 +11,7 @@ import (
 	"github.com/goliac-project/goliac/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 )
 
 func fixtureCreateUserTeam(t *testing.T, fs billy.Filesystem) {
@@ -72,7 +74,7 @@ name: repo1
 		assert.False(t, logsCollector.HasWarns())
 		assert.NotNil(t, teams)
 
-		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.False(t, logsCollector.HasErrors())
 		assert.False(t, logsCollector.HasWarns())
 		assert.NotNil(t, repos)
@@ -100,7 +102,7 @@ name: repo2
 		assert.False(t, logsCollector.HasWarns())
 		assert.NotNil(t, teams)
 
-		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.True(t, logsCollector.HasErrors())
 		assert.False(t, logsCollector.HasWarns())
 	})
@@ -130,7 +132,7 @@ spec:
 		assert.False(t, logsCollector.HasWarns())
 		assert.NotNil(t, teams)
 
-		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.True(t, logsCollector.HasErrors())
 		assert.False(t, logsCollector.HasWarns())
 	})
@@ -160,7 +162,7 @@ spec:
 		assert.False(t, logsCollector.HasWarns())
 		assert.NotNil(t, teams)
 
-		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.True(t, logsCollector.HasErrors())
 		assert.False(t, logsCollector.HasWarns())
 	})
@@ -189,7 +191,7 @@ spec:
 		assert.False(t, logsCollector.HasWarns())
 		assert.NotNil(t, teams)
 
-		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.False(t, logsCollector.HasErrors())
 		assert.False(t, logsCollector.HasWarns())
 		assert.NotNil(t, repos)
@@ -218,7 +220,7 @@ name: repo1
 		assert.False(t, logsCollector.HasWarns())
 		assert.NotNil(t, teams)
 
-		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.False(t, logsCollector.HasErrors())
 		assert.False(t, logsCollector.HasWarns())
 		assert.NotNil(t, repos)
@@ -252,7 +254,7 @@ spec:
 		assert.False(t, logsCollector.HasWarns())
 		assert.NotNil(t, teams)
 
-		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.True(t, logsCollector.HasErrors())
 		assert.False(t, logsCollector.HasWarns())
 	})
@@ -284,7 +286,7 @@ spec:
 		assert.False(t, logsCollector.HasWarns())
 		assert.NotNil(t, teams)
 
-		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.False(t, logsCollector.HasErrors())
 		assert.False(t, logsCollector.HasWarns())
 		assert.NotNil(t, repos)
@@ -334,12 +336,362 @@ spec:
 		assert.False(t, logsCollector.HasWarns())
 		assert.NotNil(t, teams)
 
-		repos := ReadRepositories(fs, "archived", "teams", teams, externalUsers, users, []*config.GithubCustomProperty{}, logsCollector)
+		repos := ReadRepositories(fs, "archived", "teams", teams, externalUsers, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.False(t, logsCollector.HasErrors())
 		assert.False(t, logsCollector.HasWarns())
 		assert.NotNil(t, repos)
 		assert.Equal(t, 1, len(repos))
 	})
+
+	t.Run("happy path: maintainers, triagers and custom roles", func(t *testing.T) {
+		fs := memfs.New()
+		fixtureCreateUserTeam(t, fs)
+		fixtureCreateRepositoryRoleTeams(t, fs)
+
+		err := utils.WriteFile(fs, "teams/team1/repo1.yaml", []byte(`
+apiVersion: v1
+kind: Repository
+name: repo1
+spec:
+  maintainers:
+  - team2
+  triagers:
+  - team3
+  roles:
+    security-triager:
+    - team4
+`), 0644)
+		assert.Nil(t, err)
+		logsCollector := observability.NewLogCollection()
+		users := ReadUserDirectory(fs, "users", logsCollector)
+		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 1, len(repos))
+		assert.Equal(t, []string{"team2"}, repos["repo1"].Spec.Maintainers)
+		assert.Equal(t, []string{"team3"}, repos["repo1"].Spec.Triagers)
+		assert.Equal(t, map[string][]string{"security-triager": {"team4"}}, repos["repo1"].Spec.Roles)
+	})
+
+	t.Run("not happy path: wrong maintainer team name", func(t *testing.T) {
+		fs := memfs.New()
+		fixtureCreateUserTeam(t, fs)
+
+		err := utils.WriteFile(fs, "teams/team1/repo1.yaml", []byte(`
+apiVersion: v1
+kind: Repository
+name: repo1
+spec:
+  maintainers:
+  - wrongteam
+`), 0644)
+		assert.Nil(t, err)
+		logsCollector := observability.NewLogCollection()
+		users := ReadUserDirectory(fs, "users", logsCollector)
+		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+
+		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
+		assert.True(t, logsCollector.HasErrors())
+	})
+
+	t.Run("not happy path: team with a custom role and another access", func(t *testing.T) {
+		fs := memfs.New()
+		fixtureCreateUserTeam(t, fs)
+		fixtureCreateRepositoryRoleTeams(t, fs)
+
+		err := utils.WriteFile(fs, "teams/team1/repo1.yaml", []byte(`
+apiVersion: v1
+kind: Repository
+name: repo1
+spec:
+  readers:
+  - team2
+  roles:
+    security-triager:
+    - team2
+`), 0644)
+		assert.Nil(t, err)
+		logsCollector := observability.NewLogCollection()
+		users := ReadUserDirectory(fs, "users", logsCollector)
+		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+
+		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
+		assert.True(t, logsCollector.HasErrors())
+	})
+
+	t.Run("not happy path: team with 2 custom roles", func(t *testing.T) {
+		fs := memfs.New()
+		fixtureCreateUserTeam(t, fs)
+		fixtureCreateRepositoryRoleTeams(t, fs)
+
+		err := utils.WriteFile(fs, "teams/team1/repo1.yaml", []byte(`
+apiVersion: v1
+kind: Repository
+name: repo1
+spec:
+  roles:
+    security-triager:
+    - team2
+    release-manager:
+    - team2
+`), 0644)
+		assert.Nil(t, err)
+		logsCollector := observability.NewLogCollection()
+		users := ReadUserDirectory(fs, "users", logsCollector)
+		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+
+		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
+		assert.True(t, logsCollector.HasErrors())
+	})
+}
+
+func fixtureCreateRepositoryRoleTeams(t *testing.T, fs billy.Filesystem) {
+	for _, teamname := range []string{"team2", "team3", "team4"} {
+		fs.MkdirAll("teams/"+teamname, 0755)
+		err := utils.WriteFile(fs, "teams/"+teamname+"/team.yaml", []byte(`
+apiVersion: v1
+kind: Team
+name: `+teamname+`
+spec:
+  owners:
+  - user1
+`), 0644)
+		assert.Nil(t, err)
+	}
+}
+
+func fixtureCreateRepositoryDefaultsTeams(t *testing.T, fs billy.Filesystem) {
+	err := utils.WriteFile(fs, "teams/team1/team.yaml", []byte(`
+apiVersion: v1
+kind: Team
+name: team1
+spec:
+  owners:
+  - user1
+  - user2
+  repository_defaults:
+    delete_branch_on_merge: true
+    allow_auto_merge: true
+    visibility: internal
+    topics:
+    - team1
+    custom_properties:
+      tier: "1"
+      domain: payments
+`), 0644)
+	assert.Nil(t, err)
+
+	fs.MkdirAll("teams/team1/team1child", 0755)
+	err = utils.WriteFile(fs, "teams/team1/team1child/team.yaml", []byte(`
+apiVersion: v1
+kind: Team
+name: team1child
+spec:
+  owners:
+  - user1
+  - user2
+  repository_defaults:
+    allow_auto_merge: false
+    readers:
+    - team1
+`), 0644)
+	assert.Nil(t, err)
+}
+
+func TestRepositoryDefaults(t *testing.T) {
+	customProperties := []*config.GithubCustomProperty{
+		{PropertyName: "tier", ValueType: "string"},
+		{PropertyName: "domain", ValueType: "string"},
+	}
+
+	t.Run("happy path: team repository_defaults are applied", func(t *testing.T) {
+		fs := memfs.New()
+		fixtureCreateUserTeam(t, fs)
+		fixtureCreateRepositoryDefaultsTeams(t, fs)
+
+		err := utils.WriteFile(fs, "teams/team1/repo1.yaml", []byte(`
+apiVersion: v1
+kind: Repository
+name: repo1
+`), 0644)
+		assert.Nil(t, err)
+
+		logsCollector := observability.NewLogCollection()
+		users := ReadUserDirectory(fs, "users", logsCollector)
+		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, customProperties, nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		require.NotNil(t, repos["repo1"])
+		assert.True(t, repos["repo1"].Spec.DeleteBranchOnMerge)
+		assert.True(t, repos["repo1"].Spec.AllowAutoMerge)
+		assert.Equal(t, "internal", repos["repo1"].Spec.Visibility)
+		assert.Equal(t, []string{"team1"}, repos["repo1"].Spec.Topics)
+		// built-in defaults are kept
+		assert.True(t, repos["repo1"].Spec.AllowSquashMerge)
+	})
+
+	t.Run("happy path: explicit repository values win", func(t *testing.T) {
+		fs := memfs.New()
+		fixtureCreateUserTeam(t, fs)
+		fixtureCreateRepositoryDefaultsTeams(t, fs)
+
+		err := utils.WriteFile(fs, "teams/team1/repo1.yaml", []byte(`
+apiVersion: v1
+kind: Repository
+name: repo1
+spec:
+  delete_branch_on_merge: false
+  visibility: private
+  topics:
+  - repo1
+  custom_properties:
+    tier: "2"
+`), 0644)
+		assert.Nil(t, err)
+
+		logsCollector := observability.NewLogCollection()
+		users := ReadUserDirectory(fs, "users", logsCollector)
+		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, customProperties, nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		require.NotNil(t, repos["repo1"])
+		assert.False(t, repos["repo1"].Spec.DeleteBranchOnMerge)
+		assert.True(t, repos["repo1"].Spec.AllowAutoMerge)
+		assert.Equal(t, "private", repos["repo1"].Spec.Visibility)
+		// lists are replaced, maps are merged
+		assert.Equal(t, []string{"repo1"}, repos["repo1"].Spec.Topics)
+		assert.Equal(t, map[string]interface{}{"tier": "2", "domain": "payments"}, repos["repo1"].Spec.CustomProperties)
+	})
+
+	t.Run("happy path: repository_defaults are inherited by children teams", func(t *testing.T) {
+		fs := memfs.New()
+		fixtureCreateUserTeam(t, fs)
+		fixtureCreateRepositoryDefaultsTeams(t, fs)
+
+		err := utils.WriteFile(fs, "teams/team1/team1child/repo2.yaml", []byte(`
+apiVersion: v1
+kind: Repository
+name: repo2
+`), 0644)
+		assert.Nil(t, err)
+
+		logsCollector := observability.NewLogCollection()
+		users := ReadUserDirectory(fs, "users", logsCollector)
+		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, customProperties, nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		require.NotNil(t, repos["repo2"])
+		assert.Equal(t, "team1child", *repos["repo2"].Owner)
+		// from team1
+		assert.True(t, repos["repo2"].Spec.DeleteBranchOnMerge)
+		assert.Equal(t, "internal", repos["repo2"].Spec.Visibility)
+		// overridden by team1child
+		assert.False(t, repos["repo2"].Spec.AllowAutoMerge)
+		assert.Equal(t, []string{"team1"}, repos["repo2"].Spec.Readers)
+	})
+
+	t.Run("happy path: archived repositories don't get the defaults", func(t *testing.T) {
+		fs := memfs.New()
+		fixtureCreateUserTeam(t, fs)
+		fixtureCreateRepositoryDefaultsTeams(t, fs)
+
+		err := utils.WriteFile(fs, "archived/repo1.yaml", []byte(`
+apiVersion: v1
+kind: Repository
+name: repo1
+`), 0644)
+		assert.Nil(t, err)
+
+		logsCollector := observability.NewLogCollection()
+		users := ReadUserDirectory(fs, "users", logsCollector)
+		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, customProperties, nil, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		require.NotNil(t, repos["repo1"])
+		assert.False(t, repos["repo1"].Spec.DeleteBranchOnMerge)
+		assert.Equal(t, "private", repos["repo1"].Spec.Visibility)
+	})
+
+	t.Run("happy path: organization repository_defaults", func(t *testing.T) {
+		fs := memfs.New()
+		fixtureCreateUserTeam(t, fs)
+		fixtureCreateRepositoryDefaultsTeams(t, fs)
+
+		err := utils.WriteFile(fs, "teams/team1/repo1.yaml", []byte(`
+apiVersion: v1
+kind: Repository
+name: repo1
+`), 0644)
+		assert.Nil(t, err)
+		err = utils.WriteFile(fs, "teams/team1/repo2.yaml", []byte(`
+apiVersion: v1
+kind: Repository
+name: repo2
+spec:
+  allow_merge_commit: true
+`), 0644)
+		assert.Nil(t, err)
+
+		logsCollector := observability.NewLogCollection()
+		users := ReadUserDirectory(fs, "users", logsCollector)
+		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+
+		disabled := false
+		orgDefaults := &config.RepositorySettings{
+			Visibility:        "public",
+			DefaultBranchName: "main",
+			AllowMergeCommit:  &disabled,
+		}
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, customProperties, orgDefaults, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		require.NotNil(t, repos["repo1"])
+		assert.False(t, repos["repo1"].Spec.AllowMergeCommit)
+		assert.Equal(t, "main", repos["repo1"].Spec.DefaultBranchName)
+		// the team's repository_defaults win over the organization ones
+		assert.Equal(t, "internal", repos["repo1"].Spec.Visibility)
+		// untouched built-in defaults
+		assert.True(t, repos["repo1"].Spec.AllowRebaseMerge)
+		// the repository definition wins
+		assert.True(t, repos["repo2"].Spec.AllowMergeCommit)
+	})
+
+	t.Run("not happy path: invalid repository_defaults", func(t *testing.T) {
+		fs := memfs.New()
+		fixtureCreateUserTeam(t, fs)
+
+		err := utils.WriteFile(fs, "teams/team1/team.yaml", []byte(`
+apiVersion: v1
+kind: Team
+name: team1
+spec:
+  owners:
+  - user1
+  - user2
+  repository_defaults:
+    topics: notalist
+`), 0644)
+		assert.Nil(t, err)
+
+		logsCollector := observability.NewLogCollection()
+		users := ReadUserDirectory(fs, "users", logsCollector)
+		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
+		assert.True(t, logsCollector.HasErrors())
+		assert.Equal(t, 0, len(teams))
+	})
 }
 
 func TestGenerateCodeownersContent(t *testing.T) {
@@ -463,7 +815,7 @@ spec:
 		logsCollector := observability.NewLogCollection()
 		users := ReadUserDirectory(fs, "users", logsCollector)
 		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
-		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.False(t, logsCollector.HasErrors())
 		assert.Equal(t, 1, len(repos))
 	})
@@ -488,7 +840,7 @@ spec:
 		logsCollector := observability.NewLogCollection()
 		users := ReadUserDirectory(fs, "users", logsCollector)
 		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
-		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.False(t, logsCollector.HasErrors())
 		assert.Equal(t, 1, len(repos))
 	})
@@ -512,7 +864,7 @@ spec:
 		logsCollector := observability.NewLogCollection()
 		users := ReadUserDirectory(fs, "users", logsCollector)
 		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
-		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.False(t, logsCollector.HasErrors())
 		assert.Equal(t, 1, len(repos))
 	})
@@ -536,7 +888,7 @@ spec:
 		logsCollector := observability.NewLogCollection()
 		users := ReadUserDirectory(fs, "users", logsCollector)
 		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
-		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.True(t, logsCollector.HasErrors())
 	})
 
@@ -569,7 +921,7 @@ spec:
 		logsCollector := observability.NewLogCollection()
 		users := ReadUserDirectory(fs, "users", logsCollector)
 		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
-		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.True(t, logsCollector.HasErrors())
 	})
 
@@ -592,7 +944,7 @@ spec:
 		logsCollector := observability.NewLogCollection()
 		users := ReadUserDirectory(fs, "users", logsCollector)
 		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
-		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.True(t, logsCollector.HasErrors())
 	})
 
@@ -614,7 +966,7 @@ spec:
 		logsCollector := observability.NewLogCollection()
 		users := ReadUserDirectory(fs, "users", logsCollector)
 		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
-		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.False(t, logsCollector.HasErrors())
 		assert.Equal(t, 1, len(repos))
 	})
@@ -642,7 +994,7 @@ spec:
 		logsCollector := observability.NewLogCollection()
 		users := ReadUserDirectory(fs, "users", logsCollector)
 		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
-		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		repos := ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.False(t, logsCollector.HasErrors())
 		assert.Equal(t, 1, len(repos))
 	})
@@ -665,7 +1017,7 @@ spec:
 		logsCollector := observability.NewLogCollection()
 		users := ReadUserDirectory(fs, "users", logsCollector)
 		teams := ReadTeamDirectory(fs, "teams", users, logsCollector)
-		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, logsCollector)
+		ReadRepositories(fs, "archived", "teams", teams, map[string]*User{}, users, []*config.GithubCustomProperty{}, nil, logsCollector)
 		assert.True(t, logsCollector.HasErrors())
 	})
 }
@@ -1029,3 +1381,194 @@ func TestRepositoryGithubPagesValidate(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestBranchProtectionToRuleSets(t *testing.T) {
+	t.Run("happy path: reviews, status checks, signatures and linear history", func(t *testing.T) {
+		bp := RepositoryBranchProtection{
+			Pattern:                        "main",
+			RequiresApprovingReviews:       true,
+			RequiredApprovingReviewCount:   2,
+			DismissesStaleReviews:          true,
+			RequiresCodeOwnerReviews:       true,
+			RequiresStatusChecks:           true,
+			RequiresStrictStatusChecks:     true,
+			RequiredStatusCheckContexts:    []string{"ci"},
+			RequiresConversationResolution: true,
+			RequiresCommitSignatures:       true,
+			RequiresLinearHistory:          true,
+		}
+		rulesets, warnings := BranchProtectionToRuleSets("bp-main", &bp)
+		assert.Equal(t, 0, len(warnings))
+		assert.Equal(t, 1, len(rulesets))
+
+		rs := rulesets[0]
+		assert.Equal(t, "bp-main", rs.Name)
+		assert.Equal(t, "active", rs.Enforcement)
+		assert.Equal(t, []string{"main"}, rs.Conditions.Include)
+		assert.Nil(t, ValidateRulesetDefinition(&rs.RuleSetDefinition, "test"))
+
+		rules := make(map[string]RuleSetParameters)
+		for _, r := range rs.Rules {
+			rules[r.Ruletype] = r.Parameters
+		}
+		assert.Equal(t, 6, len(rules))
+		assert.Equal(t, 2, rules["pull_request"].RequiredApprovingReviewCount)
+		assert.True(t, rules["pull_request"].DismissStaleReviewsOnPush)
+		assert.True(t, rules["pull_request"].RequireCodeOwnerReview)
+		assert.True(t, rules["pull_request"].RequiredReviewThreadResolution)
+		assert.Equal(t, []string{"ci"}, rules["required_status_checks"].RequiredStatusChecks)
+		assert.True(t, rules["required_status_checks"].StrictRequiredStatusChecksPolicy)
+		assert.Contains(t, rules, "required_signatures")
+		assert.Contains(t, rules, "required_linear_history")
+		assert.Contains(t, rules, "non_fast_forward")
+		assert.Contains(t, rules, "deletion")
+	})
+
+	t.Run("happy path: bypass actors get a dedicated pull request ruleset", func(t *testing.T) {
+		bp := RepositoryBranchProtection{
+			Pattern:                      "release/*",
+			RequiresApprovingReviews:     true,
+			RequiredApprovingReviewCount: 1,
+			RequiresCommitSignatures:     true,
+			AllowsForcePushes:            true,
+			AllowsDeletions:              true,
+			BypassPullRequestTeams:       []string{"team1"},
+			BypassPullRequestApps:        []string{"app1"},
+		}
+		rulesets, warnings := BranchProtectionToRuleSets("bp-release", &bp)
+		assert.Equal(t, 0, len(warnings))
+		assert.Equal(t, 2, len(rulesets))
+
+		assert.Equal(t, "bp-release", rulesets[0].Name)
+		assert.Equal(t, 1, len(rulesets[0].Rules))
+		assert.Equal(t, "required_signatures", rulesets[0].Rules[0].Ruletype)
+		assert.Equal(t, 0, len(rulesets[0].BypassTeams))
+
+		assert.Equal(t, "bp-release-pull-request", rulesets[1].Name)
+		assert.Equal(t, 1, len(rulesets[1].Rules))
+		assert.Equal(t, "pull_request", rulesets[1].Rules[0].Ruletype)
+		assert.Equal(t, "team1", rulesets[1].BypassTeams[0].TeamName)
+		assert.Equal(t, "always", rulesets[1].BypassTeams[0].Mode)
+		assert.Equal(t, "app1", rulesets[1].BypassApps[0].AppName)
+	})
+
+	t.Run("not happy path: settings without equivalent", func(t *testing.T) {
+		bp := RepositoryBranchProtection{
+			Pattern:                  "main",
+			RequiresApprovingReviews: true,
+			RequiresStatusChecks:     true,
+			AllowsForcePushes:        true,
+			AllowsDeletions:          true,
+			BypassPullRequestUsers:   []string{"user1"},
+		}
+		rulesets, warnings := BranchProtectionToRuleSets("bp-main", &bp)
+		assert.Equal(t, 2, len(warnings))
+		assert.Equal(t, 1, len(rulesets))
+		assert.Equal(t, "pull_request", rulesets[0].Rules[0].Ruletype)
+	})
+}
+
+func TestReadAndMigrateBranchProtections(t *testing.T) {
+	t.Run("happy path: migrate branch protections", func(t *testing.T) {
+		fs := memfs.New()
+		fs.MkdirAll("teams/team1", 0755)
+		err := utils.WriteFile(fs, "teams/team1/repo1.yaml", []byte(`
+apiVersion: v1
+kind: Repository
+name: repo1
+spec:
+  rulesets:
+    - name: branch-protection-main
+      enforcement: active
+      conditions:
+        include:
+          - "~ALL"
+      rules:
+        - ruletype: required_signatures
+  branch_protections:
+    - pattern: main
+      requires_approving_reviews: true
+      required_approving_review_count: 1
+`), 0644)
+		assert.Nil(t, err)
+		err = utils.WriteFile(fs, "teams/team1/repo2.yaml", []byte(`
+apiVersion: v1
+kind: Repository
+name: repo2
+`), 0644)
+		assert.Nil(t, err)
+
+		logsCollector := observability.NewLogCollection()
+		migrated := ReadAndMigrateBranchProtections(fs, "archived", "teams", logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 1, len(migrated))
+
+		document := migrated["teams/team1/repo1.yaml"]
+		require.NotNil(t, document)
+		var repo Repository
+		require.Nil(t, document.Decode(&repo))
+		assert.Equal(t, 0, len(repo.Spec.BranchProtections))
+		assert.Equal(t, 2, len(repo.Spec.Rulesets))
+		// the name is already used
+		assert.Equal(t, "branch-protection-main-2", repo.Spec.Rulesets[1].Name)
+		assert.Equal(t, []string{"main"}, repo.Spec.Rulesets[1].Conditions.Include)
+	})
+	t.Run("happy path: only the branch protections and rulesets are changed", func(t *testing.T) {
+		fs := memfs.New()
+		fs.MkdirAll("teams/team1", 0755)
+		err := utils.WriteFile(fs, "teams/team1/repo1.yaml", []byte(`apiVersion: v1
+kind: Repository
+name: repo1
+spec:
+  # squash merges are forbidden
+  allow_squash_merge: false
+  allow_rebase_merge: false
+  branch_protections:
+    - pattern: main
+      requires_linear_history: true
+  topics:
+    - backend # owned by the backend team
+`), 0644)
+		assert.Nil(t, err)
+
+		logsCollector := observability.NewLogCollection()
+		migrated := ReadAndMigrateBranchProtections(fs, "archived", "teams", logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+		require.NotNil(t, migrated["teams/team1/repo1.yaml"])
+
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		require.Nil(t, encoder.Encode(migrated["teams/team1/repo1.yaml"]))
+
+		assert.Equal(t, `apiVersion: v1
+kind: Repository
+name: repo1
+spec:
+  # squash merges are forbidden
+  allow_squash_merge: false
+  allow_rebase_merge: false
+  rulesets:
+    - enforcement: active
+      conditions:
+        include:
+          - main
+      rules:
+        - ruletype: required_linear_history
+        - ruletype: non_fast_forward
+        - ruletype: deletion
+      name: branch-protection-main
+  topics:
+    - backend # owned by the backend team
+`, buf.String())
+
+		// the merge methods are still disabled
+		require.Nil(t, utils.WriteFile(fs, "teams/team1/repo1.yaml", buf.Bytes(), 0644))
+		repo, err := NewRepository(fs, "teams/team1/repo1.yaml")
+		require.Nil(t, err)
+		assert.False(t, repo.Spec.AllowSquashMerge)
+		assert.False(t, repo.Spec.AllowRebaseMerge)
+		assert.True(t, repo.Spec.AllowMergeCommit)
+		assert.Equal(t, 1, len(repo.Spec.Rulesets))
+	})
+}
@@ -3,6 +3,8 @@ package entity
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/go-git/go-billy/v5"
@@ -16,8 +18,13 @@ type Team struct {
 	Entity `yaml:",inline"`
 	Spec   struct {
 		ExternallyManaged bool     `yaml:"externallyManaged,omitempty"`
+		SyncFrom          string   `yaml:"sync_from,omitempty"`        // members are synced from this group
+		SyncOwnersFrom    string   `yaml:"sync_owners_from,omitempty"` // owners are synced from this group
+		LdapGroup         string   `yaml:"ldapGroup,omitempty"`        // members are synced from this group DN
 		Owners            []string `yaml:"owners,omitempty"`
 		Members           []string `yaml:"members,omitempty"`
+		// repository spec applied to all the repositories owned by the team (and its children teams)
+		RepositoryDefaults yaml.Node `yaml:"repository_defaults,omitempty"`
 	} `yaml:"spec"`
 	ParentTeam *string `yaml:"-"`
 }
@@ -166,6 +173,24 @@ func (t *Team) Validate(dirname string, users map[string]*User, logsCollector *o
 		}
 	}
 
+	if t.Spec.ExternallyManaged && (t.Spec.LdapGroup != "" || t.Spec.SyncFrom != "" || t.Spec.SyncOwnersFrom != "") {
+		logsCollector.AddError(fmt.Errorf("externallyManaged team cannot be synced from a group for team filename %s/team.yaml", dirname))
+		return false
+	}
+
+	if t.Spec.LdapGroup != "" && t.Spec.SyncFrom != "" {
+		logsCollector.AddError(fmt.Errorf("team cannot have both ldapGroup and sync_from for team filename %s/team.yaml", dirname))
+		return false
+	}
+
+	if !t.Spec.RepositoryDefaults.IsZero() {
+		repository := Repository{}
+		if err := t.Spec.RepositoryDefaults.Decode(&repository.Spec); err != nil {
+			logsCollector.AddError(fmt.Errorf("invalid repository_defaults for team filename %s/team.yaml: %v", dirname, err))
+			return false
+		}
+	}
+
 	for _, owner := range t.Spec.Owners {
 		if _, ok := users[owner]; !ok {
 			logsCollector.AddError(fmt.Errorf("invalid owner: %s doesn't exist in team filename %s/team.yaml", owner, dirname))
@@ -300,3 +325,159 @@ func (t *Team) Update(fs billy.Filesystem, filename string, users map[string]*Us
 	}
 	return changed, err
 }
+
+/**
+ * ReadAndSyncTeamGroupDirectory rewrites the owners/members of the teams synced from
+ * a group (spec.sync_owners_from, spec.sync_from or spec.ldapGroup) with the (known)
+ * users member of these groups. groupsMembers resolves the groups ([group][]username),
+ * a group missing from its result being left untouched.
+ * Returns:
+ * - a list of (team's) file changes (to commit to Github)
+ */
+func ReadAndSyncTeamGroupDirectory(fs billy.Filesystem, dirname string, users map[string]*User, groupsMembers func(groups []string) (map[string][]string, error)) ([]string, error) {
+	teamschanged := []string{}
+
+	exist, err := utils.Exists(fs, dirname)
+	if err != nil {
+		return teamschanged, err
+	}
+	if !exist {
+		return teamschanged, nil
+	}
+
+	// collect the teams synced from a group
+	groupTeams := make(map[string]*Team)
+	err = walkTeamDirectory(fs, dirname, func(filename string, team *Team) {
+		ownersGroup, membersGroup := team.SyncGroups()
+		if (ownersGroup != "" || membersGroup != "") && !team.Spec.ExternallyManaged {
+			groupTeams[filename] = team
+		}
+	})
+	if err != nil {
+		return teamschanged, err
+	}
+	if len(groupTeams) == 0 {
+		return teamschanged, nil
+	}
+
+	groups := []string{}
+	for _, team := range groupTeams {
+		ownersGroup, membersGroup := team.SyncGroups()
+		for _, group := range []string{ownersGroup, membersGroup} {
+			if group != "" && !slices.Contains(groups, group) {
+				groups = append(groups, group)
+			}
+		}
+	}
+	sort.Strings(groups)
+	members, err := groupsMembers(groups)
+	if err != nil {
+		return teamschanged, err
+	}
+
+	filenames := make([]string, 0, len(groupTeams))
+	for filename := range groupTeams {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	for _, filename := range filenames {
+		changed, err := groupTeams[filename].SyncGroupMembers(fs, filename, members, users)
+		if err != nil {
+			return teamschanged, err
+		}
+		if changed {
+			teamschanged = append(teamschanged, filename)
+		}
+	}
+	return teamschanged, nil
+}
+
+func walkTeamDirectory(fs billy.Filesystem, dirname string, callback func(filename string, team *Team)) error {
+	entries, err := fs.ReadDir(dirname)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() || e.Name()[0] == '.' {
+			continue
+		}
+		teamdir := filepath.Join(dirname, e.Name())
+		team, err := NewTeam(fs, filepath.Join(teamdir, "team.yaml"), nil)
+		if err != nil {
+			return err
+		}
+		callback(filepath.Join(teamdir, "team.yaml"), team)
+		if err := walkTeamDirectory(fs, teamdir, callback); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SyncGroups returns the groups the team's owners and members are synced from
+// (spec.ldapGroup being the ldap flavor of spec.sync_from)
+func (t *Team) SyncGroups() (string, string) {
+	if t.Spec.SyncFrom != "" {
+		return t.Spec.SyncOwnersFrom, t.Spec.SyncFrom
+	}
+	return t.Spec.SyncOwnersFrom, t.Spec.LdapGroup
+}
+
+// SyncGroupMembers sets the team's owners/members to the members of their groups
+// (known users only, owners not being repeated as members), and tells if the
+// team's definition was changed on disk
+func (t *Team) SyncGroupMembers(fs billy.Filesystem, filename string, groupsMembers map[string][]string, users map[string]*User) (bool, error) {
+	ownersGroup, membersGroup := t.SyncGroups()
+
+	owners := t.Spec.Owners
+	if groupOwners, ok := groupsMembers[ownersGroup]; ok && ownersGroup != "" {
+		owners = filterGroupUsers(groupOwners, users, nil)
+	}
+	members := t.Spec.Members
+	if groupMembers, ok := groupsMembers[membersGroup]; ok && membersGroup != "" {
+		members = filterGroupUsers(groupMembers, users, owners)
+	}
+
+	if sameUsers(t.Spec.Owners, owners) && sameUsers(t.Spec.Members, members) {
+		return false, nil
+	}
+	t.Spec.Owners = owners
+	t.Spec.Members = members
+
+	file, err := fs.Create(filename)
+	if err != nil {
+		return false, fmt.Errorf("not able to create file %s: %v", filename, err)
+	}
+	defer file.Close()
+
+	encoder := yaml.NewEncoder(file)
+	encoder.SetIndent(2)
+	err = encoder.Encode(t)
+	if err != nil {
+		return false, fmt.Errorf("not able to write file %s: %v", filename, err)
+	}
+	return true, nil
+}
+
+// filterGroupUsers returns the sorted (known) users of a group, without the excluded ones
+func filterGroupUsers(groupMembers []string, users map[string]*User, excluded []string) []string {
+	result := make([]string, 0)
+	for _, member := range groupMembers {
+		if _, ok := users[member]; !ok || slices.Contains(excluded, member) || slices.Contains(result, member) {
+			continue
+		}
+		result = append(result, member)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func sameUsers(a, b []string) bool {
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return slices.Equal(a, b)
+}
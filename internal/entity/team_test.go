@@ -436,3 +436,197 @@ spec:
 		assert.Equal(t, "owner2", checkTeam.Spec.Owners[0])
 	})
 }
+
+func TestReadAndSyncTeamGroup(t *testing.T) {
+	users := make(map[string]*User)
+	for _, username := range []string{"owner1", "owner2", "member1", "member2", "member3"} {
+		u := User{}
+		u.Name = username
+		u.Spec.GithubID = username
+		users[username] = &u
+	}
+
+	t.Run("happy path: no group team", func(t *testing.T) {
+		fs := memfs.New()
+		err := utils.WriteFile(fs, "/teams/ateam/team.yaml", []byte(`
+apiVersion: v1
+kind: Team
+name: ateam
+spec:
+  owners:
+    - owner1
+`), 0644)
+		assert.Nil(t, err)
+
+		changed, err := ReadAndSyncTeamGroupDirectory(fs, "/teams", users, func(groups []string) (map[string][]string, error) {
+			t.Fatal("groups must not be resolved")
+			return nil, nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(changed))
+	})
+
+	t.Run("happy path: members synced from the group", func(t *testing.T) {
+		fs := memfs.New()
+		err := utils.WriteFile(fs, "/teams/ateam/team.yaml", []byte(`
+apiVersion: v1
+kind: Team
+name: ateam
+spec:
+  ldapGroup: cn=ateam,ou=groups,dc=company,dc=com
+  owners:
+    - owner1
+  members:
+    - member1
+`), 0644)
+		assert.Nil(t, err)
+		err = utils.WriteFile(fs, "/teams/ateam/subteam/team.yaml", []byte(`
+apiVersion: v1
+kind: Team
+name: subteam
+spec:
+  ldapGroup: cn=subteam,ou=groups,dc=company,dc=com
+  owners:
+    - owner2
+  members:
+    - member3
+`), 0644)
+		assert.Nil(t, err)
+
+		var resolved []string
+		changed, err := ReadAndSyncTeamGroupDirectory(fs, "/teams", users, func(groups []string) (map[string][]string, error) {
+			resolved = groups
+			return map[string][]string{
+				"cn=ateam,ou=groups,dc=company,dc=com":   {"member2", "owner1", "unknown", "member1"},
+				"cn=subteam,ou=groups,dc=company,dc=com": {"member3"},
+			}, nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(resolved))
+		assert.Equal(t, []string{"/teams/ateam/team.yaml"}, changed)
+
+		team, err := NewTeam(fs, "/teams/ateam/team.yaml", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"owner1"}, team.Spec.Owners)
+		assert.Equal(t, []string{"member1", "member2"}, team.Spec.Members)
+		assert.Equal(t, "cn=ateam,ou=groups,dc=company,dc=com", team.Spec.LdapGroup)
+	})
+
+	t.Run("happy path: owners and members synced from groups", func(t *testing.T) {
+		fs := memfs.New()
+		err := utils.WriteFile(fs, "/teams/ateam/team.yaml", []byte(`
+apiVersion: v1
+kind: Team
+name: ateam
+spec:
+  sync_owners_from: ateam-leads
+  sync_from: ateam
+  owners:
+    - owner1
+  members:
+    - member1
+`), 0644)
+		assert.Nil(t, err)
+		err = utils.WriteFile(fs, "/teams/bteam/team.yaml", []byte(`
+apiVersion: v1
+kind: Team
+name: bteam
+spec:
+  sync_from: unresolved
+  members:
+    - member1
+`), 0644)
+		assert.Nil(t, err)
+
+		var resolved []string
+		changed, err := ReadAndSyncTeamGroupDirectory(fs, "/teams", users, func(groups []string) (map[string][]string, error) {
+			resolved = groups
+			return map[string][]string{
+				"ateam-leads": {"owner2", "owner1"},
+				"ateam":       {"owner1", "member3"},
+			}, nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"ateam", "ateam-leads", "unresolved"}, resolved)
+		assert.Equal(t, []string{"/teams/ateam/team.yaml"}, changed)
+
+		team, err := NewTeam(fs, "/teams/ateam/team.yaml", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"owner1", "owner2"}, team.Spec.Owners)
+		assert.Equal(t, []string{"member3"}, team.Spec.Members)
+
+		// an unresolved group leaves the team untouched
+		team, err = NewTeam(fs, "/teams/bteam/team.yaml", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"member1"}, team.Spec.Members)
+	})
+
+	t.Run("not happy path: ldapGroup and sync_from", func(t *testing.T) {
+		team := Team{}
+		team.ApiVersion = "v1"
+		team.Kind = "Team"
+		team.Name = "ateam"
+		team.Spec.SyncFrom = "ateam"
+		team.Spec.LdapGroup = "cn=ateam,ou=groups,dc=company,dc=com"
+
+		logsCollector := observability.NewLogCollection()
+		assert.False(t, team.Validate("teams/ateam", users, logsCollector))
+		assert.True(t, logsCollector.HasErrors())
+	})
+
+	t.Run("happy path: group not found leaves the team untouched", func(t *testing.T) {
+		fs := memfs.New()
+		err := utils.WriteFile(fs, "/teams/ateam/team.yaml", []byte(`
+apiVersion: v1
+kind: Team
+name: ateam
+spec:
+  ldapGroup: cn=missing,ou=groups,dc=company,dc=com
+  owners:
+    - owner1
+  members:
+    - member1
+`), 0644)
+		assert.Nil(t, err)
+
+		changed, err := ReadAndSyncTeamGroupDirectory(fs, "/teams", users, func(groups []string) (map[string][]string, error) {
+			return map[string][]string{}, nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(changed))
+
+		team, err := NewTeam(fs, "/teams/ateam/team.yaml", nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"member1"}, team.Spec.Members)
+	})
+
+	t.Run("not happy path: not able to resolve the groups", func(t *testing.T) {
+		fs := memfs.New()
+		err := utils.WriteFile(fs, "/teams/ateam/team.yaml", []byte(`
+apiVersion: v1
+kind: Team
+name: ateam
+spec:
+  ldapGroup: cn=ateam,ou=groups,dc=company,dc=com
+`), 0644)
+		assert.Nil(t, err)
+
+		_, err = ReadAndSyncTeamGroupDirectory(fs, "/teams", users, func(groups []string) (map[string][]string, error) {
+			return nil, fmt.Errorf("ldap unavailable")
+		})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("not happy path: externallyManaged team with a ldapGroup", func(t *testing.T) {
+		team := Team{}
+		team.ApiVersion = "v1"
+		team.Kind = "Team"
+		team.Name = "ateam"
+		team.Spec.ExternallyManaged = true
+		team.Spec.LdapGroup = "cn=ateam,ou=groups,dc=company,dc=com"
+
+		logsCollector := observability.NewLogCollection()
+		assert.False(t, team.Validate("teams/ateam", users, logsCollector))
+		assert.True(t, logsCollector.HasErrors())
+	})
+}
@@ -2,8 +2,10 @@ package entity
 
 import (
 	"fmt"
+	"maps"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/go-git/go-billy/v5"
 	"github.com/goliac-project/goliac/internal/observability"
@@ -14,10 +16,22 @@ import (
 type User struct {
 	Entity `yaml:",inline"`
 	Spec   struct {
-		GithubID string `yaml:"githubID"`
+		GithubID    string            `yaml:"githubID"`
+		Email       string            `yaml:"email,omitempty"`
+		FullName    string            `yaml:"fullName,omitempty"`
+		Manager     string            `yaml:"manager,omitempty"` // the manager (another user) name
+		Labels      map[string]string `yaml:"labels,omitempty"`
+		Status      string            `yaml:"status,omitempty"`       // "" (active) or suspended
+		RemovalDate string            `yaml:"removal_date,omitempty"` // when a suspended user is removed (YYYY-MM-DD)
 	} `yaml:"spec"`
 }
 
+const (
+	USER_STATUS_ACTIVE       = "active"
+	USER_STATUS_SUSPENDED    = "suspended"
+	USER_REMOVAL_DATE_FORMAT = "2006-01-02"
+)
+
 /*
  * NewUser reads a file and returns a User object
  * The next step is to validate the User object using the Validate method
@@ -111,9 +125,45 @@ func (u *User) Validate(filename string) error {
 		return fmt.Errorf("spec.githubID is empty for user filename %s", filename)
 	}
 
+	if u.Spec.Status != "" && u.Spec.Status != USER_STATUS_ACTIVE && u.Spec.Status != USER_STATUS_SUSPENDED {
+		return fmt.Errorf("invalid spec.status: %s for user filename %s (must be %s or %s)", u.Spec.Status, filename, USER_STATUS_ACTIVE, USER_STATUS_SUSPENDED)
+	}
+
+	if u.Spec.Manager == u.Name {
+		return fmt.Errorf("spec.manager cannot be the user itself for user filename %s", filename)
+	}
+
+	if u.Spec.RemovalDate != "" {
+		if _, err := time.Parse(USER_REMOVAL_DATE_FORMAT, u.Spec.RemovalDate); err != nil {
+			return fmt.Errorf("invalid spec.removal_date: %s for user filename %s (must be YYYY-MM-DD)", u.Spec.RemovalDate, filename)
+		}
+	}
+
 	return nil
 }
 
+/*
+ * IsSuspended tells if the user is being offboarded: a suspended user stays
+ * member of the organization (until its removal date), but is removed from the teams
+ */
+func (u *User) IsSuspended() bool {
+	return u.Spec.Status == USER_STATUS_SUSPENDED
+}
+
+/*
+ * RemovalDue tells if a suspended user reached its removal date
+ */
+func (u *User) RemovalDue(now time.Time) bool {
+	if !u.IsSuspended() || u.Spec.RemovalDate == "" {
+		return false
+	}
+	removalDate, err := time.Parse(USER_REMOVAL_DATE_FORMAT, u.Spec.RemovalDate)
+	if err != nil {
+		return false
+	}
+	return !now.Before(removalDate)
+}
+
 func (u *User) Equals(a *User) bool {
 	if u.ApiVersion != a.ApiVersion {
 		return false
@@ -127,6 +177,15 @@ func (u *User) Equals(a *User) bool {
 	if u.Spec.GithubID != a.Spec.GithubID {
 		return false
 	}
+	if u.IsSuspended() != a.IsSuspended() || u.Spec.RemovalDate != a.Spec.RemovalDate {
+		return false
+	}
+	if u.Spec.Email != a.Spec.Email || u.Spec.FullName != a.Spec.FullName || u.Spec.Manager != a.Spec.Manager {
+		return false
+	}
+	if !maps.Equal(u.Spec.Labels, a.Spec.Labels) {
+		return false
+	}
 
 	return true
 }
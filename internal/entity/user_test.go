@@ -2,6 +2,7 @@ package entity
 
 import (
 	"testing"
+	"time"
 
 	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/goliac-project/goliac/internal/observability"
@@ -123,3 +124,98 @@ func TestEqualUser(t *testing.T) {
 		assert.False(t, res)
 	})
 }
+
+func TestSuspendedUser(t *testing.T) {
+	newUser := func(status, removalDate string) *User {
+		user := &User{}
+		user.ApiVersion = "v1"
+		user.Kind = "User"
+		user.Name = "usera"
+		user.Spec.GithubID = "githubidA"
+		user.Spec.Status = status
+		user.Spec.RemovalDate = removalDate
+		return user
+	}
+
+	t.Run("happy path: suspended user with a removal date", func(t *testing.T) {
+		user := newUser("suspended", "2025-03-31")
+		assert.Nil(t, user.Validate("users/org/usera.yaml"))
+		assert.True(t, user.IsSuspended())
+		assert.False(t, user.RemovalDue(time.Date(2025, 3, 30, 23, 0, 0, 0, time.UTC)))
+		assert.True(t, user.RemovalDue(time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)))
+		assert.False(t, user.Equals(newUser("", "")))
+	})
+
+	t.Run("happy path: suspended user without removal date", func(t *testing.T) {
+		user := newUser("suspended", "")
+		assert.Nil(t, user.Validate("users/org/usera.yaml"))
+		assert.False(t, user.RemovalDue(time.Now()))
+	})
+
+	t.Run("happy path: active user", func(t *testing.T) {
+		user := newUser("active", "")
+		assert.Nil(t, user.Validate("users/org/usera.yaml"))
+		assert.False(t, user.IsSuspended())
+		assert.True(t, user.Equals(newUser("", "")))
+	})
+
+	t.Run("not happy path: invalid status", func(t *testing.T) {
+		assert.NotNil(t, newUser("gone", "").Validate("users/org/usera.yaml"))
+	})
+
+	t.Run("not happy path: invalid removal date", func(t *testing.T) {
+		assert.NotNil(t, newUser("suspended", "31/03/2025").Validate("users/org/usera.yaml"))
+	})
+}
+
+func TestUserIdentity(t *testing.T) {
+	newUser := func() *User {
+		user := &User{}
+		user.ApiVersion = "v1"
+		user.Kind = "User"
+		user.Name = "usera"
+		user.Spec.GithubID = "githubidA"
+		user.Spec.Email = "usera@company.com"
+		user.Spec.FullName = "User A"
+		user.Spec.Manager = "userb"
+		user.Spec.Labels = map[string]string{"cost-center": "rd"}
+		return user
+	}
+
+	t.Run("happy path: read the identity", func(t *testing.T) {
+		fs := memfs.New()
+		err := utils.WriteFile(fs, "users/usera.yaml", []byte(`
+apiVersion: v1
+kind: User
+name: usera
+spec:
+  githubID: githubidA
+  email: usera@company.com
+  fullName: User A
+  manager: userb
+  labels:
+    cost-center: rd
+`), 0644)
+		assert.Nil(t, err)
+		user, err := NewUser(fs, "users/usera.yaml")
+		assert.Nil(t, err)
+		assert.Nil(t, user.Validate("users/usera.yaml"))
+		assert.True(t, user.Equals(newUser()))
+	})
+
+	t.Run("happy path: different identity", func(t *testing.T) {
+		userB := newUser()
+		userB.Spec.Email = "other@company.com"
+		assert.False(t, newUser().Equals(userB))
+
+		userB = newUser()
+		userB.Spec.Labels["cost-center"] = "sales"
+		assert.False(t, newUser().Equals(userB))
+	})
+
+	t.Run("not happy path: own manager", func(t *testing.T) {
+		user := newUser()
+		user.Spec.Manager = "usera"
+		assert.NotNil(t, user.Validate("users/usera.yaml"))
+	})
+}
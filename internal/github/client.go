@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/observability"
 	"github.com/goliac-project/goliac/internal/utils"
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel"
@@ -32,8 +34,22 @@ type GitHubClient interface {
 	GetAppSlug() string
 }
 
+/*
+ * StatusError is returned by QueryGraphQLAPI and CallRestAPI
+ * when Github answers with an unexpected (non 2xx) status
+ */
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status: %s", e.Status)
+}
+
 type GitHubClientImpl struct {
 	gitHubServer    string
+	organization    string // used to label the metrics
 	appID           int64
 	installationID  int64
 	appSlug         string
@@ -43,6 +59,7 @@ type GitHubClientImpl struct {
 	httpClient      *http.Client
 	tokenExpiration time.Time
 	mu              sync.Mutex
+	etagCache       ETagCache // if not nil, the REST GET calls are conditional requests
 }
 
 type AuthorizedTransport struct {
@@ -112,11 +129,20 @@ func NewGitHubClientImpl(githubServer, organizationName string, appID int64, pri
 
 	client := &GitHubClientImpl{
 		gitHubServer: githubServer,
+		organization: organizationName,
 		appID:        appID,
 		privateKey:   privateKey,
 		patToken:     patToken,
 	}
 
+	if config.Config.GithubCacheDir != "" {
+		etagCache, err := NewFileETagCache(filepath.Join(config.Config.GithubCacheDir, "etags"), config.Config.GithubETagCacheMaxEntries)
+		if err != nil {
+			return nil, fmt.Errorf("not able to create the ETag cache: %v", err)
+		}
+		client.etagCache = etagCache
+	}
+
 	// If a personal access token is not provided, we need to find the installation ID
 	if privateKeyFile != "" {
 
@@ -165,6 +191,14 @@ func getHeaderCaseInsensitive(headers http.Header, key string) string {
 	return headers.Get(canonicalKey)
 }
 
+// recordRateLimit records the rate limit remaining (of the Goliac token) of a Github response
+func (client *GitHubClientImpl) recordRateLimit(resp *http.Response, endpointClass string) {
+	remaining, err := strconv.ParseFloat(getHeaderCaseInsensitive(resp.Header, "X-Ratelimit-Remaining"), 64)
+	if err == nil {
+		observability.GithubRateLimitRemaining.Set(remaining, client.organization, endpointClass)
+	}
+}
+
 // waitRateLimit helps dealing with rate limits
 // cf https://docs.github.com/en/rest/guides/best-practices-for-integrators?apiVersion=2022-11-28#dealing-with-rate-limits
 func waitRateLimit(resetTimeStr string) error {
@@ -263,6 +297,7 @@ func (client *GitHubClientImpl) QueryGraphQLAPI(ctx context.Context, query strin
 		goliacStats := stats.(*config.GoliacStatistics)
 		goliacStats.GithubApiCalls++
 	}
+	observability.GithubApiCallsTotal.Inc(client.organization, "graphql")
 
 	var resp *http.Response
 	if githubToken != nil {
@@ -279,12 +314,16 @@ func (client *GitHubClientImpl) QueryGraphQLAPI(ctx context.Context, query strin
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if githubToken == nil {
+		client.recordRateLimit(resp, "graphql")
+	}
 
 	if resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode == http.StatusForbidden && getHeaderCaseInsensitive(resp.Header, "X-Ratelimit-Remaining") == "0") {
 		if stats != nil {
 			goliacStats := stats.(*config.GoliacStatistics)
 			goliacStats.GithubThrottled++
 		}
+		observability.GithubThrottledTotal.Inc(client.organization, "graphql")
 
 		if getHeaderCaseInsensitive(resp.Header, "X-RateLimit-Reset") != "" {
 			// We're being rate limited. Get the reset time from the headers.
@@ -311,7 +350,7 @@ func (client *GitHubClientImpl) QueryGraphQLAPI(ctx context.Context, query strin
 			if childSpan != nil {
 				childSpan.SetStatus(codes.Error, "rate limit headers not found")
 			}
-			return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+			return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
 		}
 
 		// Retry the request.
@@ -332,7 +371,7 @@ func (client *GitHubClientImpl) QueryGraphQLAPI(ctx context.Context, query strin
 			if childSpan != nil {
 				childSpan.SetStatus(codes.Error, fmt.Sprintf("unexpected status: %s, response: %s", resp.Status, string(responseBody)))
 			}
-			return responseBody, fmt.Errorf("unexpected status: %s", resp.Status)
+			return responseBody, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
 		}
 
 		return responseBody, nil
@@ -394,6 +433,7 @@ func (client *GitHubClientImpl) CallRestAPI(ctx context.Context, endpoint, param
 		goliacStats := stats.(*config.GoliacStatistics)
 		goliacStats.GithubApiCalls++
 	}
+	observability.GithubApiCallsTotal.Inc(client.organization, "rest")
 
 	if parameters != "" {
 		urlpath = urlpath + "?" + parameters
@@ -409,6 +449,17 @@ func (client *GitHubClientImpl) CallRestAPI(ctx context.Context, endpoint, param
 	req.Header.Set("Accept", "application/vnd.github+json")
 	//	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	// conditional request (only with the Goliac token, the responses can differ with another token)
+	var cachedBody []byte
+	cacheable := client.etagCache != nil && method == "GET" && githubToken == nil
+	if cacheable {
+		if etag, body, ok := client.etagCache.Get(urlpath); ok {
+			req.Header.Set("If-None-Match", etag)
+			cachedBody = body
+		}
+	}
+
 	var resp *http.Response
 
 	if githubToken != nil {
@@ -430,12 +481,16 @@ func (client *GitHubClientImpl) CallRestAPI(ctx context.Context, endpoint, param
 		return nil, err
 	}
 	defer resp.Body.Close()
+	if githubToken == nil {
+		client.recordRateLimit(resp, "rest")
+	}
 
 	if resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode == http.StatusForbidden && getHeaderCaseInsensitive(resp.Header, "X-Ratelimit-Remaining") == "0") {
 		if stats != nil {
 			goliacStats := stats.(*config.GoliacStatistics)
 			goliacStats.GithubThrottled++
 		}
+		observability.GithubThrottledTotal.Inc(client.organization, "rest")
 
 		// We're being rate limited. Get the reset time from the headers.
 		if err := waitRateLimit(getHeaderCaseInsensitive(resp.Header, "X-RateLimit-Reset")); err != nil {
@@ -455,11 +510,20 @@ func (client *GitHubClientImpl) CallRestAPI(ctx context.Context, endpoint, param
 			}
 			return nil, err
 		}
+		if resp.StatusCode == http.StatusNotModified && cachedBody != nil {
+			return cachedBody, nil
+		}
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 			if childSpan != nil {
 				childSpan.SetStatus(codes.Error, fmt.Sprintf("unexpected status: %s", resp.Status))
 			}
-			return responseBody, fmt.Errorf("unexpected status: %s", resp.Status)
+			return responseBody, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+
+		if cacheable {
+			if etag := resp.Header.Get("ETag"); etag != "" {
+				client.etagCache.Set(urlpath, etag, responseBody)
+			}
 		}
 
 		return responseBody, nil
@@ -506,12 +570,14 @@ func (client *GitHubClientImpl) getAccessTokenForInstallation(ctx context.Contex
 		goliacStats := stats.(*config.GoliacStatistics)
 		goliacStats.GithubApiCalls++
 	}
+	observability.GithubApiCallsTotal.Inc(client.organization, "installation_token")
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return "", time.Now(), err
 	}
 	defer resp.Body.Close()
+	client.recordRateLimit(resp, "installation_token")
 
 	if resp.StatusCode != http.StatusCreated {
 		return "", time.Now(), fmt.Errorf("unexpected status: %s", resp.Status)
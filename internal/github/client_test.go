@@ -2,11 +2,14 @@ package github
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/goliac-project/goliac/internal/observability"
 )
 
 type MockRoundTripper struct {
@@ -161,6 +164,87 @@ func TestCallRestAPI(t *testing.T) {
 			t.Errorf("expected 'octocat' in the result, got %s", result)
 		}
 	})
+
+	t.Run("not happy path: unexpected status", func(t *testing.T) {
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer testServer.Close()
+
+		client := &GitHubClientImpl{
+			gitHubServer: testServer.URL,
+			httpClient:   &http.Client{},
+		}
+
+		_, err := client.CallRestAPI(context.TODO(), "/octocat", "", "GET", nil, nil)
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+			t.Errorf("expected a 404 StatusError, got %v", err)
+		}
+		if err.Error() != "unexpected status: 404 Not Found" {
+			t.Errorf("unexpected error message: %v", err)
+		}
+	})
+
+	t.Run("happy path: GET with an ETag cache", func(t *testing.T) {
+		calls := 0
+		// Create a test server
+		testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"login": "octocat"}`))
+		}))
+		defer testServer.Close()
+
+		etagCache, err := NewFileETagCache(t.TempDir(), 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// Replace the httpClient with a mock
+		client := &GitHubClientImpl{
+			gitHubServer: testServer.URL,
+			httpClient:   &http.Client{},
+			etagCache:    etagCache,
+		}
+
+		// first call: the response is cached
+		ctx := context.TODO()
+		result, err := client.CallRestAPI(ctx, "/octocat", "", "GET", nil, nil)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !strings.Contains(string(result), "octocat") {
+			t.Errorf("expected 'octocat' in the result, got %s", result)
+		}
+
+		// second call: 304 Not Modified, the cached body is returned
+		result, err = client.CallRestAPI(ctx, "/octocat", "", "GET", nil, nil)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !strings.Contains(string(result), "octocat") {
+			t.Errorf("expected 'octocat' in the result, got %s", result)
+		}
+		if calls != 2 {
+			t.Errorf("expected 2 calls, got %d", calls)
+		}
+
+		// the cache is not used with another token
+		token := "another-token"
+		result, err = client.CallRestAPI(ctx, "/octocat", "", "GET", nil, &token)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if !strings.Contains(string(result), "octocat") {
+			t.Errorf("expected 'octocat' in the result, got %s", result)
+		}
+	})
 }
 
 func TestGetHeaderCaseInsensitive(t *testing.T) {
@@ -182,3 +266,31 @@ func TestGetHeaderCaseInsensitive(t *testing.T) {
 		t.Errorf("expected '0', got %s", header)
 	}
 }
+
+func TestCallRestAPIMetrics(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4321")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"login": "octocat"}`))
+	}))
+	defer testServer.Close()
+
+	client := &GitHubClientImpl{
+		gitHubServer: testServer.URL,
+		organization: "metrics-org",
+		httpClient:   &http.Client{},
+	}
+
+	calls := observability.GithubApiCallsTotal.Value("metrics-org", "rest")
+	_, err := client.CallRestAPI(context.TODO(), "/octocat", "", "GET", nil, nil)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if observability.GithubApiCallsTotal.Value("metrics-org", "rest") != calls+1 {
+		t.Errorf("expected the rest API calls to be counted")
+	}
+	if remaining := observability.GithubRateLimitRemaining.Value("metrics-org", "rest"); remaining != 4321 {
+		t.Errorf("expected a rate limit remaining of 4321, got %v", remaining)
+	}
+}
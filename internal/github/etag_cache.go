@@ -0,0 +1,161 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+/*
+ * ETagCache keeps the ETag (and the body) of the REST GET responses,
+ * to send conditional requests (If-None-Match): a 304 Not Modified
+ * response doesn't count against the Github rate limit
+ */
+type ETagCache interface {
+	Get(key string) (string, []byte, bool) // etag, body, found
+	Set(key string, etag string, body []byte)
+}
+
+type etagCacheEntry struct {
+	ETag string `json:"etag"`
+	Body []byte `json:"body"`
+}
+
+/*
+ * FileETagCache is an ETagCache persisted on disk (one file per url),
+ * to survive restarts. It keeps at most maxEntries entries: beyond that,
+ * the least recently used entries are evicted (0: no limit)
+ */
+type FileETagCache struct {
+	dirname    string
+	maxEntries int
+	mutex      sync.Mutex
+	nbEntries  int
+}
+
+func NewFileETagCache(dirname string, maxEntries int) (*FileETagCache, error) {
+	if err := os.MkdirAll(dirname, 0700); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dirname)
+	if err != nil {
+		return nil, err
+	}
+	c := &FileETagCache{
+		dirname:    dirname,
+		maxEntries: maxEntries,
+	}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), ".") {
+			c.nbEntries++
+		}
+	}
+	c.evict()
+	return c, nil
+}
+
+func (c *FileETagCache) filename(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dirname, hex.EncodeToString(sum[:]))
+}
+
+func (c *FileETagCache) Get(key string) (string, []byte, bool) {
+	filename := c.filename(key)
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return "", nil, false
+	}
+	var entry etagCacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil || entry.ETag == "" {
+		return "", nil, false
+	}
+	// the modification time is the last access time (for the eviction)
+	now := time.Now()
+	os.Chtimes(filename, now, now)
+	return entry.ETag, entry.Body, true
+}
+
+/*
+ * evict removes the least recently used entries when there are more than
+ * maxEntries, down to 90% of maxEntries (to not scan the directory at each Set)
+ */
+func (c *FileETagCache) evict() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.maxEntries <= 0 || c.nbEntries <= c.maxEntries {
+		return
+	}
+
+	entries, err := os.ReadDir(c.dirname)
+	if err != nil {
+		logrus.Debugf("not able to evict ETag cache entries: %v", err)
+		return
+	}
+	type cacheFile struct {
+		name    string
+		modTime time.Time
+	}
+	files := make([]cacheFile, 0, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, cacheFile{name: entry.Name(), modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	keep := c.maxEntries * 9 / 10
+	for len(files) > keep {
+		os.Remove(filepath.Join(c.dirname, files[0].name))
+		files = files[1:]
+	}
+	c.nbEntries = len(files)
+}
+
+func (c *FileETagCache) Set(key string, etag string, body []byte) {
+	content, err := json.Marshal(etagCacheEntry{ETag: etag, Body: body})
+	if err != nil {
+		logrus.Debugf("not able to cache the response of %s: %v", key, err)
+		return
+	}
+	// write then rename, to never read a partially written entry
+	tmpfile, err := os.CreateTemp(c.dirname, ".tmp-*")
+	if err != nil {
+		logrus.Debugf("not able to cache the response of %s: %v", key, err)
+		return
+	}
+	_, err = tmpfile.Write(content)
+	if err1 := tmpfile.Close(); err == nil {
+		err = err1
+	}
+	newEntry := false
+	if err == nil {
+		_, statErr := os.Stat(c.filename(key))
+		newEntry = statErr != nil
+		err = os.Rename(tmpfile.Name(), c.filename(key))
+	}
+	if err != nil {
+		os.Remove(tmpfile.Name())
+		logrus.Debugf("not able to cache the response of %s: %v", key, err)
+		return
+	}
+	if newEntry {
+		c.mutex.Lock()
+		c.nbEntries++
+		c.mutex.Unlock()
+		c.evict()
+	}
+}
@@ -0,0 +1,88 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFileETagCache(t *testing.T) {
+	t.Run("happy path: set and get", func(t *testing.T) {
+		cache, err := NewFileETagCache(t.TempDir(), 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		cache.Set("/octocat", `"v1"`, []byte(`{"login": "octocat"}`))
+		etag, body, found := cache.Get("/octocat")
+		if !found || etag != `"v1"` || string(body) != `{"login": "octocat"}` {
+			t.Errorf("expected the cached entry, got %v %s %s", found, etag, body)
+		}
+
+		if _, _, found := cache.Get("/unknown"); found {
+			t.Errorf("expected no entry for /unknown")
+		}
+	})
+
+	t.Run("happy path: the least recently used entries are evicted", func(t *testing.T) {
+		dirname := t.TempDir()
+		cache, err := NewFileETagCache(dirname, 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		past := time.Now().Add(-time.Hour)
+		for i := 0; i < 10; i++ {
+			key := fmt.Sprintf("/key%d", i)
+			cache.Set(key, `"v1"`, []byte("{}"))
+			// older and older accesses
+			accessed := past.Add(-time.Duration(i) * time.Minute)
+			os.Chtimes(cache.filename(key), accessed, accessed)
+		}
+		// key9 is the least recently used, until it is read
+		if _, _, found := cache.Get("/key9"); !found {
+			t.Errorf("expected an entry for /key9")
+		}
+
+		// the 11th entry triggers the eviction (down to 9 entries)
+		cache.Set("/key10", `"v1"`, []byte("{}"))
+		entries, err := os.ReadDir(dirname)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(entries) != 9 {
+			t.Errorf("expected 9 entries, got %d", len(entries))
+		}
+		for _, key := range []string{"/key9", "/key10", "/key0"} {
+			if _, _, found := cache.Get(key); !found {
+				t.Errorf("expected an entry for %s", key)
+			}
+		}
+		if _, _, found := cache.Get("/key8"); found {
+			t.Errorf("expected /key8 to be evicted")
+		}
+	})
+
+	t.Run("happy path: the limit is applied to an existing directory", func(t *testing.T) {
+		dirname := t.TempDir()
+		cache, err := NewFileETagCache(dirname, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for i := 0; i < 5; i++ {
+			cache.Set(fmt.Sprintf("/key%d", i), `"v1"`, []byte("{}"))
+		}
+
+		if _, err := NewFileETagCache(dirname, 2); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		entries, err := os.ReadDir(dirname)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(entries) != 1 {
+			t.Errorf("expected 1 entry, got %d", len(entries))
+		}
+	})
+}
@@ -59,6 +59,15 @@ func (g *GithubBatchExecutor) RemoveUserFromOrg(ctx context.Context, logsCollect
 	})
 }
 
+func (g *GithubBatchExecutor) UpdateUserOrgRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, ghuserid string, role string) {
+	g.commands = append(g.commands, &GithubCommandUpdateUserOrgRole{
+		client:   g.client,
+		dryrun:   dryrun,
+		ghuserid: ghuserid,
+		role:     role,
+	})
+}
+
 func (g *GithubBatchExecutor) CreateTeam(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, description string, parentTeam *int, members []string) {
 	g.commands = append(g.commands, &GithubCommandCreateTeam{
 		client:      g.client,
@@ -476,6 +485,30 @@ func (g *GithubBatchExecutor) DeleteOrgCustomProperty(ctx context.Context, logsC
 	})
 }
 
+func (g *GithubBatchExecutor) AddRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, role *engine.GithubRepositoryRole) {
+	g.commands = append(g.commands, &GithubCommandAddRepositoryRole{
+		client: g.client,
+		dryrun: dryrun,
+		role:   role,
+	})
+}
+
+func (g *GithubBatchExecutor) UpdateRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, role *engine.GithubRepositoryRole) {
+	g.commands = append(g.commands, &GithubCommandUpdateRepositoryRole{
+		client: g.client,
+		dryrun: dryrun,
+		role:   role,
+	})
+}
+
+func (g *GithubBatchExecutor) DeleteRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, roleid int) {
+	g.commands = append(g.commands, &GithubCommandDeleteRepositoryRole{
+		client: g.client,
+		dryrun: dryrun,
+		roleid: roleid,
+	})
+}
+
 func (g *GithubBatchExecutor) Begin(logsCollector *observability.LogCollection, dryrun bool) {
 	g.commands = make([]GithubCommand, 0)
 }
@@ -490,7 +523,7 @@ func (g *GithubBatchExecutor) Commit(ctx context.Context, logsCollector *observa
 		c.Apply(ctx, logsCollector)
 	}
 	g.commands = make([]GithubCommand, 0)
-	return nil
+	return g.client.Commit(ctx, logsCollector, dryrun)
 }
 
 type GithubCommandAddUserToOrg struct {
@@ -575,6 +608,17 @@ func (g *GithubCommandRemoveUserFromOrg) Apply(ctx context.Context, logsCollecto
 	g.client.RemoveUserFromOrg(ctx, logsCollector, g.dryrun, g.ghuserid)
 }
 
+type GithubCommandUpdateUserOrgRole struct {
+	client   engine.ReconciliatorExecutor
+	dryrun   bool
+	ghuserid string
+	role     string
+}
+
+func (g *GithubCommandUpdateUserOrgRole) Apply(ctx context.Context, logsCollector *observability.LogCollection) {
+	g.client.UpdateUserOrgRole(ctx, logsCollector, g.dryrun, g.ghuserid, g.role)
+}
+
 type GithubCommandUpdateRepositoryRemoveTeamAccess struct {
 	client   engine.ReconciliatorExecutor
 	dryrun   bool
@@ -1012,3 +1056,33 @@ type GithubCommandDeleteOrgCustomProperty struct {
 func (g *GithubCommandDeleteOrgCustomProperty) Apply(ctx context.Context, logsCollector *observability.LogCollection) {
 	g.client.DeleteOrgCustomProperty(ctx, logsCollector, g.dryrun, g.propertyName)
 }
+
+type GithubCommandAddRepositoryRole struct {
+	client engine.ReconciliatorExecutor
+	dryrun bool
+	role   *engine.GithubRepositoryRole
+}
+
+func (g *GithubCommandAddRepositoryRole) Apply(ctx context.Context, logsCollector *observability.LogCollection) {
+	g.client.AddRepositoryRole(ctx, logsCollector, g.dryrun, g.role)
+}
+
+type GithubCommandUpdateRepositoryRole struct {
+	client engine.ReconciliatorExecutor
+	dryrun bool
+	role   *engine.GithubRepositoryRole
+}
+
+func (g *GithubCommandUpdateRepositoryRole) Apply(ctx context.Context, logsCollector *observability.LogCollection) {
+	g.client.UpdateRepositoryRole(ctx, logsCollector, g.dryrun, g.role)
+}
+
+type GithubCommandDeleteRepositoryRole struct {
+	client engine.ReconciliatorExecutor
+	dryrun bool
+	roleid int
+}
+
+func (g *GithubCommandDeleteRepositoryRole) Apply(ctx context.Context, logsCollector *observability.LogCollection) {
+	g.client.DeleteRepositoryRole(ctx, logsCollector, g.dryrun, g.roleid)
+}
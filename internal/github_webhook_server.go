@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"bytes"
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
@@ -9,16 +10,22 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"strings"
 	"time"
 
+	"github.com/goliac-project/goliac/internal/engine"
 	"github.com/sirupsen/logrus"
 )
 
-type GithubWebhookServerCallback func()
+type GithubWebhookServerCallback func(changedFiles []string) // changedFiles is nil if not known
 
 type GithubWebhookServerIssueCommentCallback func(organization, repository, prUrl, githubIdCaller, comment string, comment_id int)
 
+type GithubWebhookServerPullRequestCallback func(organization, repository, prUrl, headBranch, headSha string)
+
+type GithubWebhookServerOrgEventCallback func(event *engine.GithubOrgEvent)
+
 /*
 GithubWebhookServer is the interface for the webhook server
 It will wait for a Github webhook event and call the callback function
@@ -41,9 +48,11 @@ type GithubWebhookServerImpl struct {
 	mainBranch           string
 	callback             GithubWebhookServerCallback
 	issueCommentCallback GithubWebhookServerIssueCommentCallback
+	pullRequestCallback  GithubWebhookServerPullRequestCallback
+	orgEventCallback     GithubWebhookServerOrgEventCallback
 }
 
-func NewGithubWebhookServerImpl(httpaddr string, httpport int, webhookPath string, secret string, organization, repository, mainBranch string, callback GithubWebhookServerCallback, issueCommentCallback GithubWebhookServerIssueCommentCallback) GithubWebhookServer {
+func NewGithubWebhookServerImpl(httpaddr string, httpport int, webhookPath string, secret string, organization, repository, mainBranch string, callback GithubWebhookServerCallback, issueCommentCallback GithubWebhookServerIssueCommentCallback, pullRequestCallback GithubWebhookServerPullRequestCallback, orgEventCallback GithubWebhookServerOrgEventCallback) GithubWebhookServer {
 	return &GithubWebhookServerImpl{
 		webhookServerAddress: httpaddr,
 		webhookServerPort:    httpport,
@@ -55,6 +64,8 @@ func NewGithubWebhookServerImpl(httpaddr string, httpport int, webhookPath strin
 		mainBranch:           mainBranch,
 		callback:             callback,
 		issueCommentCallback: issueCommentCallback,
+		pullRequestCallback:  pullRequestCallback,
+		orgEventCallback:     orgEventCallback,
 	}
 }
 
@@ -87,6 +98,34 @@ type PushEvent struct {
 	Repository struct {
 		FullName string `json:"full_name"`
 	} `json:"repository"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Removed  []string `json:"removed"`
+		Modified []string `json:"modified"`
+	} `json:"commits"`
+}
+
+/*
+ * PUSH_EVENT_MAX_COMMITS is the maximum number of commits listed in a push event:
+ * above, the list of changed files is incomplete
+ */
+const PUSH_EVENT_MAX_COMMITS = 20
+
+/*
+ * changedFiles returns the files changed by the pushed commits,
+ * or nil if the list is not known (or incomplete)
+ */
+func (p *PushEvent) changedFiles() []string {
+	if len(p.Commits) == 0 || len(p.Commits) >= PUSH_EVENT_MAX_COMMITS {
+		return nil
+	}
+	files := []string{}
+	for _, c := range p.Commits {
+		files = append(files, c.Added...)
+		files = append(files, c.Removed...)
+		files = append(files, c.Modified...)
+	}
+	return files
 }
 
 func (s *GithubWebhookServerImpl) WebhookHandler(w http.ResponseWriter, r *http.Request) {
@@ -140,6 +179,10 @@ func (s *GithubWebhookServerImpl) WebhookHandler(w http.ResponseWriter, r *http.
 		s.handlePushEvent(w, body)
 	case "issue_comment":
 		s.handleIssueCommentEvent(w, body)
+	case "pull_request":
+		s.handlePullRequestEvent(w, body)
+	case "repository", "team", "membership", "member", "organization", "branch_protection_rule", "repository_ruleset":
+		s.handleOrgEvent(w, eventType, body)
 	default:
 		logrus.Debugf("Event type %s not supported", eventType)
 		w.WriteHeader(http.StatusOK)
@@ -169,7 +212,7 @@ func (s *GithubWebhookServerImpl) handlePushEvent(w http.ResponseWriter, body []
 
 	// Check if the push is to the main branch
 	if pushEvent.Ref == fmt.Sprintf("refs/heads/%s", s.mainBranch) {
-		s.callback()
+		s.callback(pushEvent.changedFiles())
 	} else {
 		http.Error(w, "Parse push event: wrong branch", http.StatusBadRequest)
 		return
@@ -224,3 +267,290 @@ type IssueCommentEvent struct {
 		Number int `json:"number"`
 	} `json:"issue"`
 }
+
+// the goal of this function is to trigger the pullRequestCallback when a PR is opened (or updated)
+// on the teams-repo, targeting the main branch
+func (s *GithubWebhookServerImpl) handlePullRequestEvent(w http.ResponseWriter, body []byte) {
+	if s.pullRequestCallback == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var pullRequestEvent PullRequestEvent
+
+	err := json.Unmarshal(body, &pullRequestEvent)
+	if err != nil {
+		http.Error(w, "Failed to parse pull request event", http.StatusBadRequest)
+		return
+	}
+
+	switch pullRequestEvent.Action {
+	case "opened", "synchronize", "reopened", "ready_for_review":
+	default:
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// we are only interested in the teams-repo
+	teamsRepository := strings.TrimSuffix(path.Base(s.repository), ".git")
+	if pullRequestEvent.Repository.FullName != fmt.Sprintf("%s/%s", s.organization, teamsRepository) ||
+		pullRequestEvent.PullRequest.Base.Ref != s.mainBranch {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// the head branch of a PR coming from a fork cannot be cloned from the teams-repo
+	if pullRequestEvent.PullRequest.Head.Repo.FullName != pullRequestEvent.Repository.FullName {
+		logrus.Debugf("PR %s comes from a fork (%s), skipping", pullRequestEvent.PullRequest.HtmlUrl, pullRequestEvent.PullRequest.Head.Repo.FullName)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	s.pullRequestCallback(
+		s.organization,
+		teamsRepository,
+		pullRequestEvent.PullRequest.HtmlUrl,
+		pullRequestEvent.PullRequest.Head.Ref,
+		pullRequestEvent.PullRequest.Head.Sha,
+	)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+type PullRequestEvent struct {
+	Action      string `json:"action"`
+	PullRequest struct {
+		Number  int    `json:"number"`
+		HtmlUrl string `json:"html_url"`
+		Head    struct {
+			Ref  string `json:"ref"`
+			Sha  string `json:"sha"`
+			Repo struct {
+				FullName string `json:"full_name"`
+			} `json:"repo"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// the goal of this function is to keep the remote cache up to date with the changes
+// done on the organization (outside of Goliac) between 2 reloads
+func (s *GithubWebhookServerImpl) handleOrgEvent(w http.ResponseWriter, eventType string, body []byte) {
+	if s.orgEventCallback == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var orgEvent OrgEvent
+
+	err := json.Unmarshal(body, &orgEvent)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to parse %s event", eventType), http.StatusBadRequest)
+		return
+	}
+
+	if orgEvent.Organization.Login != "" && orgEvent.Organization.Login != s.organization {
+		logrus.Debugf("Organization %s does not match organization %s", orgEvent.Organization.Login, s.organization)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	event := &engine.GithubOrgEvent{
+		Event:              eventType,
+		Action:             orgEvent.Action,
+		Sender:             orgEvent.Sender.Login,
+		Repository:         orgEvent.Repository.Name,
+		PreviousRepository: orgEvent.Changes.Repository.Name.From,
+		Team:               orgEvent.Team.Slug,
+		User:               orgEvent.Member.Login,
+		UserGraphqlId:      orgEvent.Member.NodeId,
+		Permission:         orgEvent.Changes.Permission.To,
+		Visibility:         orgEvent.Repository.Visibility,
+		DefaultBranch:      orgEvent.Repository.DefaultBranch,
+		BoolProperties:     map[string]bool{},
+	}
+
+	switch eventType {
+	case "organization":
+		event.User = orgEvent.Membership.User.Login
+		event.UserGraphqlId = orgEvent.Membership.User.NodeId
+		event.Role = orgEvent.Membership.Role
+	case "branch_protection_rule":
+		event.RuleName = orgEvent.Rule.Name
+	case "repository_ruleset":
+		event.RuleName = orgEvent.RepositoryRuleset.Name
+		event.OrgRuleset = orgEvent.RepositoryRuleset.SourceType == "Organization"
+	case "repository":
+		for name, value := range map[string]*bool{
+			"archived":               orgEvent.Repository.Archived,
+			"allow_auto_merge":       orgEvent.Repository.AllowAutoMerge,
+			"delete_branch_on_merge": orgEvent.Repository.DeleteBranchOnMerge,
+			"allow_update_branch":    orgEvent.Repository.AllowUpdateBranch,
+			"allow_merge_commit":     orgEvent.Repository.AllowMergeCommit,
+			"allow_squash_merge":     orgEvent.Repository.AllowSquashMerge,
+			"allow_rebase_merge":     orgEvent.Repository.AllowRebaseMerge,
+		} {
+			if value != nil {
+				event.BoolProperties[name] = *value
+			}
+		}
+	}
+
+	s.orgEventCallback(event)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+/*
+OrgEvent gathers the fields used from the repository, team, membership, member,
+organization, branch_protection_rule and repository_ruleset events
+*/
+type OrgEvent struct {
+	Action       string `json:"action"`
+	Organization struct {
+		Login string `json:"login"`
+	} `json:"organization"`
+	Sender struct {
+		Login string `json:"login"`
+	} `json:"sender"`
+	Repository struct {
+		Name                string `json:"name"`
+		Visibility          string `json:"visibility"`
+		DefaultBranch       string `json:"default_branch"`
+		Archived            *bool  `json:"archived"`
+		AllowAutoMerge      *bool  `json:"allow_auto_merge"`
+		DeleteBranchOnMerge *bool  `json:"delete_branch_on_merge"`
+		AllowUpdateBranch   *bool  `json:"allow_update_branch"`
+		AllowMergeCommit    *bool  `json:"allow_merge_commit"`
+		AllowSquashMerge    *bool  `json:"allow_squash_merge"`
+		AllowRebaseMerge    *bool  `json:"allow_rebase_merge"`
+	} `json:"repository"`
+	Changes struct {
+		Repository struct {
+			Name struct {
+				From string `json:"from"`
+			} `json:"name"`
+		} `json:"repository"`
+		Permission struct {
+			To string `json:"to"`
+		} `json:"permission"`
+	} `json:"changes"`
+	Team struct {
+		Slug string `json:"slug"`
+	} `json:"team"`
+	Member struct {
+		Login  string `json:"login"`
+		NodeId string `json:"node_id"`
+	} `json:"member"`
+	Membership struct {
+		Role string `json:"role"`
+		User struct {
+			Login  string `json:"login"`
+			NodeId string `json:"node_id"`
+		} `json:"user"`
+	} `json:"membership"`
+	Rule struct {
+		Name string `json:"name"`
+	} `json:"rule"`
+	RepositoryRuleset struct {
+		Name       string `json:"name"`
+		SourceType string `json:"source_type"`
+	} `json:"repository_ruleset"`
+}
+
+/*
+GithubWebhookOrganizationsServer dispatches the Github webhook events to the webhook
+handler of their organization (when a Goliac server manages several organizations).
+The signature of the events is checked by the organization handler
+*/
+type GithubWebhookOrganizationsServer struct {
+	webhookServerAddress string
+	webhookServerPort    int
+	webhookPath          string
+	server               *http.Server
+	handlers             map[string]http.HandlerFunc // organization -> handler
+}
+
+func NewGithubWebhookOrganizationsServer(httpaddr string, httpport int, webhookPath string, handlers map[string]http.HandlerFunc) GithubWebhookServer {
+	return &GithubWebhookOrganizationsServer{
+		webhookServerAddress: httpaddr,
+		webhookServerPort:    httpport,
+		webhookPath:          webhookPath,
+		server:               nil,
+		handlers:             handlers,
+	}
+}
+
+func (s *GithubWebhookOrganizationsServer) Start() error {
+	s.server = &http.Server{
+		Addr: fmt.Sprintf("%s:%d", s.webhookServerAddress, s.webhookServerPort),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(s.webhookPath, s.WebhookHandler)
+	s.server.Handler = mux
+
+	if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
+
+func (s *GithubWebhookOrganizationsServer) Shutdown() error {
+	ctx, cancel := context.WithTimeout(context.TODO(), 2*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+/*
+WebhookOrganizationEvent gathers the fields used to find the organization of an event
+*/
+type WebhookOrganizationEvent struct {
+	Organization struct {
+		Login string `json:"login"`
+	} `json:"organization"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+func (s *GithubWebhookOrganizationsServer) WebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Error reading body", http.StatusInternalServerError)
+		return
+	}
+	defer r.Body.Close()
+
+	var event WebhookOrganizationEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, "Failed to parse event", http.StatusBadRequest)
+		return
+	}
+
+	organization := event.Organization.Login
+	if organization == "" {
+		organization, _, _ = strings.Cut(event.Repository.FullName, "/")
+	}
+
+	handler, ok := s.handlers[organization]
+	if !ok {
+		logrus.Debugf("Organization %s is not managed by Goliac", organization)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	handler(w, r)
+}
@@ -4,11 +4,14 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/goliac-project/goliac/internal/engine"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,13 +20,13 @@ func TestWebhookHandler(t *testing.T) {
 	t.Run("happy path: test ping webhook", func(t *testing.T) {
 		callbackreceived := false
 		issueCommentCallbackReceived := false
-		callback := func() {
+		callback := func(changedFiles []string) {
 			callbackreceived = true
 		}
 		issueCommentCallback := func(organization, repository, prUrl, githubIdCaller, comment string, comment_id int) {
 			issueCommentCallbackReceived = true
 		}
-		wh := NewGithubWebhookServerImpl("localhost", 8080, "/web", "secret", "org", "teams-repo", "main", callback, issueCommentCallback).(*GithubWebhookServerImpl)
+		wh := NewGithubWebhookServerImpl("localhost", 8080, "/web", "secret", "org", "teams-repo", "main", callback, issueCommentCallback, nil, nil).(*GithubWebhookServerImpl)
 
 		body := `{
 			"zen": "testing",
@@ -51,13 +54,13 @@ func TestWebhookHandler(t *testing.T) {
 	t.Run("happy path: test pull webhook", func(t *testing.T) {
 		callbackreceived := false
 		issueCommentCallbackReceived := false
-		callback := func() {
+		callback := func(changedFiles []string) {
 			callbackreceived = true
 		}
 		issueCommentCallback := func(organization, repository, prUrl, githubIdCaller, comment string, comment_id int) {
 			issueCommentCallbackReceived = true
 		}
-		wh := NewGithubWebhookServerImpl("localhost", 8080, "/web", "secret", "org", "teams-repo", "main", callback, issueCommentCallback).(*GithubWebhookServerImpl)
+		wh := NewGithubWebhookServerImpl("localhost", 8080, "/web", "secret", "org", "teams-repo", "main", callback, issueCommentCallback, nil, nil).(*GithubWebhookServerImpl)
 
 		body := `{
 			"ref": "refs/heads/main",
@@ -84,16 +87,169 @@ func TestWebhookHandler(t *testing.T) {
 		assert.Equal(t, false, issueCommentCallbackReceived)
 	})
 
+	t.Run("happy path: test push webhook with the changed files", func(t *testing.T) {
+		var receivedFiles []string
+		callback := func(changedFiles []string) {
+			receivedFiles = changedFiles
+		}
+		wh := NewGithubWebhookServerImpl("localhost", 8080, "/web", "secret", "org", "teams-repo", "main", callback, nil, nil, nil).(*GithubWebhookServerImpl)
+
+		body := `{
+			"ref": "refs/heads/main",
+			"repository": {
+				"full_name": "org/teams-repo"
+			},
+			"commits": [
+				{
+					"added": ["teams/team1/repo2.yaml"],
+					"removed": [],
+					"modified": ["teams/team1/team.yaml"]
+				},
+				{
+					"added": [],
+					"removed": ["rulesets/default.yaml"],
+					"modified": []
+				}
+			]
+		}`
+
+		bodyReader := strings.NewReader(body)
+		req := httptest.NewRequest("POST", "/webhook", bodyReader)
+		sign := hmac.New(sha256.New, []byte("secret"))
+		sign.Write([]byte(body))
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(sign.Sum(nil)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", "push")
+
+		w := httptest.NewRecorder()
+		wh.WebhookHandler(w, req)
+
+		resp := w.Result()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"teams/team1/repo2.yaml", "teams/team1/team.yaml", "rulesets/default.yaml"}, receivedFiles)
+	})
+
+	t.Run("happy path: test pull_request webhook", func(t *testing.T) {
+		callback := func(changedFiles []string) {}
+		issueCommentCallback := func(organization, repository, prUrl, githubIdCaller, comment string, comment_id int) {}
+		pullRequestCallbackReceived := false
+		var receivedRepository, receivedPrUrl, receivedBranch, receivedSha string
+		pullRequestCallback := func(organization, repository, prUrl, headBranch, headSha string) {
+			pullRequestCallbackReceived = true
+			receivedRepository = repository
+			receivedPrUrl = prUrl
+			receivedBranch = headBranch
+			receivedSha = headSha
+		}
+		wh := NewGithubWebhookServerImpl("localhost", 8080, "/web", "secret", "org", "https://github.com/org/teams-repo", "main", callback, issueCommentCallback, pullRequestCallback, nil).(*GithubWebhookServerImpl)
+
+		body := `{
+			"action": "synchronize",
+			"pull_request": {
+				"number": 12,
+				"html_url": "https://github.com/org/teams-repo/pull/12",
+				"head": {
+					"ref": "add_team",
+					"sha": "abcdef",
+					"repo": {
+						"full_name": "org/teams-repo"
+					}
+				},
+				"base": {
+					"ref": "main"
+				}
+			},
+			"repository": {
+				"full_name": "org/teams-repo"
+			}
+		}`
+
+		bodyReader := strings.NewReader(body)
+		req := httptest.NewRequest("POST", "/webhook", bodyReader)
+		sign := hmac.New(sha256.New, []byte("secret"))
+		sign.Write([]byte(body))
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(sign.Sum(nil)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", "pull_request")
+
+		w := httptest.NewRecorder()
+		wh.WebhookHandler(w, req)
+
+		resp := w.Result()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, true, pullRequestCallbackReceived)
+		assert.Equal(t, "teams-repo", receivedRepository)
+		assert.Equal(t, "https://github.com/org/teams-repo/pull/12", receivedPrUrl)
+		assert.Equal(t, "add_team", receivedBranch)
+		assert.Equal(t, "abcdef", receivedSha)
+	})
+
+	t.Run("not happy path: pull_request webhook from a fork or another repository", func(t *testing.T) {
+		callback := func(changedFiles []string) {}
+		issueCommentCallback := func(organization, repository, prUrl, githubIdCaller, comment string, comment_id int) {}
+		pullRequestCallbackReceived := false
+		pullRequestCallback := func(organization, repository, prUrl, headBranch, headSha string) {
+			pullRequestCallbackReceived = true
+		}
+		wh := NewGithubWebhookServerImpl("localhost", 8080, "/web", "secret", "org", "teams-repo", "main", callback, issueCommentCallback, pullRequestCallback, nil).(*GithubWebhookServerImpl)
+
+		for _, fixture := range []struct {
+			action   string
+			repo     string
+			headRepo string
+		}{
+			{"opened", "org/teams-repo", "someone/teams-repo"},
+			{"opened", "org/another-repo", "org/another-repo"},
+			{"closed", "org/teams-repo", "org/teams-repo"},
+		} {
+			body := fmt.Sprintf(`{
+				"action": "%s",
+				"pull_request": {
+					"html_url": "https://github.com/%s/pull/12",
+					"head": {
+						"ref": "add_team",
+						"sha": "abcdef",
+						"repo": {
+							"full_name": "%s"
+						}
+					},
+					"base": {
+						"ref": "main"
+					}
+				},
+				"repository": {
+					"full_name": "%s"
+				}
+			}`, fixture.action, fixture.repo, fixture.headRepo, fixture.repo)
+
+			bodyReader := strings.NewReader(body)
+			req := httptest.NewRequest("POST", "/webhook", bodyReader)
+			sign := hmac.New(sha256.New, []byte("secret"))
+			sign.Write([]byte(body))
+			req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(sign.Sum(nil)))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-GitHub-Event", "pull_request")
+
+			w := httptest.NewRecorder()
+			wh.WebhookHandler(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		}
+		assert.Equal(t, false, pullRequestCallbackReceived)
+	})
+
 	t.Run("not happy path: unsigned webhook", func(t *testing.T) {
 		callbackreceived := false
 		issueCommentCallbackReceived := false
-		callback := func() {
+		callback := func(changedFiles []string) {
 			callbackreceived = true
 		}
 		issueCommentCallback := func(organization, repository, prUrl, githubIdCaller, comment string, comment_id int) {
 			issueCommentCallbackReceived = true
 		}
-		wh := NewGithubWebhookServerImpl("localhost", 8080, "/web", "secret", "org", "teams-repo", "main", callback, issueCommentCallback).(*GithubWebhookServerImpl)
+		wh := NewGithubWebhookServerImpl("localhost", 8080, "/web", "secret", "org", "teams-repo", "main", callback, issueCommentCallback, nil, nil).(*GithubWebhookServerImpl)
 
 		body := `{
 			"zen": "testing",
@@ -115,4 +271,173 @@ func TestWebhookHandler(t *testing.T) {
 		assert.Equal(t, false, issueCommentCallbackReceived)
 	})
 
+	t.Run("happy path: test repository organization webhook", func(t *testing.T) {
+		var received *engine.GithubOrgEvent
+		orgEventCallback := func(event *engine.GithubOrgEvent) {
+			received = event
+		}
+		wh := NewGithubWebhookServerImpl("localhost", 8080, "/web", "secret", "org", "teams-repo", "main", func([]string) {}, nil, nil, orgEventCallback).(*GithubWebhookServerImpl)
+
+		body := `{
+			"action": "edited",
+			"repository": {
+				"name": "repo1",
+				"visibility": "public",
+				"default_branch": "main",
+				"archived": false,
+				"allow_merge_commit": true
+			},
+			"organization": {
+				"login": "org"
+			},
+			"sender": {
+				"login": "user1"
+			}
+		}`
+
+		bodyReader := strings.NewReader(body)
+		req := httptest.NewRequest("POST", "/webhook", bodyReader)
+		sign := hmac.New(sha256.New, []byte("secret"))
+		sign.Write([]byte(body))
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(sign.Sum(nil)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", "repository")
+
+		w := httptest.NewRecorder()
+		wh.WebhookHandler(w, req)
+
+		resp := w.Result()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotNil(t, received)
+		assert.Equal(t, "repository", received.Event)
+		assert.Equal(t, "edited", received.Action)
+		assert.Equal(t, "user1", received.Sender)
+		assert.Equal(t, "repo1", received.Repository)
+		assert.Equal(t, "public", received.Visibility)
+		assert.Equal(t, map[string]bool{"archived": false, "allow_merge_commit": true}, received.BoolProperties)
+	})
+
+	t.Run("happy path: test organization ruleset webhook", func(t *testing.T) {
+		var received *engine.GithubOrgEvent
+		orgEventCallback := func(event *engine.GithubOrgEvent) {
+			received = event
+		}
+		wh := NewGithubWebhookServerImpl("localhost", 8080, "/web", "secret", "org", "teams-repo", "main", func([]string) {}, nil, nil, orgEventCallback).(*GithubWebhookServerImpl)
+
+		body := `{
+			"action": "deleted",
+			"repository_ruleset": {
+				"name": "default",
+				"source_type": "Organization"
+			},
+			"organization": {
+				"login": "org"
+			}
+		}`
+
+		bodyReader := strings.NewReader(body)
+		req := httptest.NewRequest("POST", "/webhook", bodyReader)
+		sign := hmac.New(sha256.New, []byte("secret"))
+		sign.Write([]byte(body))
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(sign.Sum(nil)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", "repository_ruleset")
+
+		w := httptest.NewRecorder()
+		wh.WebhookHandler(w, req)
+
+		resp := w.Result()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotNil(t, received)
+		assert.Equal(t, "default", received.RuleName)
+		assert.Equal(t, true, received.OrgRuleset)
+	})
+
+	t.Run("happy path: test organization webhook of another organization", func(t *testing.T) {
+		orgEventCallbackReceived := false
+		orgEventCallback := func(event *engine.GithubOrgEvent) {
+			orgEventCallbackReceived = true
+		}
+		wh := NewGithubWebhookServerImpl("localhost", 8080, "/web", "secret", "org", "teams-repo", "main", func([]string) {}, nil, nil, orgEventCallback).(*GithubWebhookServerImpl)
+
+		body := `{
+			"action": "member_removed",
+			"membership": {
+				"user": {
+					"login": "user1"
+				}
+			},
+			"organization": {
+				"login": "another-org"
+			}
+		}`
+
+		bodyReader := strings.NewReader(body)
+		req := httptest.NewRequest("POST", "/webhook", bodyReader)
+		sign := hmac.New(sha256.New, []byte("secret"))
+		sign.Write([]byte(body))
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(sign.Sum(nil)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-GitHub-Event", "organization")
+
+		w := httptest.NewRecorder()
+		wh.WebhookHandler(w, req)
+
+		resp := w.Result()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, false, orgEventCallbackReceived)
+	})
+}
+
+func TestWebhookOrganizationsHandler(t *testing.T) {
+	receivedBy := ""
+	receivedBody := ""
+	handler := func(organization string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			receivedBy = organization
+			body, _ := io.ReadAll(r.Body)
+			receivedBody = string(body)
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}
+	wh := NewGithubWebhookOrganizationsServer("localhost", 8080, "/web", map[string]http.HandlerFunc{
+		"org1": handler("org1"),
+		"org2": handler("org2"),
+	}).(*GithubWebhookOrganizationsServer)
+
+	send := func(body string) int {
+		receivedBy = ""
+		receivedBody = ""
+		req := httptest.NewRequest("POST", "/web", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		wh.WebhookHandler(w, req)
+		return w.Result().StatusCode
+	}
+
+	t.Run("happy path: dispatched by organization", func(t *testing.T) {
+		body := `{"action": "deleted", "organization": {"login": "org2"}, "repository": {"full_name": "org2/repo1"}}`
+		assert.Equal(t, http.StatusAccepted, send(body))
+		assert.Equal(t, "org2", receivedBy)
+		assert.Equal(t, body, receivedBody)
+	})
+
+	t.Run("happy path: dispatched by repository", func(t *testing.T) {
+		body := `{"ref": "refs/heads/main", "repository": {"full_name": "org1/teams-repo"}}`
+		assert.Equal(t, http.StatusAccepted, send(body))
+		assert.Equal(t, "org1", receivedBy)
+	})
+
+	t.Run("happy path: organization not managed", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send(`{"organization": {"login": "org3"}}`))
+		assert.Equal(t, "", receivedBy)
+	})
+
+	t.Run("not happy path: invalid event", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send(`not json`))
+		assert.Equal(t, "", receivedBy)
+	})
 }
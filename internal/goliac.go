@@ -3,6 +3,7 @@ package internal
 import (
 	"context"
 	"fmt"
+	"maps"
 	"net/url"
 	"path"
 	"path/filepath"
@@ -41,7 +42,9 @@ type Goliac interface {
 
 	// will run and apply the reconciliation,
 	// it returns an error if something went wrong, and a detailed list of errors and warnings
-	Apply(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, dryrun bool, repositoryUrl, branch string) *engine.UnmanagedResources
+	// if only is not empty, only these resources (repo:<name>, team:<name>, ruleset:<name>)
+	// and their dependencies are reconciled
+	Apply(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, dryrun bool, repositoryUrl, branch string, only []string) *engine.UnmanagedResources
 
 	// will clone run the user-plugin to sync users, and will commit to the team repository, return true if a change was done
 	UsersUpdate(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, repositoryUrl, branch string, dryrun bool, force bool) bool
@@ -49,11 +52,38 @@ type Goliac interface {
 	// flush remote cache
 	FlushCache()
 
+	// update the remote cache from a Github organization webhook event,
+	// return true if the event is a change made outside of Goliac
+	HandleOrgEvent(ctx context.Context, event *engine.GithubOrgEvent) bool
+
 	ExternalCreateRepository(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, githubToken, newRepositoryName, team, visibility, newRepositorydefaultBranch string, repositoryUrl, branch string)
 
+	// generate the manifests of unmanaged resources (repo:<name>, team:<name>, user:<githubid>, ruleset:<name>)
+	// and open a PR on the teams repository (on behalf of githubToken, or of Goliac if empty). Return the PR url
+	Adopt(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, githubToken string, resources []string, ownerTeam string, repositoryUrl, branch string) string
+
+	// convert the repositories branch protections into rulesets and open a PR on the teams repository. Return the PR url
+	MigrateBranchProtections(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, repositoryUrl, branch string, dryrun bool) string
+
+	// dryrun reconciliation of a (PR) branch of the teams repository against the (cached) Github organization
+	// it doesn't change the currently loaded teams repository. The changes are reported in logsCollector
+	PlanBranch(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, repositoryUrl, mainBranch, branch string) *engine.UnmanagedResources
+
+	// compute the effective access of the users on the repositories, from the teams repository
+	// (source "local": the desired state) or from the Github organization (source "remote": the actual state).
+	// If fs is nil, the currently loaded teams repository is used (i.e. by the server)
+	AccessReport(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, repositoryUrl, branch string, source string) []*engine.AccessGrant
+
+	// explain all the paths granting access to a repository to a user (github login or Goliac username),
+	// in the teams repository and in the Github organization (including the grants not managed by Goliac)
+	ExplainAccess(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, repositoryUrl, branch string, user string, repository string) *engine.AccessExplanation
+
 	GetLocal() engine.GoliacLocalResources
 	GetRemote() engine.GoliacRemoteResources
 
+	// a copy of the users and teams of the loaded teams repository (safe to use while the organization is being applied)
+	LocalUsersAndTeams() (map[string]*entity.User, map[string]*entity.Team)
+
 	// used by the Github http auth endpoints to validate the token
 	GetRemoteClient() github.GitHubClient
 }
@@ -67,12 +97,23 @@ type GoliacImpl struct {
 	feedback              observability.RemoteObservability // mostly used for UI progressbar
 	actionMutex           sync.Mutex
 	cacheDirtyAfterAction bool
+	organization          string
+	userSyncPlugins       map[string]engine.UserSyncPlugin // usersync plugins bound to the organization
 }
 
 func NewGoliacImpl() (Goliac, error) {
+	return NewGoliacImplForOrganization(config.Config.GithubAppOrganization, nil)
+}
+
+/*
+ * NewGoliacImplForOrganization returns a Goliac instance managing a Github organization
+ * (a Goliac server can manage several organizations, each one with its own Goliac instance).
+ * extraUserSyncPlugins are usersync plugins only available to this organization
+ */
+func NewGoliacImplForOrganization(organization string, extraUserSyncPlugins map[string]engine.UserSyncPlugin) (Goliac, error) {
 	remoteGithubClient, err := github.NewGitHubClientImpl(
 		config.Config.GithubServer,
-		config.Config.GithubAppOrganization,
+		organization,
 		config.Config.GithubAppID,
 		config.Config.GithubAppPrivateKeyFile,
 		config.Config.GithubPersonalAccessToken,
@@ -83,7 +124,7 @@ func NewGoliacImpl() (Goliac, error) {
 
 	localGithubClient, err := github.NewGitHubClientImpl(
 		config.Config.GithubServer,
-		config.Config.GithubAppOrganization,
+		organization,
 		config.Config.GithubAppID,
 		config.Config.GithubAppPrivateKeyFile,
 		config.Config.GithubPersonalAccessToken,
@@ -95,13 +136,24 @@ func NewGoliacImpl() (Goliac, error) {
 	local := engine.NewGoliacLocalImpl()
 	remote := engine.NewGoliacRemoteImpl(
 		remoteGithubClient,
-		config.Config.GithubAppOrganization,
+		organization,
 		true,
 		true,
 		true,
 	)
 
-	usersync.InitPlugins(remoteGithubClient)
+	if config.Config.GithubCacheDir != "" {
+		stateCache, err := engine.NewFileRemoteStateCache(filepath.Join(config.Config.GithubCacheDir, "state"))
+		if err != nil {
+			return nil, fmt.Errorf("not able to create the Github cache directory: %v", err)
+		}
+		remote.SetRemoteStateCache(stateCache)
+	}
+
+	userSyncPlugins := usersync.NewPlugins(remoteGithubClient, organization)
+	for name, plugin := range extraUserSyncPlugins {
+		userSyncPlugins[name] = plugin
+	}
 
 	return &GoliacImpl{
 		local:                 local,
@@ -112,13 +164,50 @@ func NewGoliacImpl() (Goliac, error) {
 		feedback:              nil,
 		cacheDirtyAfterAction: false,
 		actionMutex:           sync.Mutex{},
+		organization:          organization,
+		userSyncPlugins:       userSyncPlugins,
 	}, nil
 }
 
+/*
+ * getUserSyncPlugin returns the usersync plugin bound to the organization,
+ * or else the globally registered one
+ */
+func (g *GoliacImpl) getUserSyncPlugin(pluginname string) (engine.UserSyncPlugin, bool) {
+	if plugin, found := g.userSyncPlugins[pluginname]; found {
+		return plugin, true
+	}
+	return engine.GetUserSyncPlugin(pluginname)
+}
+
 func (g *GoliacImpl) GetLocal() engine.GoliacLocalResources {
 	return g.local
 }
 
+/*
+ * LocalUsersAndTeams returns a copy of the users and teams of the loaded teams
+ * repository, taken under the actionMutex (the teams repository is reloaded at each apply)
+ */
+func (g *GoliacImpl) LocalUsersAndTeams() (map[string]*entity.User, map[string]*entity.Team) {
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	users := make(map[string]*entity.User)
+	for name, user := range g.local.Users() {
+		u := *user
+		u.Spec.Labels = maps.Clone(user.Spec.Labels)
+		users[name] = &u
+	}
+	teams := make(map[string]*entity.Team)
+	for name, team := range g.local.Teams() {
+		t := *team
+		t.Spec.Owners = append([]string{}, team.Spec.Owners...)
+		t.Spec.Members = append([]string{}, team.Spec.Members...)
+		teams[name] = &t
+	}
+	return users, teams
+}
+
 func (g *GoliacImpl) GetRemote() engine.GoliacRemoteResources {
 	return g.remote
 }
@@ -145,6 +234,10 @@ func (g *GoliacImpl) FlushCache() {
 	g.remote.FlushCache()
 }
 
+func (g *GoliacImpl) HandleOrgEvent(ctx context.Context, event *engine.GithubOrgEvent) bool {
+	return g.remote.HandleOrgEvent(ctx, event)
+}
+
 func (g *GoliacImpl) ExternalCreateRepository(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, githubToken, newRepositoryName, team, visibility, newRepositoryDefaultBranch string, repositoryUrl, branch string) {
 
 	// we need to lock the actionMutex to avoid concurrent actions
@@ -169,12 +262,7 @@ func (g *GoliacImpl) ExternalCreateRepository(ctx context.Context, logsCollector
 		logsCollector.AddError(fmt.Errorf("team %s not found", team))
 		return
 	}
-	directoryPath := lTeam.Name
-	for lTeam.ParentTeam != nil {
-		directoryPath = path.Join(*lTeam.ParentTeam, directoryPath)
-		lTeam = lTeams[*lTeam.ParentTeam]
-	}
-	directoryPath = path.Join("teams", directoryPath)
+	directoryPath := localTeamDirectory(lTeams, team)
 
 	if g.local.Repositories()[newRepositoryName] != nil {
 		logsCollector.AddError(fmt.Errorf("repository %s already exists", newRepositoryName))
@@ -285,7 +373,369 @@ func (g *GoliacImpl) ExternalCreateRepository(ctx context.Context, logsCollector
 	g.cacheDirtyAfterAction = true
 }
 
-func (g *GoliacImpl) Apply(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, dryrun bool, repositoryUrl, branch string) *engine.UnmanagedResources {
+/*
+ * Adopt generates the manifests of currently unmanaged Github resources
+ * (using the scaffold logic) and opens a pull request on the teams repository.
+ * If ownerTeam is empty, orphan repositories are attached to the admin team.
+ */
+func (g *GoliacImpl) Adopt(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, githubToken string, resources []string, ownerTeam string, repositoryUrl, branch string) string {
+	if len(resources) == 0 {
+		logsCollector.AddError(fmt.Errorf("no resource to adopt"))
+		return ""
+	}
+	toAdopt := make([]*AdoptResource, 0, len(resources))
+	for _, r := range resources {
+		res, err := ParseAdoptResource(r)
+		if err != nil {
+			logsCollector.AddError(err)
+			return ""
+		}
+		toAdopt = append(toAdopt, res)
+	}
+
+	orgname, reponame, err := utils.ExtractOrgRepo(repositoryUrl)
+	if err != nil {
+		logsCollector.AddError(fmt.Errorf("error when extracting org and repo: %v", err))
+		return ""
+	}
+
+	// we need to lock the actionMutex to avoid concurrent actions
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	g.loadAndValidateGoliacOrganization(ctx, fs, repositoryUrl, branch, logsCollector)
+	if logsCollector.HasErrors() {
+		return ""
+	}
+	g.local.Close(fs)
+
+	err = g.remote.Load(ctx, false)
+	if err != nil {
+		logsCollector.AddError(fmt.Errorf("error when loading data from Github: %v", err))
+		return ""
+	}
+
+	if ownerTeam == "" {
+		ownerTeam = g.repoconfig.AdminTeam
+	}
+
+	manifests := generateAdoptManifests(ctx, g.remote, g.local, toAdopt, ownerTeam, logsCollector)
+	if logsCollector.HasErrors() {
+		return ""
+	}
+
+	if githubToken == "" {
+		githubToken, err = g.localGithubClient.GetAccessToken(ctx)
+		if err != nil {
+			logsCollector.AddError(fmt.Errorf("error when getting access token: %v", err))
+			return ""
+		}
+	}
+
+	// clone the goliac-teams repository
+	// and create a PR on behalf of the githubToken
+	tmpLocal := engine.NewGoliacLocalImpl()
+	err = tmpLocal.Clone(fs, githubToken, repositoryUrl, branch)
+	if err != nil {
+		logsCollector.AddError(fmt.Errorf("error when cloning the repository: %v", err))
+		return ""
+	}
+	defer tmpLocal.Close(fs)
+
+	pr, err := tmpLocal.UpdateFilesViaPullRequest(
+		ctx,
+		engine.NewLocalGithubClientImpl(ctx, githubToken),
+		manifests,
+		orgname,
+		reponame,
+		githubToken,
+		branch,
+		fmt.Sprintf("adopt_resources_%d", time.Now().Unix()),
+		fmt.Sprintf("Adopting %s", strings.Join(resources, ", ")),
+	)
+	if err != nil {
+		logsCollector.AddError(fmt.Errorf("error when creating the adoption PR: %v", err))
+		return ""
+	}
+
+	return pr.GetHTMLURL()
+}
+
+/*
+ * MigrateBranchProtections rewrites the repositories definitions, replacing
+ * branch protections by equivalent rulesets, and opens a pull request on the
+ * teams repository (so the next apply swaps both at once).
+ */
+func (g *GoliacImpl) MigrateBranchProtections(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, repositoryUrl, branch string, dryrun bool) string {
+	orgname, reponame, err := utils.ExtractOrgRepo(repositoryUrl)
+	if err != nil {
+		logsCollector.AddError(fmt.Errorf("error when extracting org and repo: %v", err))
+		return ""
+	}
+
+	accessToken := ""
+	if strings.HasPrefix(repositoryUrl, "https://") {
+		accessToken, err = g.localGithubClient.GetAccessToken(ctx)
+		if err != nil {
+			logsCollector.AddError(fmt.Errorf("error when getting access token: %v", err))
+			return ""
+		}
+	}
+
+	tmpLocal := engine.NewGoliacLocalImpl()
+	err = tmpLocal.Clone(fs, accessToken, repositoryUrl, branch)
+	if err != nil {
+		logsCollector.AddError(fmt.Errorf("error when cloning the repository: %v", err))
+		return ""
+	}
+	defer tmpLocal.Close(fs)
+
+	tmpLocal.LoadAndValidate(logsCollector)
+	if logsCollector.HasErrors() {
+		return ""
+	}
+
+	files := tmpLocal.MigrateBranchProtections(logsCollector)
+	if logsCollector.HasErrors() {
+		return ""
+	}
+	if len(files) == 0 {
+		logsCollector.AddInfo(map[string]interface{}{"dryrun": dryrun, "command": "migrate_branch_protections"}, "no branch protection to migrate")
+		return ""
+	}
+	for filename := range files {
+		logsCollector.AddInfo(map[string]interface{}{"dryrun": dryrun, "command": "migrate_branch_protections"}, "repository file: %s", filename)
+	}
+	if dryrun {
+		return ""
+	}
+
+	pr, err := tmpLocal.UpdateFilesViaPullRequest(
+		ctx,
+		engine.NewLocalGithubClientImpl(ctx, accessToken),
+		files,
+		orgname,
+		reponame,
+		accessToken,
+		branch,
+		fmt.Sprintf("migrate_branch_protections_%d", time.Now().Unix()),
+		"Migrating branch protections to rulesets",
+	)
+	if err != nil {
+		logsCollector.AddError(fmt.Errorf("error when creating the migration PR: %v", err))
+		return ""
+	}
+
+	return pr.GetHTMLURL()
+}
+
+/*
+ * PlanBranch clones a branch of the teams repository (usually the head of a PR),
+ * and runs a dryrun reconciliation against the Github remote (from the cache if possible).
+ * The current local state (loaded from the main branch) is not modified.
+ */
+func (g *GoliacImpl) PlanBranch(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, repositoryUrl, mainBranch, branch string) *engine.UnmanagedResources {
+	u, err := url.Parse(repositoryUrl)
+	if err != nil {
+		logsCollector.AddError(fmt.Errorf("failed to parse %s: %v", repositoryUrl, err))
+		return nil
+	}
+	teamreponame := strings.TrimSuffix(path.Base(u.Path), filepath.Ext(path.Base(u.Path)))
+
+	accessToken := ""
+	if strings.HasPrefix(repositoryUrl, "https://") {
+		accessToken, err = g.localGithubClient.GetAccessToken(ctx)
+		if err != nil {
+			logsCollector.AddError(fmt.Errorf("error when getting access token: %v", err))
+			return nil
+		}
+	}
+
+	tmpLocal := engine.NewGoliacLocalImpl()
+	err = tmpLocal.Clone(fs, accessToken, repositoryUrl, branch)
+	if err != nil {
+		logsCollector.AddError(fmt.Errorf("unable to clone: %v", err))
+		return nil
+	}
+	defer tmpLocal.Close(fs)
+
+	tmpLocal.LoadAndValidate(logsCollector)
+	if logsCollector.HasErrors() {
+		return nil
+	}
+	repoconfig := tmpLocal.RepoConfig()
+	if repoconfig == nil {
+		logsCollector.AddError(fmt.Errorf("unable to read goliac.yaml config file"))
+		return nil
+	}
+
+	// the apply loop can be reloading the same remote
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	err = g.remote.Load(ctx, false)
+	if err != nil {
+		logsCollector.AddError(fmt.Errorf("error when loading data from Github: %v", err))
+		return nil
+	}
+
+	// no executor: nothing will be sent to Github
+	reconciliator := engine.NewGoliacReconciliatorImpl(g.remote.IsEnterprise(), nil, repoconfig)
+	isEnterprise := g.remote.IsEnterprise()
+	localDatasource := engine.NewGoliacReconciliatorDatasourceLocalForOrganization(g.organization, tmpLocal, teamreponame, mainBranch, isEnterprise, repoconfig, g.localGithubClient.GetAppSlug())
+	remoteDataSource := engine.NewGoliacReconciliatorDatasourceRemote(g.remote)
+	unmanaged, _, _, err := reconciliator.Reconciliate(
+		ctx,
+		logsCollector,
+		localDatasource,
+		remoteDataSource,
+		isEnterprise,
+		true,
+		repoconfig.Features.ManageGithubEnvAndVariables,
+		repoconfig.Features.ManageGithubAutolinks,
+		repoconfig.Features.ManageOrgCustomProperties,
+	)
+	if err != nil {
+		logsCollector.AddError(fmt.Errorf("error when reconciliating: %v", err))
+		return nil
+	}
+
+	return unmanaged
+}
+
+/*
+ * AccessReport computes the effective access of the users on the repositories
+ * (see engine.ComputeAccessReport), either from the teams repository (source "local")
+ * or from the Github organization (source "remote").
+ * If fs is nil, the currently loaded teams repository is used
+ */
+func (g *GoliacImpl) AccessReport(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, repositoryUrl, branch string, source string) []*engine.AccessGrant {
+	if source != "local" && source != "remote" {
+		logsCollector.AddError(fmt.Errorf("unknown source %s (expected local or remote)", source))
+		return nil
+	}
+
+	// the loaded teams repository is replaced by an apply
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	if fs != nil {
+		g.loadAndValidateGoliacOrganization(ctx, fs, repositoryUrl, branch, logsCollector)
+		if logsCollector.HasErrors() {
+			return nil
+		}
+		defer g.local.Close(fs)
+	}
+
+	datasource, collaborators, err := g.accessDatasource(ctx, repositoryUrl, branch, source)
+	if err != nil {
+		logsCollector.AddError(err)
+		return nil
+	}
+
+	report, err := engine.ComputeAccessReport(datasource, collaborators, g.localUsernames())
+	if err != nil {
+		logsCollector.AddError(fmt.Errorf("error when computing the access report: %v", err))
+		return nil
+	}
+	return report
+}
+
+/*
+ * ExplainAccess returns all the paths granting access to a repository to a user
+ * (github login or Goliac username), in the teams repository and in the Github organization,
+ * and the Github grants not managed by Goliac.
+ * If fs is nil, the currently loaded teams repository is used
+ */
+func (g *GoliacImpl) ExplainAccess(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, repositoryUrl, branch string, user string, repository string) *engine.AccessExplanation {
+	// the loaded teams repository is replaced by an apply
+	g.actionMutex.Lock()
+	defer g.actionMutex.Unlock()
+
+	if fs != nil {
+		g.loadAndValidateGoliacOrganization(ctx, fs, repositoryUrl, branch, logsCollector)
+		if logsCollector.HasErrors() {
+			return nil
+		}
+		defer g.local.Close(fs)
+	}
+
+	usernames := g.localUsernames()
+	paths := make(map[string]*engine.AccessPaths)
+	githubid := ""
+	for _, source := range []string{"local", "remote"} {
+		datasource, collaborators, err := g.accessDatasource(ctx, repositoryUrl, branch, source)
+		if err != nil {
+			logsCollector.AddError(err)
+			return nil
+		}
+		githubid, paths[source], err = engine.ExplainAccessPaths(datasource, collaborators, usernames, user, repository)
+		if err != nil {
+			logsCollector.AddError(fmt.Errorf("error when explaining the access: %v", err))
+			return nil
+		}
+	}
+
+	_, isMember := g.remote.Users(ctx)[githubid]
+	_, isDefined := usernames[githubid]
+	if !isMember && !isDefined && len(paths["local"].Reasons) == 0 && len(paths["remote"].Reasons) == 0 {
+		logsCollector.AddError(fmt.Errorf("user %s not found", user))
+		return nil
+	}
+	_, inRemote := g.remote.Repositories(ctx)[repository]
+	_, inLocal := g.local.Repositories()[repository]
+	if !inRemote && !inLocal {
+		logsCollector.AddError(fmt.Errorf("repository %s not found", repository))
+		return nil
+	}
+
+	return engine.NewAccessExplanation(githubid, usernames[githubid], repository, paths["local"], paths["remote"])
+}
+
+/*
+ * accessDatasource returns the datasource to resolve accesses from: the teams repository
+ * (source "local") or the Github organization (source "remote"), and the direct collaborators
+ * of the repositories ([repository][githubid]permission, nil for the teams repository)
+ */
+func (g *GoliacImpl) accessDatasource(ctx context.Context, repositoryUrl, branch string, source string) (engine.GoliacReconciliatorDatasource, map[string]map[string]string, error) {
+	if source == "local" {
+		u, err := url.Parse(repositoryUrl)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %v", repositoryUrl, err)
+		}
+		teamreponame := strings.TrimSuffix(path.Base(u.Path), filepath.Ext(path.Base(u.Path)))
+		return engine.NewGoliacReconciliatorDatasourceLocalForOrganization(g.organization, g.local, teamreponame, branch, g.remote.IsEnterprise(), g.repoconfig, g.localGithubClient.GetAppSlug()), nil, nil
+	}
+
+	err := g.remote.Load(ctx, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error when loading data from Github: %v", err)
+	}
+	collaborators := make(map[string]map[string]string)
+	for reponame, repo := range g.remote.Repositories(ctx) {
+		collaborators[reponame] = make(map[string]string)
+		for githubid, permission := range repo.ExternalUsers {
+			collaborators[reponame][githubid] = permission
+		}
+		for githubid, permission := range repo.InternalUsers {
+			collaborators[reponame][githubid] = permission
+		}
+	}
+	return engine.NewGoliacReconciliatorDatasourceRemote(g.remote), collaborators, nil
+}
+
+/*
+ * localUsernames returns the Goliac usernames of the teams repository users ([githubid]username)
+ */
+func (g *GoliacImpl) localUsernames() map[string]string {
+	usernames := make(map[string]string)
+	for username, user := range g.local.Users() {
+		usernames[user.Spec.GithubID] = username
+	}
+	return usernames
+}
+
+func (g *GoliacImpl) Apply(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, dryrun bool, repositoryUrl, branch string, only []string) *engine.UnmanagedResources {
 	if !strings.HasPrefix(repositoryUrl, "https://") &&
 		!strings.HasPrefix(repositoryUrl, "inmemory:///") { // <- only for testing purposes
 		logsCollector.AddError(fmt.Errorf("local mode is not supported for plan/apply, you must specify the https url of the remote team git repository. Check the documentation"))
@@ -367,7 +817,7 @@ func (g *GoliacImpl) Apply(ctx context.Context, logsCollector *observability.Log
 	}
 
 	// apply the changes to the github team repository
-	unmanaged := g.applyToGithub(ctx, dryrun, config.Config.GithubAppOrganization, teamreponame, branch, config.Config.SyncUsersBeforeApply, logsCollector)
+	unmanaged := g.applyToGithub(ctx, dryrun, g.organization, teamreponame, branch, config.Config.SyncUsersBeforeApply, only, logsCollector)
 
 	return unmanaged
 }
@@ -448,7 +898,7 @@ func (g *GoliacImpl) loadAndValidateGoliacOrganization(ctx context.Context, fs b
 func (g *GoliacImpl) forceSquashMergeOnTeamsRepo(ctx context.Context, teamreponame string, branchname string) error {
 	// https://docs.github.com/en/rest/repos/repos?apiVersion=2022-11-28#update-a-repository
 	_, err := g.remoteGithubClient.CallRestAPI(ctx,
-		fmt.Sprintf("/repos/%s/%s", config.Config.GithubAppOrganization, teamreponame),
+		fmt.Sprintf("/repos/%s/%s", g.organization, teamreponame),
 		"",
 		"PATCH",
 		map[string]interface{}{
@@ -471,7 +921,7 @@ func (g *GoliacImpl) forceSquashMergeOnTeamsRepo(ctx context.Context, teamrepona
 	}
 	// https://docs.github.com/en/rest/branches/branch-protection?apiVersion=2022-11-28#update-branch-protection
 	_, err = g.remoteGithubClient.CallRestAPI(ctx,
-		fmt.Sprintf("/repos/%s/%s/branches/%s/protection", config.Config.GithubAppOrganization, teamreponame, branchname),
+		fmt.Sprintf("/repos/%s/%s/branches/%s/protection", g.organization, teamreponame, branchname),
 		"",
 		"PUT",
 		map[string]interface{}{
@@ -505,7 +955,7 @@ Apply the changes to the github team repository:
   - apply the changes
   - update the codeowners file
 */
-func (g *GoliacImpl) applyToGithub(ctx context.Context, dryrun bool, githubOrganization string, teamreponame string, branch string, syncusersbeforeapply bool, logsCollector *observability.LogCollection) *engine.UnmanagedResources {
+func (g *GoliacImpl) applyToGithub(ctx context.Context, dryrun bool, githubOrganization string, teamreponame string, branch string, syncusersbeforeapply bool, only []string, logsCollector *observability.LogCollection) *engine.UnmanagedResources {
 	var childSpan trace.Span
 	if config.Config.OpenTelemetryEnabled {
 		// get back the tracer from the context
@@ -522,8 +972,8 @@ func (g *GoliacImpl) applyToGithub(ctx context.Context, dryrun bool, githubOrgan
 	//
 
 	// we try to sync users before applying the changes
-	if syncusersbeforeapply {
-		userplugin, found := engine.GetUserSyncPlugin(g.repoconfig.UserSync.Plugin)
+	if syncusersbeforeapply && len(only) == 0 {
+		userplugin, found := g.getUserSyncPlugin(g.repoconfig.UserSync.Plugin)
 		if !found {
 			logrus.Warnf("user sync plugin %s not found", g.repoconfig.UserSync.Plugin)
 		} else {
@@ -547,7 +997,7 @@ func (g *GoliacImpl) applyToGithub(ctx context.Context, dryrun bool, githubOrgan
 	//
 
 	// we apply the changes to the github team repository
-	unmanaged, err := g.applyCommitsToGithub(ctx, logsCollector, dryrun, teamreponame, branch)
+	unmanaged, err := g.applyCommitsToGithub(ctx, logsCollector, dryrun, teamreponame, branch, only)
 	if err != nil {
 		logsCollector.AddError(fmt.Errorf("error when applying to github: %v", err))
 		return unmanaged
@@ -560,8 +1010,8 @@ func (g *GoliacImpl) applyToGithub(ctx context.Context, dryrun bool, githubOrgan
 		g.feedback.LoadingAsset("finish", 1)
 	}
 
-	// we update the codeowners file
-	if !dryrun {
+	// we update the codeowners file (a targeted apply leaves it to the next full apply)
+	if !dryrun && len(only) == 0 {
 		accessToken, err := g.localGithubClient.GetAccessToken(ctx)
 		if err != nil {
 			logsCollector.AddError(fmt.Errorf("error when getting access token: %v", err))
@@ -577,7 +1027,7 @@ func (g *GoliacImpl) applyToGithub(ctx context.Context, dryrun bool, githubOrgan
 	return unmanaged
 }
 
-func (g *GoliacImpl) applyCommitsToGithub(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamreponame string, branch string) (*engine.UnmanagedResources, error) {
+func (g *GoliacImpl) applyCommitsToGithub(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamreponame string, branch string, only []string) (*engine.UnmanagedResources, error) {
 	var childSpan trace.Span
 	if config.Config.OpenTelemetryEnabled {
 		ctx, childSpan = otel.Tracer("goliac").Start(ctx, "applyCommitsToGithub")
@@ -602,8 +1052,18 @@ func (g *GoliacImpl) applyCommitsToGithub(ctx context.Context, logsCollector *ob
 	// the repo has already been cloned (to HEAD) and validated (see loadAndValidateGoliacOrganization)
 	// we can now apply the changes to the github team repository
 	isEnterprise := g.remote.IsEnterprise()
-	localDatasource := engine.NewGoliacReconciliatorDatasourceLocal(g.local, teamreponame, branch, isEnterprise, g.repoconfig, g.localGithubClient.GetAppSlug())
+	localDatasource := engine.NewGoliacReconciliatorDatasourceLocalForOrganization(g.organization, g.local, teamreponame, branch, isEnterprise, g.repoconfig, g.localGithubClient.GetAppSlug())
 	remoteDataSource := engine.NewGoliacReconciliatorDatasourceRemote(g.remote)
+	if len(only) > 0 {
+		// targeted apply: only the requested resources (and their dependencies) are reconciled
+		only = engine.ScopeWithTeamsRepositories(g.local, only)
+		scope, err := engine.NewReconciliatorScope(localDatasource, only)
+		if err != nil {
+			return unmanaged, err
+		}
+		localDatasource = engine.NewGoliacReconciliatorDatasourceScoped(localDatasource, scope)
+		remoteDataSource = engine.NewGoliacReconciliatorDatasourceScoped(remoteDataSource, scope)
+	}
 	unmanaged, reposToArchive, renameTo, err := reconciliator.Reconciliate(
 		ctx,
 		logsCollector,
@@ -619,7 +1079,8 @@ func (g *GoliacImpl) applyCommitsToGithub(ctx context.Context, logsCollector *ob
 		return unmanaged, fmt.Errorf("error when reconciliating: %v", err)
 	}
 
-	if !dryrun {
+	// the tag marks the commit as fully applied
+	if !dryrun && len(only) == 0 {
 		accessToken, err := g.localGithubClient.GetAccessToken(ctx)
 		if err != nil {
 			return unmanaged, err
@@ -677,7 +1138,7 @@ func (g *GoliacImpl) UsersUpdate(ctx context.Context, logsCollector *observabili
 		return false
 	}
 
-	userplugin, found := engine.GetUserSyncPlugin(repoconfig.UserSync.Plugin)
+	userplugin, found := g.getUserSyncPlugin(repoconfig.UserSync.Plugin)
 	if !found {
 		logsCollector.AddError(fmt.Errorf("user sync Plugin %s not found", repoconfig.UserSync.Plugin))
 		return false
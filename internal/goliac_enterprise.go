@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/engine"
+	"github.com/goliac-project/goliac/internal/entity"
+	"github.com/goliac-project/goliac/internal/github"
+	"github.com/goliac-project/goliac/internal/observability"
+)
+
+/*
+GoliacEnterprise manages the enterprise layer of a multi-organizations Goliac server
+(see the enterprise section of GOLIAC_SERVER_ORGANIZATIONS_FILE):
+the missing organizations are created, and the enterprise teams are synced
+*/
+type GoliacEnterprise struct {
+	config        *EnterpriseConfig
+	organizations []string
+	remote        engine.GoliacRemoteEnterprise
+	loaded        bool
+}
+
+func NewGoliacEnterprise(enterprise *EnterpriseConfig, organizations []OrganizationConfig) (*GoliacEnterprise, error) {
+	// the Goliac Github App must be installed on the enterprise
+	client, err := github.NewGitHubClientImpl(
+		config.Config.GithubServer,
+		enterprise.Name,
+		config.Config.GithubAppID,
+		config.Config.GithubAppPrivateKeyFile,
+		config.Config.GithubPersonalAccessToken,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newGoliacEnterprise(enterprise, organizations, engine.NewGoliacRemoteEnterpriseImpl(client, enterprise.Name)), nil
+}
+
+func newGoliacEnterprise(enterprise *EnterpriseConfig, organizations []OrganizationConfig, remote engine.GoliacRemoteEnterprise) *GoliacEnterprise {
+	names := make([]string, 0, len(organizations))
+	for _, o := range organizations {
+		names = append(names, o.Name)
+	}
+	return &GoliacEnterprise{
+		config:        enterprise,
+		organizations: names,
+		remote:        remote,
+	}
+}
+
+/*
+CreateOrganizations creates the managed organizations missing in the enterprise
+*/
+func (e *GoliacEnterprise) CreateOrganizations(ctx context.Context, logsCollector *observability.LogCollection) {
+	if err := e.remote.Load(ctx); err != nil {
+		logsCollector.AddError(fmt.Errorf("not able to load the enterprise %s: %v", e.config.Name, err))
+		return
+	}
+	e.loaded = true
+	engine.ReconcileEnterpriseOrganizations(ctx, logsCollector, e.remote, e.definition(nil, logsCollector), false)
+}
+
+/*
+Apply (re)loads the enterprise, and reconciles its organizations and teams.
+users are the enterprise-wide users (the users of the enterprise users_from organization)
+*/
+func (e *GoliacEnterprise) Apply(ctx context.Context, logsCollector *observability.LogCollection, users map[string]*entity.User, dryrun bool) {
+	if err := e.remote.Load(ctx); err != nil {
+		logsCollector.AddError(fmt.Errorf("not able to load the enterprise %s: %v", e.config.Name, err))
+		return
+	}
+	e.loaded = true
+
+	if e.config.UsersFrom != "" && len(users) == 0 {
+		// the teams repository of the users_from organization is not loaded yet
+		logsCollector.AddWarn(fmt.Errorf("the users of the %s organization are not loaded yet", e.config.UsersFrom))
+		engine.ReconcileEnterpriseOrganizations(ctx, logsCollector, e.remote, e.definition(nil, logsCollector), dryrun)
+		return
+	}
+
+	engine.ReconcileEnterprise(ctx, logsCollector, e.remote, e.definition(users, logsCollector), dryrun)
+}
+
+/*
+definition returns the expected state of the enterprise
+(the enterprise teams members usernames are resolved to github logins)
+*/
+func (e *GoliacEnterprise) definition(users map[string]*entity.User, logsCollector *observability.LogCollection) *engine.EnterpriseDefinition {
+	definition := engine.EnterpriseDefinition{
+		Organizations:         e.organizations,
+		BillingEmail:          e.config.BillingEmail,
+		Admins:                e.config.Admins,
+		Teams:                 make(map[string]*engine.EnterpriseTeamDefinition),
+		AllowDestructiveTeams: e.config.AllowDestructiveTeams,
+	}
+
+	for _, team := range e.config.Teams {
+		members := []string{}
+		for _, username := range team.Members {
+			user, ok := users[username]
+			if !ok {
+				if users != nil {
+					logsCollector.AddWarn(fmt.Errorf("enterprise team %s: user %s not found in the %s organization", team.Name, username, e.config.UsersFrom))
+				}
+				continue
+			}
+			members = append(members, user.Spec.GithubID)
+		}
+		definition.Teams[team.Name] = &engine.EnterpriseTeamDefinition{
+			Name:          team.Name,
+			Members:       members,
+			Organizations: team.Organizations,
+		}
+	}
+	return &definition
+}
+
+/*
+Member returns if the github login is a member of the enterprise,
+and the enterprise teams it belongs to
+*/
+func (e *GoliacEnterprise) Member(githubid string) (bool, []string) {
+	if !e.loaded {
+		return false, []string{}
+	}
+
+	isMember := false
+	for login := range e.remote.Members() {
+		if strings.EqualFold(login, githubid) {
+			isMember = true
+			break
+		}
+	}
+
+	teams := []string{}
+	for _, teamname := range slices.Sorted(maps.Keys(e.remote.Teams())) {
+		for _, login := range e.remote.Teams()[teamname].Members {
+			if strings.EqualFold(login, githubid) {
+				teams = append(teams, teamname)
+				break
+			}
+		}
+	}
+	return isMember, teams
+}
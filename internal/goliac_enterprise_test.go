@@ -0,0 +1,154 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/goliac-project/goliac/internal/engine"
+	"github.com/goliac-project/goliac/internal/entity"
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+type EnterpriseRemoteMock struct {
+	loadErr       error
+	organizations []string
+	members       map[string]*engine.GithubEnterpriseMember
+	teams         map[string]*engine.GithubEnterpriseTeam
+	actions       []string
+}
+
+func (m *EnterpriseRemoteMock) Load(ctx context.Context) error {
+	return m.loadErr
+}
+func (m *EnterpriseRemoteMock) Organizations() []string {
+	return m.organizations
+}
+func (m *EnterpriseRemoteMock) Members() map[string]*engine.GithubEnterpriseMember {
+	return m.members
+}
+func (m *EnterpriseRemoteMock) Teams() map[string]*engine.GithubEnterpriseTeam {
+	return m.teams
+}
+func (m *EnterpriseRemoteMock) CreateOrganization(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, login string, billingEmail string, admins []string) {
+	m.actions = append(m.actions, "create_organization "+login)
+}
+func (m *EnterpriseRemoteMock) CreateTeam(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string) {
+	m.actions = append(m.actions, "create_team "+teamname)
+	m.teams[teamname] = &engine.GithubEnterpriseTeam{Name: teamname}
+}
+func (m *EnterpriseRemoteMock) DeleteTeam(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string) {
+	m.actions = append(m.actions, "delete_team "+teamname)
+}
+func (m *EnterpriseRemoteMock) AddTeamMember(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, login string) {
+	m.actions = append(m.actions, "add_member "+teamname+" "+login)
+}
+func (m *EnterpriseRemoteMock) RemoveTeamMember(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, login string) {
+	m.actions = append(m.actions, "remove_member "+teamname+" "+login)
+}
+func (m *EnterpriseRemoteMock) AddTeamOrganization(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, organization string) {
+	m.actions = append(m.actions, "add_organization "+teamname+" "+organization)
+}
+func (m *EnterpriseRemoteMock) RemoveTeamOrganization(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, organization string) {
+	m.actions = append(m.actions, "remove_organization "+teamname+" "+organization)
+}
+
+func fixtureEnterpriseRemote() *EnterpriseRemoteMock {
+	return &EnterpriseRemoteMock{
+		organizations: []string{"prod"},
+		members: map[string]*engine.GithubEnterpriseMember{
+			"github1": {Login: "github1"},
+			"github2": {Login: "github2"},
+		},
+		teams: map[string]*engine.GithubEnterpriseTeam{
+			"platform": {Name: "platform", Members: []string{"github2"}, Organizations: []string{"prod"}},
+		},
+	}
+}
+
+func fixtureEnterpriseConfig() *EnterpriseConfig {
+	return &EnterpriseConfig{
+		Name:         "myenterprise",
+		UsersFrom:    "prod",
+		BillingEmail: "billing@company.com",
+		Admins:       []string{"github1"},
+		Teams: []EnterpriseTeamConfig{
+			{Name: "platform", Members: []string{"user1", "unknown"}, Organizations: []string{"prod", "oss"}},
+		},
+	}
+}
+
+func TestGoliacEnterprise(t *testing.T) {
+	organizations := []OrganizationConfig{{Name: "prod"}, {Name: "oss", UsersFrom: "prod"}}
+
+	t.Run("happy path: create the missing organizations", func(t *testing.T) {
+		remote := fixtureEnterpriseRemote()
+		enterprise := newGoliacEnterprise(fixtureEnterpriseConfig(), organizations, remote)
+		logsCollector := observability.NewLogCollection()
+
+		enterprise.CreateOrganizations(context.TODO(), logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, []string{"create_organization oss"}, remote.actions)
+	})
+
+	t.Run("happy path: sync the enterprise teams", func(t *testing.T) {
+		localfixture, _ := fixtureGoliacLocal()
+		remote := fixtureEnterpriseRemote()
+		remote.organizations = []string{"oss", "prod"}
+		enterprise := newGoliacEnterprise(fixtureEnterpriseConfig(), organizations, remote)
+		logsCollector := observability.NewLogCollection()
+
+		enterprise.Apply(context.TODO(), logsCollector, localfixture.users, false)
+
+		assert.False(t, logsCollector.HasErrors())
+		// user 'unknown' is not defined
+		assert.True(t, logsCollector.HasWarns())
+		assert.Equal(t, []string{
+			"add_member platform github1",
+			"remove_member platform github2",
+			"add_organization platform oss",
+		}, remote.actions)
+	})
+
+	t.Run("happy path: users not loaded yet", func(t *testing.T) {
+		remote := fixtureEnterpriseRemote()
+		remote.organizations = []string{"oss", "prod"}
+		enterprise := newGoliacEnterprise(fixtureEnterpriseConfig(), organizations, remote)
+		logsCollector := observability.NewLogCollection()
+
+		enterprise.Apply(context.TODO(), logsCollector, map[string]*entity.User{}, false)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.True(t, logsCollector.HasWarns())
+		assert.Equal(t, 0, len(remote.actions))
+	})
+
+	t.Run("not happy path: enterprise not loaded", func(t *testing.T) {
+		remote := fixtureEnterpriseRemote()
+		remote.loadErr = fmt.Errorf("enterprise myenterprise not found")
+		enterprise := newGoliacEnterprise(fixtureEnterpriseConfig(), organizations, remote)
+		logsCollector := observability.NewLogCollection()
+
+		enterprise.Apply(context.TODO(), logsCollector, nil, false)
+
+		assert.True(t, logsCollector.HasErrors())
+		isMember, teams := enterprise.Member("github2")
+		assert.False(t, isMember)
+		assert.Equal(t, []string{}, teams)
+	})
+
+	t.Run("happy path: enterprise membership", func(t *testing.T) {
+		enterprise := newGoliacEnterprise(fixtureEnterpriseConfig(), organizations, fixtureEnterpriseRemote())
+		enterprise.CreateOrganizations(context.TODO(), observability.NewLogCollection())
+
+		isMember, teams := enterprise.Member("GITHUB2")
+		assert.True(t, isMember)
+		assert.Equal(t, []string{"platform"}, teams)
+
+		isMember, teams = enterprise.Member("github3")
+		assert.False(t, isMember)
+		assert.Equal(t, []string{}, teams)
+	})
+}
@@ -1,10 +1,13 @@
 package internal
 
 import (
+	"fmt"
+
 	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/goliac-project/goliac/internal/config"
 	"github.com/goliac-project/goliac/internal/engine"
 	"github.com/goliac-project/goliac/internal/observability"
+	"gopkg.in/yaml.v3"
 )
 
 /*
@@ -15,6 +18,10 @@ import (
 type GoliacLight interface {
 	// Validate a local teams directory
 	Validate(path string, logsCollector *observability.LogCollection)
+
+	// EffectiveRepository returns the repository manifest (once the team's repository_defaults are applied)
+	// It must be called after Validate
+	EffectiveRepository(reponame string) (string, error)
 }
 
 type GoliacLightImpl struct {
@@ -33,3 +40,15 @@ func (g *GoliacLightImpl) Validate(path string, logsCollector *observability.Log
 	fs := osfs.New(path)
 	g.local.LoadAndValidateLocal(fs, logsCollector)
 }
+
+func (g *GoliacLightImpl) EffectiveRepository(reponame string) (string, error) {
+	repo, ok := g.local.Repositories()[reponame]
+	if !ok {
+		return "", fmt.Errorf("repository %s not found", reponame)
+	}
+	content, err := yaml.Marshal(repo)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
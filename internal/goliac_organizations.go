@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/goliac-project/goliac/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+OrganizationConfig is a Github organization managed by the Goliac server,
+with its own teams repository (see GOLIAC_SERVER_ORGANIZATIONS_FILE)
+*/
+type OrganizationConfig struct {
+	Name       string `yaml:"name"`
+	Repository string `yaml:"repository"`
+	Branch     string `yaml:"branch"`
+	// the users of this organization are synced from the teams repository
+	// of another organization (via the fromorganization usersync plugin)
+	UsersFrom string `yaml:"users_from,omitempty"`
+}
+
+type OrganizationsConfig struct {
+	Enterprise    *EnterpriseConfig    `yaml:"enterprise,omitempty"`
+	Organizations []OrganizationConfig `yaml:"organizations"`
+}
+
+/*
+EnterpriseConfig is the (optional) Github enterprise owning the managed organizations.
+The missing organizations are created, and the enterprise teams are synced
+*/
+type EnterpriseConfig struct {
+	Name string `yaml:"name"` // enterprise slug
+	// the enterprise-wide users are the users of the teams repository of this organization
+	// (the enterprise teams members are usernames of this organization)
+	UsersFrom             string                 `yaml:"users_from,omitempty"`
+	BillingEmail          string                 `yaml:"billing_email,omitempty"` // to create the missing organizations
+	Admins                []string               `yaml:"admins,omitempty"`        // github logins, admins of the created organizations
+	Teams                 []EnterpriseTeamConfig `yaml:"teams,omitempty"`
+	AllowDestructiveTeams bool                   `yaml:"allow_destructive_teams,omitempty"`
+}
+
+type EnterpriseTeamConfig struct {
+	Name          string   `yaml:"name"`
+	Members       []string `yaml:"members,omitempty"`
+	Organizations []string `yaml:"organizations,omitempty"`
+}
+
+/*
+GetOrganizationsConfig returns the Github organizations managed by the Goliac server:
+the ones of GOLIAC_SERVER_ORGANIZATIONS_FILE if set, else the GOLIAC_GITHUB_APP_ORGANIZATION
+organization (with the GOLIAC_SERVER_GIT_REPOSITORY teams repository).
+The first organization is the default one (of the /api/v1 REST API and of the UI)
+*/
+func GetOrganizationsConfig() ([]OrganizationConfig, error) {
+	if config.Config.ServerOrganizationsFile == "" {
+		return []OrganizationConfig{
+			{
+				Name:       config.Config.GithubAppOrganization,
+				Repository: config.Config.ServerGitRepository,
+				Branch:     config.Config.ServerGitBranch,
+			},
+		}, nil
+	}
+
+	organizationsConfig, err := readOrganizationsFile()
+	if err != nil {
+		return nil, err
+	}
+	return organizationsConfig.Organizations, nil
+}
+
+/*
+GetEnterpriseConfig returns the enterprise section of GOLIAC_SERVER_ORGANIZATIONS_FILE
+(nil if not set)
+*/
+func GetEnterpriseConfig() (*EnterpriseConfig, error) {
+	if config.Config.ServerOrganizationsFile == "" {
+		return nil, nil
+	}
+
+	organizationsConfig, err := readOrganizationsFile()
+	if err != nil {
+		return nil, err
+	}
+	return organizationsConfig.Enterprise, nil
+}
+
+func readOrganizationsFile() (*OrganizationsConfig, error) {
+	content, err := os.ReadFile(config.Config.ServerOrganizationsFile)
+	if err != nil {
+		return nil, fmt.Errorf("not able to read the organizations file %s: %v", config.Config.ServerOrganizationsFile, err)
+	}
+	return parseOrganizationsFile(content)
+}
+
+/*
+ParseOrganizationsConfig parses and validates the organizations file
+*/
+func ParseOrganizationsConfig(content []byte) ([]OrganizationConfig, error) {
+	organizationsConfig, err := parseOrganizationsFile(content)
+	if err != nil {
+		return nil, err
+	}
+	return organizationsConfig.Organizations, nil
+}
+
+func parseOrganizationsFile(content []byte) (*OrganizationsConfig, error) {
+	var organizationsConfig OrganizationsConfig
+	if err := yaml.Unmarshal(content, &organizationsConfig); err != nil {
+		return nil, fmt.Errorf("not able to parse the organizations file: %v", err)
+	}
+
+	organizations := organizationsConfig.Organizations
+	if len(organizations) == 0 {
+		return nil, fmt.Errorf("no organization defined in the organizations file")
+	}
+
+	byName := make(map[string]*OrganizationConfig)
+	for i := range organizations {
+		org := &organizations[i]
+		if org.Name == "" {
+			return nil, fmt.Errorf("organization #%d: name is missing", i+1)
+		}
+		if _, ok := byName[org.Name]; ok {
+			return nil, fmt.Errorf("organization %s is defined twice", org.Name)
+		}
+		if org.Repository == "" {
+			return nil, fmt.Errorf("organization %s: repository is missing", org.Name)
+		}
+		if org.Branch == "" {
+			org.Branch = "main"
+		}
+		byName[org.Name] = org
+	}
+
+	for _, org := range organizations {
+		// follow the users_from chain: it must end on an organization owning its users
+		seen := map[string]bool{org.Name: true}
+		for current := org; current.UsersFrom != ""; {
+			source, ok := byName[current.UsersFrom]
+			if !ok {
+				return nil, fmt.Errorf("organization %s: users_from %s is not a managed organization", current.Name, current.UsersFrom)
+			}
+			if seen[source.Name] {
+				return nil, fmt.Errorf("organization %s: users_from loop detected", org.Name)
+			}
+			seen[source.Name] = true
+			current = *source
+		}
+	}
+
+	if organizationsConfig.Enterprise != nil {
+		if err := validateEnterpriseConfig(organizationsConfig.Enterprise, byName); err != nil {
+			return nil, err
+		}
+	}
+
+	return &organizationsConfig, nil
+}
+
+func validateEnterpriseConfig(enterprise *EnterpriseConfig, organizations map[string]*OrganizationConfig) error {
+	if enterprise.Name == "" {
+		return fmt.Errorf("enterprise: name is missing")
+	}
+	if enterprise.UsersFrom != "" {
+		if _, ok := organizations[enterprise.UsersFrom]; !ok {
+			return fmt.Errorf("enterprise: users_from %s is not a managed organization", enterprise.UsersFrom)
+		}
+	}
+
+	teams := make(map[string]bool)
+	for i, team := range enterprise.Teams {
+		if team.Name == "" {
+			return fmt.Errorf("enterprise team #%d: name is missing", i+1)
+		}
+		if teams[team.Name] {
+			return fmt.Errorf("enterprise team %s is defined twice", team.Name)
+		}
+		teams[team.Name] = true
+		if len(team.Members) > 0 && enterprise.UsersFrom == "" {
+			return fmt.Errorf("enterprise team %s: members are defined but the enterprise users_from is missing", team.Name)
+		}
+		for _, o := range team.Organizations {
+			if _, ok := organizations[o]; !ok {
+				return fmt.Errorf("enterprise team %s: organization %s is not a managed organization", team.Name, o)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOrganizationsConfig(t *testing.T) {
+	t.Run("happy path: several organizations", func(t *testing.T) {
+		organizations, err := ParseOrganizationsConfig([]byte(`
+organizations:
+  - name: prod
+    repository: https://github.com/prod/goliac-teams
+    branch: master
+  - name: oss
+    repository: https://github.com/oss/goliac-teams
+    users_from: prod
+`))
+		assert.Nil(t, err)
+		assert.Equal(t, []OrganizationConfig{
+			{Name: "prod", Repository: "https://github.com/prod/goliac-teams", Branch: "master"},
+			{Name: "oss", Repository: "https://github.com/oss/goliac-teams", Branch: "main", UsersFrom: "prod"},
+		}, organizations)
+	})
+
+	t.Run("not happy path: invalid organizations", func(t *testing.T) {
+		for name, content := range map[string]string{
+			"no organization": `organizations: []`,
+			"missing name": `
+organizations:
+  - repository: https://github.com/prod/goliac-teams`,
+			"missing repository": `
+organizations:
+  - name: prod`,
+			"defined twice": `
+organizations:
+  - name: prod
+    repository: https://github.com/prod/goliac-teams
+  - name: prod
+    repository: https://github.com/prod/goliac-teams2`,
+			"unknown users_from": `
+organizations:
+  - name: prod
+    repository: https://github.com/prod/goliac-teams
+    users_from: unknown`,
+			"users_from loop": `
+organizations:
+  - name: prod
+    repository: https://github.com/prod/goliac-teams
+    users_from: oss
+  - name: oss
+    repository: https://github.com/oss/goliac-teams
+    users_from: prod`,
+			"not yaml": `organizations: [`,
+		} {
+			_, err := ParseOrganizationsConfig([]byte(content))
+			assert.NotNil(t, err, name)
+		}
+	})
+}
+
+func TestParseEnterpriseConfig(t *testing.T) {
+	t.Run("happy path: enterprise", func(t *testing.T) {
+		organizationsConfig, err := parseOrganizationsFile([]byte(`
+enterprise:
+  name: mycompany
+  users_from: prod
+  billing_email: billing@company.com
+  admins:
+    - admin1
+  teams:
+    - name: platform
+      members:
+        - user1
+      organizations:
+        - prod
+        - oss
+organizations:
+  - name: prod
+    repository: https://github.com/prod/goliac-teams
+  - name: oss
+    repository: https://github.com/oss/goliac-teams
+    users_from: prod
+`))
+		assert.Nil(t, err)
+		assert.Equal(t, &EnterpriseConfig{
+			Name:         "mycompany",
+			UsersFrom:    "prod",
+			BillingEmail: "billing@company.com",
+			Admins:       []string{"admin1"},
+			Teams: []EnterpriseTeamConfig{
+				{Name: "platform", Members: []string{"user1"}, Organizations: []string{"prod", "oss"}},
+			},
+		}, organizationsConfig.Enterprise)
+	})
+
+	t.Run("not happy path: invalid enterprise", func(t *testing.T) {
+		organizations := `
+organizations:
+  - name: prod
+    repository: https://github.com/prod/goliac-teams`
+		for name, content := range map[string]string{
+			"missing name": `
+enterprise:
+  users_from: prod`,
+			"unknown users_from": `
+enterprise:
+  name: mycompany
+  users_from: unknown`,
+			"team defined twice": `
+enterprise:
+  name: mycompany
+  teams:
+    - name: platform
+    - name: platform`,
+			"members without users_from": `
+enterprise:
+  name: mycompany
+  teams:
+    - name: platform
+      members:
+        - user1`,
+			"unknown organization": `
+enterprise:
+  name: mycompany
+  teams:
+    - name: platform
+      organizations:
+        - unknown`,
+		} {
+			_, err := parseOrganizationsFile([]byte(content + organizations))
+			assert.NotNil(t, err, name)
+		}
+	})
+}
+
+func TestGetOrganizationsConfig(t *testing.T) {
+	previousOrganization := config.Config.GithubAppOrganization
+	previousRepository := config.Config.ServerGitRepository
+	previousBranch := config.Config.ServerGitBranch
+	previousFile := config.Config.ServerOrganizationsFile
+	defer func() {
+		config.Config.GithubAppOrganization = previousOrganization
+		config.Config.ServerGitRepository = previousRepository
+		config.Config.ServerGitBranch = previousBranch
+		config.Config.ServerOrganizationsFile = previousFile
+	}()
+
+	t.Run("happy path: single organization from the environment", func(t *testing.T) {
+		config.Config.GithubAppOrganization = "prod"
+		config.Config.ServerGitRepository = "https://github.com/prod/goliac-teams"
+		config.Config.ServerGitBranch = "main"
+		config.Config.ServerOrganizationsFile = ""
+
+		organizations, err := GetOrganizationsConfig()
+		assert.Nil(t, err)
+		assert.Equal(t, []OrganizationConfig{
+			{Name: "prod", Repository: "https://github.com/prod/goliac-teams", Branch: "main"},
+		}, organizations)
+
+		enterprise, err := GetEnterpriseConfig()
+		assert.Nil(t, err)
+		assert.Nil(t, enterprise)
+	})
+
+	t.Run("happy path: organizations file", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "organizations.yaml")
+		err := os.WriteFile(filename, []byte(`
+organizations:
+  - name: oss
+    repository: https://github.com/oss/goliac-teams
+`), 0644)
+		assert.Nil(t, err)
+		config.Config.ServerOrganizationsFile = filename
+
+		organizations, err := GetOrganizationsConfig()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(organizations))
+		assert.Equal(t, "oss", organizations[0].Name)
+	})
+
+	t.Run("not happy path: missing organizations file", func(t *testing.T) {
+		config.Config.ServerOrganizationsFile = filepath.Join(t.TempDir(), "missing.yaml")
+
+		_, err := GetOrganizationsConfig()
+		assert.NotNil(t, err)
+	})
+}
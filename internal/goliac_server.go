@@ -4,9 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"net/http"
 	"os"
 	"os/signal"
+	"path"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -15,6 +19,7 @@ import (
 
 	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/go-openapi/loads"
+	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/goliac-project/goliac/internal/config"
 	"github.com/goliac-project/goliac/internal/engine"
@@ -22,6 +27,7 @@ import (
 	"github.com/goliac-project/goliac/internal/github"
 	"github.com/goliac-project/goliac/internal/notification"
 	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/goliac-project/goliac/internal/usersync"
 	"github.com/goliac-project/goliac/internal/workflow"
 	"github.com/goliac-project/goliac/swagger_gen/models"
 	"github.com/goliac-project/goliac/swagger_gen/restapi"
@@ -53,6 +59,7 @@ type GoliacServer interface {
 
 	GetUsers(app.GetUsersParams) middleware.Responder
 	GetUser(app.GetUserParams) middleware.Responder
+	GetEnterpriseUser(app.GetEnterpriseUserParams) middleware.Responder
 	GetCollaborators(app.GetCollaboratorsParams) middleware.Responder
 	GetCollaborator(app.GetCollaboratorParams) middleware.Responder
 	GetTeams(app.GetTeamsParams) middleware.Responder
@@ -61,6 +68,8 @@ type GoliacServer interface {
 	GetRepository(app.GetRepositoryParams) middleware.Responder
 	GetStatistics(app.GetStatiticsParams) middleware.Responder
 	GetUnmanaged(app.GetUnmanagedParams) middleware.Responder
+	GetAccessReport(app.GetAccessReportParams) middleware.Responder
+	GetAccessExplanation(app.GetAccessExplanationParams) middleware.Responder
 
 	AuthGetLogin(params auth.GetAuthenticationLoginParams) middleware.Responder
 	AuthGetCallback(params auth.GetAuthenticationCallbackParams) middleware.Responder
@@ -68,6 +77,7 @@ type GoliacServer interface {
 	AuthGetWorkflow(params auth.GetWorkflowParams) middleware.Responder
 	AuthPostWorkflow(params auth.PostWorkflowParams) middleware.Responder
 	AuthWorkflows(params auth.GetWorkflowsParams) middleware.Responder
+	AuthPostAdopt(params auth.PostAdoptParams) middleware.Responder
 
 	PostExternalCreateRepository(external.PostExternalCreateRepositoryParams) middleware.Responder
 }
@@ -84,7 +94,9 @@ type GoliacServerImpl struct {
 	applyLobbyCond      *sync.Cond
 	applyCurrent        bool
 	applyLobby          bool
-	ready               bool // when the server has finished to load the local configuration
+	applyLobbyOnly      []string // resources to apply by the run waiting in the lobby (all if empty)
+	applyNextOnly       []string // resources to apply by the run leaving the lobby
+	ready               bool     // when the server has finished to load the local configuration
 	lastSyncTime        *time.Time
 	lastSyncError       error
 	lastSyncWarnings    string // all the warnings that happened during the last sync (sorted)
@@ -97,11 +109,20 @@ type GoliacServerImpl struct {
 	lastTimeToApply     time.Duration
 	maxTimeToApply      time.Duration
 	lastUnmanaged       *engine.UnmanagedResources
+	organization        string                       // Github organization
+	repository          string                       // teams repository
+	branch              string                       // teams repository branch
+	organizationServers map[string]*GoliacServerImpl // all the organizations (when the server manages several organizations)
+	enterprise          *GoliacEnterprise            // enterprise layer (if any)
+	lastEnterpriseError error
 
 	// auth related
-	client       github.GitHubClient
-	oauthConfig  OAuth2Config
-	sessionStore *sessions.CookieStore
+	client             github.GitHubClient
+	oauthConfig        OAuth2Config
+	sessionStore       sessions.Store
+	sessionChecks      map[string]*sessionCheck // last membership check per session (access token hash)
+	sessionChecksMutex sync.Mutex
+	ghesInfo           *engine.GHESInfo // nil on github.com
 }
 
 type GithubAppInfo struct {
@@ -137,24 +158,122 @@ func GetSelfGithubAppClientID(client github.GitHubClient, clientSecret string) (
 }
 
 func NewGoliacServer(goliac Goliac, notificationService notification.NotificationService) GoliacServer {
-	endpoints := oauth2.Endpoint{
-		AuthURL:  "https://github.com/login/oauth/authorize",
-		TokenURL: "https://github.com/login/oauth/access_token",
+	organization := OrganizationConfig{
+		Name:       config.Config.GithubAppOrganization,
+		Repository: config.Config.ServerGitRepository,
+		Branch:     config.Config.ServerGitBranch,
 	}
-	appInfo, err := GetSelfGithubAppClientID(goliac.GetRemoteClient(), config.Config.GithubAppClientSecret)
+	return newGoliacServerImpl(goliac, notificationService, organization, newOAuthConfig(goliac.GetRemoteClient()), newServerSessionStore())
+}
+
+/*
+ * NewGoliacMultiOrgServer returns a Goliac server managing several Github organizations,
+ * each one with its own teams repository, remote cache and apply loop.
+ * The first organization is served by /api/v1, and each organization by /api/v1/orgs/<organization>.
+ * If enterprise is set, the missing organizations are created first
+ */
+func NewGoliacMultiOrgServer(organizations []OrganizationConfig, enterpriseConfig *EnterpriseConfig, notificationService notification.NotificationService) (GoliacServer, error) {
+	if len(organizations) == 0 {
+		return nil, fmt.Errorf("no organization to manage")
+	}
+
+	var enterprise *GoliacEnterprise
+	if enterpriseConfig != nil {
+		var err error
+		enterprise, err = NewGoliacEnterprise(enterpriseConfig, organizations)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create goliac for the %s enterprise: %v", enterpriseConfig.Name, err)
+		}
+		logsCollector := observability.NewLogCollection()
+		enterprise.CreateOrganizations(context.Background(), logsCollector)
+		for _, info := range logsCollector.Logs {
+			logrus.WithFields(info.Fields).Logf(info.LogLevel, info.Format, info.Args...)
+		}
+		// the enterprise sync (enterpriseLoop) creates the missing organizations again
+		for _, err := range logsCollector.Errors {
+			logrus.Errorf("not able to create the organizations of the %s enterprise (retried at the next enterprise sync): %v", enterpriseConfig.Name, err)
+		}
+	}
+
+	// the organizations sharing their users must be created first
+	goliacs := make(map[string]Goliac)
+	for len(goliacs) < len(organizations) {
+		created := false
+		for _, org := range organizations {
+			if _, ok := goliacs[org.Name]; ok {
+				continue
+			}
+			userSyncPlugins := make(map[string]engine.UserSyncPlugin)
+			if org.UsersFrom != "" {
+				source, ok := goliacs[org.UsersFrom]
+				if !ok {
+					continue
+				}
+				userSyncPlugins["fromorganization"] = usersync.NewUserSyncPluginFromOrganization(org.UsersFrom, source)
+			}
+			goliac, err := NewGoliacImplForOrganization(org.Name, userSyncPlugins)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create goliac for the %s organization: %v", org.Name, err)
+			}
+			goliacs[org.Name] = goliac
+			created = true
+		}
+		if !created {
+			return nil, fmt.Errorf("invalid users_from in the organizations")
+		}
+	}
+
+	primary := goliacs[organizations[0].Name]
+	oauthConfig := newOAuthConfig(primary.GetRemoteClient())
+	sessionStore := newServerSessionStore()
+
+	servers := make(map[string]*GoliacServerImpl)
+	for _, org := range organizations {
+		servers[org.Name] = newGoliacServerImpl(goliacs[org.Name], notificationService, org, oauthConfig, sessionStore)
+	}
+	for _, server := range servers {
+		server.organizationServers = servers
+		server.enterprise = enterprise
+	}
+
+	return servers[organizations[0].Name], nil
+}
+
+func newOAuthConfig(client github.GitHubClient) OAuth2Config {
+	appInfo, err := GetSelfGithubAppClientID(client, config.Config.GithubAppClientSecret)
 	if err != nil {
 		logrus.Errorf("error when getting the Github App client ID: %s", err)
 	}
 
-	oauthConfig := &oauth2.Config{
+	return &oauth2.Config{
 		ClientID:     appInfo.ClientID,
 		ClientSecret: appInfo.ClientSecret,
 		RedirectURL:  config.Config.GithubAppCallbackURL,
-		Endpoint:     endpoints,
+		Endpoint:     GithubOAuthEndpoint(config.Config.GithubServer),
 		Scopes:       []string{"openid", "read:org", "user"},
 	}
+}
+
+func newServerSessionStore() sessions.Store {
+	secure, err := sessionCookieSecure(config.Config.ServerSessionSecure, config.Config.GithubAppCallbackURL)
+	if err != nil {
+		logrus.Fatalf("error when creating the session store: GOLIAC_SERVER_SESSION_SECURE: %s", err)
+	}
+	sessionStore, err := NewSessionStore(
+		config.Config.ServerSessionKeys,
+		config.Config.ServerSessionStoreDir,
+		config.Config.ServerSessionMaxAge,
+		secure,
+		config.Config.ServerSessionSameSite,
+	)
+	if err != nil {
+		logrus.Fatalf("error when creating the session store: %s", err)
+	}
+	return sessionStore
+}
 
-	ws := workflow.NewWorkflowService(config.Config.GithubAppOrganization, goliac.GetLocal(), goliac.GetRemote(), goliac.GetRemoteClient())
+func newGoliacServerImpl(goliac Goliac, notificationService notification.NotificationService, organization OrganizationConfig, oauthConfig OAuth2Config, sessionStore sessions.Store) *GoliacServerImpl {
+	ws := workflow.NewWorkflowService(organization.Name, goliac.GetLocal(), goliac.GetRemote(), goliac.GetRemoteClient())
 
 	worflowInstances := make(map[string]workflow.Workflow)
 	worflowInstances["forcemerge"] = workflow.NewForcemergeImpl(ws)
@@ -165,9 +284,13 @@ func NewGoliacServer(goliac Goliac, notificationService notification.Notificatio
 		worflowInstances:    worflowInstances,
 		ready:               false,
 		notificationService: notificationService,
+		organization:        organization.Name,
+		repository:          organization.Repository,
+		branch:              organization.Branch,
 		oauthConfig:         oauthConfig,
-		sessionStore:        sessions.NewCookieStore([]byte("your-secret-key")),
+		sessionStore:        sessionStore,
 		client:              goliac.GetRemoteClient(),
+		ghesInfo:            engine.GetGHESInfo(context.Background(), goliac.GetRemoteClient()),
 	}
 	server.applyLobbyCond = sync.NewCond(&server.applyLobbyMutex)
 
@@ -208,6 +331,107 @@ func (g *GoliacServerImpl) GetUnmanaged(app.GetUnmanagedParams) middleware.Respo
 	}
 }
 
+/*
+GetAccessReport returns the effective permission of each user on each repository
+(from the teams repository or from the Github organization), as JSON or CSV
+*/
+func (g *GoliacServerImpl) GetAccessReport(params app.GetAccessReportParams) middleware.Responder {
+	source := "local"
+	if params.Source != nil {
+		source = *params.Source
+	}
+
+	logsCollector := observability.NewLogCollection()
+	report := g.goliac.AccessReport(context.Background(), logsCollector, nil, g.repository, g.branch, source)
+	if logsCollector.HasErrors() {
+		message := fmt.Sprintf("Error when computing the access report: %s", logsCollector.Errors[0])
+		return app.NewGetAccessReportDefault(500).WithPayload(&models.Error{Message: &message})
+	}
+
+	if params.Format != nil && *params.Format == "csv" {
+		return middleware.ResponderFunc(func(rw http.ResponseWriter, _ runtime.Producer) {
+			rw.Header().Set("Content-Type", "text/csv")
+			rw.Header().Set("Content-Disposition", "attachment; filename=access_report.csv")
+			rw.WriteHeader(http.StatusOK)
+			if err := engine.WriteAccessReportCSV(rw, report); err != nil {
+				logrus.Errorf("not able to write the access report: %v", err)
+			}
+		})
+	}
+
+	payload := make(models.AccessReport, 0, len(report))
+	for _, grant := range report {
+		payload = append(payload, &models.AccessGrant{
+			Githubid:   grant.Githubid,
+			Username:   grant.Username,
+			Repository: grant.Repository,
+			Permission: grant.Permission,
+			Reasons:    accessReasonsToModel(grant.Reasons),
+		})
+	}
+	return app.NewGetAccessReportOK().WithPayload(payload)
+}
+
+/*
+GetAccessExplanation returns all the paths granting access to a repository to a user
+(in the teams repository and in Github), and the Github grants not managed by Goliac
+*/
+func (g *GoliacServerImpl) GetAccessExplanation(params app.GetAccessExplanationParams) middleware.Responder {
+	logsCollector := observability.NewLogCollection()
+	explanation := g.goliac.ExplainAccess(context.Background(), logsCollector, nil, g.repository, g.branch, params.UserID, params.RepositoryID)
+	if logsCollector.HasErrors() {
+		message := fmt.Sprintf("Error when explaining the access: %s", logsCollector.Errors[0])
+		return app.NewGetAccessExplanationDefault(404).WithPayload(&models.Error{Message: &message})
+	}
+
+	return app.NewGetAccessExplanationOK().WithPayload(&models.AccessExplanation{
+		Githubid:          explanation.Githubid,
+		Username:          explanation.Username,
+		Repository:        explanation.Repository,
+		Local:             accessPathsToModel(explanation.Local),
+		Remote:            accessPathsToModel(explanation.Remote),
+		Unmanaged:         accessReasonsToModel(explanation.Unmanaged),
+		UnmanagedBypasses: accessBypassesToModel(explanation.UnmanagedBypasses),
+	})
+}
+
+func accessPathsToModel(paths *engine.AccessPaths) *models.AccessPaths {
+	return &models.AccessPaths{
+		Permission: paths.Permission,
+		Reasons:    accessReasonsToModel(paths.Reasons),
+		Bypasses:   accessBypassesToModel(paths.Bypasses),
+	}
+}
+
+func accessReasonsToModel(reasons []engine.AccessReason) []*models.AccessReason {
+	result := make([]*models.AccessReason, 0, len(reasons))
+	for _, r := range reasons {
+		result = append(result, &models.AccessReason{
+			Kind:        r.Kind,
+			Team:        r.Team,
+			Via:         r.Via,
+			Permission:  r.Permission,
+			Description: r.String(),
+		})
+	}
+	return result
+}
+
+func accessBypassesToModel(bypasses []engine.AccessBypass) []*models.AccessBypass {
+	result := make([]*models.AccessBypass, 0, len(bypasses))
+	for _, b := range bypasses {
+		result = append(result, &models.AccessBypass{
+			Kind:        b.Kind,
+			Name:        b.Name,
+			Team:        b.Team,
+			Via:         b.Via,
+			Mode:        b.Mode,
+			Description: b.String(),
+		})
+	}
+	return result
+}
+
 func (g *GoliacServerImpl) GetStatistics(app.GetStatiticsParams) middleware.Responder {
 	return app.NewGetStatiticsOK().WithPayload(&models.Statistics{
 		LastTimeToApply:     g.lastTimeToApply.Truncate(time.Second).String(),
@@ -367,13 +591,13 @@ func (g *GoliacServerImpl) GetRepository(params app.GetRepositoryParams) middlew
 			}
 		}
 		if pages.HTMLURL == "" && gp.Source == "branch" && gp.Branch != "" {
-			pages.HTMLURL = fmt.Sprintf("https://%s.github.io/%s/", config.Config.GithubAppOrganization, repository.Name)
+			pages.HTMLURL = fmt.Sprintf("https://%s.github.io/%s/", g.organization, repository.Name)
 		}
 		githubPages = pages
 	}
 
 	repositoryDetails := models.RepositoryDetails{
-		Organization:        config.Config.GithubAppOrganization,
+		Organization:        g.organization,
 		Name:                repository.Name,
 		Visibility:          repository.Spec.Visibility,
 		AutoMergeAllowed:    repository.Spec.AllowAutoMerge,
@@ -657,16 +881,30 @@ func (g *GoliacServerImpl) GetUser(params app.GetUserParams) middleware.Responde
 	}
 
 	userdetails := models.UserDetails{
-		Githubid:     user.Spec.GithubID,
-		Teams:        make([]*models.Team, 0),
-		Repositories: make([]*models.Repository, 0),
+		Githubid: user.Spec.GithubID,
+		Email:    user.Spec.Email,
+		FullName: user.Spec.FullName,
+		Manager:  user.Spec.Manager,
+		Labels:   user.Spec.Labels,
 	}
+	userdetails.Teams, userdetails.Repositories = userTeamsAndRepositories(local, params.UserID)
+
+	return app.NewGetUserOK().WithPayload(&userdetails)
+}
+
+/*
+userTeamsAndRepositories returns the teams a user is owner or member of,
+and the repositories of these teams
+*/
+func userTeamsAndRepositories(local engine.GoliacLocalResources, username string) ([]*models.Team, []*models.Repository) {
+	teams := make([]*models.Team, 0)
+	repositories := make([]*models.Repository, 0)
 
 	// [teamname]team
 	userTeams := make(map[string]*models.Team)
 	for teamname, team := range local.Teams() {
 		for _, owner := range team.Spec.Owners {
-			if owner == params.UserID {
+			if owner == username {
 				team := models.Team{
 					Name:    teamname,
 					Members: team.Spec.Members,
@@ -677,7 +915,7 @@ func (g *GoliacServerImpl) GetUser(params app.GetUserParams) middleware.Responde
 			}
 		}
 		for _, member := range team.Spec.Members {
-			if member == params.UserID {
+			if member == username {
 				team := models.Team{
 					Name:    teamname,
 					Members: team.Spec.Members,
@@ -690,7 +928,7 @@ func (g *GoliacServerImpl) GetUser(params app.GetUserParams) middleware.Responde
 	}
 
 	for _, t := range userTeams {
-		userdetails.Teams = append(userdetails.Teams, t)
+		teams = append(teams, t)
 	}
 
 	// let's sort repo per team
@@ -718,9 +956,9 @@ func (g *GoliacServerImpl) GetUser(params app.GetUserParams) middleware.Responde
 
 	// [reponame]repo
 	userRepos := make(map[string]*entity.Repository)
-	for _, team := range userdetails.Teams {
-		if repositories, ok := teamRepo[team.Name]; ok {
-			for n, r := range repositories {
+	for _, team := range teams {
+		if teamRepositories, ok := teamRepo[team.Name]; ok {
+			for n, r := range teamRepositories {
 				userRepos[n] = r
 			}
 		}
@@ -732,26 +970,98 @@ func (g *GoliacServerImpl) GetUser(params app.GetUserParams) middleware.Responde
 			Visibility: r.Spec.Visibility,
 			Archived:   r.Archived,
 		}
-		userdetails.Repositories = append(userdetails.Repositories, &repo)
+		repositories = append(repositories, &repo)
 	}
 
-	return app.NewGetUserOK().WithPayload(&userdetails)
+	return teams, repositories
+}
+
+/*
+GetEnterpriseUser returns the organizations, teams and repositories a github user
+has access to, across all the managed organizations
+*/
+func (g *GoliacServerImpl) GetEnterpriseUser(params app.GetEnterpriseUserParams) middleware.Responder {
+	servers := g.organizationServers
+	if servers == nil {
+		servers = map[string]*GoliacServerImpl{g.organization: g}
+	}
+
+	details := models.EnterpriseUserDetails{
+		Githubid:        params.GithubID,
+		EnterpriseTeams: []string{},
+		Organizations:   make([]*models.OrganizationAccess, 0),
+	}
+	if g.enterprise != nil {
+		details.EnterpriseMember, details.EnterpriseTeams = g.enterprise.Member(params.GithubID)
+	}
+
+	found := details.EnterpriseMember
+	for _, organization := range slices.Sorted(maps.Keys(servers)) {
+		local := servers[organization].goliac.GetLocal()
+		access := models.OrganizationAccess{
+			Organization:         organization,
+			Teams:                make([]*models.Team, 0),
+			Repositories:         make([]*models.Repository, 0),
+			ExternalRepositories: make([]*models.Repository, 0),
+		}
+
+		for username, user := range local.Users() {
+			if strings.EqualFold(user.Spec.GithubID, params.GithubID) {
+				access.Username = username
+				access.Teams, access.Repositories = userTeamsAndRepositories(local, username)
+				break
+			}
+		}
+
+		for collaboratorname, collaborator := range local.ExternalUsers() {
+			if !strings.EqualFold(collaborator.Spec.GithubID, params.GithubID) {
+				continue
+			}
+			for _, r := range local.Repositories() {
+				if slices.Contains(r.Spec.ExternalUserReaders, collaboratorname) || slices.Contains(r.Spec.ExternalUserWriters, collaboratorname) {
+					access.ExternalRepositories = append(access.ExternalRepositories, &models.Repository{
+						Name:       r.Name,
+						Visibility: r.Spec.Visibility,
+						Archived:   r.Archived,
+					})
+				}
+			}
+		}
+
+		if access.Username == "" && len(access.ExternalRepositories) == 0 {
+			continue
+		}
+		found = true
+		details.Organizations = append(details.Organizations, &access)
+	}
+
+	if !found {
+		message := fmt.Sprintf("Github user %s not found", params.GithubID)
+		return app.NewGetEnterpriseUserDefault(404).WithPayload(&models.Error{Message: &message})
+	}
+
+	return app.NewGetEnterpriseUserOK().WithPayload(&details)
 }
 
 func (g *GoliacServerImpl) GetStatus(app.GetStatusParams) middleware.Responder {
 	nbworkflows := len(g.goliac.GetLocal().Workflows())
 	s := models.Status{
-		Organization:     config.Config.GithubAppOrganization,
-		LastSyncError:    "",
-		LastSyncTime:     "N/A",
-		NbRepos:          int64(len(g.goliac.GetLocal().Repositories())),
-		NbTeams:          int64(len(g.goliac.GetLocal().Teams())),
-		NbUsers:          int64(len(g.goliac.GetLocal().Users())),
-		NbUsersExternal:  int64(len(g.goliac.GetLocal().ExternalUsers())),
-		Version:          config.GoliacBuildVersion,
-		DetailedErrors:   make([]string, 0),
-		DetailedWarnings: make([]string, 0),
-		NbWorkflows:      int64(nbworkflows),
+		Organization:        g.organization,
+		Organizations:       g.organizationNames(),
+		LastSyncError:       "",
+		LastSyncTime:        "N/A",
+		NbRepos:             int64(len(g.goliac.GetLocal().Repositories())),
+		NbTeams:             int64(len(g.goliac.GetLocal().Teams())),
+		NbUsers:             int64(len(g.goliac.GetLocal().Users())),
+		NbUsersExternal:     int64(len(g.goliac.GetLocal().ExternalUsers())),
+		Version:             config.GoliacBuildVersion,
+		DetailedErrors:      make([]string, 0),
+		DetailedWarnings:    make([]string, 0),
+		NbWorkflows:         int64(nbworkflows),
+		UnsupportedFeatures: g.ghesInfo.UnsupportedFeatures(),
+	}
+	if g.ghesInfo != nil {
+		s.GithubServerVersion = g.ghesInfo.InstalledVersion
 	}
 	if g.lastSyncError != nil {
 		s.LastSyncError = g.lastSyncError.Error()
@@ -799,7 +1109,7 @@ func (g *GoliacServerImpl) PostResync(params app.PostResyncParams) middleware.Re
 			ctx, span = tracer.Start(ctx, "backgroundResync")
 			defer span.End()
 		}
-		g.triggerApply(ctx)
+		g.triggerApply(ctx, nil)
 	}()
 
 	return app.NewPostResyncOK()
@@ -827,8 +1137,8 @@ func (g *GoliacServerImpl) PostExternalCreateRepository(params external.PostExte
 		params.Body.TeamName,
 		params.Body.Visibility,
 		params.Body.DefaultBranch,
-		config.Config.ServerGitRepository,
-		config.Config.ServerGitBranch,
+		g.repository,
+		g.branch,
 	)
 
 	if logsCollector.HasErrors() {
@@ -867,44 +1177,21 @@ func (g *GoliacServerImpl) Serve() {
 		config.Config.GithubWebhookPath != "" &&
 		config.Config.GithubWebhookSecret != "" &&
 		config.Config.GithubWebhookDedicatedPort != config.Config.SwaggerPort {
-		webhookserver = NewGithubWebhookServerImpl(
-			config.Config.GithubWebhookDedicatedHost,
-			config.Config.GithubWebhookDedicatedPort,
-			config.Config.GithubWebhookPath,
-			config.Config.GithubWebhookSecret,
-			config.Config.GithubAppOrganization,
-			config.Config.ServerGitRepository,
-			config.Config.ServerGitBranch, func() {
-				// when receiving a Github webhook event
-				// let's start the apply process asynchronously
-				go func() {
-					ctx := context.Background()
-					var span trace.Span
-					if config.Config.OpenTelemetryEnabled {
-						tracer := otel.Tracer("goliac")
-						ctx, span = tracer.Start(ctx, "github-webhook")
-					}
-					g.triggerApply(ctx)
-					if span != nil {
-						span.End()
-					}
-				}()
-			},
-			func(organization, repository, prUrl, githubIdCaller, comment string, comment_id int) {
-				go func() {
-					ctx := context.Background()
-					var span trace.Span
-					if config.Config.OpenTelemetryEnabled {
-						tracer := otel.Tracer("goliac")
-						ctx, span = tracer.Start(ctx, "github-webhook")
-					}
-					g.handleIssueComment(ctx, organization, repository, prUrl, githubIdCaller, comment, comment_id)
-					if span != nil {
-						span.End()
-					}
-				}()
-			},
-		)
+		if len(g.organizationServers) > 1 {
+			// the events are dispatched to the webhook handler of their organization
+			handlers := make(map[string]http.HandlerFunc)
+			for name, server := range g.organizationServers {
+				handlers[name] = server.newGithubWebhookServer().WebhookHandler
+			}
+			webhookserver = NewGithubWebhookOrganizationsServer(
+				config.Config.GithubWebhookDedicatedHost,
+				config.Config.GithubWebhookDedicatedPort,
+				config.Config.GithubWebhookPath,
+				handlers,
+			)
+		} else {
+			webhookserver = g.newGithubWebhookServer()
+		}
 		go func() {
 			if err := webhookserver.Start(); err != nil {
 				logrus.Fatal(err)
@@ -914,42 +1201,33 @@ func (g *GoliacServerImpl) Serve() {
 	}
 
 	logrus.Info("Server started")
-	// Start the goroutine
+	// Start the goroutines (one apply loop per organization)
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		g.syncInterval = 0
-		for {
-			select {
-			case <-stopCh:
-				restserver.Shutdown()
-				if webhookserver != nil {
-					webhookserver.Shutdown()
-				}
-				return
-			default:
-				g.syncInterval--
-				time.Sleep(1 * time.Second)
-				if g.syncInterval <= 0 {
-					// we want to forceSync.
-					// because we want to reconciliate even if there
-					// is no new commit
-					// (and also it will populate the lastUnmanaged structure)
-
-					ctx := context.Background()
-					var span trace.Span
-					if config.Config.OpenTelemetryEnabled {
-						tracer := otel.Tracer("cronjob-tracer")
-						ctx, span = tracer.Start(ctx, "cronjob")
-					}
-					g.triggerApply(ctx)
-					if span != nil {
-						span.End()
-					}
-				}
-			}
+		g.applyLoop(stopCh)
+		restserver.Shutdown()
+		if webhookserver != nil {
+			webhookserver.Shutdown()
 		}
 	}()
+	for _, server := range g.organizationServers {
+		if server == g {
+			continue
+		}
+		wg.Add(1)
+		go func(server *GoliacServerImpl) {
+			defer wg.Done()
+			server.applyLoop(stopCh)
+		}(server)
+	}
+	if g.enterprise != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			g.enterpriseLoop(stopCh)
+		}()
+	}
 
 	// Handle OS signals
 	signalCh := make(chan os.Signal, 1)
@@ -962,6 +1240,174 @@ func (g *GoliacServerImpl) Serve() {
 	config.ShutdownTraceProvider()
 }
 
+/*
+applyLoop applies the teams repository periodically (GOLIAC_SERVER_APPLY_INTERVAL)
+until stopCh is closed
+*/
+func (g *GoliacServerImpl) applyLoop(stopCh chan struct{}) {
+	g.syncInterval = 0
+	for {
+		select {
+		case <-stopCh:
+			return
+		default:
+			g.syncInterval--
+			time.Sleep(1 * time.Second)
+			if g.syncInterval <= 0 {
+				// we want to forceSync.
+				// because we want to reconciliate even if there
+				// is no new commit
+				// (and also it will populate the lastUnmanaged structure)
+
+				ctx := context.Background()
+				var span trace.Span
+				if config.Config.OpenTelemetryEnabled {
+					tracer := otel.Tracer("cronjob-tracer")
+					ctx, span = tracer.Start(ctx, "cronjob")
+				}
+				g.triggerApply(ctx, nil)
+				if span != nil {
+					span.End()
+				}
+			}
+		}
+	}
+}
+
+/*
+enterpriseLoop syncs the enterprise teams periodically (GOLIAC_SERVER_APPLY_INTERVAL)
+until stopCh is closed
+*/
+func (g *GoliacServerImpl) enterpriseLoop(stopCh chan struct{}) {
+	interval := int64(0)
+	for {
+		select {
+		case <-stopCh:
+			return
+		default:
+			interval--
+			time.Sleep(1 * time.Second)
+			if interval <= 0 {
+				g.applyEnterprise(context.Background())
+				interval = config.Config.ServerApplyInterval
+			}
+		}
+	}
+}
+
+func (g *GoliacServerImpl) applyEnterprise(ctx context.Context) {
+	var users map[string]*entity.User
+	if server, ok := g.organizationServers[g.enterprise.config.UsersFrom]; ok {
+		users = server.goliac.GetLocal().Users()
+	}
+
+	logsCollector := observability.NewLogCollection()
+	g.enterprise.Apply(ctx, logsCollector, users, false)
+	for _, info := range logsCollector.Logs {
+		logrus.WithFields(info.Fields).Logf(info.LogLevel, info.Format, info.Args...)
+	}
+	for _, w := range logsCollector.Warns {
+		logrus.Warn(w)
+	}
+
+	previousError := g.lastEnterpriseError
+	g.lastEnterpriseError = nil
+	if logsCollector.HasErrors() {
+		g.lastEnterpriseError = logsCollector.Errors[len(logsCollector.Errors)-1]
+	}
+	// log the error only if it's a new one
+	if g.lastEnterpriseError != nil && (previousError == nil || g.lastEnterpriseError.Error() != previousError.Error()) {
+		logrus.Error(g.lastEnterpriseError)
+		if err := g.notificationService.SendNotification(fmt.Sprintf("Goliac error when syncing the enterprise: %s", g.lastEnterpriseError)); err != nil {
+			logrus.Error(err)
+		}
+	}
+}
+
+/*
+newGithubWebhookServer returns the webhook server of the organization
+(its events trigger the apply, the PR plan comments and the remote cache updates)
+*/
+func (g *GoliacServerImpl) newGithubWebhookServer() *GithubWebhookServerImpl {
+	var pullRequestCallback GithubWebhookServerPullRequestCallback
+	if config.Config.ServerPullRequestPlanComment || config.Config.ServerPullRequestReviewers {
+		pullRequestCallback = func(organization, repository, prUrl, headBranch, headSha string) {
+			go func() {
+				ctx := context.Background()
+				var span trace.Span
+				if config.Config.OpenTelemetryEnabled {
+					tracer := otel.Tracer("goliac")
+					ctx, span = tracer.Start(ctx, "github-webhook")
+				}
+				g.handlePullRequest(ctx, organization, repository, prUrl, headBranch, headSha)
+				if span != nil {
+					span.End()
+				}
+			}()
+		}
+	}
+	return NewGithubWebhookServerImpl(
+		config.Config.GithubWebhookDedicatedHost,
+		config.Config.GithubWebhookDedicatedPort,
+		config.Config.GithubWebhookPath,
+		config.Config.GithubWebhookSecret,
+		g.organization,
+		g.repository,
+		g.branch, func(changedFiles []string) {
+			// when receiving a Github webhook event
+			// let's start the apply process asynchronously
+			go func() {
+				ctx := context.Background()
+				var span trace.Span
+				if config.Config.OpenTelemetryEnabled {
+					tracer := otel.Tracer("goliac")
+					ctx, span = tracer.Start(ctx, "github-webhook")
+				}
+				var only []string
+				if config.Config.ServerTargetedApply {
+					only = ApplyScopeFromChangedFiles(changedFiles)
+				}
+				g.triggerApply(ctx, only)
+				if span != nil {
+					span.End()
+				}
+			}()
+		},
+		func(organization, repository, prUrl, githubIdCaller, comment string, comment_id int) {
+			go func() {
+				ctx := context.Background()
+				var span trace.Span
+				if config.Config.OpenTelemetryEnabled {
+					tracer := otel.Tracer("goliac")
+					ctx, span = tracer.Start(ctx, "github-webhook")
+				}
+				g.handleIssueComment(ctx, organization, repository, prUrl, githubIdCaller, comment, comment_id)
+				if span != nil {
+					span.End()
+				}
+			}()
+		},
+		pullRequestCallback,
+		func(event *engine.GithubOrgEvent) {
+			go func() {
+				ctx := context.Background()
+				var span trace.Span
+				if config.Config.OpenTelemetryEnabled {
+					tracer := otel.Tracer("goliac")
+					ctx, span = tracer.Start(ctx, "github-webhook-org-event")
+				}
+				if g.goliac.HandleOrgEvent(ctx, event) && config.Config.GithubWebhookReconcile {
+					// revert the change made outside of Goliac
+					g.triggerApply(ctx, nil)
+				}
+				if span != nil {
+					span.End()
+				}
+			}()
+		},
+	).(*GithubWebhookServerImpl)
+}
+
 // handleIssueComment handles the issue comment event
 // it is mainly used for PR comments
 func (g *GoliacServerImpl) handleIssueComment(ctx context.Context, organization, repository, prUrl, githubIdCaller, comment string, comment_id int) {
@@ -998,18 +1444,9 @@ func (g *GoliacServerImpl) handleIssueComment(ctx context.Context, organization,
 }
 
 func (g *GoliacServerImpl) handleIssueCommandExecuteWorkflow(ctx context.Context, organization, repository, prUrl, githubIdCaller, workflowInstance, workflowName, explanation string) {
-	found := false
-	// list enabled workflows
-	for _, workflowName := range g.goliac.GetLocal().RepoConfig().Workflows {
-		// list available workflows
-		for name := range g.goliac.GetLocal().Workflows() {
-			if name == workflowName {
-				found = true
-				break
-			}
-		}
-	}
-	if !found {
+	// the workflow must be enabled and defined
+	_, found := g.goliac.GetLocal().Workflows()[workflowName]
+	if !found || !slices.Contains(g.goliac.GetLocal().RepoConfig().Workflows, workflowName) {
 		g.handleIssueCommandProvideHelp(ctx, organization, repository, prUrl, githubIdCaller, workflowInstance)
 		return
 	}
@@ -1047,6 +1484,7 @@ func (g *GoliacServerImpl) handleIssueCommandExecuteWorkflow(ctx context.Context
 				},
 				false,
 			)
+			g.recordWorkflowExecution(workflowName, err)
 
 			if err != nil {
 				err = g.CreateComment(
@@ -1209,15 +1647,27 @@ func (g *GoliacServerImpl) CreateComment(ctx context.Context, organization, repo
 triggerApply will trigger the apply process (by calling serveApply())
 inside serverApply, it will check if the lobby is free
 - if the lobby is free, it will start the apply process
-- if the lobby is busy, it will do nothing
+- if the lobby is busy, it will do nothing (except adding the resources to apply to the waiting run)
+if only is not empty, only these resources (repo:<name>, team:<name>, ruleset:<name>) are applied
 */
-func (g *GoliacServerImpl) triggerApply(ctx context.Context) {
+func (g *GoliacServerImpl) triggerApply(ctx context.Context, only []string) {
 	logsCollector := observability.NewLogCollection()
-	applied := g.serveApply(ctx, logsCollector)
+	applied := g.serveApply(ctx, logsCollector, only)
 	for _, info := range logsCollector.Logs {
 		logrus.WithFields(info.Fields).Logf(info.LogLevel, info.Format, info.Args...)
 	}
 
+	if len(only) > 0 {
+		// a targeted apply doesn't replace the next (full) apply
+		for _, err := range logsCollector.Errors {
+			logrus.Error(err)
+		}
+		for _, w := range logsCollector.Warns {
+			logrus.Warn(w)
+		}
+		return
+	}
+
 	if !applied && !logsCollector.HasErrors() {
 		// the run was skipped
 		g.syncInterval = config.Config.ServerApplyInterval
@@ -1262,6 +1712,36 @@ func (g *GoliacServerImpl) triggerApply(ctx context.Context) {
 }
 
 func (g *GoliacServerImpl) StartRESTApi() (*restapi.Server, error) {
+	api, err := g.newGoliacAPI()
+	if err != nil {
+		return nil, err
+	}
+
+	server := restapi.NewServer(api)
+
+	server.Host = config.Config.SwaggerHost
+	server.Port = config.Config.SwaggerPort
+
+	server.ConfigureAPI()
+
+	handler := api.Serve(nil)
+	if len(g.organizationServers) > 1 {
+		handlers := make(map[string]http.Handler)
+		for name, orgServer := range g.organizationServers {
+			orgApi, err := orgServer.newGoliacAPI()
+			if err != nil {
+				return nil, err
+			}
+			handlers[name] = orgApi.Serve(nil)
+		}
+		handler = NewOrganizationsHandler(handler, handlers)
+	}
+	server.SetHandler(config.SetupGlobalMiddleware(NewMetricsHandler(handler)))
+
+	return server, nil
+}
+
+func (g *GoliacServerImpl) newGoliacAPI() (*operations.GoliacAPI, error) {
 	swaggerSpec, err := loads.Embedded(restapi.SwaggerJSON, restapi.FlatSwaggerJSON)
 	if err != nil {
 		return nil, err
@@ -1280,9 +1760,12 @@ func (g *GoliacServerImpl) StartRESTApi() (*restapi.Server, error) {
 	api.AppGetStatusHandler = app.GetStatusHandlerFunc(g.GetStatus)
 	api.AppGetStatiticsHandler = app.GetStatiticsHandlerFunc(g.GetStatistics)
 	api.AppGetUnmanagedHandler = app.GetUnmanagedHandlerFunc(g.GetUnmanaged)
+	api.AppGetAccessReportHandler = app.GetAccessReportHandlerFunc(g.GetAccessReport)
+	api.AppGetAccessExplanationHandler = app.GetAccessExplanationHandlerFunc(g.GetAccessExplanation)
 
 	api.AppGetUsersHandler = app.GetUsersHandlerFunc(g.GetUsers)
 	api.AppGetUserHandler = app.GetUserHandlerFunc(g.GetUser)
+	api.AppGetEnterpriseUserHandler = app.GetEnterpriseUserHandlerFunc(g.GetEnterpriseUser)
 	api.AppGetCollaboratorsHandler = app.GetCollaboratorsHandlerFunc(g.GetCollaborators)
 	api.AppGetCollaboratorHandler = app.GetCollaboratorHandlerFunc(g.GetCollaborator)
 	api.AppGetTeamsHandler = app.GetTeamsHandlerFunc(g.GetTeams)
@@ -1296,25 +1779,88 @@ func (g *GoliacServerImpl) StartRESTApi() (*restapi.Server, error) {
 	api.AuthGetWorkflowsHandler = auth.GetWorkflowsHandlerFunc(g.AuthWorkflows)
 	api.AuthGetWorkflowHandler = auth.GetWorkflowHandlerFunc(g.AuthGetWorkflow)
 	api.AuthPostWorkflowHandler = auth.PostWorkflowHandlerFunc(g.AuthPostWorkflow)
+	api.AuthPostAdoptHandler = auth.PostAdoptHandlerFunc(g.AuthPostAdopt)
 
 	api.ExternalPostExternalCreateRepositoryHandler = external.PostExternalCreateRepositoryHandlerFunc(g.PostExternalCreateRepository)
 
-	server := restapi.NewServer(api)
+	return api, nil
+}
 
-	server.Host = config.Config.SwaggerHost
-	server.Port = config.Config.SwaggerPort
+/*
+organizationNames returns the organizations managed by the server (sorted)
+*/
+func (g *GoliacServerImpl) organizationNames() []string {
+	if len(g.organizationServers) == 0 {
+		return []string{g.organization}
+	}
+	names := make([]string, 0, len(g.organizationServers))
+	for name := range g.organizationServers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
 
-	server.ConfigureAPI()
+const ORGANIZATIONS_API_PREFIX = "/api/v1/orgs/"
 
-	return server, nil
+/*
+NewOrganizationsHandler routes the /api/v1/orgs/<organization>/... requests to the
+REST API of the organization (as /api/v1/...), and the other requests to defaultHandler
+*/
+func NewOrganizationsHandler(defaultHandler http.Handler, handlers map[string]http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, found := strings.CutPrefix(r.URL.Path, ORGANIZATIONS_API_PREFIX)
+		if !found {
+			defaultHandler.ServeHTTP(w, r)
+			return
+		}
+		organization, apiPath, _ := strings.Cut(rest, "/")
+		handler, ok := handlers[organization]
+		if !ok {
+			message := fmt.Sprintf("organization %s not managed by Goliac", organization)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(&models.Error{Message: &message})
+			return
+		}
+		orgRequest := r.Clone(r.Context())
+		orgRequest.URL.Path = "/api/v1/" + apiPath
+		orgRequest.URL.RawPath = ""
+		orgRequest.RequestURI = orgRequest.URL.RequestURI()
+		handler.ServeHTTP(w, orgRequest)
+	})
+}
+
+const METRICS_PATH = "/metrics"
+
+/*
+NewMetricsHandler serves the metrics (of all the organizations) on /metrics,
+in the Prometheus text format, and the other requests with defaultHandler
+*/
+func NewMetricsHandler(defaultHandler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != METRICS_PATH {
+			defaultHandler.ServeHTTP(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := observability.Metrics.Write(w); err != nil {
+			logrus.Errorf("not able to write the metrics: %v", err)
+		}
+	})
 }
 
-func (g *GoliacServerImpl) serveApply(ctx context.Context, logsCollector *observability.LogCollection) bool {
+func (g *GoliacServerImpl) serveApply(ctx context.Context, logsCollector *observability.LogCollection, only []string) bool {
 	// we want to run ApplyToGithub
 	// and queue one new run (the lobby) if a new run is asked
 	g.applyLobbyMutex.Lock()
 	// we already have a current run, and another waiting in the lobby
 	if g.applyLobby {
+		g.applyLobbyOnly = mergeApplyScopes(g.applyLobbyOnly, only)
 		g.applyLobbyMutex.Unlock()
 		return false
 	}
@@ -1323,9 +1869,11 @@ func (g *GoliacServerImpl) serveApply(ctx context.Context, logsCollector *observ
 		g.applyCurrent = true
 	} else {
 		g.applyLobby = true
+		g.applyLobbyOnly = only
 		for g.applyLobby {
 			g.applyLobbyCond.Wait()
 		}
+		only = g.applyNextOnly
 	}
 	g.applyLobbyMutex.Unlock()
 
@@ -1334,6 +1882,7 @@ func (g *GoliacServerImpl) serveApply(ctx context.Context, logsCollector *observ
 		g.applyLobbyMutex.Lock()
 		if g.applyLobby {
 			g.applyLobby = false
+			g.applyNextOnly = g.applyLobbyOnly
 			g.applyLobbyCond.Signal()
 		} else {
 			g.applyCurrent = false
@@ -1341,8 +1890,8 @@ func (g *GoliacServerImpl) serveApply(ctx context.Context, logsCollector *observ
 		g.applyLobbyMutex.Unlock()
 	}()
 
-	repo := config.Config.ServerGitRepository
-	branch := config.Config.ServerGitBranch
+	repo := g.repository
+	branch := g.branch
 
 	if repo == "" {
 		logsCollector.AddError(fmt.Errorf("GOLIAC_SERVER_GIT_REPOSITORY env variable not set"))
@@ -1361,11 +1910,12 @@ func (g *GoliacServerImpl) serveApply(ctx context.Context, logsCollector *observ
 	newctx := context.WithValue(ctx, config.ContextKeyStatistics, &stats)
 
 	fs := osfs.New("/")
-	unmanaged := g.goliac.Apply(newctx, logsCollector, fs, false, repo, branch)
+	unmanaged := g.goliac.Apply(newctx, logsCollector, fs, false, repo, branch, only)
+	endTime := time.Now()
+	g.recordApplyMetrics(logsCollector, endTime.Sub(startTime), unmanaged, only)
 	if logsCollector.HasErrors() {
 		return false
 	}
-	endTime := time.Now()
 	g.lastTimeToApply = endTime.Sub(startTime)
 	g.lastStatistics.GithubApiCalls = stats.GithubApiCalls
 	g.lastStatistics.GithubThrottled = stats.GithubThrottled
@@ -1382,9 +1932,106 @@ func (g *GoliacServerImpl) serveApply(ctx context.Context, logsCollector *observ
 		g.maxStatistics.GithubThrottled = stats.GithubThrottled
 	}
 
-	if unmanaged != nil {
+	// the unmanaged resources of a targeted apply are partial
+	if unmanaged != nil && len(only) == 0 {
 		g.lastUnmanaged = unmanaged
 	}
 
 	return true
 }
+
+/*
+recordApplyMetrics records the metrics of an apply: duration, result, changes applied
+and (for a full apply) the unmanaged resources
+*/
+func (g *GoliacServerImpl) recordApplyMetrics(logsCollector *observability.LogCollection, duration time.Duration, unmanaged *engine.UnmanagedResources, only []string) {
+	result := "success"
+	if logsCollector.HasErrors() {
+		result = "failure"
+	}
+	observability.ApplyTotal.Inc(g.organization, result)
+	observability.ApplyDurationSeconds.Observe(duration.Seconds(), g.organization)
+
+	for _, change := range PlanChangesFromLogs(logsCollector.Logs) {
+		observability.ChangesAppliedTotal.Inc(g.organization, change.Resource, planAction(change.Command))
+	}
+
+	if unmanaged != nil && len(only) == 0 && !logsCollector.HasErrors() {
+		observability.UnmanagedResources.Set(float64(len(unmanaged.Users)), g.organization, "users")
+		observability.UnmanagedResources.Set(float64(len(unmanaged.ExternallyManagedTeams)), g.organization, "externally_managed_teams")
+		observability.UnmanagedResources.Set(float64(len(unmanaged.Teams)), g.organization, "teams")
+		observability.UnmanagedResources.Set(float64(len(unmanaged.Repositories)), g.organization, "repositories")
+		observability.UnmanagedResources.Set(float64(len(unmanaged.RuleSets)), g.organization, "rulesets")
+	}
+}
+
+/*
+recordWorkflowExecution records the result of a workflow execution
+The workflow name comes from the caller (a PR comment or the API), so
+only a defined workflow is used as a label, to keep the metric cardinality bounded
+*/
+func (g *GoliacServerImpl) recordWorkflowExecution(workflowName string, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	if _, ok := g.goliac.GetLocal().Workflows()[workflowName]; !ok {
+		workflowName = "unknown"
+	}
+	observability.WorkflowExecutionsTotal.Inc(g.organization, workflowName, result)
+}
+
+/*
+mergeApplyScopes merges the resources to apply by 2 runs (an empty scope means all resources)
+*/
+func mergeApplyScopes(scope1, scope2 []string) []string {
+	if len(scope1) == 0 || len(scope2) == 0 {
+		return nil
+	}
+	merged := append([]string{}, scope1...)
+	for _, resource := range scope2 {
+		if !slices.Contains(merged, resource) {
+			merged = append(merged, resource)
+		}
+	}
+	return merged
+}
+
+/*
+ApplyScopeFromChangedFiles returns the resources (repo:<name>, team:<name>, ruleset:<name>)
+defined by the files changed in the teams repository, or nil if a full apply is needed
+(unknown changes, or changes of users, goliac.yaml, ...).
+A changed team also reconciles the repositories it owns (see engine.ScopeWithTeamsRepositories)
+*/
+func ApplyScopeFromChangedFiles(changedFiles []string) []string {
+	if len(changedFiles) == 0 {
+		return nil
+	}
+	resources := []string{}
+	add := func(resource string) {
+		if !slices.Contains(resources, resource) {
+			resources = append(resources, resource)
+		}
+	}
+	for _, f := range changedFiles {
+		f = strings.TrimPrefix(f, "/")
+		if path.Ext(f) != ".yaml" {
+			return nil
+		}
+		name := strings.TrimSuffix(path.Base(f), ".yaml")
+		dir := path.Dir(f)
+		switch {
+		case strings.HasPrefix(f, "teams/") && path.Base(f) == "team.yaml" && dir != "teams":
+			add(engine.SCOPE_KIND_TEAM + ":" + path.Base(dir))
+		case strings.HasPrefix(f, "teams/") && dir != "teams":
+			add(engine.SCOPE_KIND_REPOSITORY + ":" + name)
+		case dir == "archived":
+			add(engine.SCOPE_KIND_REPOSITORY + ":" + name)
+		case dir == "rulesets":
+			add(engine.SCOPE_KIND_RULESET + ":" + name)
+		default:
+			return nil
+		}
+	}
+	return resources
+}
@@ -2,21 +2,51 @@ package internal
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
+	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/go-openapi/runtime"
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/go-openapi/strfmt"
 	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/github"
+	"github.com/goliac-project/goliac/internal/observability"
 	"github.com/goliac-project/goliac/swagger_gen/models"
 	"github.com/goliac-project/goliac/swagger_gen/restapi/operations/auth"
 	"github.com/gorilla/sessions"
 	"golang.org/x/oauth2"
 )
 
+/*
+GithubOAuthEndpoint returns the OAuth endpoints of the Github server hosting the API:
+- https://api.github.com -> https://github.com/login/oauth/...
+- https://api.<subdomain>.ghe.com -> https://<subdomain>.ghe.com/login/oauth/...
+- https://<ghes host>/api/v3 -> https://<ghes host>/login/oauth/...
+*/
+func GithubOAuthEndpoint(githubServer string) oauth2.Endpoint {
+	webURL := "https://github.com"
+	if u, err := url.Parse(strings.TrimSuffix(githubServer, "/")); err == nil && u.Host != "" {
+		if u.Host == "api.github.com" || (strings.HasPrefix(u.Host, "api.") && strings.HasSuffix(u.Host, ".ghe.com")) {
+			u.Host = strings.TrimPrefix(u.Host, "api.")
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/api/v3")
+		u.RawQuery = ""
+		webURL = strings.TrimSuffix(u.String(), "/")
+	}
+	return oauth2.Endpoint{
+		AuthURL:  webURL + "/login/oauth/authorize",
+		TokenURL: webURL + "/login/oauth/access_token",
+	}
+}
+
 func (g *GoliacServerImpl) AuthGetLogin(params auth.GetAuthenticationLoginParams) middleware.Responder {
 	// Get the original URL user was trying to access
 
@@ -69,7 +99,7 @@ func (g *GoliacServerImpl) AuthGetCallback(params auth.GetAuthenticationCallback
 		return auth.NewGetAuthenticationCallbackDefault(404).WithPayload(&models.Error{Message: &message})
 	}
 
-	session, _ := g.sessionStore.Get(params.HTTPRequest, "auth-session")
+	session, _ := g.sessionStore.Get(params.HTTPRequest, SESSION_NAME)
 	session.Values["access_token"] = token.AccessToken
 	// session.Values["user"] = user.Login
 
@@ -117,32 +147,73 @@ func (g *GoliacServerImpl) GetUserInfo(ctx context.Context, ghuToken string) (*G
 }
 
 func (g *GoliacServerImpl) GetOrgMembership(ctx context.Context, ghuToken string, org string, username string) (bool, error) {
-	_, err := g.client.CallRestAPI(ctx, "/orgs/"+org+"/memberships/"+username, "", "GET", nil, &ghuToken)
+	body, err := g.client.CallRestAPI(ctx, "/orgs/"+org+"/memberships/"+username, "", "GET", nil, &ghuToken)
 	if err != nil {
+		// the user is not (or not anymore) a member of the organization
+		var statusErr *github.StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
 		return false, err
 	}
+	var membership struct {
+		State string `json:"state"`
+	}
+	// an invitation not accepted yet
+	if json.Unmarshal(body, &membership) == nil && membership.State == "pending" {
+		return false, nil
+	}
 	return true, nil
 }
 
+/*
+sessionCheck is the result of the last organization membership check of a session
+*/
+type sessionCheck struct {
+	userinfo  *GithubUserInfo
+	checkedAt time.Time
+}
+
+func sessionCheckKey(accessToken string) string {
+	hash := sha256.Sum256([]byte(accessToken))
+	return hex.EncodeToString(hash[:])
+}
+
 /*
 helperCheckOrgMembership checks if the user is in the required GitHub organization.
 If the user is not in the organization, it returns an error.
+The membership is checked again every GOLIAC_SERVER_SESSION_MEMBERSHIP_CHECK_INTERVAL seconds,
+so a session is rejected once the user is removed from the organization.
 */
 func (g *GoliacServerImpl) helperCheckOrgMembership(req *http.Request) (*GithubUserInfo, int, *models.Error) {
 	// Retrieve the access token from the session
-	session, _ := g.sessionStore.Get(req, "auth-session")
+	session, _ := g.sessionStore.Get(req, SESSION_NAME)
 	accessToken, ok := session.Values["access_token"].(string)
 	if !ok {
 		message := "Access token not found"
 		return nil, 401, &models.Error{Message: &message}
 	}
+
+	key := sessionCheckKey(accessToken)
+	interval := time.Duration(config.Config.ServerSessionMembershipCheckInterval) * time.Second
+	g.sessionChecksMutex.Lock()
+	check, found := g.sessionChecks[key]
+	g.sessionChecksMutex.Unlock()
+	if found && time.Since(check.checkedAt) < interval {
+		return check.userinfo, 200, nil
+	}
+
 	userinfo, err := g.GetUserInfo(req.Context(), accessToken)
 	if err != nil {
 		message := "Failed to get user info"
 		return nil, 401, &models.Error{Message: &message}
 	}
 	// Check organization membership
-	isMember, err := g.GetOrgMembership(req.Context(), accessToken, config.Config.GithubAppOrganization, userinfo.Login)
+	isMember, err := g.GetOrgMembership(req.Context(), accessToken, g.organization, userinfo.Login)
+
+	g.sessionChecksMutex.Lock()
+	defer g.sessionChecksMutex.Unlock()
+	delete(g.sessionChecks, key)
 	if err != nil {
 		message := "Failed to check organization membership"
 		return nil, 401, &models.Error{Message: &message}
@@ -152,6 +223,20 @@ func (g *GoliacServerImpl) helperCheckOrgMembership(req *http.Request) (*GithubU
 		return nil, 403, &models.Error{Message: &message}
 	}
 
+	if g.sessionChecks == nil {
+		g.sessionChecks = make(map[string]*sessionCheck)
+	}
+	// forget the sessions not used anymore
+	for k, c := range g.sessionChecks {
+		if time.Since(c.checkedAt) >= interval {
+			delete(g.sessionChecks, k)
+		}
+	}
+	g.sessionChecks[key] = &sessionCheck{
+		userinfo:  userinfo,
+		checkedAt: time.Now(),
+	}
+
 	return userinfo, 200, nil
 }
 
@@ -222,6 +307,7 @@ func (g *GoliacServerImpl) AuthPostWorkflow(params auth.PostWorkflowParams) midd
 		params.Body.Explanation,
 		properties,
 		false)
+	g.recordWorkflowExecution(params.WorkflowName, err)
 	if err != nil {
 		message := fmt.Sprintf("Failed to execute workflow: %s", err.Error())
 		return auth.NewPostWorkflowDefault(500).WithPayload(&models.Error{Message: &message})
@@ -253,3 +339,40 @@ func (g *GoliacServerImpl) AuthWorkflows(params auth.GetWorkflowsParams) middlew
 
 	return auth.NewGetWorkflowsOK().WithPayload(workflows)
 }
+
+/*
+AuthPostAdopt opens a Pull Request (on behalf of the authenticated user)
+to bring unmanaged resources under Goliac management.
+*/
+func (g *GoliacServerImpl) AuthPostAdopt(params auth.PostAdoptParams) middleware.Responder {
+	_, codestatus, merr := g.helperCheckOrgMembership(params.HTTPRequest)
+
+	if merr != nil {
+		return auth.NewPostAdoptDefault(codestatus).WithPayload(merr)
+	}
+
+	// the PR is created on behalf of the user
+	session, _ := g.sessionStore.Get(params.HTTPRequest, SESSION_NAME)
+	accessToken, _ := session.Values["access_token"].(string)
+
+	logsCollector := observability.NewLogCollection()
+	prUrl := g.goliac.Adopt(
+		params.HTTPRequest.Context(),
+		logsCollector,
+		osfs.New("/"),
+		accessToken,
+		params.Body.Resources,
+		params.Body.OwnerTeam,
+		g.repository,
+		g.branch,
+	)
+
+	if logsCollector.HasErrors() {
+		message := fmt.Sprintf("Error when adopting resources: %s", logsCollector.Errors[0])
+		return auth.NewPostAdoptDefault(500).WithPayload(&models.Error{Message: &message})
+	}
+
+	return auth.NewPostAdoptOK().WithPayload(&auth.PostAdoptOKBody{
+		PullRequestURL: prUrl,
+	})
+}
@@ -76,6 +76,33 @@ func TestAuthGetLogin(t *testing.T) {
 	})
 }
 
+func TestGithubOAuthEndpoint(t *testing.T) {
+	t.Run("happy path: github.com", func(t *testing.T) {
+		endpoint := GithubOAuthEndpoint("https://api.github.com")
+		assert.Equal(t, "https://github.com/login/oauth/authorize", endpoint.AuthURL)
+		assert.Equal(t, "https://github.com/login/oauth/access_token", endpoint.TokenURL)
+	})
+
+	t.Run("happy path: GHE.com data residency", func(t *testing.T) {
+		endpoint := GithubOAuthEndpoint("https://api.acme.ghe.com/")
+		assert.Equal(t, "https://acme.ghe.com/login/oauth/authorize", endpoint.AuthURL)
+	})
+
+	t.Run("happy path: GHES", func(t *testing.T) {
+		endpoint := GithubOAuthEndpoint("https://github.mycompany.com/api/v3")
+		assert.Equal(t, "https://github.mycompany.com/login/oauth/authorize", endpoint.AuthURL)
+		assert.Equal(t, "https://github.mycompany.com/login/oauth/access_token", endpoint.TokenURL)
+
+		endpoint = GithubOAuthEndpoint("https://github.mycompany.com:8443")
+		assert.Equal(t, "https://github.mycompany.com:8443/login/oauth/authorize", endpoint.AuthURL)
+	})
+
+	t.Run("not happy path: invalid server", func(t *testing.T) {
+		endpoint := GithubOAuthEndpoint("")
+		assert.Equal(t, "https://github.com/login/oauth/authorize", endpoint.AuthURL)
+	})
+}
+
 func TestAuthGetCallback(t *testing.T) {
 	t.Run("happy path: ", func(t *testing.T) {
 		localfixture, remotefixture := fixtureGoliacLocal()
@@ -151,6 +178,7 @@ func TestGetUserInfo(t *testing.T) {
 		}
 		ghInfo, err := server.GetUserInfo(context.Background(), "aToken")
 		assert.Nil(t, err)
+		assert.Equal(t, "/user", server.client.(*GithubClientMock).lastEndpoint)
 		assert.Equal(t, "testuser", ghInfo.Login)
 		assert.Equal(t, "Test User", ghInfo.Name)
 	})
@@ -266,3 +294,59 @@ func TestAuthWorkflowsForcemerge(t *testing.T) {
 		assert.Equal(t, "fmtest", fmWorkflow.WorkflowName)
 	})
 }
+
+func TestAuthPostAdopt(t *testing.T) {
+	t.Run("happy path: ", func(t *testing.T) {
+		localfixture, remotefixture := fixtureGoliacLocal()
+		goliac := NewGoliacMock(localfixture, remotefixture, &GithubClientMock{})
+		server := GoliacServerImpl{
+			goliac:       goliac,
+			client:       &GithubClientMock{},
+			sessionStore: sessions.NewCookieStore([]byte("your-secret-key")),
+		}
+		httpRequest := &http.Request{
+			Method: "POST",
+			URL:    nil,
+			Header: http.Header{
+				"Authorization": []string{"Bearer test-token"},
+			},
+			Body: nil,
+		}
+		// set session
+		session, _ := server.sessionStore.Get(httpRequest, "auth-session")
+		session.Values["access_token"] = "my-access-token"
+
+		res := server.AuthPostAdopt(auth.PostAdoptParams{
+			HTTPRequest: httpRequest,
+			Body: auth.PostAdoptBody{
+				Resources: []string{"repo:repo1"},
+			},
+		})
+		payload := res.(*auth.PostAdoptOK)
+		assert.Equal(t, "https://github.com/org/teams/pull/1", payload.Payload.PullRequestURL)
+	})
+
+	t.Run("not happy path: no auth-session ", func(t *testing.T) {
+		localfixture, remotefixture := fixtureGoliacLocal()
+		goliac := NewGoliacMock(localfixture, remotefixture, &GithubClientMock{})
+		server := GoliacServerImpl{
+			goliac:       goliac,
+			client:       &GithubClientMock{},
+			sessionStore: sessions.NewCookieStore([]byte("your-secret-key")),
+		}
+		httpRequest := &http.Request{
+			Method: "POST",
+			URL:    nil,
+			Body:   nil,
+		}
+
+		res := server.AuthPostAdopt(auth.PostAdoptParams{
+			HTTPRequest: httpRequest,
+			Body: auth.PostAdoptBody{
+				Resources: []string{"repo:repo1"},
+			},
+		})
+		_, ok := res.(*auth.PostAdoptDefault)
+		assert.True(t, ok)
+	})
+}
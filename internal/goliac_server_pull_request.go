@@ -0,0 +1,453 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/engine"
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/gosimple/slug"
+	"github.com/sirupsen/logrus"
+)
+
+// used to find back (and update) the Goliac comments of a PR
+const (
+	PR_PLAN_COMMENT_MARKER      = "<!-- goliac-plan -->"
+	PR_REVIEWERS_COMMENT_MARKER = "<!-- goliac-reviewers -->"
+)
+
+var prReviewersTeamsRegex = regexp.MustCompile(`<!-- goliac-reviewers-teams:(.*) -->`)
+
+/*
+ * PlanChange is a change that would be applied to Github, extracted from the
+ * (dryrun) reconciliation logs
+ */
+type PlanChange struct {
+	Resource    string // organization, user, team, repository, ruleset
+	Name        string
+	Command     string
+	Details     string
+	Destructive bool
+}
+
+/*
+ * planResource returns the kind of resource changed by a reconciliation command
+ * (the order matters: update_repository_add_team is a repository change)
+ */
+func planResource(command string) string {
+	switch {
+	case strings.Contains(command, "org_custom_property"):
+		return "organization"
+	case strings.Contains(command, "repository"):
+		return "repository"
+	case strings.Contains(command, "ruleset"):
+		return "ruleset"
+	case strings.Contains(command, "team"):
+		return "team"
+	case strings.Contains(command, "user"):
+		return "user"
+	}
+	return "other"
+}
+
+/*
+ * planAction returns the action of a reconciliation command (add, create, update, rename, remove, delete)
+ */
+func planAction(command string) string {
+	action, _, _ := strings.Cut(command, "_")
+	return action
+}
+
+func planDestructive(command string) bool {
+	return strings.HasPrefix(command, "delete_") ||
+		strings.HasPrefix(command, "remove_") ||
+		strings.Contains(command, "_remove_")
+}
+
+/*
+ * PlanChangesFromLogs extracts the changes from the (dryrun) reconciliation logs
+ */
+func PlanChangesFromLogs(logs []observability.InfoEntry) []PlanChange {
+	changes := []PlanChange{}
+	for _, l := range logs {
+		command, ok := l.Fields["command"].(string)
+		if !ok || command == "" {
+			continue
+		}
+		if _, ok := l.Fields["dryrun"]; !ok {
+			continue
+		}
+		name := ""
+		if len(l.Args) > 0 {
+			name = fmt.Sprintf("%v", l.Args[0])
+		}
+		changes = append(changes, PlanChange{
+			Resource:    planResource(command),
+			Name:        name,
+			Command:     command,
+			Details:     fmt.Sprintf(l.Format, l.Args...),
+			Destructive: planDestructive(command),
+		})
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Resource != changes[j].Resource {
+			return changes[i].Resource < changes[j].Resource
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+func escapeMarkdownTableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+/*
+ * RenderPlanComment renders the markdown (sticky) comment posted on a teams repository PR
+ */
+func RenderPlanComment(headSha string, logsCollector *observability.LogCollection) string {
+	comment := PR_PLAN_COMMENT_MARKER + "\n"
+	comment += fmt.Sprintf("### Goliac plan (commit %s)\n\n", headSha)
+
+	if logsCollector.HasErrors() {
+		comment += ":x: Goliac is not able to plan this PR:\n\n"
+		for _, err := range logsCollector.Errors {
+			comment += fmt.Sprintf("- %s\n", escapeMarkdownTableCell(err.Error()))
+		}
+		return comment
+	}
+
+	changes := PlanChangesFromLogs(logsCollector.Logs)
+	if len(changes) == 0 {
+		comment += "No change to apply to Github.\n"
+	} else {
+		nbDestructive := 0
+		for _, c := range changes {
+			if c.Destructive {
+				nbDestructive++
+			}
+		}
+		if nbDestructive > 0 {
+			comment += fmt.Sprintf("> [!WARNING]\n> This PR contains %d destructive change(s) (marked with :warning:)\n\n", nbDestructive)
+		}
+
+		comment += fmt.Sprintf("%d change(s) will be applied to Github once merged:\n\n", len(changes))
+		comment += "| Resource | Name | Change | Details |\n"
+		comment += "|----------|------|--------|---------|\n"
+		for _, c := range changes {
+			change := c.Command
+			if c.Destructive {
+				change = ":warning: " + change
+			}
+			comment += fmt.Sprintf("| %s | %s | %s | %s |\n", c.Resource, escapeMarkdownTableCell(c.Name), change, escapeMarkdownTableCell(c.Details))
+		}
+	}
+
+	if logsCollector.HasWarns() {
+		comment += "\nWarnings:\n\n"
+		for _, w := range logsCollector.Warns {
+			comment += fmt.Sprintf("- %s\n", escapeMarkdownTableCell(w.Error()))
+		}
+	}
+
+	return comment
+}
+
+/*
+ * handlePullRequest handles a PR opened (or updated) on the teams repository:
+ * - it requests the reviews of the teams owning the changed files
+ * - it posts (or updates) the plan of the changes as a PR comment
+ */
+func (g *GoliacServerImpl) handlePullRequest(ctx context.Context, organization, repository, prUrl, headBranch, headSha string) {
+	logrus.Debugf("Pull request event received for organization %s, repository %s, prUrl %s, branch %s", organization, repository, prUrl, headBranch)
+
+	if config.Config.ServerPullRequestReviewers {
+		err := g.handlePullRequestReviewers(ctx, organization, repository, prUrl)
+		if err != nil {
+			logrus.Error("error when requesting the PR reviewers: " + err.Error())
+		}
+	}
+
+	if config.Config.ServerPullRequestPlanComment {
+		g.handlePullRequestPlan(ctx, organization, repository, prUrl, headBranch, headSha)
+	}
+}
+
+/*
+ * handlePullRequestPlan runs a dryrun reconciliation of a PR (of the teams repository)
+ * and posts (or updates) the plan as a PR comment
+ */
+func (g *GoliacServerImpl) handlePullRequestPlan(ctx context.Context, organization, repository, prUrl, headBranch, headSha string) {
+	repo := g.repository
+	if repo == "" {
+		logrus.Error("GOLIAC_SERVER_GIT_REPOSITORY env variable not set")
+		return
+	}
+
+	logsCollector := observability.NewLogCollection()
+	fs := osfs.New("/")
+	g.goliac.PlanBranch(ctx, logsCollector, fs, repo, g.branch, headBranch)
+
+	comment := RenderPlanComment(headSha, logsCollector)
+	err := g.UpsertStickyComment(ctx, organization, repository, prUrl, PR_PLAN_COMMENT_MARKER, comment)
+	if err != nil {
+		logrus.Error("error when creating the plan comment: " + err.Error())
+	}
+}
+
+/*
+ * handlePullRequestReviewers computes the teams owning the files changed by a PR
+ * (based on the CODEOWNERS rules), requests their reviews, and comments a summary.
+ * The teams are stored in the summary comment, to only (re-)request reviews
+ * when the affected teams change.
+ */
+func (g *GoliacServerImpl) handlePullRequestReviewers(ctx context.Context, organization, repository, prUrl string) error {
+	prNumber, err := extractPRNumber(prUrl)
+	if err != nil {
+		return err
+	}
+
+	repoconfig := g.goliac.GetLocal().RepoConfig()
+	if repoconfig == nil {
+		return fmt.Errorf("the teams repository is not loaded yet")
+	}
+
+	files, err := g.listPullRequestFiles(ctx, organization, repository, prNumber)
+	if err != nil {
+		return err
+	}
+	owners := engine.CodeOwnersTeams(g.goliac.GetLocal().Teams(), repoconfig.AdminTeam, files)
+
+	teams := make([]string, 0, len(owners))
+	for teamname := range owners {
+		teams = append(teams, teamname)
+	}
+	sort.Strings(teams)
+
+	_, previous, err := g.findStickyComment(ctx, organization, repository, prNumber, PR_REVIEWERS_COMMENT_MARKER)
+	if err != nil {
+		return err
+	}
+	if m := prReviewersTeamsRegex.FindStringSubmatch(previous); previous != "" && m != nil && m[1] == strings.Join(teams, ",") {
+		// the affected teams didn't change
+		return nil
+	}
+
+	reviewers := make(map[string]string) // teamname -> team slug to request the review from
+	for _, teamname := range teams {
+		if teamname == repoconfig.AdminTeam {
+			reviewers[teamname] = slug.Make(teamname)
+		} else {
+			reviewers[teamname] = slug.Make(teamname) + config.Config.GoliacTeamOwnerSuffix
+		}
+	}
+
+	if len(reviewers) > 0 {
+		teamReviewers := make([]string, 0, len(reviewers))
+		for _, teamname := range teams {
+			teamReviewers = append(teamReviewers, reviewers[teamname])
+		}
+		// https://docs.github.com/en/rest/pulls/review-requests?apiVersion=2022-11-28#request-reviewers-for-a-pull-request
+		_, err = g.goliac.GetRemoteClient().CallRestAPI(
+			ctx,
+			fmt.Sprintf("/repos/%s/%s/pulls/%s/requested_reviewers", organization, repository, prNumber),
+			"",
+			"POST",
+			map[string]interface{}{
+				"team_reviewers": teamReviewers,
+			},
+			nil,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	comment := RenderReviewersComment(organization, teams, reviewers, owners)
+	return g.UpsertStickyComment(ctx, organization, repository, prUrl, PR_REVIEWERS_COMMENT_MARKER, comment)
+}
+
+/*
+ * RenderReviewersComment renders the markdown (sticky) comment listing the teams affected by a PR
+ */
+func RenderReviewersComment(organization string, teams []string, reviewers map[string]string, owners map[string][]string) string {
+	comment := PR_REVIEWERS_COMMENT_MARKER + "\n"
+	comment += fmt.Sprintf("<!-- goliac-reviewers-teams:%s -->\n", strings.Join(teams, ","))
+	comment += "### Goliac reviewers\n\n"
+
+	if len(teams) == 0 {
+		comment += "This PR doesn't change any file.\n"
+		return comment
+	}
+
+	comment += "This PR changes files owned by the following teams, their review has been requested:\n\n"
+	comment += "| Team | Reviewers | Files |\n"
+	comment += "|------|-----------|-------|\n"
+	for _, teamname := range teams {
+		files := make([]string, 0, len(owners[teamname]))
+		for _, f := range owners[teamname] {
+			files = append(files, "`"+escapeMarkdownTableCell(f)+"`")
+		}
+		comment += fmt.Sprintf("| %s | @%s/%s | %s |\n", escapeMarkdownTableCell(teamname), organization, reviewers[teamname], strings.Join(files, "<br>"))
+	}
+	return comment
+}
+
+/*
+ * listPullRequestFiles returns the files changed by a PR
+ * (for a renamed file, both the previous and the new path)
+ */
+func (g *GoliacServerImpl) listPullRequestFiles(ctx context.Context, organization, repository, prNumber string) ([]string, error) {
+	files := []string{}
+	// https://docs.github.com/en/rest/pulls/pulls?apiVersion=2022-11-28#list-pull-requests-files
+	for page := 1; ; page++ {
+		body, err := g.goliac.GetRemoteClient().CallRestAPI(
+			ctx,
+			fmt.Sprintf("/repos/%s/%s/pulls/%s/files", organization, repository, prNumber),
+			fmt.Sprintf("page=%d&per_page=100", page),
+			"GET",
+			nil,
+			nil,
+		)
+		if err != nil {
+			return nil, err
+		}
+		var prFiles []struct {
+			Filename         string `json:"filename"`
+			PreviousFilename string `json:"previous_filename"`
+		}
+		if err := json.Unmarshal(body, &prFiles); err != nil {
+			return nil, fmt.Errorf("not able to unmarshall the PR files: %v", err)
+		}
+		for _, f := range prFiles {
+			files = append(files, f.Filename)
+			if f.PreviousFilename != "" {
+				files = append(files, f.PreviousFilename)
+			}
+		}
+		if len(prFiles) < 100 {
+			break
+		}
+	}
+	return files, nil
+}
+
+func extractPRNumber(prUrl string) (string, error) {
+	prExtract := regexp.MustCompile(`.*/([^/]*)/pull/(\d+)`)
+	prMatch := prExtract.FindStringSubmatch(prUrl)
+	if len(prMatch) != 3 {
+		return "", fmt.Errorf("prUrl %s is not a valid PR URL", prUrl)
+	}
+	return prMatch[2], nil
+}
+
+/*
+ * commentsAuthor returns the login of the author of the Goliac comments:
+ * the Github App bot (<app slug>[bot]), or the personal access token user
+ */
+func (g *GoliacServerImpl) commentsAuthor(ctx context.Context) (string, error) {
+	client := g.goliac.GetRemoteClient()
+	if slug := client.GetAppSlug(); slug != "" {
+		return slug + "[bot]", nil
+	}
+	body, err := client.CallRestAPI(ctx, "/user", "", "GET", nil, nil)
+	if err != nil {
+		return "", err
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.Unmarshal(body, &user); err != nil {
+		return "", fmt.Errorf("not able to unmarshall the user: %v", err)
+	}
+	if user.Login == "" {
+		return "", fmt.Errorf("not able to find the Goliac comments author")
+	}
+	return user.Login, nil
+}
+
+/*
+ * findStickyComment returns the id and the body of the PR comment (posted by Goliac)
+ * containing the marker (0 and "" if not found). The comments of other authors are
+ * ignored: anyone able to comment the PR could plant the marker
+ */
+func (g *GoliacServerImpl) findStickyComment(ctx context.Context, organization, repository, prNumber, marker string) (int, string, error) {
+	author, err := g.commentsAuthor(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+
+	// https://docs.github.com/en/rest/issues/comments?apiVersion=2022-11-28#list-issue-comments
+	for page := 1; ; page++ {
+		body, err := g.goliac.GetRemoteClient().CallRestAPI(
+			ctx,
+			fmt.Sprintf("/repos/%s/%s/issues/%s/comments", organization, repository, prNumber),
+			fmt.Sprintf("page=%d&per_page=100", page),
+			"GET",
+			nil,
+			nil,
+		)
+		if err != nil {
+			return 0, "", err
+		}
+		var comments []struct {
+			ID   int    `json:"id"`
+			Body string `json:"body"`
+			User struct {
+				Login string `json:"login"`
+			} `json:"user"`
+		}
+		if err := json.Unmarshal(body, &comments); err != nil {
+			return 0, "", fmt.Errorf("not able to unmarshall the PR comments: %v", err)
+		}
+
+		for _, c := range comments {
+			if strings.EqualFold(c.User.Login, author) && strings.Contains(c.Body, marker) {
+				return c.ID, c.Body, nil
+			}
+		}
+
+		if len(comments) < 100 {
+			break
+		}
+	}
+	return 0, "", nil
+}
+
+/*
+ * UpsertStickyComment updates the PR comment containing the marker, or creates it
+ */
+func (g *GoliacServerImpl) UpsertStickyComment(ctx context.Context, organization, repository, prUrl, marker, comment string) error {
+	prNumber, err := extractPRNumber(prUrl)
+	if err != nil {
+		return err
+	}
+
+	commentId, _, err := g.findStickyComment(ctx, organization, repository, prNumber, marker)
+	if err != nil {
+		return err
+	}
+	if commentId == 0 {
+		return g.CreateComment(ctx, organization, repository, prUrl, "", comment)
+	}
+
+	// https://docs.github.com/en/rest/issues/comments?apiVersion=2022-11-28#update-an-issue-comment
+	_, err = g.goliac.GetRemoteClient().CallRestAPI(
+		ctx,
+		fmt.Sprintf("/repos/%s/%s/issues/comments/%d", organization, repository, commentId),
+		"",
+		"PATCH",
+		map[string]interface{}{
+			"body": comment,
+		},
+		nil,
+	)
+	return err
+}
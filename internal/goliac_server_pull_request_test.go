@@ -0,0 +1,197 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goliac-project/goliac/internal/github"
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+type PullRequestGithubClientMock struct {
+	GithubClientMock
+	comments string
+	files    string
+	calls    []string
+	bodies   []map[string]interface{}
+}
+
+func (g *PullRequestGithubClientMock) CallRestAPI(ctx context.Context, endpoint, parameters, method string, body map[string]interface{}, githubToken *string) ([]byte, error) {
+	g.calls = append(g.calls, method+" "+endpoint)
+	g.lastBody = body
+	if body != nil {
+		g.bodies = append(g.bodies, body)
+	}
+	if method == "GET" {
+		if strings.HasSuffix(endpoint, "/files") {
+			return []byte(g.files), nil
+		}
+		return []byte(g.comments), nil
+	}
+	return nil, nil
+}
+
+// pullRequestGoliacMock returns a PullRequestGithubClientMock as the remote client
+type pullRequestGoliacMock struct {
+	*GoliacMock
+	client *PullRequestGithubClientMock
+}
+
+func (g *pullRequestGoliacMock) GetRemoteClient() github.GitHubClient {
+	return g.client
+}
+
+func TestRenderPlanComment(t *testing.T) {
+	t.Run("happy path: no change", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		comment := RenderPlanComment("abcdef", logsCollector)
+		assert.Contains(t, comment, PR_PLAN_COMMENT_MARKER)
+		assert.Contains(t, comment, "commit abcdef")
+		assert.Contains(t, comment, "No change to apply to Github.")
+	})
+
+	t.Run("happy path: changes and destructive changes", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		logsCollector.AddInfo(map[string]interface{}{"dryrun": true, "command": "update_repository_add_team"}, "repositoryname: %s, teamslug: %s, permission: %s", "repo1", "team1", "push")
+		logsCollector.AddInfo(map[string]interface{}{"dryrun": true, "command": "create_team"}, "teamname: %s, parentTeam: %s, members: %s", "team2", "nil", "user1")
+		logsCollector.AddInfo(map[string]interface{}{"dryrun": true, "command": "delete_repository"}, "repositoryname: %s", "repo2")
+		logsCollector.AddInfo(map[string]interface{}{"command": "adopt"}, "not a reconciliation change")
+		logsCollector.AddWarn(fmt.Errorf("a warning"))
+
+		changes := PlanChangesFromLogs(logsCollector.Logs)
+		assert.Equal(t, 3, len(changes))
+		assert.Equal(t, "repository", changes[0].Resource)
+		assert.Equal(t, "repo1", changes[0].Name)
+		assert.False(t, changes[0].Destructive)
+		assert.Equal(t, "repo2", changes[1].Name)
+		assert.True(t, changes[1].Destructive)
+		assert.Equal(t, "team", changes[2].Resource)
+
+		comment := RenderPlanComment("abcdef", logsCollector)
+		assert.Contains(t, comment, "3 change(s)")
+		assert.Contains(t, comment, "1 destructive change(s)")
+		assert.Contains(t, comment, "| repository | repo2 | :warning: delete_repository | repositoryname: repo2 |")
+		assert.Contains(t, comment, "| team | team2 | create_team |")
+		assert.Contains(t, comment, "- a warning")
+	})
+
+	t.Run("not happy path: errors", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		logsCollector.AddError(fmt.Errorf("invalid team"))
+		comment := RenderPlanComment("abcdef", logsCollector)
+		assert.Contains(t, comment, ":x: Goliac is not able to plan this PR")
+		assert.Contains(t, comment, "- invalid team")
+	})
+}
+
+func TestUpsertStickyComment(t *testing.T) {
+	t.Run("happy path: create the comment", func(t *testing.T) {
+		localfixture, remotefixture := fixtureGoliacLocal()
+		client := &PullRequestGithubClientMock{comments: `[{"id": 1, "body": "LGTM"}]`}
+		server := GoliacServerImpl{
+			goliac: &pullRequestGoliacMock{GoliacMock: &GoliacMock{local: localfixture, remote: remotefixture}, client: client},
+		}
+
+		err := server.UpsertStickyComment(context.TODO(), "org", "teams", "https://github.com/org/teams/pull/3", PR_PLAN_COMMENT_MARKER, PR_PLAN_COMMENT_MARKER+"\nplan")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"GET /repos/org/teams/issues/3/comments", "POST /repos/org/teams/issues/3/comments"}, client.calls)
+	})
+
+	t.Run("happy path: update the comment", func(t *testing.T) {
+		localfixture, remotefixture := fixtureGoliacLocal()
+		client := &PullRequestGithubClientMock{comments: `[{"id": 1, "body": "LGTM"},{"id": 42, "user": {"login": "test-app-slug[bot]"}, "body": "` + PR_PLAN_COMMENT_MARKER + `\nold plan"}]`}
+		server := GoliacServerImpl{
+			goliac: &pullRequestGoliacMock{GoliacMock: &GoliacMock{local: localfixture, remote: remotefixture}, client: client},
+		}
+
+		err := server.UpsertStickyComment(context.TODO(), "org", "teams", "https://github.com/org/teams/pull/3", PR_PLAN_COMMENT_MARKER, PR_PLAN_COMMENT_MARKER+"\nnew plan")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"GET /repos/org/teams/issues/3/comments", "PATCH /repos/org/teams/issues/comments/42"}, client.calls)
+		assert.Equal(t, PR_PLAN_COMMENT_MARKER+"\nnew plan", client.lastBody["body"])
+	})
+
+	t.Run("not happy path: marker planted by another user", func(t *testing.T) {
+		localfixture, remotefixture := fixtureGoliacLocal()
+		client := &PullRequestGithubClientMock{comments: `[{"id": 42, "user": {"login": "attacker"}, "body": "` + PR_PLAN_COMMENT_MARKER + `\nfake plan"}]`}
+		server := GoliacServerImpl{
+			goliac: &pullRequestGoliacMock{GoliacMock: &GoliacMock{local: localfixture, remote: remotefixture}, client: client},
+		}
+
+		err := server.UpsertStickyComment(context.TODO(), "org", "teams", "https://github.com/org/teams/pull/3", PR_PLAN_COMMENT_MARKER, PR_PLAN_COMMENT_MARKER+"\nplan")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"GET /repos/org/teams/issues/3/comments", "POST /repos/org/teams/issues/3/comments"}, client.calls)
+	})
+}
+
+func TestHandlePullRequestReviewers(t *testing.T) {
+	t.Run("happy path: request the reviews of the affected teams", func(t *testing.T) {
+		localfixture, remotefixture := fixtureGoliacLocal()
+		localfixture.repoconfig.AdminTeam = "admin"
+		client := &PullRequestGithubClientMock{
+			comments: `[]`,
+			files:    `[{"filename": "teams/ateam/repo1.yaml"},{"filename": "teams/mixteam/repo2.yaml", "previous_filename": "teams/ateam/repo2.yaml"},{"filename": "goliac.yaml"}]`,
+		}
+		server := GoliacServerImpl{
+			goliac: &pullRequestGoliacMock{GoliacMock: &GoliacMock{local: localfixture, remote: remotefixture}, client: client},
+		}
+
+		err := server.handlePullRequestReviewers(context.TODO(), "org", "teams", "https://github.com/org/teams/pull/3")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"GET /repos/org/teams/pulls/3/files",
+			"GET /repos/org/teams/issues/3/comments",
+			"POST /repos/org/teams/pulls/3/requested_reviewers",
+			"GET /repos/org/teams/issues/3/comments",
+			"POST /repos/org/teams/issues/3/comments",
+		}, client.calls)
+		assert.Equal(t, []string{"admin", "ateam-goliac-owners", "mixteam-goliac-owners"}, client.bodies[0]["team_reviewers"])
+		comment := client.bodies[1]["body"].(string)
+		assert.Contains(t, comment, "<!-- goliac-reviewers-teams:admin,ateam,mixteam -->")
+		assert.Contains(t, comment, "| ateam | @org/ateam-goliac-owners | `teams/ateam/repo1.yaml`<br>`teams/ateam/repo2.yaml` |")
+	})
+
+	t.Run("happy path: the affected teams didn't change", func(t *testing.T) {
+		localfixture, remotefixture := fixtureGoliacLocal()
+		localfixture.repoconfig.AdminTeam = "admin"
+		client := &PullRequestGithubClientMock{
+			comments: `[{"id": 42, "user": {"login": "test-app-slug[bot]"}, "body": "<!-- goliac-reviewers -->\n<!-- goliac-reviewers-teams:ateam -->\n"}]`,
+			files:    `[{"filename": "teams/ateam/repo1.yaml"}]`,
+		}
+		server := GoliacServerImpl{
+			goliac: &pullRequestGoliacMock{GoliacMock: &GoliacMock{local: localfixture, remote: remotefixture}, client: client},
+		}
+
+		err := server.handlePullRequestReviewers(context.TODO(), "org", "teams", "https://github.com/org/teams/pull/3")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"GET /repos/org/teams/pulls/3/files",
+			"GET /repos/org/teams/issues/3/comments",
+		}, client.calls)
+	})
+
+	t.Run("happy path: the affected teams changed", func(t *testing.T) {
+		localfixture, remotefixture := fixtureGoliacLocal()
+		localfixture.repoconfig.AdminTeam = "admin"
+		client := &PullRequestGithubClientMock{
+			comments: `[{"id": 42, "user": {"login": "test-app-slug[bot]"}, "body": "<!-- goliac-reviewers -->\n<!-- goliac-reviewers-teams:ateam -->\n"}]`,
+			files:    `[{"filename": "teams/ateam/repo1.yaml"},{"filename": "teams/mixteam/repo2.yaml"}]`,
+		}
+		server := GoliacServerImpl{
+			goliac: &pullRequestGoliacMock{GoliacMock: &GoliacMock{local: localfixture, remote: remotefixture}, client: client},
+		}
+
+		err := server.handlePullRequestReviewers(context.TODO(), "org", "teams", "https://github.com/org/teams/pull/3")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"GET /repos/org/teams/pulls/3/files",
+			"GET /repos/org/teams/issues/3/comments",
+			"POST /repos/org/teams/pulls/3/requested_reviewers",
+			"GET /repos/org/teams/issues/3/comments",
+			"PATCH /repos/org/teams/issues/comments/42",
+		}, client.calls)
+		assert.Equal(t, []string{"ateam-goliac-owners", "mixteam-goliac-owners"}, client.bodies[0]["team_reviewers"])
+	})
+}
@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/sessions"
+	"github.com/sirupsen/logrus"
+)
+
+const SESSION_NAME = "auth-session"
+
+// how often the expired sessions files (GOLIAC_SERVER_SESSION_STORE_DIR) are removed
+const SESSION_CLEANUP_INTERVAL = time.Hour
+
+/*
+sessionKeyPairs derives, for each configured key, the key used to sign the cookies
+and the (AES-256) key used to encrypt them. The first key is used for the new
+cookies, the others are only used to decode the existing ones (keys rotation)
+*/
+func sessionKeyPairs(keys []string) [][]byte {
+	keyPairs := [][]byte{}
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		hashKey := sha256.Sum256([]byte("goliac-session-hash:" + key))
+		blockKey := sha256.Sum256([]byte("goliac-session-block:" + key))
+		keyPairs = append(keyPairs, hashKey[:], blockKey[:])
+	}
+	return keyPairs
+}
+
+func parseSameSite(sameSite string) (http.SameSite, error) {
+	switch strings.ToLower(sameSite) {
+	case "", "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	}
+	return http.SameSiteDefaultMode, fmt.Errorf("invalid SameSite value %s: expected lax, strict or none", sameSite)
+}
+
+/*
+sessionCookieSecure returns if the session cookie must only be sent over https:
+as configured (true or false), or if not set, depending on the scheme of the
+OAuth callback url (so the UI still works on a plain http deployment)
+*/
+func sessionCookieSecure(secure string, callbackURL string) (bool, error) {
+	if secure != "" {
+		value, err := strconv.ParseBool(secure)
+		if err != nil {
+			return false, fmt.Errorf("invalid secure value %s: expected true or false", secure)
+		}
+		return value, nil
+	}
+	u, err := url.Parse(callbackURL)
+	if err == nil && strings.EqualFold(u.Scheme, "http") {
+		return false, nil
+	}
+	return true, nil
+}
+
+/*
+cleanupSessionFiles removes the sessions files (of a sessions.FilesystemStore)
+not saved for more than maxAge seconds: the sessions are expired
+*/
+func cleanupSessionFiles(storeDir string, maxAge int) {
+	files, err := filepath.Glob(filepath.Join(storeDir, "session_*"))
+	if err != nil {
+		logrus.Debugf("not able to list the sessions files: %v", err)
+		return
+	}
+	expiration := time.Now().Add(-time.Duration(maxAge) * time.Second)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil || info.ModTime().After(expiration) {
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			logrus.Debugf("not able to remove the expired session file %s: %v", file, err)
+		}
+	}
+}
+
+/*
+NewSessionStore returns the store of the UI sessions:
+- cookies signed and encrypted with the keys (random keys if none is set)
+- stored server side (the cookie only contains the session id) if storeDir is set
+The expired sessions files are removed every SESSION_CLEANUP_INTERVAL.
+*/
+func NewSessionStore(keys []string, storeDir string, maxAge int, secure bool, sameSite string) (sessions.Store, error) {
+	keyPairs := sessionKeyPairs(keys)
+	if len(keyPairs) == 0 {
+		logrus.Warn("GOLIAC_SERVER_SESSION_KEYS is not set: using random session keys (the UI sessions will not survive a restart)")
+		hashKey := make([]byte, 32)
+		blockKey := make([]byte, 32)
+		if _, err := rand.Read(hashKey); err != nil {
+			return nil, err
+		}
+		if _, err := rand.Read(blockKey); err != nil {
+			return nil, err
+		}
+		keyPairs = [][]byte{hashKey, blockKey}
+	}
+
+	sameSiteMode, err := parseSameSite(sameSite)
+	if err != nil {
+		return nil, err
+	}
+	options := sessions.Options{
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: sameSiteMode,
+	}
+
+	if storeDir != "" {
+		if err := os.MkdirAll(storeDir, 0700); err != nil {
+			return nil, err
+		}
+		store := sessions.NewFilesystemStore(storeDir, keyPairs...)
+		store.Options = &options
+		store.MaxAge(maxAge)
+
+		cleanupSessionFiles(storeDir, maxAge)
+		go func() {
+			for range time.Tick(SESSION_CLEANUP_INTERVAL) {
+				cleanupSessionFiles(storeDir, maxAge)
+			}
+		}()
+		return store, nil
+	}
+
+	store := sessions.NewCookieStore(keyPairs...)
+	store.Options = &options
+	store.MaxAge(maxAge)
+	return store, nil
+}
@@ -0,0 +1,260 @@
+package internal
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func helperSessionCookie(t *testing.T, keys []string, accessToken string) *http.Cookie {
+	store, err := NewSessionStore(keys, "", 3600, true, "strict")
+	assert.Nil(t, err)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	rec := httptest.NewRecorder()
+	session, _ := store.Get(req, SESSION_NAME)
+	session.Values["access_token"] = accessToken
+	assert.Nil(t, session.Save(req, rec))
+
+	cookies := rec.Result().Cookies()
+	assert.Equal(t, 1, len(cookies))
+	return cookies[0]
+}
+
+func TestSessionStore(t *testing.T) {
+	t.Run("happy path: encrypted cookie with the configured flags", func(t *testing.T) {
+		cookie := helperSessionCookie(t, []string{"key1"}, "my-access-token")
+
+		assert.Equal(t, SESSION_NAME, cookie.Name)
+		assert.True(t, cookie.Secure)
+		assert.True(t, cookie.HttpOnly)
+		assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+		assert.Equal(t, 3600, cookie.MaxAge)
+
+		decoded, _ := base64.URLEncoding.DecodeString(cookie.Value)
+		assert.NotContains(t, string(decoded), "my-access-token")
+	})
+
+	t.Run("happy path: keys rotation", func(t *testing.T) {
+		cookie := helperSessionCookie(t, []string{"old-key"}, "my-access-token")
+
+		// the old key is still accepted
+		store, err := NewSessionStore([]string{"new-key", "old-key"}, "", 3600, true, "lax")
+		assert.Nil(t, err)
+		req := httptest.NewRequest("GET", "/", nil)
+		req.AddCookie(cookie)
+		session, _ := store.Get(req, SESSION_NAME)
+		assert.Equal(t, "my-access-token", session.Values["access_token"])
+
+		// but not anymore once removed
+		store, err = NewSessionStore([]string{"new-key"}, "", 3600, true, "lax")
+		assert.Nil(t, err)
+		req = httptest.NewRequest("GET", "/", nil)
+		req.AddCookie(cookie)
+		session, _ = store.Get(req, SESSION_NAME)
+		assert.Nil(t, session.Values["access_token"])
+	})
+
+	t.Run("happy path: forged cookie with the previous hardcoded key", func(t *testing.T) {
+		store, err := NewSessionStore(nil, "", 3600, true, "lax")
+		assert.Nil(t, err)
+
+		forged := helperSessionCookie(t, []string{"your-secret-key"}, "forged-token")
+		req := httptest.NewRequest("GET", "/", nil)
+		req.AddCookie(forged)
+		session, _ := store.Get(req, SESSION_NAME)
+		assert.Nil(t, session.Values["access_token"])
+	})
+
+	t.Run("happy path: server side sessions", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := NewSessionStore([]string{"key1"}, dir, 3600, false, "lax")
+		assert.Nil(t, err)
+
+		req := httptest.NewRequest("GET", "/", nil)
+		rec := httptest.NewRecorder()
+		session, _ := store.Get(req, SESSION_NAME)
+		session.Values["access_token"] = "my-access-token"
+		assert.Nil(t, session.Save(req, rec))
+		cookie := rec.Result().Cookies()[0]
+		assert.False(t, cookie.Secure)
+
+		req = httptest.NewRequest("GET", "/", nil)
+		req.AddCookie(cookie)
+		session, _ = store.Get(req, SESSION_NAME)
+		assert.Equal(t, "my-access-token", session.Values["access_token"])
+	})
+
+	t.Run("happy path: expired server side sessions are removed", func(t *testing.T) {
+		dir := t.TempDir()
+		old := time.Now().Add(-2 * time.Hour)
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "session_EXPIRED"), []byte("x"), 0600))
+		assert.Nil(t, os.Chtimes(filepath.Join(dir, "session_EXPIRED"), old, old))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "session_ACTIVE"), []byte("x"), 0600))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "other"), []byte("x"), 0600))
+		assert.Nil(t, os.Chtimes(filepath.Join(dir, "other"), old, old))
+
+		_, err := NewSessionStore([]string{"key1"}, dir, 3600, true, "lax")
+		assert.Nil(t, err)
+
+		_, err = os.Stat(filepath.Join(dir, "session_EXPIRED"))
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(filepath.Join(dir, "session_ACTIVE"))
+		assert.Nil(t, err)
+		_, err = os.Stat(filepath.Join(dir, "other"))
+		assert.Nil(t, err)
+	})
+
+	t.Run("not happy path: invalid SameSite", func(t *testing.T) {
+		_, err := NewSessionStore([]string{"key1"}, "", 3600, true, "sometimes")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestSessionCookieSecure(t *testing.T) {
+	t.Run("happy path: configured", func(t *testing.T) {
+		secure, err := sessionCookieSecure("false", "https://goliac.company.com/api/v1/auth/callback")
+		assert.Nil(t, err)
+		assert.False(t, secure)
+
+		secure, err = sessionCookieSecure("true", "http://localhost:18000/api/v1/auth/callback")
+		assert.Nil(t, err)
+		assert.True(t, secure)
+	})
+
+	t.Run("happy path: from the callback url scheme", func(t *testing.T) {
+		secure, err := sessionCookieSecure("", "https://goliac.company.com/api/v1/auth/callback")
+		assert.Nil(t, err)
+		assert.True(t, secure)
+
+		secure, err = sessionCookieSecure("", "http://localhost:18000/api/v1/auth/callback")
+		assert.Nil(t, err)
+		assert.False(t, secure)
+
+		secure, err = sessionCookieSecure("", "")
+		assert.Nil(t, err)
+		assert.True(t, secure)
+	})
+
+	t.Run("not happy path: invalid value", func(t *testing.T) {
+		_, err := sessionCookieSecure("sometimes", "")
+		assert.NotNil(t, err)
+	})
+}
+
+type MembershipClientMock struct {
+	GithubClientMock
+	member          bool
+	nbMembershipReq int
+}
+
+func (g *MembershipClientMock) CallRestAPI(ctx context.Context, endpoint, parameters, method string, body map[string]interface{}, githubToken *string) ([]byte, error) {
+	if endpoint == "/user" {
+		return []byte(`{"login": "testuser", "name":"Test User"}`), nil
+	}
+	g.nbMembershipReq++
+	if !g.member {
+		return nil, &github.StatusError{StatusCode: 404, Status: "404 Not Found"}
+	}
+	return []byte(`{"state": "active"}`), nil
+}
+
+func TestSessionMembershipCheck(t *testing.T) {
+	t.Run("happy path: the membership is checked periodically", func(t *testing.T) {
+		previousInterval := config.Config.ServerSessionMembershipCheckInterval
+		config.Config.ServerSessionMembershipCheckInterval = 300
+		defer func() { config.Config.ServerSessionMembershipCheckInterval = previousInterval }()
+
+		client := &MembershipClientMock{member: true}
+		store, err := NewSessionStore([]string{"key1"}, "", 3600, true, "lax")
+		assert.Nil(t, err)
+		server := GoliacServerImpl{
+			client:       client,
+			sessionStore: store,
+		}
+		req := httptest.NewRequest("GET", "/", nil)
+		req.AddCookie(helperSessionCookie(t, []string{"key1"}, "my-access-token"))
+
+		userinfo, code, merr := server.helperCheckOrgMembership(req)
+		assert.Nil(t, merr)
+		assert.Equal(t, 200, code)
+		assert.Equal(t, "testuser", userinfo.Login)
+
+		// checked less than 300s ago
+		_, code, _ = server.helperCheckOrgMembership(req)
+		assert.Equal(t, 200, code)
+		assert.Equal(t, 1, client.nbMembershipReq)
+
+		// the user is removed from the organization
+		client.member = false
+		check := server.sessionChecks[sessionCheckKey("my-access-token")]
+		check.checkedAt = check.checkedAt.Add(-301 * time.Second)
+		_, code, merr = server.helperCheckOrgMembership(req)
+		assert.NotNil(t, merr)
+		assert.Equal(t, 403, code)
+		assert.Equal(t, 2, client.nbMembershipReq)
+
+		// and the session stays rejected
+		_, code, _ = server.helperCheckOrgMembership(req)
+		assert.Equal(t, 403, code)
+		assert.Equal(t, 3, client.nbMembershipReq)
+	})
+}
+
+func TestGetOrgMembership(t *testing.T) {
+	t.Run("happy path: active and pending memberships", func(t *testing.T) {
+		server := GoliacServerImpl{
+			client: &MembershipClientMock{member: true},
+		}
+		isMember, err := server.GetOrgMembership(context.TODO(), "token", "org", "testuser")
+		assert.Nil(t, err)
+		assert.True(t, isMember)
+
+		server.client = &GithubClientStatusMock{body: `{"state": "pending"}`}
+		isMember, err = server.GetOrgMembership(context.TODO(), "token", "org", "testuser")
+		assert.Nil(t, err)
+		assert.False(t, isMember)
+	})
+
+	t.Run("happy path: not a member", func(t *testing.T) {
+		server := GoliacServerImpl{
+			client: &MembershipClientMock{member: false},
+		}
+		isMember, err := server.GetOrgMembership(context.TODO(), "token", "org", "testuser")
+		assert.Nil(t, err)
+		assert.False(t, isMember)
+	})
+
+	t.Run("not happy path: Github error", func(t *testing.T) {
+		server := GoliacServerImpl{
+			client: &GithubClientStatusMock{err: &github.StatusError{StatusCode: 502, Status: "502 Bad Gateway"}},
+		}
+		_, err := server.GetOrgMembership(context.TODO(), "token", "org", "testuser")
+		assert.NotNil(t, err)
+
+		// an error mentioning 404 is not a 404 status
+		server.client = &GithubClientStatusMock{err: fmt.Errorf("dial tcp 10.0.0.404: connection refused")}
+		_, err = server.GetOrgMembership(context.TODO(), "token", "org", "testuser")
+		assert.NotNil(t, err)
+	})
+}
+
+type GithubClientStatusMock struct {
+	GithubClientMock
+	body string
+	err  error
+}
+
+func (g *GithubClientStatusMock) CallRestAPI(ctx context.Context, endpoint, parameters, method string, body map[string]interface{}, githubToken *string) ([]byte, error) {
+	return []byte(g.body), g.err
+}
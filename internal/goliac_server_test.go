@@ -2,6 +2,10 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -16,6 +20,7 @@ import (
 	"github.com/goliac-project/goliac/internal/github"
 	"github.com/goliac-project/goliac/internal/observability"
 	"github.com/goliac-project/goliac/internal/workflow"
+	"github.com/goliac-project/goliac/swagger_gen/models"
 	"github.com/goliac-project/goliac/swagger_gen/restapi/operations/app"
 )
 
@@ -44,6 +49,9 @@ func (g *GoliacLocalMock) ExternalUsers() map[string]*entity.User {
 func (g *GoliacLocalMock) RuleSets() map[string]*entity.RuleSet {
 	return g.rulesets
 }
+func (g *GoliacLocalMock) RepositoryRoles() map[string]*entity.RepositoryRole {
+	return map[string]*entity.RepositoryRole{}
+}
 func (g *GoliacLocalMock) Workflows() map[string]*entity.Workflow {
 	return g.workflows
 }
@@ -72,6 +80,10 @@ func fixtureGoliacLocal() (*GoliacLocalMock, *GoliacRemoteMock) {
 	user1 := entity.User{}
 	user1.Name = "user1"
 	user1.Spec.GithubID = "github1"
+	user1.Spec.Email = "user1@company.com"
+	user1.Spec.FullName = "User One"
+	user1.Spec.Manager = "user2"
+	user1.Spec.Labels = map[string]string{"cost-center": "rd"}
 
 	user2 := entity.User{}
 	user2.Name = "user2"
@@ -247,7 +259,7 @@ type GoliacMock struct {
 	client *GithubClientMock
 }
 
-func (g *GoliacMock) Apply(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, dryrun bool, repo string, branch string) *engine.UnmanagedResources {
+func (g *GoliacMock) Apply(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, dryrun bool, repo string, branch string, only []string) *engine.UnmanagedResources {
 	unmanaged := &engine.UnmanagedResources{
 		Users:        make(map[string]bool),
 		Teams:        make(map[string]bool),
@@ -262,10 +274,16 @@ func (g *GoliacMock) UsersUpdate(ctx context.Context, logsCollector *observabili
 }
 func (g *GoliacMock) FlushCache() {
 }
+func (g *GoliacMock) HandleOrgEvent(ctx context.Context, event *engine.GithubOrgEvent) bool {
+	return false
+}
 
 func (g *GoliacMock) GetLocal() engine.GoliacLocalResources {
 	return g.local
 }
+func (g *GoliacMock) LocalUsersAndTeams() (map[string]*entity.User, map[string]*entity.Team) {
+	return g.local.Users(), g.local.Teams()
+}
 func (g *GoliacMock) GetRemote() engine.GoliacRemoteResources {
 	return g.remote
 }
@@ -274,6 +292,42 @@ func (g *GoliacMock) GetRemoteClient() github.GitHubClient {
 }
 func (g *GoliacMock) ExternalCreateRepository(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, githubToken, newRepositoryName, team, visibility, newRepositoryDefaultBranch string, repositoryUrl, branch string) {
 }
+func (g *GoliacMock) Adopt(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, githubToken string, resources []string, ownerTeam string, repositoryUrl, branch string) string {
+	return "https://github.com/org/teams/pull/1"
+}
+func (g *GoliacMock) MigrateBranchProtections(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, repositoryUrl, branch string, dryrun bool) string {
+	return ""
+}
+func (g *GoliacMock) PlanBranch(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, repositoryUrl, mainBranch, branch string) *engine.UnmanagedResources {
+	return nil
+}
+func (g *GoliacMock) AccessReport(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, repositoryUrl, branch string, source string) []*engine.AccessGrant {
+	if source != "local" && source != "remote" {
+		logsCollector.AddError(fmt.Errorf("unknown source %s", source))
+		return nil
+	}
+	return []*engine.AccessGrant{
+		{
+			Githubid:   "github1",
+			Username:   "user1",
+			Repository: "repo1",
+			Permission: "write",
+			Reasons:    []engine.AccessReason{{Kind: engine.AccessReasonTeam, Team: "team1", Permission: "write"}},
+		},
+	}
+}
+func (g *GoliacMock) ExplainAccess(ctx context.Context, logsCollector *observability.LogCollection, fs billy.Filesystem, repositoryUrl, branch string, user string, repository string) *engine.AccessExplanation {
+	if user != "user1" {
+		logsCollector.AddError(fmt.Errorf("user %s not found", user))
+		return nil
+	}
+	reason := engine.AccessReason{Kind: engine.AccessReasonTeam, Team: "team1", Permission: "write"}
+	collaborator := engine.AccessReason{Kind: engine.AccessReasonCollaborator, Permission: "admin"}
+	return engine.NewAccessExplanation("github1", "user1", repository,
+		&engine.AccessPaths{Permission: "write", Reasons: []engine.AccessReason{reason}, Bypasses: []engine.AccessBypass{}},
+		&engine.AccessPaths{Permission: "admin", Reasons: []engine.AccessReason{reason, collaborator}, Bypasses: []engine.AccessBypass{}},
+	)
+}
 func (g *GoliacMock) SetRemoteObservability(feedback observability.RemoteObservability) error {
 	return nil
 }
@@ -345,6 +399,67 @@ func TestGetUnmanaged(t *testing.T) {
 	})
 }
 
+func TestGetAccessReport(t *testing.T) {
+	localfixture, remotefixture := fixtureGoliacLocal()
+	server := GoliacServerImpl{
+		goliac:     NewGoliacMock(localfixture, remotefixture, &GithubClientMock{}),
+		repository: "https://github.com/org/teams.git",
+		branch:     "main",
+	}
+
+	t.Run("happy path: json access report", func(t *testing.T) {
+		res := server.GetAccessReport(app.GetAccessReportParams{})
+		payload := res.(*app.GetAccessReportOK)
+		assert.Equal(t, 1, len(payload.Payload))
+		assert.Equal(t, "github1", payload.Payload[0].Githubid)
+		assert.Equal(t, "write", payload.Payload[0].Permission)
+		assert.Equal(t, "member of team team1 which is write", payload.Payload[0].Reasons[0].Description)
+	})
+
+	t.Run("happy path: csv access report", func(t *testing.T) {
+		format := "csv"
+		res := server.GetAccessReport(app.GetAccessReportParams{Format: &format})
+
+		rec := httptest.NewRecorder()
+		res.WriteResponse(rec, nil)
+		assert.Equal(t, 200, rec.Code)
+		assert.Equal(t, "text/csv", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "githubid,username,repository,permission,reasons\n"+
+			"github1,user1,repo1,write,member of team team1 which is write\n", rec.Body.String())
+	})
+
+	t.Run("not happy path: unknown source", func(t *testing.T) {
+		source := "github"
+		res := server.GetAccessReport(app.GetAccessReportParams{Source: &source})
+		assert.NotZero(t, res.(*app.GetAccessReportDefault))
+	})
+}
+
+func TestGetAccessExplanation(t *testing.T) {
+	localfixture, remotefixture := fixtureGoliacLocal()
+	server := GoliacServerImpl{
+		goliac:     NewGoliacMock(localfixture, remotefixture, &GithubClientMock{}),
+		repository: "https://github.com/org/teams.git",
+		branch:     "main",
+	}
+
+	t.Run("happy path: explain the access of a user", func(t *testing.T) {
+		res := server.GetAccessExplanation(app.GetAccessExplanationParams{UserID: "user1", RepositoryID: "repo1"})
+		payload := res.(*app.GetAccessExplanationOK)
+		assert.Equal(t, "github1", payload.Payload.Githubid)
+		assert.Equal(t, "write", payload.Payload.Local.Permission)
+		assert.Equal(t, "admin", payload.Payload.Remote.Permission)
+		assert.Equal(t, 2, len(payload.Payload.Remote.Reasons))
+		assert.Equal(t, 1, len(payload.Payload.Unmanaged))
+		assert.Equal(t, "collaborator with admin", payload.Payload.Unmanaged[0].Description)
+	})
+
+	t.Run("not happy path: unknown user", func(t *testing.T) {
+		res := server.GetAccessExplanation(app.GetAccessExplanationParams{UserID: "unknown", RepositoryID: "repo1"})
+		assert.NotZero(t, res.(*app.GetAccessExplanationDefault))
+	})
+}
+
 func TestGetStatistics(t *testing.T) {
 
 	t.Run("happy path: get statistics", func(t *testing.T) {
@@ -392,6 +507,17 @@ func TestAppGetUsers(t *testing.T) {
 		assert.Equal(t, int64(1), payload.Payload.NbUsersExternal)
 	})
 
+	t.Run("happy path: get status on GHES", func(t *testing.T) {
+		server := GoliacServerImpl{
+			goliac:   goliac,
+			ghesInfo: &engine.GHESInfo{InstalledVersion: "3.10.0"},
+		}
+		res := server.GetStatus(app.GetStatusParams{})
+		payload := res.(*app.GetStatusOK)
+		assert.Equal(t, "3.10.0", payload.Payload.GithubServerVersion)
+		assert.Equal(t, []string{"rulesets", "workflows"}, payload.Payload.UnsupportedFeatures)
+	})
+
 	t.Run("happy path: list users", func(t *testing.T) {
 		res := server.GetUsers(app.GetUsersParams{})
 		payload := res.(*app.GetUsersOK)
@@ -404,6 +530,10 @@ func TestAppGetUsers(t *testing.T) {
 		assert.Equal(t, 2, len(payload.Payload.Teams))
 		assert.Equal(t, 2, len(payload.Payload.Teams))
 		assert.Equal(t, 2, len(payload.Payload.Repositories))
+		assert.Equal(t, "user1@company.com", payload.Payload.Email)
+		assert.Equal(t, "User One", payload.Payload.FullName)
+		assert.Equal(t, "user2", payload.Payload.Manager)
+		assert.Equal(t, map[string]string{"cost-center": "rd"}, payload.Payload.Labels)
 	})
 }
 func TestAppGetTeams(t *testing.T) {
@@ -668,3 +798,212 @@ func TestHandleIssueComment(t *testing.T) {
 		assert.Contains(t, body, "Example:")
 	})
 }
+
+func TestApplyScopeFromChangedFiles(t *testing.T) {
+	t.Run("happy path: teams, repositories and rulesets", func(t *testing.T) {
+		only := ApplyScopeFromChangedFiles([]string{
+			"teams/team1/repo2.yaml",
+			"teams/team1/team.yaml",
+			"teams/parent/team2/team.yaml",
+			"teams/team1/repo2.yaml",
+			"archived/repo3.yaml",
+			"rulesets/default.yaml",
+		})
+		assert.Equal(t, []string{"repo:repo2", "team:team1", "team:team2", "repo:repo3", "ruleset:default"}, only)
+	})
+
+	t.Run("happy path: full apply needed", func(t *testing.T) {
+		assert.Nil(t, ApplyScopeFromChangedFiles(nil))
+		assert.Nil(t, ApplyScopeFromChangedFiles([]string{"teams/team1/team.yaml", "users/org/user1.yaml"}))
+		assert.Nil(t, ApplyScopeFromChangedFiles([]string{"goliac.yaml"}))
+		assert.Nil(t, ApplyScopeFromChangedFiles([]string{"teams/team1/README.md"}))
+	})
+
+	t.Run("happy path: merge scopes", func(t *testing.T) {
+		assert.Equal(t, []string{"repo:repo1", "team:team1"}, mergeApplyScopes([]string{"repo:repo1"}, []string{"team:team1", "repo:repo1"}))
+		assert.Nil(t, mergeApplyScopes([]string{"repo:repo1"}, nil))
+		assert.Nil(t, mergeApplyScopes(nil, []string{"repo:repo1"}))
+	})
+}
+
+func TestMetrics(t *testing.T) {
+	t.Run("happy path: apply metrics", func(t *testing.T) {
+		server := GoliacServerImpl{organization: "metrics-apply"}
+		logsCollector := observability.NewLogCollection()
+		logsCollector.AddInfo(map[string]interface{}{"dryrun": false, "command": "create_team"}, "teamname: %s", "team1")
+		logsCollector.AddInfo(map[string]interface{}{"dryrun": false, "command": "update_repository_add_team"}, "repositoryname: %s", "repo1")
+		logsCollector.AddInfo(map[string]interface{}{"dryrun": false, "command": "update_repository_add_team"}, "repositoryname: %s", "repo2")
+		unmanaged := &engine.UnmanagedResources{
+			Users:        map[string]bool{"user1": true},
+			Repositories: map[string]bool{"repo3": true, "repo4": true},
+		}
+
+		server.recordApplyMetrics(logsCollector, 12*time.Second, unmanaged, nil)
+
+		assert.Equal(t, float64(1), observability.ApplyTotal.Value("metrics-apply", "success"))
+		assert.Equal(t, uint64(1), observability.ApplyDurationSeconds.Count("metrics-apply"))
+		assert.Equal(t, float64(1), observability.ChangesAppliedTotal.Value("metrics-apply", "team", "create"))
+		assert.Equal(t, float64(2), observability.ChangesAppliedTotal.Value("metrics-apply", "repository", "update"))
+		assert.Equal(t, float64(1), observability.UnmanagedResources.Value("metrics-apply", "users"))
+		assert.Equal(t, float64(2), observability.UnmanagedResources.Value("metrics-apply", "repositories"))
+
+		// a failed (or targeted) apply doesn't update the unmanaged resources
+		logsCollector = observability.NewLogCollection()
+		logsCollector.AddError(fmt.Errorf("not able to load the teams repository"))
+		server.recordApplyMetrics(logsCollector, time.Second, &engine.UnmanagedResources{}, nil)
+
+		assert.Equal(t, float64(1), observability.ApplyTotal.Value("metrics-apply", "failure"))
+		assert.Equal(t, uint64(2), observability.ApplyDurationSeconds.Count("metrics-apply"))
+		assert.Equal(t, float64(2), observability.UnmanagedResources.Value("metrics-apply", "repositories"))
+	})
+
+	t.Run("happy path: workflow executions", func(t *testing.T) {
+		localfixture, remotefixture := fixtureGoliacLocal()
+		githubClient := &GithubClientMock{}
+		goliac := NewGoliacMock(localfixture, remotefixture, githubClient)
+		server := GoliacServerImpl{
+			goliac:       goliac,
+			organization: "metrics-workflow",
+			worflowInstances: map[string]workflow.Workflow{
+				"forcemerge": &WorkflowMock{},
+			},
+		}
+
+		server.handleIssueComment(context.Background(), "org", "repoB", "https://github.com/org/repoB/pull/123", "userE1", "/forcemerge:fmtest: foobar", 123)
+
+		assert.Equal(t, float64(1), observability.WorkflowExecutionsTotal.Value("metrics-workflow", "fmtest", "success"))
+
+		// an unknown workflow is not executed, and doesn't create a new label
+		server.handleIssueComment(context.Background(), "org", "repoB", "https://github.com/org/repoB/pull/123", "userE1", "/forcemerge:random-name: foobar", 124)
+		assert.Equal(t, float64(0), observability.WorkflowExecutionsTotal.Value("metrics-workflow", "random-name", "success"))
+		assert.Equal(t, float64(0), observability.WorkflowExecutionsTotal.Value("metrics-workflow", "random-name", "failure"))
+
+		server.recordWorkflowExecution("random-name", fmt.Errorf("workflow not found"))
+		assert.Equal(t, float64(1), observability.WorkflowExecutionsTotal.Value("metrics-workflow", "unknown", "failure"))
+		assert.Equal(t, float64(0), observability.WorkflowExecutionsTotal.Value("metrics-workflow", "random-name", "failure"))
+	})
+
+	t.Run("happy path: metrics endpoint", func(t *testing.T) {
+		observability.ApplyTotal.Inc("metrics-endpoint", "success")
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+		handler := NewMetricsHandler(next)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+		assert.Equal(t, 200, rec.Code)
+		assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Body.String(), "# TYPE goliac_apply_total counter\n")
+		assert.Contains(t, rec.Body.String(), "goliac_apply_total{organization=\"metrics-endpoint\",result=\"success\"} 1\n")
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("POST", "/metrics", nil))
+		assert.Equal(t, 405, rec.Code)
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/status", nil))
+		assert.Equal(t, http.StatusTeapot, rec.Code)
+	})
+}
+
+func TestOrganizationsServer(t *testing.T) {
+	localfixture, remotefixture := fixtureGoliacLocal()
+	goliac := NewGoliacMock(localfixture, remotefixture, &GithubClientMock{})
+	servers := map[string]*GoliacServerImpl{
+		"org1": {goliac: goliac, organization: "org1"},
+		"org2": {goliac: goliac, organization: "org2"},
+	}
+	for _, server := range servers {
+		server.organizationServers = servers
+	}
+
+	t.Run("happy path: get status", func(t *testing.T) {
+		res := servers["org2"].GetStatus(app.GetStatusParams{})
+		payload := res.(*app.GetStatusOK)
+		assert.Equal(t, "org2", payload.Payload.Organization)
+		assert.Equal(t, []string{"org1", "org2"}, payload.Payload.Organizations)
+	})
+
+	t.Run("happy path: REST API scoped by organization", func(t *testing.T) {
+		handlers := make(map[string]http.Handler)
+		for name, server := range servers {
+			api, err := server.newGoliacAPI()
+			require.Nil(t, err)
+			handlers[name] = api.Serve(nil)
+		}
+		handler := NewOrganizationsHandler(handlers["org1"], handlers)
+
+		getOrganization := func(url string) (int, string) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+			var status models.Status
+			json.Unmarshal(rec.Body.Bytes(), &status)
+			return rec.Code, status.Organization
+		}
+
+		code, organization := getOrganization("/api/v1/status")
+		assert.Equal(t, 200, code)
+		assert.Equal(t, "org1", organization)
+
+		code, organization = getOrganization("/api/v1/orgs/org2/status")
+		assert.Equal(t, 200, code)
+		assert.Equal(t, "org2", organization)
+
+		code, _ = getOrganization("/api/v1/orgs/org3/status")
+		assert.Equal(t, 404, code)
+	})
+
+	t.Run("happy path: cross organizations user view", func(t *testing.T) {
+		res := servers["org2"].GetEnterpriseUser(app.GetEnterpriseUserParams{GithubID: "github1"})
+		payload := res.(*app.GetEnterpriseUserOK)
+		assert.False(t, payload.Payload.EnterpriseMember)
+		assert.Equal(t, 2, len(payload.Payload.Organizations))
+		assert.Equal(t, "org1", payload.Payload.Organizations[0].Organization)
+		assert.Equal(t, "user1", payload.Payload.Organizations[0].Username)
+		assert.Equal(t, 2, len(payload.Payload.Organizations[0].Teams))
+		assert.Equal(t, 2, len(payload.Payload.Organizations[0].Repositories))
+	})
+
+	t.Run("happy path: external collaborator and enterprise member", func(t *testing.T) {
+		ossLocal, _ := fixtureGoliacLocal()
+		repoC := entity.Repository{}
+		repoC.Name = "repoC"
+		repoC.Spec.ExternalUserReaders = []string{"userE1"}
+		ossLocal.repositories = map[string]*entity.Repository{"repoC": &repoC}
+		ossLocal.users = map[string]*entity.User{}
+
+		enterprise := newGoliacEnterprise(&EnterpriseConfig{Name: "myenterprise"}, nil, fixtureEnterpriseRemote())
+		enterprise.CreateOrganizations(context.TODO(), observability.NewLogCollection())
+		oss := map[string]*GoliacServerImpl{
+			"oss": {goliac: NewGoliacMock(ossLocal, remotefixture, &GithubClientMock{}), organization: "oss", enterprise: enterprise},
+		}
+		oss["oss"].organizationServers = oss
+
+		res := oss["oss"].GetEnterpriseUser(app.GetEnterpriseUserParams{GithubID: "githubE1"})
+		payload := res.(*app.GetEnterpriseUserOK)
+		assert.Equal(t, 1, len(payload.Payload.Organizations))
+		assert.Equal(t, "", payload.Payload.Organizations[0].Username)
+		assert.Equal(t, "repoC", payload.Payload.Organizations[0].ExternalRepositories[0].Name)
+
+		// only in the enterprise
+		res = oss["oss"].GetEnterpriseUser(app.GetEnterpriseUserParams{GithubID: "github2"})
+		payload = res.(*app.GetEnterpriseUserOK)
+		assert.True(t, payload.Payload.EnterpriseMember)
+		assert.Equal(t, []string{"platform"}, payload.Payload.EnterpriseTeams)
+		assert.Equal(t, 0, len(payload.Payload.Organizations))
+	})
+
+	t.Run("not happy path: unknown user", func(t *testing.T) {
+		res := servers["org1"].GetEnterpriseUser(app.GetEnterpriseUserParams{GithubID: "unknown"})
+		assert.NotZero(t, res.(*app.GetEnterpriseUserDefault))
+	})
+
+	t.Run("happy path: single organization", func(t *testing.T) {
+		server := GoliacServerImpl{goliac: goliac, organization: "org1"}
+		res := server.GetStatus(app.GetStatusParams{})
+		payload := res.(*app.GetStatusOK)
+		assert.Equal(t, []string{"org1"}, payload.Payload.Organizations)
+	})
+}
@@ -404,6 +404,9 @@ func (e *GoliacRemoteExecutorMock) FlushCache() {
 }
 func (e *GoliacRemoteExecutorMock) FlushCacheUsersTeamsOnly() {
 }
+func (e *GoliacRemoteExecutorMock) HandleOrgEvent(ctx context.Context, event *engine.GithubOrgEvent) bool {
+	return false
+}
 func (e *GoliacRemoteExecutorMock) Users(ctx context.Context) map[string]*engine.GithubUser {
 	return map[string]*engine.GithubUser{
 		"github1": &engine.GithubUser{
@@ -616,6 +619,10 @@ func (e *GoliacRemoteExecutorMock) RemoveUserFromOrg(ctx context.Context, logsCo
 	fmt.Println("*** RemoveUserFromOrg", ghuserid)
 	e.nbChanges++
 }
+func (e *GoliacRemoteExecutorMock) UpdateUserOrgRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, ghuserid string, role string) {
+	fmt.Println("*** UpdateUserOrgRole", ghuserid, role)
+	e.nbChanges++
+}
 
 func (e *GoliacRemoteExecutorMock) CreateTeam(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, teamname string, description string, parentTeam *int, members []string) {
 	fmt.Println("*** CreateTeam", teamname, description, parentTeam, members)
@@ -753,6 +760,21 @@ func (e *GoliacRemoteExecutorMock) DeleteOrgCustomProperty(ctx context.Context,
 	fmt.Println("*** DeleteOrgCustomProperty", propertyName)
 	e.nbChanges++
 }
+func (e *GoliacRemoteExecutorMock) RepositoryRoles(ctx context.Context) map[string]*engine.GithubRepositoryRole {
+	return make(map[string]*engine.GithubRepositoryRole)
+}
+func (e *GoliacRemoteExecutorMock) AddRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, role *engine.GithubRepositoryRole) {
+	fmt.Println("*** AddRepositoryRole", role.Name)
+	e.nbChanges++
+}
+func (e *GoliacRemoteExecutorMock) UpdateRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, role *engine.GithubRepositoryRole) {
+	fmt.Println("*** UpdateRepositoryRole", role.Name)
+	e.nbChanges++
+}
+func (e *GoliacRemoteExecutorMock) DeleteRepositoryRole(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, roleid int) {
+	fmt.Println("*** DeleteRepositoryRole", roleid)
+	e.nbChanges++
+}
 func (e *GoliacRemoteExecutorMock) AddRepositoryEnvironment(ctx context.Context, logsCollector *observability.LogCollection, dryrun bool, repositoryName string, environmentName string) {
 	fmt.Println("*** AddRepositoryEnvironment", repositoryName, environmentName)
 	e.nbChanges++
@@ -853,7 +875,7 @@ func TestGoliacApply(t *testing.T) {
 			repoconfig:         &config.RepositoryConfig{},
 		}
 
-		unmanaged := goliac.Apply(context.Background(), logsCollector, fs, false, "inmemory:///src", "master")
+		unmanaged := goliac.Apply(context.Background(), logsCollector, fs, false, "inmemory:///src", "master", nil)
 		assert.Equal(t, false, logsCollector.HasErrors())
 		assert.Equal(t, false, logsCollector.HasWarns())
 		assert.NotNil(t, unmanaged)
@@ -891,7 +913,7 @@ func TestGoliacApply(t *testing.T) {
 			repoconfig:         &config.RepositoryConfig{},
 		}
 
-		unmanaged := goliac.Apply(context.Background(), logsCollector, fs, false, "inmemory:///src", "master")
+		unmanaged := goliac.Apply(context.Background(), logsCollector, fs, false, "inmemory:///src", "master", nil)
 		assert.Equal(t, false, logsCollector.HasErrors())
 		assert.Equal(t, false, logsCollector.HasWarns())
 		assert.NotNil(t, unmanaged)
@@ -930,7 +952,7 @@ func TestGoliacApply(t *testing.T) {
 			repoconfig:         &config.RepositoryConfig{},
 		}
 
-		unmanaged := goliac.Apply(context.Background(), logsCollector, fs, false, "inmemory:///src", "master")
+		unmanaged := goliac.Apply(context.Background(), logsCollector, fs, false, "inmemory:///src", "master", nil)
 		assert.Equal(t, false, logsCollector.HasErrors())
 		assert.Equal(t, 1, len(logsCollector.Warns))
 		assert.NotNil(t, unmanaged)
@@ -938,6 +960,77 @@ func TestGoliacApply(t *testing.T) {
 
 	})
 
+	t.Run("happy path: targeted apply", func(t *testing.T) {
+
+		fs := memfs.New()
+		fs.MkdirAll("src", 0755)        // create a fake bare repository
+		fs.MkdirAll("teams", 0755)      // create a fake cloned repository
+		fs.MkdirAll(os.TempDir(), 0755) // need a tmp folder
+		srcsFs, _ := fs.Chroot("src")
+		clonedFs, _ := fs.Chroot("teams")
+		_, _, err := helperCreateAndClone(fs, srcsFs, clonedFs, repoFixtureRename)
+		assert.Nil(t, err)
+
+		local := engine.NewGoliacLocalImpl()
+
+		logsCollector := observability.NewLogCollection()
+		local.LoadAndValidateLocal(clonedFs, logsCollector)
+		assert.Equal(t, false, logsCollector.HasErrors())
+
+		githubClient := NewGitHubClientMock()
+		remote := NewGoliacRemoteExecutorMock().(*GoliacRemoteExecutorMock)
+
+		usersync.InitPlugins(githubClient)
+
+		goliac := GoliacImpl{
+			local:              local,
+			remote:             remote,
+			remoteGithubClient: githubClient,
+			localGithubClient:  githubClient,
+			repoconfig:         &config.RepositoryConfig{},
+		}
+
+		// repo2 (to rename) is not part of the scope
+		unmanaged := goliac.Apply(context.Background(), logsCollector, fs, false, "inmemory:///src", "master", []string{"team:team1"})
+		assert.Equal(t, false, logsCollector.HasErrors())
+		assert.NotNil(t, unmanaged)
+		assert.Equal(t, 0, remote.nbChanges) // neither the rename, nor the (goliac admin) src repository
+	})
+
+	t.Run("not happy path: targeted apply of an invalid resource", func(t *testing.T) {
+
+		fs := memfs.New()
+		fs.MkdirAll("src", 0755)        // create a fake bare repository
+		fs.MkdirAll("teams", 0755)      // create a fake cloned repository
+		fs.MkdirAll(os.TempDir(), 0755) // need a tmp folder
+		srcsFs, _ := fs.Chroot("src")
+		clonedFs, _ := fs.Chroot("teams")
+		_, _, err := helperCreateAndClone(fs, srcsFs, clonedFs, repoFixture1)
+		assert.Nil(t, err)
+
+		local := engine.NewGoliacLocalImpl()
+
+		logsCollector := observability.NewLogCollection()
+		local.LoadAndValidateLocal(clonedFs, logsCollector)
+
+		githubClient := NewGitHubClientMock()
+		remote := NewGoliacRemoteExecutorMock().(*GoliacRemoteExecutorMock)
+
+		usersync.InitPlugins(githubClient)
+
+		goliac := GoliacImpl{
+			local:              local,
+			remote:             remote,
+			remoteGithubClient: githubClient,
+			localGithubClient:  githubClient,
+			repoconfig:         &config.RepositoryConfig{},
+		}
+
+		goliac.Apply(context.Background(), logsCollector, fs, false, "inmemory:///src", "master", []string{"user:user1"})
+		assert.Equal(t, true, logsCollector.HasErrors())
+		assert.Equal(t, 0, remote.nbChanges)
+	})
+
 	t.Run("refresh failure blocks apply", func(t *testing.T) {
 		fs := memfs.New()
 		fs.MkdirAll("src", 0755)        // create a fake bare repository
@@ -967,7 +1060,7 @@ func TestGoliacApply(t *testing.T) {
 			repoconfig:         &config.RepositoryConfig{},
 		}
 
-		unmanaged := goliac.Apply(context.Background(), logsCollector, fs, false, "inmemory:///src", "master")
+		unmanaged := goliac.Apply(context.Background(), logsCollector, fs, false, "inmemory:///src", "master", nil)
 		assert.Nil(t, unmanaged)
 		assert.Equal(t, true, logsCollector.HasErrors())
 		assert.Contains(t, logsCollector.Errors[0].Error(), "error when loading data from Github")
@@ -975,3 +1068,229 @@ func TestGoliacApply(t *testing.T) {
 		assert.Equal(t, 0, remote.nbChanges)
 	})
 }
+
+func TestGoliacLocalUsersAndTeams(t *testing.T) {
+	t.Run("happy path: copy of the loaded users and teams", func(t *testing.T) {
+		fs := memfs.New()
+		repoFixture1(fs)
+		local := engine.NewGoliacLocalImpl()
+		logsCollector := observability.NewLogCollection()
+		local.LoadAndValidateLocal(fs, logsCollector)
+		assert.False(t, logsCollector.HasErrors())
+
+		goliac := &GoliacImpl{
+			local:      local,
+			repoconfig: &config.RepositoryConfig{},
+		}
+
+		users, teams := goliac.LocalUsersAndTeams()
+		assert.Equal(t, len(local.Users()), len(users))
+		assert.Equal(t, len(local.Teams()), len(teams))
+		assert.Equal(t, "github1", users["user1"].Spec.GithubID)
+
+		// the copies are not shared with the loaded teams repository
+		delete(users, "user1")
+		users["user2"].Spec.GithubID = "changed"
+		for _, team := range teams {
+			team.Spec.Members = append(team.Spec.Members, "user4")
+		}
+		assert.NotNil(t, local.Users()["user1"])
+		assert.Equal(t, "github2", local.Users()["user2"].Spec.GithubID)
+		for _, team := range local.Teams() {
+			assert.NotContains(t, team.Spec.Members, "user4")
+		}
+	})
+}
+
+func TestGoliacAccessReport(t *testing.T) {
+	loadGoliac := func(t *testing.T) (billy.Filesystem, *GoliacImpl) {
+		fs := memfs.New()
+		fs.MkdirAll("src", 0755)        // create a fake bare repository
+		fs.MkdirAll("teams", 0755)      // create a fake cloned repository
+		fs.MkdirAll(os.TempDir(), 0755) // need a tmp folder
+		srcsFs, _ := fs.Chroot("src")
+		clonedFs, _ := fs.Chroot("teams")
+		_, _, err := helperCreateAndClone(fs, srcsFs, clonedFs, repoFixture1)
+		assert.Nil(t, err)
+
+		githubClient := NewGitHubClientMock()
+		return fs, &GoliacImpl{
+			local:              engine.NewGoliacLocalImpl(),
+			remote:             NewGoliacRemoteExecutorMock().(*GoliacRemoteExecutorMock),
+			remoteGithubClient: githubClient,
+			localGithubClient:  githubClient,
+			repoconfig:         &config.RepositoryConfig{},
+		}
+	}
+
+	t.Run("happy path: local access report", func(t *testing.T) {
+		fs, goliac := loadGoliac(t)
+		logsCollector := observability.NewLogCollection()
+
+		report := goliac.AccessReport(context.Background(), logsCollector, fs, "inmemory:///src", "master", "local")
+
+		assert.Equal(t, false, logsCollector.HasErrors())
+		grants := make(map[string]*engine.AccessGrant)
+		for _, g := range report {
+			grants[g.Repository+"/"+g.Githubid] = g
+		}
+		assert.Equal(t, "user1", grants["repo1/github1"].Username)
+		assert.Equal(t, "write", grants["repo1/github1"].Permission)
+		assert.Equal(t, engine.AccessReasonTeam, grants["repo1/github1"].Reasons[0].Kind)
+		assert.Nil(t, grants["repo2/github1"])
+		// the teams repository
+		assert.Equal(t, engine.AccessReasonOwnersTeam, grants["src/github3"].Reasons[0].Kind)
+	})
+
+	t.Run("happy path: remote access report", func(t *testing.T) {
+		fs, goliac := loadGoliac(t)
+		logsCollector := observability.NewLogCollection()
+
+		report := goliac.AccessReport(context.Background(), logsCollector, fs, "inmemory:///src", "master", "remote")
+
+		assert.Equal(t, false, logsCollector.HasErrors())
+		grants := make(map[string]*engine.AccessGrant)
+		for _, g := range report {
+			grants[g.Repository+"/"+g.Githubid] = g
+		}
+		assert.Equal(t, "user3", grants["repo2/github3"].Username)
+		assert.Equal(t, "write", grants["repo2/github3"].Permission)
+	})
+
+	t.Run("happy path: the loaded teams repository is read under the lock", func(t *testing.T) {
+		_, goliac := loadGoliac(t)
+		logsCollector := observability.NewLogCollection()
+
+		goliac.actionMutex.Lock()
+		done := make(chan bool)
+		go func() {
+			goliac.AccessReport(context.Background(), logsCollector, nil, "inmemory:///src", "master", "remote")
+			close(done)
+		}()
+		select {
+		case <-done:
+			t.Fatal("the access report didn't wait for the running action")
+		case <-time.After(50 * time.Millisecond):
+		}
+		goliac.actionMutex.Unlock()
+		<-done
+	})
+
+	t.Run("not happy path: unknown source", func(t *testing.T) {
+		fs, goliac := loadGoliac(t)
+		logsCollector := observability.NewLogCollection()
+
+		report := goliac.AccessReport(context.Background(), logsCollector, fs, "inmemory:///src", "master", "github")
+
+		assert.Equal(t, true, logsCollector.HasErrors())
+		assert.Nil(t, report)
+	})
+}
+
+func TestGoliacExplainAccess(t *testing.T) {
+	loadGoliac := func(t *testing.T) (billy.Filesystem, *GoliacImpl) {
+		fs := memfs.New()
+		fs.MkdirAll("src", 0755)        // create a fake bare repository
+		fs.MkdirAll("teams", 0755)      // create a fake cloned repository
+		fs.MkdirAll(os.TempDir(), 0755) // need a tmp folder
+		srcsFs, _ := fs.Chroot("src")
+		clonedFs, _ := fs.Chroot("teams")
+		_, _, err := helperCreateAndClone(fs, srcsFs, clonedFs, repoFixture1)
+		assert.Nil(t, err)
+
+		githubClient := NewGitHubClientMock()
+		return fs, &GoliacImpl{
+			local:              engine.NewGoliacLocalImpl(),
+			remote:             NewGoliacRemoteExecutorMock().(*GoliacRemoteExecutorMock),
+			remoteGithubClient: githubClient,
+			localGithubClient:  githubClient,
+			repoconfig:         &config.RepositoryConfig{},
+		}
+	}
+
+	t.Run("happy path: explain the access of a user", func(t *testing.T) {
+		fs, goliac := loadGoliac(t)
+		logsCollector := observability.NewLogCollection()
+
+		explanation := goliac.ExplainAccess(context.Background(), logsCollector, fs, "inmemory:///src", "master", "user1", "repo1")
+
+		assert.Equal(t, false, logsCollector.HasErrors())
+		assert.Equal(t, "github1", explanation.Githubid)
+		assert.Equal(t, "user1", explanation.Username)
+		assert.Equal(t, "write", explanation.Local.Permission)
+		assert.Equal(t, "write", explanation.Remote.Permission)
+		assert.Equal(t, []engine.AccessReason{{Kind: engine.AccessReasonTeam, Team: "team1", Permission: "write"}}, explanation.Remote.Reasons)
+		assert.Equal(t, 0, len(explanation.Unmanaged))
+	})
+
+	t.Run("happy path: no access", func(t *testing.T) {
+		fs, goliac := loadGoliac(t)
+		logsCollector := observability.NewLogCollection()
+
+		explanation := goliac.ExplainAccess(context.Background(), logsCollector, fs, "inmemory:///src", "master", "github3", "repo1")
+
+		assert.Equal(t, false, logsCollector.HasErrors())
+		assert.Equal(t, "user3", explanation.Username)
+		assert.Equal(t, "", explanation.Local.Permission)
+		assert.Equal(t, "", explanation.Remote.Permission)
+	})
+
+	t.Run("not happy path: unknown user or repository", func(t *testing.T) {
+		fs, goliac := loadGoliac(t)
+		logsCollector := observability.NewLogCollection()
+
+		explanation := goliac.ExplainAccess(context.Background(), logsCollector, fs, "inmemory:///src", "master", "unknown", "repo1")
+
+		assert.Equal(t, true, logsCollector.HasErrors())
+		assert.Nil(t, explanation)
+
+		fs, goliac = loadGoliac(t)
+		logsCollector = observability.NewLogCollection()
+
+		explanation = goliac.ExplainAccess(context.Background(), logsCollector, fs, "inmemory:///src", "master", "user1", "unknown")
+
+		assert.Equal(t, true, logsCollector.HasErrors())
+		assert.Nil(t, explanation)
+	})
+}
+
+func TestGoliacPlanBranch(t *testing.T) {
+
+	t.Run("happy path: plan a branch without applying it", func(t *testing.T) {
+
+		fs := memfs.New()
+		fs.MkdirAll("src", 0755)        // create a fake bare repository
+		fs.MkdirAll("teams", 0755)      // create a fake cloned repository
+		fs.MkdirAll(os.TempDir(), 0755) // need a tmp folder
+		srcsFs, _ := fs.Chroot("src")
+		clonedFs, _ := fs.Chroot("teams")
+		_, _, err := helperCreateAndClone(fs, srcsFs, clonedFs, repoFixtureRename)
+		assert.Nil(t, err)
+
+		local := engine.NewGoliacLocalImpl()
+
+		githubClient := NewGitHubClientMock()
+		remote := NewGoliacRemoteExecutorMock().(*GoliacRemoteExecutorMock)
+
+		goliac := GoliacImpl{
+			local:              local,
+			remote:             remote,
+			remoteGithubClient: githubClient,
+			localGithubClient:  githubClient,
+			repoconfig:         &config.RepositoryConfig{},
+		}
+
+		logsCollector := observability.NewLogCollection()
+		unmanaged := goliac.PlanBranch(context.Background(), logsCollector, fs, "inmemory:///src", "master", "master")
+		assert.Equal(t, false, logsCollector.HasErrors())
+		assert.NotNil(t, unmanaged)
+
+		// nothing sent to Github
+		assert.Equal(t, 0, remote.nbChanges)
+		// and the current local state is untouched
+		assert.Equal(t, 0, len(local.Repositories()))
+
+		changes := PlanChangesFromLogs(logsCollector.Logs)
+		assert.Equal(t, 3, len(changes))
+	})
+}
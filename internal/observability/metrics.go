@@ -0,0 +1,323 @@
+package observability
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+Metrics is the registry of the metrics exposed by the Goliac server (on /metrics),
+in the Prometheus text exposition format
+*/
+var Metrics = NewMetricsRegistry()
+
+var (
+	ApplyDurationSeconds = Metrics.NewHistogram(
+		"goliac_apply_duration_seconds",
+		"Duration of the applies of the teams repository to Github",
+		[]float64{1, 5, 15, 30, 60, 120, 300, 600, 1800},
+		"organization")
+	ApplyTotal = Metrics.NewCounter(
+		"goliac_apply_total",
+		"Number of applies of the teams repository to Github, by result (success, failure)",
+		"organization", "result")
+	ChangesAppliedTotal = Metrics.NewCounter(
+		"goliac_changes_applied_total",
+		"Number of changes applied to Github, by resource type and action",
+		"organization", "resource", "action")
+	GithubApiCallsTotal = Metrics.NewCounter(
+		"goliac_github_api_calls_total",
+		"Number of Github API calls, by endpoint class (graphql, rest, installation_token)",
+		"organization", "endpoint_class")
+	GithubThrottledTotal = Metrics.NewCounter(
+		"goliac_github_throttled_total",
+		"Number of Github API calls rate limited, by endpoint class",
+		"organization", "endpoint_class")
+	GithubRateLimitRemaining = Metrics.NewGauge(
+		"goliac_github_ratelimit_remaining",
+		"Github API rate limit remaining (as of the last call), by endpoint class",
+		"organization", "endpoint_class")
+	RemoteCacheRequestsTotal = Metrics.NewCounter(
+		"goliac_remote_cache_requests_total",
+		"Number of requests to the Github remote caches, by cache and result (hit, miss)",
+		"organization", "cache", "result")
+	UnmanagedResources = Metrics.NewGauge(
+		"goliac_unmanaged_resources",
+		"Number of Github resources not managed by Goliac (as of the last apply), by type",
+		"organization", "type")
+	WorkflowExecutionsTotal = Metrics.NewCounter(
+		"goliac_workflow_executions_total",
+		"Number of workflow executions, by workflow and result (success, failure)",
+		"organization", "workflow", "result")
+)
+
+/*
+MetricsRegistry holds metrics and writes them in the Prometheus text format
+*/
+type MetricsRegistry struct {
+	mutex   sync.Mutex
+	metrics map[string]*metricVec
+}
+
+func NewMetricsRegistry() *MetricsRegistry {
+	return &MetricsRegistry{
+		metrics: make(map[string]*metricVec),
+	}
+}
+
+// Counter is a metric that only goes up
+type Counter struct {
+	vec *metricVec
+}
+
+// Gauge is a metric that can go up and down
+type Gauge struct {
+	vec *metricVec
+}
+
+// Histogram samples observations (like durations) in buckets
+type Histogram struct {
+	vec *metricVec
+}
+
+type metricSample struct {
+	labelValues  []string
+	value        float64
+	bucketCounts []uint64 // histogram only, one per bucket (non cumulative)
+	count        uint64   // histogram only
+}
+
+type metricVec struct {
+	name       string
+	help       string
+	kind       string // counter, gauge, histogram
+	labelNames []string
+	buckets    []float64 // histogram only, sorted upper bounds
+	mutex      sync.Mutex
+	samples    map[string]*metricSample
+}
+
+func (r *MetricsRegistry) register(name, help, kind string, buckets []float64, labelNames []string) *metricVec {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if vec, ok := r.metrics[name]; ok {
+		return vec
+	}
+	vec := &metricVec{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		buckets:    buckets,
+		samples:    make(map[string]*metricSample),
+	}
+	r.metrics[name] = vec
+	return vec
+}
+
+func (r *MetricsRegistry) NewCounter(name, help string, labelNames ...string) *Counter {
+	return &Counter{vec: r.register(name, help, "counter", nil, labelNames)}
+}
+
+func (r *MetricsRegistry) NewGauge(name, help string, labelNames ...string) *Gauge {
+	return &Gauge{vec: r.register(name, help, "gauge", nil, labelNames)}
+}
+
+func (r *MetricsRegistry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+	return &Histogram{vec: r.register(name, help, "histogram", sorted, labelNames)}
+}
+
+/*
+sample returns the sample of the label values (created if needed).
+The caller must hold the mutex. Missing label values are empty, extra ones are ignored
+*/
+func (m *metricVec) sample(labelValues []string) *metricSample {
+	values := make([]string, len(m.labelNames))
+	copy(values, labelValues)
+	key := strings.Join(values, "\xff")
+
+	s, ok := m.samples[key]
+	if !ok {
+		s = &metricSample{labelValues: values}
+		if m.kind == "histogram" {
+			s.bucketCounts = make([]uint64, len(m.buckets))
+		}
+		m.samples[key] = s
+	}
+	return s
+}
+
+func (m *metricVec) get(labelValues []string) *metricSample {
+	values := make([]string, len(m.labelNames))
+	copy(values, labelValues)
+	return m.samples[strings.Join(values, "\xff")]
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increases the counter (negative values are ignored)
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	c.vec.mutex.Lock()
+	defer c.vec.mutex.Unlock()
+	c.vec.sample(labelValues).value += v
+}
+
+func (c *Counter) Value(labelValues ...string) float64 {
+	c.vec.mutex.Lock()
+	defer c.vec.mutex.Unlock()
+	if s := c.vec.get(labelValues); s != nil {
+		return s.value
+	}
+	return 0
+}
+
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.vec.mutex.Lock()
+	defer g.vec.mutex.Unlock()
+	g.vec.sample(labelValues).value = v
+}
+
+func (g *Gauge) Value(labelValues ...string) float64 {
+	g.vec.mutex.Lock()
+	defer g.vec.mutex.Unlock()
+	if s := g.vec.get(labelValues); s != nil {
+		return s.value
+	}
+	return 0
+}
+
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.vec.mutex.Lock()
+	defer h.vec.mutex.Unlock()
+	s := h.vec.sample(labelValues)
+	for i, upper := range h.vec.buckets {
+		if v <= upper {
+			s.bucketCounts[i]++
+			break
+		}
+	}
+	s.value += v
+	s.count++
+}
+
+// Count returns the number of observations
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	h.vec.mutex.Lock()
+	defer h.vec.mutex.Unlock()
+	if s := h.vec.get(labelValues); s != nil {
+		return s.count
+	}
+	return 0
+}
+
+/*
+Write writes all the metrics (sorted by name) in the Prometheus text exposition format (version 0.0.4).
+The metrics without samples are skipped
+*/
+func (r *MetricsRegistry) Write(w io.Writer) error {
+	r.mutex.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	r.mutex.Unlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		r.mutex.Lock()
+		vec := r.metrics[name]
+		r.mutex.Unlock()
+		if err := vec.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *metricVec) write(w io.Writer) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if len(m.samples) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m.samples))
+	for key := range m.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# HELP %s %s\n", m.name, escapeMetricHelp(m.help))
+	fmt.Fprintf(&sb, "# TYPE %s %s\n", m.name, m.kind)
+	for _, key := range keys {
+		s := m.samples[key]
+		if m.kind != "histogram" {
+			fmt.Fprintf(&sb, "%s%s %s\n", m.name, formatMetricLabels(m.labelNames, s.labelValues, ""), formatMetricValue(s.value))
+			continue
+		}
+		var cumulative uint64
+		for i, upper := range m.buckets {
+			cumulative += s.bucketCounts[i]
+			fmt.Fprintf(&sb, "%s_bucket%s %d\n", m.name, formatMetricLabels(m.labelNames, s.labelValues, formatMetricValue(upper)), cumulative)
+		}
+		fmt.Fprintf(&sb, "%s_bucket%s %d\n", m.name, formatMetricLabels(m.labelNames, s.labelValues, "+Inf"), s.count)
+		fmt.Fprintf(&sb, "%s_sum%s %s\n", m.name, formatMetricLabels(m.labelNames, s.labelValues, ""), formatMetricValue(s.value))
+		fmt.Fprintf(&sb, "%s_count%s %d\n", m.name, formatMetricLabels(m.labelNames, s.labelValues, ""), s.count)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+/*
+formatMetricLabels returns {name1="value1",...}, with the le label of the histogram buckets (if not empty)
+*/
+func formatMetricLabels(names []string, values []string, le string) string {
+	pairs := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", name, escapeMetricLabelValue(values[i])))
+	}
+	if le != "" {
+		pairs = append(pairs, fmt.Sprintf("le=\"%s\"", le))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatMetricValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeMetricHelp(help string) string {
+	help = strings.ReplaceAll(help, "\\", "\\\\")
+	return strings.ReplaceAll(help, "\n", "\\n")
+}
+
+func escapeMetricLabelValue(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "\"", "\\\"")
+	return strings.ReplaceAll(value, "\n", "\\n")
+}
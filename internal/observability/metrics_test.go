@@ -0,0 +1,88 @@
+package observability
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsRegistry(t *testing.T) {
+	t.Run("happy path: counters and gauges", func(t *testing.T) {
+		registry := NewMetricsRegistry()
+		counter := registry.NewCounter("test_calls_total", "Number of calls", "organization", "result")
+		gauge := registry.NewGauge("test_remaining", "Remaining calls")
+
+		counter.Inc("myorg", "success")
+		counter.Add(2, "myorg", "success")
+		counter.Inc("myorg", "failure")
+		counter.Add(-1, "myorg", "failure")
+		gauge.Set(4999)
+
+		assert.Equal(t, float64(3), counter.Value("myorg", "success"))
+		assert.Equal(t, float64(1), counter.Value("myorg", "failure"))
+		assert.Equal(t, float64(0), counter.Value("otherorg", "success"))
+
+		var buf bytes.Buffer
+		err := registry.Write(&buf)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "# HELP test_calls_total Number of calls\n"+
+			"# TYPE test_calls_total counter\n"+
+			"test_calls_total{organization=\"myorg\",result=\"failure\"} 1\n"+
+			"test_calls_total{organization=\"myorg\",result=\"success\"} 3\n"+
+			"# HELP test_remaining Remaining calls\n"+
+			"# TYPE test_remaining gauge\n"+
+			"test_remaining 4999\n", buf.String())
+	})
+
+	t.Run("happy path: histogram", func(t *testing.T) {
+		registry := NewMetricsRegistry()
+		histogram := registry.NewHistogram("test_duration_seconds", "Duration", []float64{10, 1}, "organization")
+
+		histogram.Observe(0.5, "myorg")
+		histogram.Observe(5, "myorg")
+		histogram.Observe(20, "myorg")
+
+		assert.Equal(t, uint64(3), histogram.Count("myorg"))
+
+		var buf bytes.Buffer
+		err := registry.Write(&buf)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "# HELP test_duration_seconds Duration\n"+
+			"# TYPE test_duration_seconds histogram\n"+
+			"test_duration_seconds_bucket{organization=\"myorg\",le=\"1\"} 1\n"+
+			"test_duration_seconds_bucket{organization=\"myorg\",le=\"10\"} 2\n"+
+			"test_duration_seconds_bucket{organization=\"myorg\",le=\"+Inf\"} 3\n"+
+			"test_duration_seconds_sum{organization=\"myorg\"} 25.5\n"+
+			"test_duration_seconds_count{organization=\"myorg\"} 3\n", buf.String())
+	})
+
+	t.Run("happy path: label values are escaped, metrics without samples skipped", func(t *testing.T) {
+		registry := NewMetricsRegistry()
+		registry.NewGauge("test_unused", "Unused")
+		counter := registry.NewCounter("test_total", "Total", "workflow")
+
+		counter.Inc("my \"workflow\"\n")
+
+		var buf bytes.Buffer
+		err := registry.Write(&buf)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "# HELP test_total Total\n"+
+			"# TYPE test_total counter\n"+
+			"test_total{workflow=\"my \\\"workflow\\\"\\n\"} 1\n", buf.String())
+	})
+
+	t.Run("happy path: registering twice returns the same metric", func(t *testing.T) {
+		registry := NewMetricsRegistry()
+		counter1 := registry.NewCounter("test_total", "Total")
+		counter2 := registry.NewCounter("test_total", "Total")
+
+		counter1.Inc()
+		counter2.Inc()
+
+		assert.Equal(t, float64(2), counter1.Value())
+	})
+}
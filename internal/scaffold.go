@@ -46,7 +46,7 @@ func NewScaffold() (*Scaffold, error) {
 
 	loadUsersFromGithubOrgSaml := func(feedback observability.RemoteObservability) (map[string]*entity.User, error) {
 		ctx := context.Background()
-		return engine.LoadUsersFromGithubOrgSaml(ctx, githubClient, feedback)
+		return engine.LoadUsersFromGithubOrgSaml(ctx, githubClient, config.Config.GithubAppOrganization, feedback)
 	}
 
 	return &Scaffold{
@@ -291,33 +291,7 @@ func (s *Scaffold) generateTeams(ctx context.Context, fs billy.Filesystem, teams
 			if usersOnly && team != adminteam {
 				continue
 			}
-			lTeam := entity.Team{}
-			lTeam.ApiVersion = "v1"
-			lTeam.Kind = "Team"
-			lTeam.Name = t.Name
-
-			// if we have 1 or more maintainers in the Github team
-			// we will use them as owners
-			if len(t.Maintainers) >= 1 {
-				for _, m := range t.Maintainers {
-					// put the right user name instead of the github id
-					lTeam.Spec.Owners = append(lTeam.Spec.Owners, usermap[m])
-				}
-				for _, m := range t.Members {
-					// put the right user name instead of the github id
-					lTeam.Spec.Members = append(lTeam.Spec.Members, usermap[m])
-				}
-			} else {
-				for _, m := range t.Maintainers {
-					// put the right user name instead of the github id
-					lTeam.Spec.Owners = append(lTeam.Spec.Owners, usermap[m])
-				}
-				// else we put everyone as owners
-				for _, m := range t.Members {
-					// put the right user name instead of the github id
-					lTeam.Spec.Owners = append(lTeam.Spec.Owners, usermap[m])
-				}
-			}
+			lTeam := scaffoldTeam(t, t.Name, usermap)
 
 			teamPath, err := buildTeamPath(teamIds, teams[team])
 			if err != nil {
@@ -337,200 +311,7 @@ func (s *Scaffold) generateTeams(ctx context.Context, fs billy.Filesystem, teams
 			// write repos
 			rRepos := s.remote.Repositories(ctx)
 			for _, r := range repos {
-				lRepo := entity.Repository{}
-				lRepo.ApiVersion = "v1"
-				lRepo.Kind = "Repository"
-				lRepo.Name = r
-				lRepo.Spec.Writers = repoWrite[r]
-				lRepo.Spec.Readers = repoRead[r]
-
-				if rRepo, ok := rRepos[r]; ok {
-					// basic repository properties
-					lRepo.Spec.Visibility = rRepo.Visibility
-					lRepo.Spec.AllowAutoMerge = rRepo.BoolProperties["allow_auto_merge"]
-					if !rRepo.BoolProperties["allow_merge_commit"] {
-						lRepo.Spec.AllowMergeCommit = rRepo.BoolProperties["allow_merge_commit"]
-					}
-					if !rRepo.BoolProperties["allow_squash_merge"] {
-						lRepo.Spec.AllowSquashMerge = rRepo.BoolProperties["allow_squash_merge"]
-					}
-					if !rRepo.BoolProperties["allow_rebase_merge"] {
-						lRepo.Spec.AllowRebaseMerge = rRepo.BoolProperties["allow_rebase_merge"]
-					}
-					if rRepo.DefaultMergeCommitMessage != "Default message" {
-						lRepo.Spec.DefaultMergeCommitMessage = rRepo.DefaultMergeCommitMessage
-					}
-					if rRepo.DefaultSquashCommitMessage != "Default message" {
-						lRepo.Spec.DefaultSquashCommitMessage = rRepo.DefaultSquashCommitMessage
-					}
-					lRepo.Spec.DeleteBranchOnMerge = rRepo.BoolProperties["delete_branch_on_merge"]
-					lRepo.Spec.AllowUpdateBranch = rRepo.BoolProperties["allow_update_branch"]
-					if rRepo.DefaultBranchName != "main" {
-						lRepo.Spec.DefaultBranchName = rRepo.DefaultBranchName
-					}
-					lRepo.Archived = rRepo.BoolProperties["archived"]
-					if lRepo.Archived {
-						lRepo.Spec.Writers = append(lRepo.Spec.Writers, teams[team].Name)
-					}
-
-					// scaffoldling repository rulesets
-					rRulesets := rRepo.RuleSets
-					if rRulesets != nil {
-						lRepo.Spec.Rulesets = make([]entity.RepositoryRuleSet, 0, len(rRulesets))
-
-						for rRulesetname, rRuleset := range rRulesets {
-							lRuleset := entity.RepositoryRuleSet{
-								Name: rRulesetname,
-							}
-							lRuleset.Enforcement = rRuleset.Enforcement
-							for appname, mode := range rRuleset.BypassApps {
-								lRuleset.BypassApps = append(lRuleset.BypassApps, struct {
-									AppName string
-									Mode    string
-								}{
-									AppName: appname,
-									Mode:    mode,
-								})
-							}
-							for teamslug, mode := range rRuleset.BypassTeams {
-								teamname := teamsNameBySlug[teamslug]
-								lRuleset.BypassTeams = append(lRuleset.BypassTeams, struct {
-									TeamName string
-									Mode     string
-								}{
-									TeamName: teamname,
-									Mode:     mode,
-								})
-							}
-							lRuleset.Conditions.Include = rRuleset.OnInclude
-							lRuleset.Conditions.Exclude = rRuleset.OnExclude
-							for rulename, rulespec := range rRuleset.Rules {
-								lRuleset.Rules = append(lRuleset.Rules, struct {
-									Ruletype   string
-									Parameters entity.RuleSetParameters `yaml:"parameters,omitempty"`
-								}{
-									Ruletype:   rulename,
-									Parameters: rulespec,
-								})
-							}
-
-							lRepo.Spec.Rulesets = append(lRepo.Spec.Rulesets, lRuleset)
-						}
-					}
-
-					// scaffoldling repository branch protections
-					rBranchprotection := rRepo.BranchProtections
-					if rBranchprotection != nil {
-						lRepo.Spec.BranchProtections = make([]entity.RepositoryBranchProtection, 0, len(rBranchprotection))
-
-						for rBranchprotectionPattern, rBranchprotection := range rBranchprotection {
-							lbranchprotection := entity.RepositoryBranchProtection{
-								Pattern: rBranchprotectionPattern,
-							}
-							lbranchprotection.RequiresApprovingReviews = rBranchprotection.RequiresApprovingReviews
-							lbranchprotection.RequiredApprovingReviewCount = rBranchprotection.RequiredApprovingReviewCount
-							lbranchprotection.DismissesStaleReviews = rBranchprotection.DismissesStaleReviews
-							lbranchprotection.RequiresCodeOwnerReviews = rBranchprotection.RequiresCodeOwnerReviews
-							lbranchprotection.RequireLastPushApproval = rBranchprotection.RequireLastPushApproval
-							lbranchprotection.RequiresStatusChecks = rBranchprotection.RequiresStatusChecks
-							lbranchprotection.RequiresStrictStatusChecks = rBranchprotection.RequiresStrictStatusChecks
-							lbranchprotection.RequiredStatusCheckContexts = rBranchprotection.RequiredStatusCheckContexts
-							lbranchprotection.RequiresConversationResolution = rBranchprotection.RequiresConversationResolution
-							lbranchprotection.RequiresCommitSignatures = rBranchprotection.RequiresCommitSignatures
-							lbranchprotection.RequiresLinearHistory = rBranchprotection.RequiresLinearHistory
-							lbranchprotection.AllowsForcePushes = rBranchprotection.AllowsForcePushes
-							lbranchprotection.AllowsDeletions = rBranchprotection.AllowsDeletions
-							for _, node := range rBranchprotection.BypassPullRequestAllowances.Nodes {
-								if node.Actor.TeamSlug != "" {
-									if teamname, ok := teamsNameBySlug[node.Actor.TeamSlug]; ok {
-										lbranchprotection.BypassPullRequestTeams = append(lbranchprotection.BypassPullRequestTeams, teamname)
-									}
-								}
-								if node.Actor.UserLogin != "" {
-									if username, ok := usermap[node.Actor.UserLogin]; ok {
-										lbranchprotection.BypassPullRequestUsers = append(lbranchprotection.BypassPullRequestUsers, username)
-									}
-								}
-								if node.Actor.AppSlug != "" {
-									lbranchprotection.BypassPullRequestApps = append(lbranchprotection.BypassPullRequestApps, node.Actor.AppSlug)
-								}
-							}
-
-							lRepo.Spec.BranchProtections = append(lRepo.Spec.BranchProtections, lbranchprotection)
-						}
-					}
-
-					// scaffoldling repository environments, env variables and variables
-					rEnvironments := rRepo.Environments
-					if rEnvironments != nil {
-						lRepo.Spec.Environments = make([]entity.RepositoryEnvironment, 0, len(rEnvironments.GetEntity()))
-
-						for _, e := range rEnvironments.GetEntity() {
-							re := entity.RepositoryEnvironment{
-								Name:      e.Name,
-								Variables: make(map[string]string),
-							}
-							for k, v := range e.Variables {
-								re.Variables[k] = v
-							}
-							lRepo.Spec.Environments = append(lRepo.Spec.Environments, re)
-						}
-					}
-
-					lRepo.Spec.ActionsVariables = make(map[string]string)
-					if rRepo.RepositoryVariables != nil {
-						for n, v := range rRepo.RepositoryVariables.GetEntity() {
-							lRepo.Spec.ActionsVariables[n] = v
-						}
-					}
-
-					rAutolinks := rRepo.Autolinks
-					if rAutolinks != nil && len(rAutolinks.GetEntity()) != 0 {
-						autolinksPtr := make([]entity.RepositoryAutolink, 0, len(rAutolinks.GetEntity()))
-						lRepo.Spec.Autolinks = &autolinksPtr
-						for _, e := range rAutolinks.GetEntity() {
-							ra := entity.RepositoryAutolink{
-								KeyPrefix:      e.KeyPrefix,
-								UrlTemplate:    e.UrlTemplate,
-								IsAlphanumeric: e.IsAlphanumeric,
-							}
-							*lRepo.Spec.Autolinks = append(*lRepo.Spec.Autolinks, ra)
-						}
-					}
-
-					if gp := engine.EntityGithubPagesFromRemote(rRepo.GithubPages); gp != nil {
-						lRepo.Spec.GithubPages = gp
-					}
-				}
-
-				// removing team name from writer
-				for i, t := range lRepo.Spec.Writers {
-					if teamsSlugByName[t] == team {
-						lRepo.Spec.Writers = append(lRepo.Spec.Writers[:i], lRepo.Spec.Writers[i+1:]...)
-						break
-					}
-				}
-				// removing team owner (especially for the special case teams repo)
-				for i, t := range lRepo.Spec.Writers {
-					if strings.HasSuffix(t, config.Config.GoliacTeamOwnerSuffix) {
-						lRepo.Spec.Writers = append(lRepo.Spec.Writers[:i], lRepo.Spec.Writers[i+1:]...)
-						break
-					}
-				}
-
-				// scaffold custom properties (inside the if block where rRepo is in scope)
-				if rRepo, ok := rRepos[r]; ok {
-					if len(rRepo.CustomProperties) > 0 {
-						lRepo.Spec.CustomProperties = make(map[string]interface{})
-						for propName, propValue := range rRepo.CustomProperties {
-							lRepo.Spec.CustomProperties[propName] = propValue
-						}
-					}
-					// scaffold topics
-					if len(rRepo.Topics) > 0 {
-						lRepo.Spec.Topics = rRepo.Topics
-					}
-				}
+				lRepo := scaffoldRepository(r, rRepos[r], team, repoWrite[r], repoRead[r], teams, teamsSlugByName, teamsNameBySlug, usermap)
 
 				if lRepo.Archived {
 					if err := writeYamlFile(path.Join(archivepath, r+".yaml"), &lRepo, fs); err != nil {
@@ -558,33 +339,7 @@ func (s *Scaffold) generateTeams(ctx context.Context, fs billy.Filesystem, teams
 
 		// searching for loney team (ie without repos)
 		if _, ok := teamsRepos[slugName]; !ok {
-			lTeam := entity.Team{}
-			lTeam.ApiVersion = "v1"
-			lTeam.Kind = "Team"
-			lTeam.Name = teamName
-
-			// if we have 1 or more maintainers in the Github team
-			// we will use them as owners
-			if len(t.Maintainers) >= 1 {
-				for _, m := range t.Maintainers {
-					// put the right user name instead of the github id
-					lTeam.Spec.Owners = append(lTeam.Spec.Owners, usermap[m])
-				}
-				for _, m := range t.Members {
-					// put the right user name instead of the github id
-					lTeam.Spec.Members = append(lTeam.Spec.Members, usermap[m])
-				}
-			} else {
-				for _, m := range t.Maintainers {
-					// put the right user name instead of the github id
-					lTeam.Spec.Owners = append(lTeam.Spec.Owners, usermap[m])
-				}
-				// else we put everyone as owners
-				for _, m := range t.Members {
-					// put the right user name instead of the github id
-					lTeam.Spec.Owners = append(lTeam.Spec.Owners, usermap[m])
-				}
-			}
+			lTeam := scaffoldTeam(t, teamName, usermap)
 
 			teamPath, err := buildTeamPath(teamIds, teams[slugName])
 			if err != nil {
@@ -600,6 +355,216 @@ func (s *Scaffold) generateTeams(ctx context.Context, fs billy.Filesystem, teams
 	}
 }
 
+/*
+ * scaffoldTeam converts a Github team into a team definition.
+ * usermap is a map[<githubid>]<username>
+ */
+func scaffoldTeam(t *engine.GithubTeam, teamname string, usermap map[string]string) entity.Team {
+	lTeam := entity.Team{}
+	lTeam.ApiVersion = "v1"
+	lTeam.Kind = "Team"
+	lTeam.Name = teamname
+
+	// if we have 1 or more maintainers in the Github team
+	// we will use them as owners
+	if len(t.Maintainers) >= 1 {
+		for _, m := range t.Maintainers {
+			// put the right user name instead of the github id
+			lTeam.Spec.Owners = append(lTeam.Spec.Owners, usermap[m])
+		}
+		for _, m := range t.Members {
+			// put the right user name instead of the github id
+			lTeam.Spec.Members = append(lTeam.Spec.Members, usermap[m])
+		}
+	} else {
+		for _, m := range t.Maintainers {
+			// put the right user name instead of the github id
+			lTeam.Spec.Owners = append(lTeam.Spec.Owners, usermap[m])
+		}
+		// else we put everyone as owners
+		for _, m := range t.Members {
+			// put the right user name instead of the github id
+			lTeam.Spec.Owners = append(lTeam.Spec.Owners, usermap[m])
+		}
+	}
+	return lTeam
+}
+
+/*
+ * scaffoldRepository converts a Github repository (owned by the team slug 'team')
+ * into a repository definition.
+ * - writers and readers are team names
+ * - usermap is a map[<githubid>]<username>
+ */
+func scaffoldRepository(r string, rRepo *engine.GithubRepository, team string, writers []string, readers []string, teams map[string]*engine.GithubTeam, teamsSlugByName map[string]string, teamsNameBySlug map[string]string, usermap map[string]string) entity.Repository {
+	lRepo := entity.Repository{}
+	lRepo.ApiVersion = "v1"
+	lRepo.Kind = "Repository"
+	lRepo.Name = r
+	lRepo.Spec.Writers = writers
+	lRepo.Spec.Readers = readers
+
+	if rRepo != nil {
+		// basic repository properties
+		lRepo.Spec.Visibility = rRepo.Visibility
+		lRepo.Spec.AllowAutoMerge = rRepo.BoolProperties["allow_auto_merge"]
+		if !rRepo.BoolProperties["allow_merge_commit"] {
+			lRepo.Spec.AllowMergeCommit = rRepo.BoolProperties["allow_merge_commit"]
+		}
+		if !rRepo.BoolProperties["allow_squash_merge"] {
+			lRepo.Spec.AllowSquashMerge = rRepo.BoolProperties["allow_squash_merge"]
+		}
+		if !rRepo.BoolProperties["allow_rebase_merge"] {
+			lRepo.Spec.AllowRebaseMerge = rRepo.BoolProperties["allow_rebase_merge"]
+		}
+		if rRepo.DefaultMergeCommitMessage != "Default message" {
+			lRepo.Spec.DefaultMergeCommitMessage = rRepo.DefaultMergeCommitMessage
+		}
+		if rRepo.DefaultSquashCommitMessage != "Default message" {
+			lRepo.Spec.DefaultSquashCommitMessage = rRepo.DefaultSquashCommitMessage
+		}
+		lRepo.Spec.DeleteBranchOnMerge = rRepo.BoolProperties["delete_branch_on_merge"]
+		lRepo.Spec.AllowUpdateBranch = rRepo.BoolProperties["allow_update_branch"]
+		if rRepo.DefaultBranchName != "main" {
+			lRepo.Spec.DefaultBranchName = rRepo.DefaultBranchName
+		}
+		lRepo.Archived = rRepo.BoolProperties["archived"]
+		if lRepo.Archived {
+			lRepo.Spec.Writers = append(lRepo.Spec.Writers, teams[team].Name)
+		}
+
+		// scaffoldling repository rulesets
+		rRulesets := rRepo.RuleSets
+		if rRulesets != nil {
+			lRepo.Spec.Rulesets = make([]entity.RepositoryRuleSet, 0, len(rRulesets))
+
+			for rRulesetname, rRuleset := range rRulesets {
+				lRuleset := entity.RepositoryRuleSet{
+					Name:              rRulesetname,
+					RuleSetDefinition: scaffoldRuleSetDefinition(rRuleset, teamsNameBySlug),
+				}
+
+				lRepo.Spec.Rulesets = append(lRepo.Spec.Rulesets, lRuleset)
+			}
+		}
+
+		// scaffoldling repository branch protections
+		rBranchprotection := rRepo.BranchProtections
+		if rBranchprotection != nil {
+			lRepo.Spec.BranchProtections = make([]entity.RepositoryBranchProtection, 0, len(rBranchprotection))
+
+			for rBranchprotectionPattern, rBranchprotection := range rBranchprotection {
+				lbranchprotection := entity.RepositoryBranchProtection{
+					Pattern: rBranchprotectionPattern,
+				}
+				lbranchprotection.RequiresApprovingReviews = rBranchprotection.RequiresApprovingReviews
+				lbranchprotection.RequiredApprovingReviewCount = rBranchprotection.RequiredApprovingReviewCount
+				lbranchprotection.DismissesStaleReviews = rBranchprotection.DismissesStaleReviews
+				lbranchprotection.RequiresCodeOwnerReviews = rBranchprotection.RequiresCodeOwnerReviews
+				lbranchprotection.RequireLastPushApproval = rBranchprotection.RequireLastPushApproval
+				lbranchprotection.RequiresStatusChecks = rBranchprotection.RequiresStatusChecks
+				lbranchprotection.RequiresStrictStatusChecks = rBranchprotection.RequiresStrictStatusChecks
+				lbranchprotection.RequiredStatusCheckContexts = rBranchprotection.RequiredStatusCheckContexts
+				lbranchprotection.RequiresConversationResolution = rBranchprotection.RequiresConversationResolution
+				lbranchprotection.RequiresCommitSignatures = rBranchprotection.RequiresCommitSignatures
+				lbranchprotection.RequiresLinearHistory = rBranchprotection.RequiresLinearHistory
+				lbranchprotection.AllowsForcePushes = rBranchprotection.AllowsForcePushes
+				lbranchprotection.AllowsDeletions = rBranchprotection.AllowsDeletions
+				for _, node := range rBranchprotection.BypassPullRequestAllowances.Nodes {
+					if node.Actor.TeamSlug != "" {
+						if teamname, ok := teamsNameBySlug[node.Actor.TeamSlug]; ok {
+							lbranchprotection.BypassPullRequestTeams = append(lbranchprotection.BypassPullRequestTeams, teamname)
+						}
+					}
+					if node.Actor.UserLogin != "" {
+						if username, ok := usermap[node.Actor.UserLogin]; ok {
+							lbranchprotection.BypassPullRequestUsers = append(lbranchprotection.BypassPullRequestUsers, username)
+						}
+					}
+					if node.Actor.AppSlug != "" {
+						lbranchprotection.BypassPullRequestApps = append(lbranchprotection.BypassPullRequestApps, node.Actor.AppSlug)
+					}
+				}
+
+				lRepo.Spec.BranchProtections = append(lRepo.Spec.BranchProtections, lbranchprotection)
+			}
+		}
+
+		// scaffoldling repository environments, env variables and variables
+		rEnvironments := rRepo.Environments
+		if rEnvironments != nil {
+			lRepo.Spec.Environments = make([]entity.RepositoryEnvironment, 0, len(rEnvironments.GetEntity()))
+
+			for _, e := range rEnvironments.GetEntity() {
+				re := entity.RepositoryEnvironment{
+					Name:      e.Name,
+					Variables: make(map[string]string),
+				}
+				for k, v := range e.Variables {
+					re.Variables[k] = v
+				}
+				lRepo.Spec.Environments = append(lRepo.Spec.Environments, re)
+			}
+		}
+
+		lRepo.Spec.ActionsVariables = make(map[string]string)
+		if rRepo.RepositoryVariables != nil {
+			for n, v := range rRepo.RepositoryVariables.GetEntity() {
+				lRepo.Spec.ActionsVariables[n] = v
+			}
+		}
+
+		rAutolinks := rRepo.Autolinks
+		if rAutolinks != nil && len(rAutolinks.GetEntity()) != 0 {
+			autolinksPtr := make([]entity.RepositoryAutolink, 0, len(rAutolinks.GetEntity()))
+			lRepo.Spec.Autolinks = &autolinksPtr
+			for _, e := range rAutolinks.GetEntity() {
+				ra := entity.RepositoryAutolink{
+					KeyPrefix:      e.KeyPrefix,
+					UrlTemplate:    e.UrlTemplate,
+					IsAlphanumeric: e.IsAlphanumeric,
+				}
+				*lRepo.Spec.Autolinks = append(*lRepo.Spec.Autolinks, ra)
+			}
+		}
+
+		if gp := engine.EntityGithubPagesFromRemote(rRepo.GithubPages); gp != nil {
+			lRepo.Spec.GithubPages = gp
+		}
+	}
+
+	// removing team name from writer
+	for i, t := range lRepo.Spec.Writers {
+		if teamsSlugByName[t] == team {
+			lRepo.Spec.Writers = append(lRepo.Spec.Writers[:i], lRepo.Spec.Writers[i+1:]...)
+			break
+		}
+	}
+	// removing team owner (especially for the special case teams repo)
+	for i, t := range lRepo.Spec.Writers {
+		if strings.HasSuffix(t, config.Config.GoliacTeamOwnerSuffix) {
+			lRepo.Spec.Writers = append(lRepo.Spec.Writers[:i], lRepo.Spec.Writers[i+1:]...)
+			break
+		}
+	}
+
+	// scaffold custom properties
+	if rRepo != nil {
+		if len(rRepo.CustomProperties) > 0 {
+			lRepo.Spec.CustomProperties = make(map[string]interface{})
+			for propName, propValue := range rRepo.CustomProperties {
+				lRepo.Spec.CustomProperties[propName] = propValue
+			}
+		}
+		// scaffold topics
+		if len(rRepo.Topics) > 0 {
+			lRepo.Spec.Topics = rRepo.Topics
+		}
+	}
+
+	return lRepo
+}
+
 func buildTeamPath(teamIds map[int]*engine.GithubTeam, team *engine.GithubTeam) (string, error) {
 	maxRecursive := 100
 	fullpath := team.Name
@@ -899,3 +864,42 @@ func writeFile(filename string, content []byte, fs billy.Filesystem) error {
 	}
 	return nil
 }
+
+/*
+ * scaffoldRuleSetDefinition converts a Github ruleset into its Goliac definition
+ */
+func scaffoldRuleSetDefinition(rRuleset *engine.GithubRuleSet, teamsNameBySlug map[string]string) entity.RuleSetDefinition {
+	lRuleset := entity.RuleSetDefinition{}
+	lRuleset.Enforcement = rRuleset.Enforcement
+	for appname, mode := range rRuleset.BypassApps {
+		lRuleset.BypassApps = append(lRuleset.BypassApps, struct {
+			AppName string
+			Mode    string
+		}{
+			AppName: appname,
+			Mode:    mode,
+		})
+	}
+	for teamslug, mode := range rRuleset.BypassTeams {
+		teamname := teamsNameBySlug[teamslug]
+		lRuleset.BypassTeams = append(lRuleset.BypassTeams, struct {
+			TeamName string
+			Mode     string
+		}{
+			TeamName: teamname,
+			Mode:     mode,
+		})
+	}
+	lRuleset.Conditions.Include = rRuleset.OnInclude
+	lRuleset.Conditions.Exclude = rRuleset.OnExclude
+	for rulename, rulespec := range rRuleset.Rules {
+		lRuleset.Rules = append(lRuleset.Rules, struct {
+			Ruletype   string
+			Parameters entity.RuleSetParameters `yaml:"parameters,omitempty"`
+		}{
+			Ruletype:   rulename,
+			Parameters: rulespec,
+		})
+	}
+	return lRuleset
+}
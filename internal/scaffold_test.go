@@ -22,6 +22,7 @@ type ScaffoldGoliacRemoteMock struct {
 	teams      map[string]*engine.GithubTeam
 	repos      map[string]*engine.GithubRepository
 	teamsRepos map[string]map[string]*engine.GithubTeamRepo
+	rulesets   map[string]*engine.GithubRuleSet
 }
 
 func (s *ScaffoldGoliacRemoteMock) Load(ctx context.Context, continueOnError bool) error {
@@ -31,6 +32,9 @@ func (s *ScaffoldGoliacRemoteMock) FlushCache() {
 }
 func (s *ScaffoldGoliacRemoteMock) FlushCacheUsersTeamsOnly() {
 }
+func (s *ScaffoldGoliacRemoteMock) HandleOrgEvent(ctx context.Context, event *engine.GithubOrgEvent) bool {
+	return false
+}
 func (s *ScaffoldGoliacRemoteMock) Users(ctx context.Context) map[string]*engine.GithubUser {
 	return s.users
 }
@@ -51,7 +55,7 @@ func (s *ScaffoldGoliacRemoteMock) TeamRepositories(ctx context.Context) map[str
 	return s.teamsRepos
 }
 func (s *ScaffoldGoliacRemoteMock) RuleSets(ctx context.Context) map[string]*engine.GithubRuleSet {
-	return nil
+	return s.rulesets
 }
 func (s *ScaffoldGoliacRemoteMock) AppIds(ctx context.Context) map[string]*engine.GithubApp {
 	return nil
@@ -80,6 +84,10 @@ func (s *ScaffoldGoliacRemoteMock) OrgCustomProperties(ctx context.Context) map[
 	return make(map[string]*config.GithubCustomProperty)
 }
 
+func (s *ScaffoldGoliacRemoteMock) RepositoryRoles(ctx context.Context) map[string]*engine.GithubRepositoryRole {
+	return make(map[string]*engine.GithubRepositoryRole)
+}
+
 func (s *ScaffoldGoliacRemoteMock) GetRepositoryPages(ctx context.Context, repositoryName string) (*engine.GithubPagesRemote, error) {
 	return nil, nil
 }
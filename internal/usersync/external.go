@@ -0,0 +1,312 @@
+package usersync
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/engine"
+	"github.com/goliac-project/goliac/internal/entity"
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/sirupsen/logrus"
+)
+
+const EXTERNAL_PLUGIN_TIMEOUT = 15 * time.Minute
+
+// time given to the plugin to exit once its stdin is closed, before killing it
+var externalPluginCloseTimeout = 10 * time.Second
+
+// JSON-RPC 2.0 error codes
+const (
+	JSONRPC_METHOD_NOT_FOUND = -32601
+)
+
+/*
+ * UserSyncPluginExternal: this plugin runs the usersync.path binary (with usersync.args),
+ * and talks to it with JSON-RPC 2.0 over stdio (one JSON message per line):
+ * - ListUsers: {"config": {...}} -> {"users": [{"name": "alice", "githubID": "alice-gh", "email": "...", "fullName": "...", "manager": "bob", "labels": {...}}]}
+ * - ListGroups: {"config": {...}, "groups": ["g1"]} -> {"groups": {"g1": ["alice"]}}
+ * While processing a call, the plugin can send "Progress" notifications
+ * ({"total": 100} and/or {"loaded": 10}) that are reported to the RemoteObservability.
+ * The plugin stderr is logged (debug level).
+ */
+type UserSyncPluginExternal struct{}
+
+func NewUserSyncPluginExternal() engine.UserSyncPlugin {
+	return &UserSyncPluginExternal{}
+}
+
+type ExternalPluginUser struct {
+	Name     string            `json:"name"`
+	GithubID string            `json:"githubID"`
+	Email    string            `json:"email,omitempty"`
+	FullName string            `json:"fullName,omitempty"`
+	Manager  string            `json:"manager,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+}
+
+type externalListUsersParams struct {
+	Config map[string]string `json:"config"`
+}
+
+type externalListUsersResult struct {
+	Users []ExternalPluginUser `json:"users"`
+}
+
+type externalListGroupsParams struct {
+	Config map[string]string `json:"config"`
+	Groups []string          `json:"groups"`
+}
+
+type externalListGroupsResult struct {
+	Groups map[string][]string `json:"groups"`
+}
+
+type externalProgressParams struct {
+	Total  *int `json:"total,omitempty"`
+	Loaded int  `json:"loaded,omitempty"`
+}
+
+type jsonrpcRequest struct {
+	JsonRpc string      `json:"jsonrpc"`
+	Id      int         `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type jsonrpcMessage struct {
+	JsonRpc string               `json:"jsonrpc"`
+	Id      *int                 `json:"id,omitempty"`
+	Method  string               `json:"method,omitempty"`
+	Params  json.RawMessage      `json:"params,omitempty"`
+	Result  json.RawMessage      `json:"result,omitempty"`
+	Error   *ExternalPluginError `json:"error,omitempty"`
+}
+
+/*
+ * ExternalPluginError is a (JSON-RPC) error returned by an external plugin
+ */
+type ExternalPluginError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *ExternalPluginError) Error() string {
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("plugin error %d: %s (%s)", e.Code, e.Message, string(e.Data))
+	}
+	return fmt.Sprintf("plugin error %d: %s", e.Code, e.Message)
+}
+
+/*
+ * externalPluginProcess is a running external plugin
+ */
+type externalPluginProcess struct {
+	cmd       *exec.Cmd
+	cancel    context.CancelFunc
+	stdin     io.WriteCloser
+	stdoutRaw io.Reader
+	stdout    *bufio.Scanner
+	nextId    int
+}
+
+func startExternalPlugin(repoconfig *config.RepositoryConfig) (*externalPluginProcess, error) {
+	if repoconfig.UserSync.Path == "" {
+		return nil, fmt.Errorf("usersync.path is not defined in goliac.yaml")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), EXTERNAL_PLUGIN_TIMEOUT)
+	cmd := exec.CommandContext(ctx, repoconfig.UserSync.Path, repoconfig.UserSync.Args...)
+	// don't wait for the pipes forever once the plugin is killed
+	cmd.WaitDelay = externalPluginCloseTimeout
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("not able to start the plugin %s: %v", repoconfig.UserSync.Path, err)
+	}
+
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			logrus.WithField("plugin", repoconfig.UserSync.Path).Debug(scanner.Text())
+		}
+	}()
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	return &externalPluginProcess{
+		cmd:       cmd,
+		cancel:    cancel,
+		stdin:     stdin,
+		stdoutRaw: stdout,
+		stdout:    scanner,
+	}, nil
+}
+
+/*
+ * call sends a JSON-RPC request and waits for its response, reporting the
+ * Progress notifications received in between
+ */
+func (p *externalPluginProcess) call(method string, params interface{}, result interface{}, feedback observability.RemoteObservability) error {
+	p.nextId++
+	request, err := json.Marshal(jsonrpcRequest{JsonRpc: "2.0", Id: p.nextId, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	if _, err := p.stdin.Write(append(request, '\n')); err != nil {
+		return fmt.Errorf("not able to send %s to the plugin: %v", method, err)
+	}
+
+	for p.stdout.Scan() {
+		var message jsonrpcMessage
+		if err := json.Unmarshal(p.stdout.Bytes(), &message); err != nil {
+			return fmt.Errorf("invalid message from the plugin: %v", err)
+		}
+
+		if message.Id == nil {
+			if message.Method == "Progress" && feedback != nil {
+				var progress externalProgressParams
+				if err := json.Unmarshal(message.Params, &progress); err == nil {
+					if progress.Total != nil {
+						feedback.Init(*progress.Total)
+					}
+					if progress.Loaded > 0 {
+						feedback.LoadingAsset("users", progress.Loaded)
+					}
+				}
+			}
+			continue
+		}
+		if *message.Id != p.nextId {
+			return fmt.Errorf("unexpected response id %d from the plugin (expected %d)", *message.Id, p.nextId)
+		}
+		if message.Error != nil {
+			return message.Error
+		}
+		if err := json.Unmarshal(message.Result, result); err != nil {
+			return fmt.Errorf("invalid %s result from the plugin: %v", method, err)
+		}
+		return nil
+	}
+	if err := p.stdout.Err(); err != nil {
+		return fmt.Errorf("not able to read the plugin response: %v", err)
+	}
+	return fmt.Errorf("the plugin exited without answering %s", method)
+}
+
+/*
+ * Close closes the plugin stdin and waits for it to exit. The remaining stdout
+ * is discarded (a plugin blocked writing a response we don't read anymore, after
+ * a failed call, would never exit), and the plugin is killed if it doesn't exit
+ * within externalPluginCloseTimeout.
+ */
+func (p *externalPluginProcess) Close() error {
+	defer p.cancel()
+	p.stdin.Close()
+	go io.Copy(io.Discard, p.stdoutRaw)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.cmd.Wait()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(externalPluginCloseTimeout):
+		p.cancel()
+		<-done
+		return fmt.Errorf("the plugin didn't exit within %v and was killed", externalPluginCloseTimeout)
+	}
+}
+
+/*
+Return a map of [username]*entity.User
+*/
+func (p *UserSyncPluginExternal) UpdateUsers(repoconfig *config.RepositoryConfig, fs billy.Filesystem, orguserdirrectorypath string, feedback observability.RemoteObservability, logsCollector *observability.LogCollection) map[string]*entity.User {
+	process, err := startExternalPlugin(repoconfig)
+	if err != nil {
+		logsCollector.AddError(err)
+		return nil
+	}
+
+	var result externalListUsersResult
+	err = process.call("ListUsers", externalListUsersParams{Config: repoconfig.UserSync.Config}, &result, feedback)
+	closeErr := process.Close()
+	if err != nil {
+		logsCollector.AddError(fmt.Errorf("not able to list users with the plugin %s: %w", repoconfig.UserSync.Path, err))
+		return nil
+	}
+	if closeErr != nil {
+		logsCollector.AddWarn(fmt.Errorf("the plugin %s didn't exit properly: %v", repoconfig.UserSync.Path, closeErr))
+	}
+
+	users := make(map[string]*entity.User)
+	for _, u := range result.Users {
+		if u.Name == "" || u.GithubID == "" {
+			logsCollector.AddWarn(fmt.Errorf("plugin user without name or githubID (%s/%s), skipping", u.Name, u.GithubID))
+			continue
+		}
+		user := &entity.User{}
+		user.ApiVersion = "v1"
+		user.Kind = "User"
+		user.Name = u.Name
+		user.Spec.GithubID = u.GithubID
+		user.Spec.Email = u.Email
+		user.Spec.FullName = u.FullName
+		user.Spec.Manager = u.Manager
+		user.Spec.Labels = u.Labels
+		users[u.Name] = user
+	}
+
+	if len(users) == 0 {
+		return nil
+	}
+	return users
+}
+
+/*
+ * GroupsMembers returns the usernames member of the groups (teams' spec.sync_from)
+ * with the ListGroups call. A plugin not implementing ListGroups cannot sync teams.
+ */
+func (p *UserSyncPluginExternal) GroupsMembers(repoconfig *config.RepositoryConfig, groups []string, logsCollector *observability.LogCollection) (map[string][]string, error) {
+	process, err := startExternalPlugin(repoconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var result externalListGroupsResult
+	err = process.call("ListGroups", externalListGroupsParams{Config: repoconfig.UserSync.Config, Groups: groups}, &result, nil)
+	process.Close()
+	if err != nil {
+		if pluginErr, ok := err.(*ExternalPluginError); ok && pluginErr.Code == JSONRPC_METHOD_NOT_FOUND {
+			logsCollector.AddWarn(fmt.Errorf("the plugin %s cannot sync teams from groups", repoconfig.UserSync.Path))
+			return map[string][]string{}, nil
+		}
+		return nil, fmt.Errorf("not able to list groups with the plugin %s: %w", repoconfig.UserSync.Path, err)
+	}
+	if result.Groups == nil {
+		return map[string][]string{}, nil
+	}
+	return result.Groups, nil
+}
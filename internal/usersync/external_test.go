@@ -0,0 +1,174 @@
+package usersync
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+ * TestExternalPluginHelperProcess is not a real test: it is the external plugin
+ * started (via the test binary) by the TestUserSyncPluginExternal tests.
+ * The plugin behavior is driven by the "mode" config setting.
+ */
+func TestExternalPluginHelperProcess(t *testing.T) {
+	if os.Getenv("GOLIAC_TEST_EXTERNAL_PLUGIN") != "1" {
+		return
+	}
+	defer os.Exit(0)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var request struct {
+			Id     int    `json:"id"`
+			Method string `json:"method"`
+			Params struct {
+				Config map[string]string `json:"config"`
+				Groups []string          `json:"groups"`
+			} `json:"params"`
+		}
+		json.Unmarshal(scanner.Bytes(), &request)
+		fmt.Fprintf(os.Stderr, "received %s\n", request.Method)
+
+		switch {
+		case request.Params.Config["mode"] == "error":
+			fmt.Printf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-32000,"message":"IdP unavailable","data":{"status":503}}}`+"\n", request.Id)
+		case request.Params.Config["mode"] == "crash":
+			os.Exit(1)
+		case request.Params.Config["mode"] == "stuck":
+			// answer garbage and never exit
+			for {
+				fmt.Println("not json")
+				time.Sleep(10 * time.Millisecond)
+			}
+		case request.Method == "ListUsers":
+			fmt.Println(`{"jsonrpc":"2.0","method":"Progress","params":{"total":3}}`)
+			fmt.Println(`{"jsonrpc":"2.0","method":"Progress","params":{"loaded":3}}`)
+			fmt.Printf(`{"jsonrpc":"2.0","id":%d,"result":{"users":[{"name":"alice","githubID":"alice-gh","email":"alice@company.com","fullName":"Alice","manager":"bob","labels":{"team":"rd"}},{"name":"bob","githubID":"bob-gh"},{"name":"nogithub"}]}}`+"\n", request.Id)
+		case request.Method == "ListGroups" && request.Params.Config["mode"] == "nogroups":
+			fmt.Printf(`{"jsonrpc":"2.0","id":%d,"error":{"code":-32601,"message":"method not found"}}`+"\n", request.Id)
+		case request.Method == "ListGroups":
+			groups := map[string][]string{}
+			for _, group := range request.Params.Groups {
+				groups[group] = []string{"alice"}
+			}
+			result, _ := json.Marshal(map[string]interface{}{"groups": groups})
+			fmt.Printf(`{"jsonrpc":"2.0","id":%d,"result":%s}`+"\n", request.Id, string(result))
+		}
+	}
+}
+
+type ExternalPluginFeedbackMock struct {
+	total  int
+	loaded int
+}
+
+func (f *ExternalPluginFeedbackMock) Init(nbTotalAssets int) {
+	f.total = nbTotalAssets
+}
+func (f *ExternalPluginFeedbackMock) Extend(nbAssets int) {
+	f.total += nbAssets
+}
+func (f *ExternalPluginFeedbackMock) LoadingAsset(entity string, nb int) {
+	f.loaded += nb
+}
+
+func fixtureExternalRepoConfig(t *testing.T, mode string) *config.RepositoryConfig {
+	t.Setenv("GOLIAC_TEST_EXTERNAL_PLUGIN", "1")
+	repoconfig := &config.RepositoryConfig{}
+	repoconfig.UserSync.Plugin = "external"
+	repoconfig.UserSync.Path = os.Args[0]
+	repoconfig.UserSync.Args = []string{"-test.run=^TestExternalPluginHelperProcess$"}
+	repoconfig.UserSync.Config = map[string]string{"mode": mode}
+	return repoconfig
+}
+
+func TestUserSyncPluginExternal(t *testing.T) {
+	t.Run("happy path: list users", func(t *testing.T) {
+		feedback := &ExternalPluginFeedbackMock{}
+		logsCollector := observability.NewLogCollection()
+		users := NewUserSyncPluginExternal().UpdateUsers(fixtureExternalRepoConfig(t, "ok"), memfs.New(), "users/org", feedback, logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 1, len(logsCollector.Warns)) // nogithub
+		assert.Equal(t, 2, len(users))
+		assert.Equal(t, "alice-gh", users["alice"].Spec.GithubID)
+		assert.Equal(t, "alice@company.com", users["alice"].Spec.Email)
+		assert.Equal(t, "Alice", users["alice"].Spec.FullName)
+		assert.Equal(t, "bob", users["alice"].Spec.Manager)
+		assert.Equal(t, map[string]string{"team": "rd"}, users["alice"].Spec.Labels)
+		assert.Equal(t, "bob-gh", users["bob"].Spec.GithubID)
+		assert.Equal(t, 3, feedback.total)
+		assert.Equal(t, 3, feedback.loaded)
+	})
+
+	t.Run("happy path: list groups", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		plugin := NewUserSyncPluginExternal().(*UserSyncPluginExternal)
+		members, err := plugin.GroupsMembers(fixtureExternalRepoConfig(t, "ok"), []string{"g1", "g2"}, logsCollector)
+
+		assert.Nil(t, err)
+		assert.Equal(t, map[string][]string{"g1": {"alice"}, "g2": {"alice"}}, members)
+	})
+
+	t.Run("happy path: the plugin doesn't support groups", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		plugin := NewUserSyncPluginExternal().(*UserSyncPluginExternal)
+		members, err := plugin.GroupsMembers(fixtureExternalRepoConfig(t, "nogroups"), []string{"g1"}, logsCollector)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(members))
+		assert.Equal(t, 1, len(logsCollector.Warns))
+	})
+
+	t.Run("not happy path: structured plugin error", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		users := NewUserSyncPluginExternal().UpdateUsers(fixtureExternalRepoConfig(t, "error"), memfs.New(), "users/org", nil, logsCollector)
+
+		assert.Nil(t, users)
+		assert.True(t, logsCollector.HasErrors())
+		var pluginErr *ExternalPluginError
+		assert.ErrorAs(t, logsCollector.Errors[0], &pluginErr)
+		assert.Equal(t, -32000, pluginErr.Code)
+		assert.Equal(t, "IdP unavailable", pluginErr.Message)
+		assert.JSONEq(t, `{"status":503}`, string(pluginErr.Data))
+	})
+
+	t.Run("not happy path: the plugin crashes", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		users := NewUserSyncPluginExternal().UpdateUsers(fixtureExternalRepoConfig(t, "crash"), memfs.New(), "users/org", nil, logsCollector)
+
+		assert.Nil(t, users)
+		assert.True(t, logsCollector.HasErrors())
+	})
+
+	t.Run("not happy path: the plugin doesn't exit", func(t *testing.T) {
+		closeTimeout := externalPluginCloseTimeout
+		externalPluginCloseTimeout = 500 * time.Millisecond
+		defer func() { externalPluginCloseTimeout = closeTimeout }()
+
+		logsCollector := observability.NewLogCollection()
+		start := time.Now()
+		users := NewUserSyncPluginExternal().UpdateUsers(fixtureExternalRepoConfig(t, "stuck"), memfs.New(), "users/org", nil, logsCollector)
+
+		assert.Nil(t, users)
+		assert.True(t, logsCollector.HasErrors())
+		assert.Less(t, time.Since(start), 10*time.Second)
+	})
+
+	t.Run("not happy path: no plugin path", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		users := NewUserSyncPluginExternal().UpdateUsers(&config.RepositoryConfig{}, memfs.New(), "users/org", nil, logsCollector)
+
+		assert.Nil(t, users)
+		assert.True(t, logsCollector.HasErrors())
+	})
+}
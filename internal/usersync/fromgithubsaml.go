@@ -19,12 +19,16 @@ import (
  * Note: this plugin doesn't clear the Remote cache.
  */
 type UserSyncPluginFromGithubSaml struct {
-	client github.GitHubClient
+	client       github.GitHubClient
+	organization string
+	teamSync     *engine.TeamSyncGroupsCache
 }
 
-func NewUserSyncPluginFromGithubSaml(client github.GitHubClient) engine.UserSyncPlugin {
+func NewUserSyncPluginFromGithubSaml(client github.GitHubClient, organization string) engine.UserSyncPlugin {
 	return &UserSyncPluginFromGithubSaml{
-		client: client,
+		client:       client,
+		organization: organization,
+		teamSync:     engine.NewTeamSyncGroupsCache(),
 	}
 }
 
@@ -34,12 +38,12 @@ Return a map of [username]*entity.User
 func (p *UserSyncPluginFromGithubSaml) UpdateUsers(repoconfig *config.RepositoryConfig, fs billy.Filesystem, orguserdirrectorypath string, feedback observability.RemoteObservability, logsCollector *observability.LogCollection) map[string]*entity.User {
 
 	ctx := context.Background()
-	pendingLogin, err := engine.LoadGithubLoginPendingInvitations(ctx, p.client)
+	pendingLogin, err := engine.LoadGithubLoginPendingInvitations(ctx, p.client, p.organization)
 	if err != nil {
 		logsCollector.AddError(fmt.Errorf("not able to load pending invitations: %w", err))
 		return nil
 	}
-	users, err := engine.LoadUsersFromGithubOrgSaml(ctx, p.client, feedback)
+	users, err := engine.LoadUsersFromGithubOrgSaml(ctx, p.client, p.organization, feedback)
 	if err != nil {
 		logsCollector.AddError(fmt.Errorf("not able to load users from Github: %w", err))
 		return nil
@@ -58,3 +62,40 @@ func (p *UserSyncPluginFromGithubSaml) UpdateUsers(repoconfig *config.Repository
 
 	return finalUsers
 }
+
+/*
+ * GroupsMembers returns the usernames member of the IdP groups (teams' spec.sync_from),
+ * using the Github team synchronization IdP group mappings
+ */
+func (p *UserSyncPluginFromGithubSaml) GroupsMembers(repoconfig *config.RepositoryConfig, groups []string, logsCollector *observability.LogCollection) (map[string][]string, error) {
+	ctx := context.Background()
+	users, err := engine.LoadUsersFromGithubOrgSaml(ctx, p.client, p.organization, nil)
+	if err != nil {
+		return nil, fmt.Errorf("not able to load users from Github: %w", err)
+	}
+	usernames := make(map[string]string)
+	for name, user := range users {
+		usernames[user.Spec.GithubID] = name
+	}
+
+	logins, err := engine.LoadTeamSyncGroupsMembers(ctx, p.client, p.organization, groups, p.teamSync)
+	if err != nil {
+		return nil, fmt.Errorf("not able to load the IdP groups from Github: %w", err)
+	}
+
+	members := make(map[string][]string)
+	for _, group := range groups {
+		groupLogins, ok := logins[group]
+		if !ok {
+			logsCollector.AddWarn(fmt.Errorf("IdP group %s is not mapped to any Github team", group))
+			continue
+		}
+		members[group] = []string{}
+		for _, login := range groupLogins {
+			if name, ok := usernames[login]; ok {
+				members[group] = append(members[group], name)
+			}
+		}
+	}
+	return members, nil
+}
@@ -0,0 +1,76 @@
+package usersync
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/engine"
+	"github.com/goliac-project/goliac/internal/entity"
+	"github.com/goliac-project/goliac/internal/observability"
+)
+
+/*
+ * UserSyncPluginFromOrganization: this plugin sync users from the teams repository
+ * of another Github organization managed by the same Goliac server (see the
+ * users_from attribute of GOLIAC_SERVER_ORGANIZATIONS_FILE), to share the users across
+ * organizations.
+ */
+type UserSyncPluginFromOrganization struct {
+	organization string
+	source       OrganizationUsersSource
+}
+
+/*
+ * OrganizationUsersSource gives a copy of the users and teams of an organization,
+ * taken under its lock (the organization can be applied at the same time)
+ */
+type OrganizationUsersSource interface {
+	LocalUsersAndTeams() (map[string]*entity.User, map[string]*entity.Team)
+}
+
+func NewUserSyncPluginFromOrganization(organization string, source OrganizationUsersSource) engine.UserSyncPlugin {
+	return &UserSyncPluginFromOrganization{
+		organization: organization,
+		source:       source,
+	}
+}
+
+/*
+Return a map of [username]*entity.User
+*/
+func (p *UserSyncPluginFromOrganization) UpdateUsers(repoconfig *config.RepositoryConfig, fs billy.Filesystem, orguserdirrectorypath string, feedback observability.RemoteObservability, logsCollector *observability.LogCollection) map[string]*entity.User {
+	users, _ := p.source.LocalUsersAndTeams()
+	if len(users) == 0 {
+		// the teams repository of the source organization is not loaded yet: nothing changes
+		logsCollector.AddWarn(fmt.Errorf("the users of the %s organization are not loaded yet", p.organization))
+		return entity.ReadUserDirectory(fs, orguserdirrectorypath, logsCollector)
+	}
+	return users
+}
+
+/*
+ * GroupsMembers returns the usernames member of the teams (teams' spec.sync_from)
+ * of the source organization
+ */
+func (p *UserSyncPluginFromOrganization) GroupsMembers(repoconfig *config.RepositoryConfig, groups []string, logsCollector *observability.LogCollection) (map[string][]string, error) {
+	_, teams := p.source.LocalUsersAndTeams()
+	if len(teams) == 0 {
+		return nil, fmt.Errorf("the teams of the %s organization are not loaded yet", p.organization)
+	}
+
+	members := make(map[string][]string)
+	for _, group := range groups {
+		team, ok := teams[group]
+		if !ok {
+			logsCollector.AddWarn(fmt.Errorf("team %s not found in the %s organization", group, p.organization))
+			continue
+		}
+		groupMembers := append([]string{}, team.Spec.Owners...)
+		groupMembers = append(groupMembers, team.Spec.Members...)
+		slices.Sort(groupMembers)
+		members[group] = slices.Compact(groupMembers)
+	}
+	return members, nil
+}
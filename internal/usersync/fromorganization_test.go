@@ -0,0 +1,86 @@
+package usersync
+
+import (
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/entity"
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/goliac-project/goliac/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type OrganizationLocalMock struct {
+	users map[string]*entity.User
+	teams map[string]*entity.Team
+}
+
+func (m *OrganizationLocalMock) LocalUsersAndTeams() (map[string]*entity.User, map[string]*entity.Team) {
+	return m.users, m.teams
+}
+
+func fixtureOrganizationLocal() *OrganizationLocalMock {
+	user1 := &entity.User{}
+	user1.Name = "user1"
+	user1.Spec.GithubID = "github1"
+	user2 := &entity.User{}
+	user2.Name = "user2"
+	user2.Spec.GithubID = "github2"
+
+	team1 := &entity.Team{}
+	team1.Name = "team1"
+	team1.Spec.Owners = []string{"user2"}
+	team1.Spec.Members = []string{"user1", "user2"}
+
+	return &OrganizationLocalMock{
+		users: map[string]*entity.User{"user1": user1, "user2": user2},
+		teams: map[string]*entity.Team{"team1": team1},
+	}
+}
+
+func TestUserSyncPluginFromOrganization(t *testing.T) {
+	t.Run("happy path: users of the source organization", func(t *testing.T) {
+		plugin := NewUserSyncPluginFromOrganization("prod", fixtureOrganizationLocal())
+		logsCollector := observability.NewLogCollection()
+
+		users := plugin.UpdateUsers(&config.RepositoryConfig{}, memfs.New(), "users/org", nil, logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 2, len(users))
+		assert.Equal(t, "github1", users["user1"].Spec.GithubID)
+	})
+
+	t.Run("happy path: source organization not loaded yet", func(t *testing.T) {
+		fs := memfs.New()
+		err := utils.WriteFile(fs, "users/org/user3.yaml", []byte(`
+apiVersion: v1
+kind: User
+name: user3
+spec:
+  githubID: github3
+`), 0644)
+		assert.Nil(t, err)
+
+		plugin := NewUserSyncPluginFromOrganization("prod", &OrganizationLocalMock{})
+		logsCollector := observability.NewLogCollection()
+
+		users := plugin.UpdateUsers(&config.RepositoryConfig{}, fs, "users/org", nil, logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.True(t, logsCollector.HasWarns())
+		assert.Equal(t, 1, len(users))
+		assert.Equal(t, "github3", users["user3"].Spec.GithubID)
+	})
+
+	t.Run("happy path: teams of the source organization", func(t *testing.T) {
+		plugin := NewUserSyncPluginFromOrganization("prod", fixtureOrganizationLocal()).(*UserSyncPluginFromOrganization)
+		logsCollector := observability.NewLogCollection()
+
+		members, err := plugin.GroupsMembers(&config.RepositoryConfig{}, []string{"team1", "unknown"}, logsCollector)
+
+		assert.Nil(t, err)
+		assert.Equal(t, map[string][]string{"team1": {"user1", "user2"}}, members)
+		assert.True(t, logsCollector.HasWarns())
+	})
+}
@@ -1,12 +1,28 @@
 package usersync
 
 import (
+	"github.com/goliac-project/goliac/internal/config"
 	"github.com/goliac-project/goliac/internal/engine"
 	"github.com/goliac-project/goliac/internal/github"
 )
 
 func InitPlugins(client github.GitHubClient) {
-	engine.RegisterPlugin("noop", NewUserSyncPluginNoop())
-	engine.RegisterPlugin("shellscript", NewUserSyncPluginShellScript())
-	engine.RegisterPlugin("fromgithubsaml", NewUserSyncPluginFromGithubSaml(client))
+	for name, plugin := range NewPlugins(client, config.Config.GithubAppOrganization) {
+		engine.RegisterPlugin(name, plugin)
+	}
+}
+
+/*
+NewPlugins returns the usersync plugins bound to a Github organization
+(when a Goliac server manages several organizations)
+*/
+func NewPlugins(client github.GitHubClient, organization string) map[string]engine.UserSyncPlugin {
+	return map[string]engine.UserSyncPlugin{
+		"noop":           NewUserSyncPluginNoop(),
+		"shellscript":    NewUserSyncPluginShellScript(),
+		"fromgithubsaml": NewUserSyncPluginFromGithubSaml(client, organization),
+		"scim":           NewUserSyncPluginScim(),
+		"ldap":           NewUserSyncPluginLdap(),
+		"external":       NewUserSyncPluginExternal(),
+	}
 }
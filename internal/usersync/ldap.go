@@ -0,0 +1,257 @@
+package usersync
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/engine"
+	"github.com/goliac-project/goliac/internal/entity"
+	"github.com/goliac-project/goliac/internal/observability"
+)
+
+const LDAP_PAGE_SIZE = 500
+
+/*
+ * ldapConnection is the subset of *ldap.Conn used by the plugin
+ */
+type ldapConnection interface {
+	Bind(username, password string) error
+	Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error)
+	SearchWithPaging(searchRequest *ldap.SearchRequest, pagingSize uint32) (*ldap.SearchResult, error)
+	Close() error
+}
+
+/*
+ * UserSyncPluginLdap: this plugin sync users from a LDAP directory
+ * - users are searched under usersync.ldap.base_dn with usersync.ldap.user_filter
+ * - the user name is taken from the usersync.ldap.username_attribute attribute (uid by default)
+ * - the Github ID is taken from the usersync.ldap.githubid_attribute attribute
+ * - the email, full name and manager (DN) are taken from the usersync.ldap.email_attribute,
+ *   fullname_attribute and manager_attribute attributes (mail, displayName, manager by default)
+ * It also resolves the members of the groups used by the teams' spec.ldapGroup (or spec.sync_from)
+ */
+type UserSyncPluginLdap struct {
+	dial func(ldapConfig config.UserSyncLdap) (ldapConnection, error)
+}
+
+func NewUserSyncPluginLdap() engine.UserSyncPlugin {
+	return &UserSyncPluginLdap{
+		dial: dialLdap,
+	}
+}
+
+func dialLdap(ldapConfig config.UserSyncLdap) (ldapConnection, error) {
+	u, err := url.Parse(ldapConfig.Url)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "ldap" && !ldapConfig.StartTLS {
+		return nil, fmt.Errorf("usersync.ldap.url must be a ldaps:// url, or usersync.ldap.start_tls must be enabled")
+	}
+	tlsConfig := &tls.Config{ServerName: u.Hostname()}
+
+	conn, err := ldap.DialURL(ldapConfig.Url, ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "ldap" {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+/*
+ * connect dials and binds to the directory, with the GOLIAC_USERSYNC_LDAP_BIND_DN
+ * and GOLIAC_USERSYNC_LDAP_BIND_PASSWORD credentials
+ */
+func (p *UserSyncPluginLdap) connect(ldapConfig config.UserSyncLdap) (ldapConnection, error) {
+	if ldapConfig.Url == "" {
+		return nil, fmt.Errorf("usersync.ldap.url is not defined in goliac.yaml")
+	}
+	if ldapConfig.BaseDN == "" {
+		return nil, fmt.Errorf("usersync.ldap.base_dn is not defined in goliac.yaml")
+	}
+	if ldapConfig.GithubIdAttribute == "" {
+		return nil, fmt.Errorf("usersync.ldap.githubid_attribute is not defined in goliac.yaml")
+	}
+
+	conn, err := p.dial(ldapConfig)
+	if err != nil {
+		return nil, fmt.Errorf("not able to connect to %s: %v", ldapConfig.Url, err)
+	}
+	if err := conn.Bind(config.Config.UserSyncLdapBindDN, config.Config.UserSyncLdapBindPassword); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("not able to bind to %s: %v", ldapConfig.Url, err)
+	}
+	return conn, nil
+}
+
+/*
+ * searchUsers returns the directory users as a map of [dn]entry
+ */
+func searchUsers(conn ldapConnection, ldapConfig config.UserSyncLdap) (map[string]*ldap.Entry, error) {
+	filter := ldapConfig.UserFilter
+	if filter == "" {
+		filter = "(objectClass=person)"
+	}
+	request := ldap.NewSearchRequest(
+		ldapConfig.BaseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		filter,
+		[]string{
+			ldapUsernameAttribute(ldapConfig),
+			ldapConfig.GithubIdAttribute,
+			ldapAttributeOrDefault(ldapConfig.EmailAttribute, "mail"),
+			ldapAttributeOrDefault(ldapConfig.FullNameAttribute, "displayName"),
+			ldapAttributeOrDefault(ldapConfig.ManagerAttribute, "manager"),
+		},
+		nil,
+	)
+	result, err := conn.SearchWithPaging(request, LDAP_PAGE_SIZE)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]*ldap.Entry)
+	for _, entry := range result.Entries {
+		entries[strings.ToLower(entry.DN)] = entry
+	}
+	return entries, nil
+}
+
+func ldapUsernameAttribute(ldapConfig config.UserSyncLdap) string {
+	return ldapAttributeOrDefault(ldapConfig.UsernameAttribute, "uid")
+}
+
+func ldapAttributeOrDefault(attribute string, defaultAttribute string) string {
+	if attribute == "" {
+		return defaultAttribute
+	}
+	return attribute
+}
+
+/*
+Return a map of [username]*entity.User
+*/
+func (p *UserSyncPluginLdap) UpdateUsers(repoconfig *config.RepositoryConfig, fs billy.Filesystem, orguserdirrectorypath string, feedback observability.RemoteObservability, logsCollector *observability.LogCollection) map[string]*entity.User {
+	ldapConfig := repoconfig.UserSync.Ldap
+
+	conn, err := p.connect(ldapConfig)
+	if err != nil {
+		logsCollector.AddError(err)
+		return nil
+	}
+	defer conn.Close()
+
+	entries, err := searchUsers(conn, ldapConfig)
+	if err != nil {
+		logsCollector.AddError(fmt.Errorf("not able to search users in %s: %v", ldapConfig.BaseDN, err))
+		return nil
+	}
+	if feedback != nil {
+		feedback.Init(len(entries))
+	}
+
+	usernameAttribute := ldapUsernameAttribute(ldapConfig)
+	users := make(map[string]*entity.User)
+	for _, entry := range entries {
+		if feedback != nil {
+			feedback.LoadingAsset("users", 1)
+		}
+		username := entry.GetAttributeValue(usernameAttribute)
+		if username == "" {
+			logsCollector.AddWarn(fmt.Errorf("LDAP user %s without %s attribute, skipping", entry.DN, usernameAttribute))
+			continue
+		}
+		githubId := entry.GetAttributeValue(ldapConfig.GithubIdAttribute)
+		if githubId == "" {
+			logsCollector.AddWarn(fmt.Errorf("LDAP user %s without %s attribute, skipping", entry.DN, ldapConfig.GithubIdAttribute))
+			continue
+		}
+
+		user := &entity.User{}
+		user.ApiVersion = "v1"
+		user.Kind = "User"
+		user.Name = username
+		user.Spec.GithubID = githubId
+		user.Spec.Email = entry.GetAttributeValue(ldapAttributeOrDefault(ldapConfig.EmailAttribute, "mail"))
+		user.Spec.FullName = entry.GetAttributeValue(ldapAttributeOrDefault(ldapConfig.FullNameAttribute, "displayName"))
+		if manager, ok := entries[strings.ToLower(entry.GetAttributeValue(ldapAttributeOrDefault(ldapConfig.ManagerAttribute, "manager")))]; ok {
+			user.Spec.Manager = manager.GetAttributeValue(usernameAttribute)
+		}
+		users[username] = user
+	}
+
+	if len(users) == 0 {
+		return nil
+	}
+	return users
+}
+
+/*
+ * GroupsMembers returns the usernames member of each group DN ([group][]username).
+ * The group members (usersync.ldap.group_member_attribute, member by default) can be
+ * users DN (member, uniqueMember) or usernames (memberUid)
+ */
+func (p *UserSyncPluginLdap) GroupsMembers(repoconfig *config.RepositoryConfig, groups []string, logsCollector *observability.LogCollection) (map[string][]string, error) {
+	ldapConfig := repoconfig.UserSync.Ldap
+	memberAttribute := ldapConfig.GroupMemberAttribute
+	if memberAttribute == "" {
+		memberAttribute = "member"
+	}
+
+	conn, err := p.connect(ldapConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entries, err := searchUsers(conn, ldapConfig)
+	if err != nil {
+		return nil, fmt.Errorf("not able to search users in %s: %v", ldapConfig.BaseDN, err)
+	}
+	usernameAttribute := ldapUsernameAttribute(ldapConfig)
+
+	members := make(map[string][]string)
+	for _, group := range groups {
+		request := ldap.NewSearchRequest(
+			group,
+			ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+			"(objectClass=*)",
+			[]string{memberAttribute},
+			nil,
+		)
+		result, err := conn.Search(request)
+		if err != nil {
+			if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+				logsCollector.AddWarn(fmt.Errorf("LDAP group %s not found", group))
+				continue
+			}
+			return nil, fmt.Errorf("not able to read the LDAP group %s: %v", group, err)
+		}
+
+		usernames := []string{}
+		for _, entry := range result.Entries {
+			for _, member := range entry.GetAttributeValues(memberAttribute) {
+				if user, ok := entries[strings.ToLower(member)]; ok {
+					if username := user.GetAttributeValue(usernameAttribute); username != "" {
+						usernames = append(usernames, username)
+					}
+				} else if !strings.Contains(member, "=") {
+					// memberUid style group
+					usernames = append(usernames, member)
+				}
+			}
+		}
+		members[group] = usernames
+	}
+	return members, nil
+}
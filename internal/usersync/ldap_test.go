@@ -0,0 +1,152 @@
+package usersync
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+type LdapConnectionMock struct {
+	bindDN   string
+	password string
+	users    []*ldap.Entry
+	groups   map[string]*ldap.Entry
+	filters  []string
+	closed   bool
+}
+
+func (c *LdapConnectionMock) Bind(username, password string) error {
+	if username != c.bindDN || password != c.password {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, fmt.Errorf("invalid credentials"))
+	}
+	return nil
+}
+
+func (c *LdapConnectionMock) Search(searchRequest *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	group, ok := c.groups[strings.ToLower(searchRequest.BaseDN)]
+	if !ok {
+		return nil, ldap.NewError(ldap.LDAPResultNoSuchObject, fmt.Errorf("no such object"))
+	}
+	return &ldap.SearchResult{Entries: []*ldap.Entry{group}}, nil
+}
+
+func (c *LdapConnectionMock) SearchWithPaging(searchRequest *ldap.SearchRequest, pagingSize uint32) (*ldap.SearchResult, error) {
+	c.filters = append(c.filters, searchRequest.Filter)
+	return &ldap.SearchResult{Entries: c.users}, nil
+}
+
+func (c *LdapConnectionMock) Close() error {
+	c.closed = true
+	return nil
+}
+
+func fixtureLdapConnection() *LdapConnectionMock {
+	return &LdapConnectionMock{
+		bindDN:   "cn=goliac,dc=company,dc=com",
+		password: "secret",
+		users: []*ldap.Entry{
+			ldap.NewEntry("uid=user1,ou=people,dc=company,dc=com", map[string][]string{"uid": {"user1"}, "githubUsername": {"user1-gh"}, "mail": {"user1@company.com"}, "displayName": {"User One"}, "manager": {"uid=user2,ou=people,dc=company,dc=com"}}),
+			ldap.NewEntry("uid=user2,ou=people,dc=company,dc=com", map[string][]string{"uid": {"user2"}, "githubUsername": {"user2-gh"}}),
+			ldap.NewEntry("uid=user3,ou=people,dc=company,dc=com", map[string][]string{"uid": {"user3"}}),
+		},
+		groups: map[string]*ldap.Entry{
+			"cn=team1,ou=groups,dc=company,dc=com": ldap.NewEntry("cn=team1,ou=groups,dc=company,dc=com", map[string][]string{
+				"member": {"UID=user1,ou=people,dc=company,dc=com", "uid=user2,ou=people,dc=company,dc=com", "uid=unknown,ou=people,dc=company,dc=com"},
+			}),
+			"cn=team2,ou=groups,dc=company,dc=com": ldap.NewEntry("cn=team2,ou=groups,dc=company,dc=com", map[string][]string{
+				"memberUid": {"user2"},
+			}),
+		},
+	}
+}
+
+func fixtureLdapRepoConfig() *config.RepositoryConfig {
+	repoconfig := &config.RepositoryConfig{}
+	repoconfig.UserSync.Ldap.Url = "ldaps://ldap.company.com"
+	repoconfig.UserSync.Ldap.BaseDN = "ou=people,dc=company,dc=com"
+	repoconfig.UserSync.Ldap.GithubIdAttribute = "githubUsername"
+	return repoconfig
+}
+
+func TestUserSyncPluginLdap(t *testing.T) {
+	config.Config.UserSyncLdapBindDN = "cn=goliac,dc=company,dc=com"
+	config.Config.UserSyncLdapBindPassword = "secret"
+	defer func() {
+		config.Config.UserSyncLdapBindDN = ""
+		config.Config.UserSyncLdapBindPassword = ""
+	}()
+
+	t.Run("happy path: users with a Github ID", func(t *testing.T) {
+		conn := fixtureLdapConnection()
+		plugin := &UserSyncPluginLdap{dial: func(config.UserSyncLdap) (ldapConnection, error) { return conn, nil }}
+		repoconfig := fixtureLdapRepoConfig()
+		repoconfig.UserSync.Ldap.UserFilter = "(memberOf=cn=github,ou=groups,dc=company,dc=com)"
+
+		logsCollector := observability.NewLogCollection()
+		users := plugin.UpdateUsers(repoconfig, memfs.New(), "users/org", nil, logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 1, len(logsCollector.Warns)) // user3 has no githubUsername
+		assert.Equal(t, 2, len(users))
+		assert.Equal(t, "user1-gh", users["user1"].Spec.GithubID)
+		assert.Equal(t, "user2-gh", users["user2"].Spec.GithubID)
+		assert.Equal(t, "user1@company.com", users["user1"].Spec.Email)
+		assert.Equal(t, "User One", users["user1"].Spec.FullName)
+		assert.Equal(t, "user2", users["user1"].Spec.Manager)
+		assert.Equal(t, "", users["user2"].Spec.Manager)
+		assert.Equal(t, []string{"(memberOf=cn=github,ou=groups,dc=company,dc=com)"}, conn.filters)
+		assert.True(t, conn.closed)
+	})
+
+	t.Run("happy path: groups members", func(t *testing.T) {
+		conn := fixtureLdapConnection()
+		plugin := &UserSyncPluginLdap{dial: func(config.UserSyncLdap) (ldapConnection, error) { return conn, nil }}
+		repoconfig := fixtureLdapRepoConfig()
+
+		logsCollector := observability.NewLogCollection()
+		members, err := plugin.GroupsMembers(repoconfig, []string{"cn=team1,ou=groups,dc=company,dc=com", "cn=missing,ou=groups,dc=company,dc=com"}, logsCollector)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(logsCollector.Warns))
+		assert.Equal(t, []string{"user1", "user2"}, members["cn=team1,ou=groups,dc=company,dc=com"])
+		_, found := members["cn=missing,ou=groups,dc=company,dc=com"]
+		assert.False(t, found)
+		assert.Equal(t, []string{"(objectClass=person)"}, conn.filters)
+
+		repoconfig.UserSync.Ldap.GroupMemberAttribute = "memberUid"
+		members, err = plugin.GroupsMembers(repoconfig, []string{"cn=team2,ou=groups,dc=company,dc=com"}, logsCollector)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"user2"}, members["cn=team2,ou=groups,dc=company,dc=com"])
+	})
+
+	t.Run("not happy path: invalid credentials", func(t *testing.T) {
+		conn := fixtureLdapConnection()
+		conn.password = "other"
+		plugin := &UserSyncPluginLdap{dial: func(config.UserSyncLdap) (ldapConnection, error) { return conn, nil }}
+
+		logsCollector := observability.NewLogCollection()
+		users := plugin.UpdateUsers(fixtureLdapRepoConfig(), memfs.New(), "users/org", nil, logsCollector)
+		assert.Nil(t, users)
+		assert.True(t, logsCollector.HasErrors())
+		assert.True(t, conn.closed)
+	})
+
+	t.Run("not happy path: missing configuration", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		users := NewUserSyncPluginLdap().UpdateUsers(&config.RepositoryConfig{}, memfs.New(), "users/org", nil, logsCollector)
+		assert.Nil(t, users)
+		assert.True(t, logsCollector.HasErrors())
+	})
+
+	t.Run("not happy path: plain ldap without StartTLS", func(t *testing.T) {
+		ldapConfig := fixtureLdapRepoConfig().UserSync.Ldap
+		ldapConfig.Url = "ldap://ldap.company.com"
+		_, err := dialLdap(ldapConfig)
+		assert.NotNil(t, err)
+	})
+}
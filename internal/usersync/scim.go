@@ -0,0 +1,220 @@
+package usersync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/engine"
+	"github.com/goliac-project/goliac/internal/entity"
+	"github.com/goliac-project/goliac/internal/observability"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const SCIM_PAGE_SIZE = 100
+
+/*
+ * UserSyncPluginScim: this plugin sync users from a SCIM 2.0 /Users endpoint
+ * (exposed by most of the IdPs).
+ * - the user name is the SCIM userName
+ * - the Github ID is taken from the usersync.scim.githubid_attribute attribute (userName by default)
+ * - inactive users are skipped
+ * - the email (primary one) and full name are taken when available
+ */
+type UserSyncPluginScim struct {
+	httpClient *http.Client
+}
+
+func NewUserSyncPluginScim() engine.UserSyncPlugin {
+	return &UserSyncPluginScim{
+		httpClient: http.DefaultClient,
+	}
+}
+
+type scimListResponse struct {
+	TotalResults int                      `json:"totalResults"`
+	ItemsPerPage int                      `json:"itemsPerPage"`
+	StartIndex   int                      `json:"startIndex"`
+	Resources    []map[string]interface{} `json:"Resources"`
+}
+
+/*
+Return a map of [username]*entity.User
+*/
+func (p *UserSyncPluginScim) UpdateUsers(repoconfig *config.RepositoryConfig, fs billy.Filesystem, orguserdirrectorypath string, feedback observability.RemoteObservability, logsCollector *observability.LogCollection) map[string]*entity.User {
+	ctx := context.Background()
+	scimConfig := repoconfig.UserSync.Scim
+
+	if scimConfig.Url == "" {
+		logsCollector.AddError(fmt.Errorf("usersync.scim.url is not defined in goliac.yaml"))
+		return nil
+	}
+	githubIdAttribute := scimConfig.GithubIdAttribute
+	if githubIdAttribute == "" {
+		githubIdAttribute = "userName"
+	}
+
+	client := p.httpClient
+	if scimConfig.TokenUrl != "" {
+		cc := clientcredentials.Config{
+			ClientID:     config.Config.UserSyncScimClientID,
+			ClientSecret: config.Config.UserSyncScimClientSecret,
+			TokenURL:     scimConfig.TokenUrl,
+			Scopes:       scimConfig.Scopes,
+		}
+		client = cc.Client(context.WithValue(ctx, oauth2.HTTPClient, p.httpClient))
+	}
+
+	users := make(map[string]*entity.User)
+	startIndex := 1
+	for {
+		page, err := p.listUsers(ctx, client, scimConfig, startIndex)
+		if err != nil {
+			logsCollector.AddError(fmt.Errorf("not able to load users from SCIM: %w", err))
+			return nil
+		}
+		if feedback != nil && startIndex == 1 {
+			feedback.Init(page.TotalResults)
+		}
+
+		for _, resource := range page.Resources {
+			if feedback != nil {
+				feedback.LoadingAsset("users", 1)
+			}
+			if active, ok := resource["active"].(bool); ok && !active {
+				continue
+			}
+			username, _ := resource["userName"].(string)
+			if username == "" {
+				logsCollector.AddWarn(fmt.Errorf("SCIM user %v without userName, skipping", resource["id"]))
+				continue
+			}
+			githubId, _ := scimAttribute(resource, githubIdAttribute).(string)
+			if githubId == "" {
+				logsCollector.AddWarn(fmt.Errorf("SCIM user %s without %s attribute, skipping", username, githubIdAttribute))
+				continue
+			}
+
+			user := &entity.User{}
+			user.ApiVersion = "v1"
+			user.Kind = "User"
+			user.Name = username
+			user.Spec.GithubID = githubId
+			user.Spec.Email = scimPrimaryEmail(resource)
+			if fullName, ok := scimAttribute(resource, "name.formatted").(string); ok {
+				user.Spec.FullName = fullName
+			} else if displayName, ok := resource["displayName"].(string); ok {
+				user.Spec.FullName = displayName
+			}
+			users[username] = user
+		}
+
+		startIndex += len(page.Resources)
+		if len(page.Resources) == 0 || startIndex > page.TotalResults {
+			break
+		}
+	}
+
+	if len(users) == 0 {
+		return nil
+	}
+	return users
+}
+
+func (p *UserSyncPluginScim) listUsers(ctx context.Context, client *http.Client, scimConfig config.UserSyncScim, startIndex int) (*scimListResponse, error) {
+	params := url.Values{}
+	params.Set("startIndex", fmt.Sprintf("%d", startIndex))
+	params.Set("count", fmt.Sprintf("%d", SCIM_PAGE_SIZE))
+	if scimConfig.Filter != "" {
+		params.Set("filter", scimConfig.Filter)
+	}
+	endpoint := strings.TrimSuffix(scimConfig.Url, "/") + "/Users?" + params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/scim+json")
+	if scimConfig.TokenUrl == "" && config.Config.UserSyncScimToken != "" {
+		req.Header.Set("Authorization", "Bearer "+config.Config.UserSyncScimToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+
+	var page scimListResponse
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("not able to unmarshall the SCIM response: %v", err)
+	}
+	return &page, nil
+}
+
+/*
+ * scimAttribute returns the value of an attribute of a SCIM resource, the attribute can be
+ * - a (dotted) path: userName, name.givenName
+ * - an extension attribute (urn:<schema>:<attribute>): urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber
+ */
+func scimAttribute(resource map[string]interface{}, attribute string) interface{} {
+	var current interface{} = resource
+	if strings.HasPrefix(attribute, "urn:") {
+		i := strings.LastIndex(attribute, ":")
+		extension, ok := resource[attribute[:i]].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = extension
+		attribute = attribute[i+1:]
+	}
+
+	for _, part := range strings.Split(attribute, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+	return current
+}
+
+/*
+ * scimPrimaryEmail returns the primary email of a SCIM resource (or the first one)
+ */
+func scimPrimaryEmail(resource map[string]interface{}) string {
+	emails, ok := resource["emails"].([]interface{})
+	if !ok {
+		return ""
+	}
+	email := ""
+	for _, e := range emails {
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		value, _ := m["value"].(string)
+		if primary, _ := m["primary"].(bool); primary {
+			return value
+		}
+		if email == "" {
+			email = value
+		}
+	}
+	return email
+}
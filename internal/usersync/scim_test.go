@@ -0,0 +1,157 @@
+package usersync
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/goliac-project/goliac/internal/config"
+	"github.com/goliac-project/goliac/internal/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+const scimEnterpriseExtension = "urn:ietf:params:scim:schemas:extension:goliac:2.0:User"
+
+/*
+ * newScimStubServer returns a SCIM 2.0 server listing nbUsers users (2 per page),
+ * every 3rd user being inactive. It expects the "Bearer <token>" authorization
+ */
+func newScimStubServer(nbUsers int, token string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		clientId, clientSecret, _ := r.BasicAuth()
+		if r.Form.Get("grant_type") != "client_credentials" || clientId != "clientid" || clientSecret != "clientsecret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token": "%s", "token_type": "Bearer", "expires_in": 3600}`, token)
+	})
+	mux.HandleFunc("/scim/v2/Users", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		startIndex, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+		resources := []map[string]interface{}{}
+		for i := startIndex; i < startIndex+2 && i <= nbUsers; i++ {
+			resources = append(resources, map[string]interface{}{
+				"id":       fmt.Sprintf("%d", i),
+				"userName": fmt.Sprintf("user%d@company.com", i),
+				"active":   i%3 != 0,
+				"name":     map[string]interface{}{"formatted": fmt.Sprintf("User %d", i)},
+				"emails": []map[string]interface{}{
+					{"value": fmt.Sprintf("user%d@home.com", i), "type": "home"},
+					{"value": fmt.Sprintf("user%d@company.com", i), "type": "work", "primary": true},
+				},
+				scimEnterpriseExtension: map[string]interface{}{
+					"githubUsername": fmt.Sprintf("user%d-gh", i),
+				},
+			})
+		}
+		w.Header().Set("Content-Type", "application/scim+json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"schemas":      []string{"urn:ietf:params:scim:api:messages:2.0:ListResponse"},
+			"totalResults": nbUsers,
+			"itemsPerPage": len(resources),
+			"startIndex":   startIndex,
+			"Resources":    resources,
+		})
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestUserSyncPluginScim(t *testing.T) {
+	t.Run("happy path: bearer token, pagination and inactive users", func(t *testing.T) {
+		server := newScimStubServer(5, "token")
+		defer server.Close()
+
+		config.Config.UserSyncScimToken = "token"
+		defer func() { config.Config.UserSyncScimToken = "" }()
+
+		repoconfig := &config.RepositoryConfig{}
+		repoconfig.UserSync.Scim.Url = server.URL + "/scim/v2"
+
+		logsCollector := observability.NewLogCollection()
+		users := NewUserSyncPluginScim().UpdateUsers(repoconfig, memfs.New(), "users/org", nil, logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 4, len(users)) // user3 is inactive
+		assert.Equal(t, "user1@company.com", users["user1@company.com"].Spec.GithubID)
+		assert.Nil(t, users["user3@company.com"])
+		assert.Equal(t, "user5@company.com", users["user5@company.com"].Name)
+		assert.Equal(t, "user5@company.com", users["user5@company.com"].Spec.Email)
+		assert.Equal(t, "User 5", users["user5@company.com"].Spec.FullName)
+	})
+
+	t.Run("happy path: oauth client credentials and extension attribute", func(t *testing.T) {
+		server := newScimStubServer(3, "oauthtoken")
+		defer server.Close()
+
+		config.Config.UserSyncScimClientID = "clientid"
+		config.Config.UserSyncScimClientSecret = "clientsecret"
+		defer func() {
+			config.Config.UserSyncScimClientID = ""
+			config.Config.UserSyncScimClientSecret = ""
+		}()
+
+		repoconfig := &config.RepositoryConfig{}
+		repoconfig.UserSync.Scim.Url = server.URL + "/scim/v2/"
+		repoconfig.UserSync.Scim.TokenUrl = server.URL + "/oauth/token"
+		repoconfig.UserSync.Scim.GithubIdAttribute = scimEnterpriseExtension + ":githubUsername"
+
+		logsCollector := observability.NewLogCollection()
+		users := NewUserSyncPluginScim().UpdateUsers(repoconfig, memfs.New(), "users/org", nil, logsCollector)
+
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 2, len(users))
+		assert.Equal(t, "user1-gh", users["user1@company.com"].Spec.GithubID)
+		assert.Equal(t, "user2-gh", users["user2@company.com"].Spec.GithubID)
+	})
+
+	t.Run("not happy path: missing attribute", func(t *testing.T) {
+		server := newScimStubServer(2, "token")
+		defer server.Close()
+
+		config.Config.UserSyncScimToken = "token"
+		defer func() { config.Config.UserSyncScimToken = "" }()
+
+		repoconfig := &config.RepositoryConfig{}
+		repoconfig.UserSync.Scim.Url = server.URL + "/scim/v2"
+		repoconfig.UserSync.Scim.GithubIdAttribute = "name.githubUsername"
+
+		logsCollector := observability.NewLogCollection()
+		users := NewUserSyncPluginScim().UpdateUsers(repoconfig, memfs.New(), "users/org", nil, logsCollector)
+
+		assert.Nil(t, users)
+		assert.False(t, logsCollector.HasErrors())
+		assert.Equal(t, 2, len(logsCollector.Warns))
+	})
+
+	t.Run("not happy path: unauthorized", func(t *testing.T) {
+		server := newScimStubServer(2, "token")
+		defer server.Close()
+
+		repoconfig := &config.RepositoryConfig{}
+		repoconfig.UserSync.Scim.Url = server.URL + "/scim/v2"
+
+		logsCollector := observability.NewLogCollection()
+		users := NewUserSyncPluginScim().UpdateUsers(repoconfig, memfs.New(), "users/org", nil, logsCollector)
+
+		assert.Nil(t, users)
+		assert.True(t, logsCollector.HasErrors())
+	})
+
+	t.Run("not happy path: no url", func(t *testing.T) {
+		logsCollector := observability.NewLogCollection()
+		users := NewUserSyncPluginScim().UpdateUsers(&config.RepositoryConfig{}, memfs.New(), "users/org", nil, logsCollector)
+
+		assert.Nil(t, users)
+		assert.True(t, logsCollector.HasErrors())
+	})
+}
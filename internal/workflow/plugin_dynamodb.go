@@ -69,15 +69,17 @@ func (f *StepPluginDynamoDB) Execute(ctx context.Context, username, workflowDesc
 		return "", fmt.Errorf("the PR url is not defined (nil)")
 	}
 
-	// Extract PR number and repository from URL
+	// Extract PR number, organization and repository from URL
 	prNumber := ""
 	repo := ""
+	organization := config.Config.GithubAppOrganization
 	if url != nil {
-		prExtract := regexp.MustCompile(`.*/([^/]*)/pull/(\d+)`)
+		prExtract := regexp.MustCompile(`.*/([^/]*)/([^/]*)/pull/(\d+)`)
 		prMatch := prExtract.FindStringSubmatch(url.Path)
-		if len(prMatch) == 3 {
-			repo = prMatch[1]
-			prNumber = prMatch[2]
+		if len(prMatch) == 4 {
+			organization = prMatch[1]
+			repo = prMatch[2]
+			prNumber = prMatch[3]
 		}
 	}
 
@@ -86,7 +88,7 @@ func (f *StepPluginDynamoDB) Execute(ctx context.Context, username, workflowDesc
 		Timestamp:    time.Now().UTC().Unix(),
 		PullRequest:  url.String(),
 		GithubCaller: username,
-		Organization: config.Config.GithubAppOrganization,
+		Organization: organization,
 		Repository:   repo,
 		PRNumber:     prNumber,
 		Explanation:  explanation,
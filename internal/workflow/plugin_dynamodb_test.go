@@ -52,6 +52,29 @@ func TestDynamoDBPluginWorkflow(t *testing.T) {
 		mockClient.AssertExpectations(t)
 	})
 
+	t.Run("happy path: the organization comes from the PR url", func(t *testing.T) {
+		mockClient := new(MockDynamoDBClient)
+
+		mockClient.On("PutItem", mock.Anything, mock.MatchedBy(func(input *dynamodb.PutItemInput) bool {
+			organization, ok := input.Item["organization"].(*types.AttributeValueMemberS)
+			repository, ok2 := input.Item["repository"].(*types.AttributeValueMemberS)
+			return ok && ok2 && organization.Value == "othercompany" && repository.Value == "myrepo"
+		})).Return(&dynamodb.PutItemOutput{}, nil)
+
+		plugin := &StepPluginDynamoDB{
+			TableName: "test-table",
+			client:    mockClient,
+		}
+
+		prurl, err := url.Parse("https://github.com/othercompany/myrepo/pull/123")
+		assert.Nil(t, err)
+
+		_, err = plugin.Execute(context.Background(), "test-user", "workflowdescription", "test explanation", prurl, map[string]interface{}{})
+
+		assert.Nil(t, err)
+		mockClient.AssertExpectations(t)
+	})
+
 	t.Run("error path: dynamodb put item fails", func(t *testing.T) {
 		// Create mock client
 		mockClient := new(MockDynamoDBClient)
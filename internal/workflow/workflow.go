@@ -56,6 +56,8 @@ type Workflow interface {
 type WorkflowService interface {
 	GetWorkflow(ctx context.Context, repoconfigForceMergeworkflows []string, workflowName, repo, githubId string) (*entity.Workflow, error)
 	CallRestAPI(ctx context.Context, endpoint, parameters, method string, body map[string]interface{}, githubToken *string) ([]byte, error)
+	// the Github organization of the workflows
+	GetOrganization() string
 }
 
 type WorkflowServiceImpl struct {
@@ -74,6 +76,10 @@ func NewWorkflowService(organization string, local WorkflowLocalResource, remote
 	}
 }
 
+func (ws *WorkflowServiceImpl) GetOrganization() string {
+	return ws.organization
+}
+
 func (ws *WorkflowServiceImpl) CallRestAPI(ctx context.Context, endpoint, parameters, method string, body map[string]interface{}, githubToken *string) ([]byte, error) {
 	return ws.ghclient.CallRestAPI(ctx, endpoint, parameters, method, body, githubToken)
 }
@@ -93,7 +93,7 @@ func (g *ForcemergeImpl) ExecuteWorkflow(ctx context.Context, repoconfigForceMer
 func (g *ForcemergeImpl) fetchPullRequestTitle(ctx context.Context, repo, prNumber string) (string, error) {
 	body, err := g.ws.CallRestAPI(
 		ctx,
-		fmt.Sprintf("/repos/%s/%s/pulls/%s", config.Config.GithubAppOrganization, repo, prNumber),
+		fmt.Sprintf("/repos/%s/%s/pulls/%s", g.ws.GetOrganization(), repo, prNumber),
 		"",
 		"GET",
 		nil,
@@ -136,7 +136,7 @@ func (g *ForcemergeImpl) mergePR(ctx context.Context, username string, repo stri
 
 	body, err := g.ws.CallRestAPI(
 		ctx,
-		fmt.Sprintf("/repos/%s/%s/pulls/%s/reviews", config.Config.GithubAppOrganization, repo, prNumber),
+		fmt.Sprintf("/repos/%s/%s/pulls/%s/reviews", g.ws.GetOrganization(), repo, prNumber),
 		"",
 		"POST",
 		map[string]interface{}{
@@ -151,7 +151,7 @@ func (g *ForcemergeImpl) mergePR(ctx context.Context, username string, repo stri
 	// https://docs.github.com/en/rest/pulls/pulls?apiVersion=2022-11-28#merge-a-pull-request
 	body, err = g.ws.CallRestAPI(
 		ctx,
-		fmt.Sprintf("/repos/%s/%s/pulls/%s/merge", config.Config.GithubAppOrganization, repo, prNumber),
+		fmt.Sprintf("/repos/%s/%s/pulls/%s/merge", g.ws.GetOrganization(), repo, prNumber),
 		"",
 		"PUT",
 		map[string]interface{}{
@@ -165,7 +165,7 @@ func (g *ForcemergeImpl) mergePR(ctx context.Context, username string, repo stri
 			// in case of we want a squash merge
 			body, err = g.ws.CallRestAPI(
 				ctx,
-				fmt.Sprintf("/repos/%s/%s/pulls/%s/merge", config.Config.GithubAppOrganization, repo, prNumber),
+				fmt.Sprintf("/repos/%s/%s/pulls/%s/merge", g.ws.GetOrganization(), repo, prNumber),
 				"",
 				"PUT",
 				map[string]interface{}{
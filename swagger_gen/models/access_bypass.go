@@ -0,0 +1,62 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package models
+
+import (
+	"context"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+)
+
+// AccessBypass access bypass
+//
+// swagger:model accessBypass
+type AccessBypass struct {
+
+	// description
+	Description string `json:"description,omitempty"`
+
+	// kind
+	Kind string `json:"kind,omitempty"`
+
+	// mode
+	Mode string `json:"mode,omitempty"`
+
+	// name
+	Name string `json:"name,omitempty"`
+
+	// team
+	Team string `json:"team,omitempty"`
+
+	// via
+	Via string `json:"via,omitempty"`
+}
+
+// Validate validates this access bypass
+func (m *AccessBypass) Validate(formats strfmt.Registry) error {
+	return nil
+}
+
+// ContextValidate validates this access bypass based on context it is used
+func (m *AccessBypass) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *AccessBypass) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *AccessBypass) UnmarshalBinary(b []byte) error {
+	var res AccessBypass
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
@@ -0,0 +1,324 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package models
+
+import (
+	"context"
+	stderrors "errors"
+	"strconv"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+)
+
+// AccessExplanation access explanation
+//
+// swagger:model accessExplanation
+type AccessExplanation struct {
+
+	// githubid
+	Githubid string `json:"githubid,omitempty"`
+
+	// local
+	Local *AccessPaths `json:"local,omitempty"`
+
+	// remote
+	Remote *AccessPaths `json:"remote,omitempty"`
+
+	// repository
+	Repository string `json:"repository,omitempty"`
+
+	// unmanaged
+	Unmanaged []*AccessReason `json:"unmanaged"`
+
+	// unmanaged bypasses
+	UnmanagedBypasses []*AccessBypass `json:"unmanagedBypasses"`
+
+	// username
+	Username string `json:"username,omitempty"`
+}
+
+// Validate validates this access explanation
+func (m *AccessExplanation) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateLocal(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateRemote(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateUnmanaged(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateUnmanagedBypasses(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *AccessExplanation) validateLocal(formats strfmt.Registry) error {
+	if swag.IsZero(m.Local) { // not required
+		return nil
+	}
+
+	if m.Local != nil {
+		if err := m.Local.Validate(formats); err != nil {
+			ve := new(errors.Validation)
+			if stderrors.As(err, &ve) {
+				return ve.ValidateName("local")
+			}
+			ce := new(errors.CompositeError)
+			if stderrors.As(err, &ce) {
+				return ce.ValidateName("local")
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *AccessExplanation) validateRemote(formats strfmt.Registry) error {
+	if swag.IsZero(m.Remote) { // not required
+		return nil
+	}
+
+	if m.Remote != nil {
+		if err := m.Remote.Validate(formats); err != nil {
+			ve := new(errors.Validation)
+			if stderrors.As(err, &ve) {
+				return ve.ValidateName("remote")
+			}
+			ce := new(errors.CompositeError)
+			if stderrors.As(err, &ce) {
+				return ce.ValidateName("remote")
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *AccessExplanation) validateUnmanaged(formats strfmt.Registry) error {
+	if swag.IsZero(m.Unmanaged) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Unmanaged); i++ {
+		if swag.IsZero(m.Unmanaged[i]) { // not required
+			continue
+		}
+
+		if m.Unmanaged[i] != nil {
+			if err := m.Unmanaged[i].Validate(formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("unmanaged" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("unmanaged" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *AccessExplanation) validateUnmanagedBypasses(formats strfmt.Registry) error {
+	if swag.IsZero(m.UnmanagedBypasses) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.UnmanagedBypasses); i++ {
+		if swag.IsZero(m.UnmanagedBypasses[i]) { // not required
+			continue
+		}
+
+		if m.UnmanagedBypasses[i] != nil {
+			if err := m.UnmanagedBypasses[i].Validate(formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("unmanagedBypasses" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("unmanagedBypasses" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+// ContextValidate validate this access explanation based on the context it is used
+func (m *AccessExplanation) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.contextValidateLocal(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateRemote(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateUnmanaged(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateUnmanagedBypasses(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *AccessExplanation) contextValidateLocal(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Local != nil {
+
+		if swag.IsZero(m.Local) { // not required
+			return nil
+		}
+
+		if err := m.Local.ContextValidate(ctx, formats); err != nil {
+			ve := new(errors.Validation)
+			if stderrors.As(err, &ve) {
+				return ve.ValidateName("local")
+			}
+			ce := new(errors.CompositeError)
+			if stderrors.As(err, &ce) {
+				return ce.ValidateName("local")
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *AccessExplanation) contextValidateRemote(ctx context.Context, formats strfmt.Registry) error {
+
+	if m.Remote != nil {
+
+		if swag.IsZero(m.Remote) { // not required
+			return nil
+		}
+
+		if err := m.Remote.ContextValidate(ctx, formats); err != nil {
+			ve := new(errors.Validation)
+			if stderrors.As(err, &ve) {
+				return ve.ValidateName("remote")
+			}
+			ce := new(errors.CompositeError)
+			if stderrors.As(err, &ce) {
+				return ce.ValidateName("remote")
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *AccessExplanation) contextValidateUnmanaged(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Unmanaged); i++ {
+
+		if m.Unmanaged[i] != nil {
+
+			if swag.IsZero(m.Unmanaged[i]) { // not required
+				return nil
+			}
+
+			if err := m.Unmanaged[i].ContextValidate(ctx, formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("unmanaged" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("unmanaged" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *AccessExplanation) contextValidateUnmanagedBypasses(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.UnmanagedBypasses); i++ {
+
+		if m.UnmanagedBypasses[i] != nil {
+
+			if swag.IsZero(m.UnmanagedBypasses[i]) { // not required
+				return nil
+			}
+
+			if err := m.UnmanagedBypasses[i].ContextValidate(ctx, formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("unmanagedBypasses" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("unmanagedBypasses" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *AccessExplanation) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *AccessExplanation) UnmarshalBinary(b []byte) error {
+	var res AccessExplanation
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
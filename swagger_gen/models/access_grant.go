@@ -0,0 +1,139 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package models
+
+import (
+	"context"
+	stderrors "errors"
+	"strconv"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+)
+
+// AccessGrant access grant
+//
+// swagger:model accessGrant
+type AccessGrant struct {
+
+	// githubid
+	Githubid string `json:"githubid,omitempty"`
+
+	// permission
+	Permission string `json:"permission,omitempty"`
+
+	// reasons
+	Reasons []*AccessReason `json:"reasons"`
+
+	// repository
+	Repository string `json:"repository,omitempty"`
+
+	// username
+	Username string `json:"username,omitempty"`
+}
+
+// Validate validates this access grant
+func (m *AccessGrant) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateReasons(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *AccessGrant) validateReasons(formats strfmt.Registry) error {
+	if swag.IsZero(m.Reasons) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Reasons); i++ {
+		if swag.IsZero(m.Reasons[i]) { // not required
+			continue
+		}
+
+		if m.Reasons[i] != nil {
+			if err := m.Reasons[i].Validate(formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("reasons" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("reasons" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+// ContextValidate validate this access grant based on the context it is used
+func (m *AccessGrant) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.contextValidateReasons(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *AccessGrant) contextValidateReasons(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Reasons); i++ {
+
+		if m.Reasons[i] != nil {
+
+			if swag.IsZero(m.Reasons[i]) { // not required
+				return nil
+			}
+
+			if err := m.Reasons[i].ContextValidate(ctx, formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("reasons" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("reasons" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *AccessGrant) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *AccessGrant) UnmarshalBinary(b []byte) error {
+	var res AccessGrant
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
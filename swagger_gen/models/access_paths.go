@@ -0,0 +1,200 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package models
+
+import (
+	"context"
+	stderrors "errors"
+	"strconv"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+)
+
+// AccessPaths access paths
+//
+// swagger:model accessPaths
+type AccessPaths struct {
+
+	// bypasses
+	Bypasses []*AccessBypass `json:"bypasses"`
+
+	// permission
+	Permission string `json:"permission,omitempty"`
+
+	// reasons
+	Reasons []*AccessReason `json:"reasons"`
+}
+
+// Validate validates this access paths
+func (m *AccessPaths) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateBypasses(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateReasons(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *AccessPaths) validateBypasses(formats strfmt.Registry) error {
+	if swag.IsZero(m.Bypasses) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Bypasses); i++ {
+		if swag.IsZero(m.Bypasses[i]) { // not required
+			continue
+		}
+
+		if m.Bypasses[i] != nil {
+			if err := m.Bypasses[i].Validate(formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("bypasses" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("bypasses" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *AccessPaths) validateReasons(formats strfmt.Registry) error {
+	if swag.IsZero(m.Reasons) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Reasons); i++ {
+		if swag.IsZero(m.Reasons[i]) { // not required
+			continue
+		}
+
+		if m.Reasons[i] != nil {
+			if err := m.Reasons[i].Validate(formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("reasons" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("reasons" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+// ContextValidate validate this access paths based on the context it is used
+func (m *AccessPaths) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.contextValidateBypasses(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateReasons(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *AccessPaths) contextValidateBypasses(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Bypasses); i++ {
+
+		if m.Bypasses[i] != nil {
+
+			if swag.IsZero(m.Bypasses[i]) { // not required
+				return nil
+			}
+
+			if err := m.Bypasses[i].ContextValidate(ctx, formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("bypasses" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("bypasses" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *AccessPaths) contextValidateReasons(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Reasons); i++ {
+
+		if m.Reasons[i] != nil {
+
+			if swag.IsZero(m.Reasons[i]) { // not required
+				return nil
+			}
+
+			if err := m.Reasons[i].ContextValidate(ctx, formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("reasons" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("reasons" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *AccessPaths) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *AccessPaths) UnmarshalBinary(b []byte) error {
+	var res AccessPaths
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
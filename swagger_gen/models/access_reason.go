@@ -0,0 +1,59 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package models
+
+import (
+	"context"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+)
+
+// AccessReason access reason
+//
+// swagger:model accessReason
+type AccessReason struct {
+
+	// description
+	Description string `json:"description,omitempty"`
+
+	// kind
+	Kind string `json:"kind,omitempty"`
+
+	// permission
+	Permission string `json:"permission,omitempty"`
+
+	// team
+	Team string `json:"team,omitempty"`
+
+	// via
+	Via string `json:"via,omitempty"`
+}
+
+// Validate validates this access reason
+func (m *AccessReason) Validate(formats strfmt.Registry) error {
+	return nil
+}
+
+// ContextValidate validates this access reason based on context it is used
+func (m *AccessReason) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *AccessReason) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *AccessReason) UnmarshalBinary(b []byte) error {
+	var res AccessReason
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
@@ -0,0 +1,273 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package models
+
+import (
+	"context"
+	stderrors "errors"
+	"strconv"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/swag"
+)
+
+// OrganizationAccess organization access
+//
+// swagger:model organizationAccess
+type OrganizationAccess struct {
+
+	// external repositories
+	ExternalRepositories []*Repository `json:"externalRepositories"`
+
+	// organization
+	Organization string `json:"organization,omitempty"`
+
+	// repositories
+	Repositories []*Repository `json:"repositories"`
+
+	// teams
+	Teams []*Team `json:"teams"`
+
+	// username
+	Username string `json:"username,omitempty"`
+}
+
+// Validate validates this organization access
+func (m *OrganizationAccess) Validate(formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.validateExternalRepositories(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateRepositories(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.validateTeams(formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *OrganizationAccess) validateExternalRepositories(formats strfmt.Registry) error {
+	if swag.IsZero(m.ExternalRepositories) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.ExternalRepositories); i++ {
+		if swag.IsZero(m.ExternalRepositories[i]) { // not required
+			continue
+		}
+
+		if m.ExternalRepositories[i] != nil {
+			if err := m.ExternalRepositories[i].Validate(formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("externalRepositories" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("externalRepositories" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *OrganizationAccess) validateRepositories(formats strfmt.Registry) error {
+	if swag.IsZero(m.Repositories) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Repositories); i++ {
+		if swag.IsZero(m.Repositories[i]) { // not required
+			continue
+		}
+
+		if m.Repositories[i] != nil {
+			if err := m.Repositories[i].Validate(formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("repositories" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("repositories" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *OrganizationAccess) validateTeams(formats strfmt.Registry) error {
+	if swag.IsZero(m.Teams) { // not required
+		return nil
+	}
+
+	for i := 0; i < len(m.Teams); i++ {
+		if swag.IsZero(m.Teams[i]) { // not required
+			continue
+		}
+
+		if m.Teams[i] != nil {
+			if err := m.Teams[i].Validate(formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("teams" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("teams" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+// ContextValidate validate this organization access based on the context it is used
+func (m *OrganizationAccess) ContextValidate(ctx context.Context, formats strfmt.Registry) error {
+	var res []error
+
+	if err := m.contextValidateExternalRepositories(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateRepositories(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if err := m.contextValidateTeams(ctx, formats); err != nil {
+		res = append(res, err)
+	}
+
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+func (m *OrganizationAccess) contextValidateExternalRepositories(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.ExternalRepositories); i++ {
+
+		if m.ExternalRepositories[i] != nil {
+
+			if swag.IsZero(m.ExternalRepositories[i]) { // not required
+				return nil
+			}
+
+			if err := m.ExternalRepositories[i].ContextValidate(ctx, formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("externalRepositories" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("externalRepositories" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *OrganizationAccess) contextValidateRepositories(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Repositories); i++ {
+
+		if m.Repositories[i] != nil {
+
+			if swag.IsZero(m.Repositories[i]) { // not required
+				return nil
+			}
+
+			if err := m.Repositories[i].ContextValidate(ctx, formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("repositories" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("repositories" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+func (m *OrganizationAccess) contextValidateTeams(ctx context.Context, formats strfmt.Registry) error {
+
+	for i := 0; i < len(m.Teams); i++ {
+
+		if m.Teams[i] != nil {
+
+			if swag.IsZero(m.Teams[i]) { // not required
+				return nil
+			}
+
+			if err := m.Teams[i].ContextValidate(ctx, formats); err != nil {
+				ve := new(errors.Validation)
+				if stderrors.As(err, &ve) {
+					return ve.ValidateName("teams" + "." + strconv.Itoa(i))
+				}
+				ce := new(errors.CompositeError)
+				if stderrors.As(err, &ce) {
+					return ce.ValidateName("teams" + "." + strconv.Itoa(i))
+				}
+
+				return err
+			}
+		}
+
+	}
+
+	return nil
+}
+
+// MarshalBinary interface implementation
+func (m *OrganizationAccess) MarshalBinary() ([]byte, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return swag.WriteJSON(m)
+}
+
+// UnmarshalBinary interface implementation
+func (m *OrganizationAccess) UnmarshalBinary(b []byte) error {
+	var res OrganizationAccess
+	if err := swag.ReadJSON(b, &res); err != nil {
+		return err
+	}
+	*m = res
+	return nil
+}
@@ -22,6 +22,9 @@ type Status struct {
 	// detailed warnings
 	DetailedWarnings []string `json:"detailedWarnings"`
 
+	// github server version
+	GithubServerVersion string `json:"githubServerVersion,omitempty"`
+
 	// last sync error
 	LastSyncError string `json:"lastSyncError,omitempty"`
 
@@ -47,6 +50,12 @@ type Status struct {
 	// organization
 	Organization string `json:"organization,omitempty"`
 
+	// organizations
+	Organizations []string `json:"organizations"`
+
+	// unsupported features
+	UnsupportedFeatures []string `json:"unsupportedFeatures"`
+
 	// version
 	Version string `json:"version,omitempty"`
 }
@@ -17,9 +17,21 @@ import (
 // swagger:model userDetails
 type UserDetails struct {
 
+	// email
+	Email string `json:"email,omitempty"`
+
+	// full name
+	FullName string `json:"fullName,omitempty"`
+
 	// githubid
 	Githubid string `json:"githubid,omitempty"`
 
+	// labels
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// manager
+	Manager string `json:"manager,omitempty"`
+
 	// repositories
 	Repositories []*Repository `json:"repositories"`
 
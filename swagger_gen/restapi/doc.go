@@ -14,6 +14,7 @@
 //	  - application/json
 //
 //	Produces:
+//	  - text/csv
 //	  - application/json
 //
 // swagger:meta
@@ -32,6 +32,76 @@ func init() {
   },
   "basePath": "/api/v1",
   "paths": {
+    "/auth/adopt": {
+      "post": {
+        "description": "Open a Pull Request to adopt unmanaged resources",
+        "tags": [
+          "auth"
+        ],
+        "operationId": "postAdopt",
+        "parameters": [
+          {
+            "description": "Resources to adopt",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "type": "object",
+              "required": [
+                "resources"
+              ],
+              "properties": {
+                "owner_team": {
+                  "type": "string",
+                  "x-nullable": false
+                },
+                "resources": {
+                  "type": "array",
+                  "minItems": 1,
+                  "items": {
+                    "type": "string",
+                    "minLength": 1,
+                    "x-nullable": false
+                  }
+                }
+              }
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Pull Request created",
+            "schema": {
+              "type": "object",
+              "properties": {
+                "pull_request_url": {
+                  "type": "string",
+                  "x-nullable": false
+                }
+              }
+            }
+          },
+          "401": {
+            "description": "Unauthorized",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          },
+          "403": {
+            "description": "Forbidden",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
     "/auth/callback": {
       "get": {
         "description": "Receive the callback from github after authentication",
@@ -358,6 +428,80 @@ func init() {
         }
       }
     },
+    "/enterprise/users/{githubID}": {
+      "get": {
+        "description": "Get the organizations, teams and repositories a Github user has access to, across all the managed organizations",
+        "tags": [
+          "app"
+        ],
+        "operationId": "getEnterpriseUser",
+        "parameters": [
+          {
+            "minLength": 1,
+            "type": "string",
+            "description": "github login",
+            "name": "githubID",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "get the user access per organization",
+            "schema": {
+              "$ref": "#/definitions/enterpriseUserDetails"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/explain/users/{userID}/repositories/{repositoryID}": {
+      "get": {
+        "description": "Explain all the paths granting access to a repository to a user, in the teams repository and in Github (including the grants not managed by Goliac)",
+        "tags": [
+          "app"
+        ],
+        "operationId": "getAccessExplanation",
+        "parameters": [
+          {
+            "minLength": 1,
+            "type": "string",
+            "description": "github login or Goliac username",
+            "name": "userID",
+            "in": "path",
+            "required": true
+          },
+          {
+            "minLength": 1,
+            "type": "string",
+            "description": "repository name",
+            "name": "repositoryID",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "get the access explanation",
+            "schema": {
+              "$ref": "#/definitions/accessExplanation"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
     "/external/createrepository": {
       "post": {
         "description": "Create a Repository via Goliac",
@@ -488,6 +632,57 @@ func init() {
         }
       }
     },
+    "/reports/access": {
+      "get": {
+        "description": "Get the effective permission of each user on each repository, and the reasons granting it (access review)",
+        "produces": [
+          "application/json",
+          "text/csv"
+        ],
+        "tags": [
+          "app"
+        ],
+        "operationId": "getAccessReport",
+        "parameters": [
+          {
+            "enum": [
+              "json",
+              "csv"
+            ],
+            "type": "string",
+            "default": "json",
+            "description": "output format",
+            "name": "format",
+            "in": "query"
+          },
+          {
+            "enum": [
+              "local",
+              "remote"
+            ],
+            "type": "string",
+            "default": "local",
+            "description": "local (the teams repository, desired state) or remote (the Github organization, actual state)",
+            "name": "source",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "get the access report",
+            "schema": {
+              "$ref": "#/definitions/accessReport"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
     "/repositories": {
       "get": {
         "description": "Get all repositories",
@@ -747,6 +942,143 @@ func init() {
     }
   },
   "definitions": {
+    "accessBypass": {
+      "type": "object",
+      "properties": {
+        "description": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "kind": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "mode": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "name": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "team": {
+          "type": "string"
+        },
+        "via": {
+          "type": "string"
+        }
+      }
+    },
+    "accessExplanation": {
+      "type": "object",
+      "properties": {
+        "githubid": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "local": {
+          "$ref": "#/definitions/accessPaths"
+        },
+        "remote": {
+          "$ref": "#/definitions/accessPaths"
+        },
+        "repository": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "unmanaged": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/accessReason"
+          }
+        },
+        "unmanagedBypasses": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/accessBypass"
+          }
+        },
+        "username": {
+          "type": "string"
+        }
+      }
+    },
+    "accessGrant": {
+      "type": "object",
+      "properties": {
+        "githubid": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "permission": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "reasons": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/accessReason"
+          }
+        },
+        "repository": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "username": {
+          "type": "string"
+        }
+      }
+    },
+    "accessPaths": {
+      "type": "object",
+      "properties": {
+        "bypasses": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/accessBypass"
+          }
+        },
+        "permission": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "reasons": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/accessReason"
+          }
+        }
+      }
+    },
+    "accessReason": {
+      "type": "object",
+      "properties": {
+        "description": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "kind": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "permission": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "team": {
+          "type": "string"
+        },
+        "via": {
+          "type": "string"
+        }
+      }
+    },
+    "accessReport": {
+      "type": "array",
+      "items": {
+        "$ref": "#/definitions/accessGrant"
+      }
+    },
     "collaboratorDetails": {
       "type": "object",
       "properties": {
@@ -762,6 +1094,32 @@ func init() {
         }
       }
     },
+    "enterpriseUserDetails": {
+      "type": "object",
+      "properties": {
+        "enterpriseMember": {
+          "type": "boolean",
+          "x-isnullable": false,
+          "x-omitempty": false
+        },
+        "enterpriseTeams": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "githubid": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "organizations": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/organizationAccess"
+          }
+        }
+      }
+    },
     "error": {
       "type": "object",
       "required": [
@@ -795,6 +1153,36 @@ func init() {
         }
       }
     },
+    "organizationAccess": {
+      "type": "object",
+      "properties": {
+        "externalRepositories": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/repository"
+          }
+        },
+        "organization": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "repositories": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/repository"
+          }
+        },
+        "teams": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/team"
+          }
+        },
+        "username": {
+          "type": "string"
+        }
+      }
+    },
     "repositories": {
       "type": "array",
       "items": {
@@ -1045,6 +1433,9 @@ func init() {
             "type": "string"
           }
         },
+        "githubServerVersion": {
+          "type": "string"
+        },
         "lastSyncError": {
           "type": "string"
         },
@@ -1076,6 +1467,18 @@ func init() {
           "type": "string",
           "x-isnullable": false
         },
+        "organizations": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "unsupportedFeatures": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
         "version": {
           "type": "string"
         }
@@ -1230,10 +1633,25 @@ func init() {
     "userDetails": {
       "type": "object",
       "properties": {
+        "email": {
+          "type": "string"
+        },
+        "fullName": {
+          "type": "string"
+        },
         "githubid": {
           "type": "string",
           "x-isnullable": false
         },
+        "labels": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "string"
+          }
+        },
+        "manager": {
+          "type": "string"
+        },
         "repositories": {
           "type": "array",
           "items": {
@@ -1344,6 +1762,76 @@ func init() {
   },
   "basePath": "/api/v1",
   "paths": {
+    "/auth/adopt": {
+      "post": {
+        "description": "Open a Pull Request to adopt unmanaged resources",
+        "tags": [
+          "auth"
+        ],
+        "operationId": "postAdopt",
+        "parameters": [
+          {
+            "description": "Resources to adopt",
+            "name": "body",
+            "in": "body",
+            "required": true,
+            "schema": {
+              "type": "object",
+              "required": [
+                "resources"
+              ],
+              "properties": {
+                "owner_team": {
+                  "type": "string",
+                  "x-nullable": false
+                },
+                "resources": {
+                  "type": "array",
+                  "minItems": 1,
+                  "items": {
+                    "type": "string",
+                    "minLength": 1,
+                    "x-nullable": false
+                  }
+                }
+              }
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "Pull Request created",
+            "schema": {
+              "type": "object",
+              "properties": {
+                "pull_request_url": {
+                  "type": "string",
+                  "x-nullable": false
+                }
+              }
+            }
+          },
+          "401": {
+            "description": "Unauthorized",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          },
+          "403": {
+            "description": "Forbidden",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
     "/auth/callback": {
       "get": {
         "description": "Receive the callback from github after authentication",
@@ -1576,21 +2064,77 @@ func init() {
         ],
         "responses": {
           "200": {
-            "description": "Workflow executed",
-            "schema": {
-              "$ref": "#/definitions/workflow"
-            }
-          },
-          "401": {
-            "description": "Unauthorized",
-            "schema": {
-              "$ref": "#/definitions/error"
-            }
-          },
-          "403": {
-            "description": "Forbidden",
+            "description": "Workflow executed",
+            "schema": {
+              "$ref": "#/definitions/workflow"
+            }
+          },
+          "401": {
+            "description": "Unauthorized",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          },
+          "403": {
+            "description": "Forbidden",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/collaborators": {
+      "get": {
+        "description": "Get all external collaborators",
+        "tags": [
+          "app"
+        ],
+        "operationId": "getCollaborators",
+        "responses": {
+          "200": {
+            "description": "get list of collarators",
+            "schema": {
+              "$ref": "#/definitions/users"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
+    "/collaborators/{collaboratorID}": {
+      "get": {
+        "description": "Get collaborator and repos",
+        "tags": [
+          "app"
+        ],
+        "operationId": "getCollaborator",
+        "parameters": [
+          {
+            "minLength": 1,
+            "type": "string",
+            "description": "collaborator name",
+            "name": "collaboratorID",
+            "in": "path",
+            "required": true
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "get collaborator details especially repositories",
             "schema": {
-              "$ref": "#/definitions/error"
+              "$ref": "#/definitions/collaboratorDetails"
             }
           },
           "default": {
@@ -1602,18 +2146,28 @@ func init() {
         }
       }
     },
-    "/collaborators": {
+    "/enterprise/users/{githubID}": {
       "get": {
-        "description": "Get all external collaborators",
+        "description": "Get the organizations, teams and repositories a Github user has access to, across all the managed organizations",
         "tags": [
           "app"
         ],
-        "operationId": "getCollaborators",
+        "operationId": "getEnterpriseUser",
+        "parameters": [
+          {
+            "minLength": 1,
+            "type": "string",
+            "description": "github login",
+            "name": "githubID",
+            "in": "path",
+            "required": true
+          }
+        ],
         "responses": {
           "200": {
-            "description": "get list of collarators",
+            "description": "get the user access per organization",
             "schema": {
-              "$ref": "#/definitions/users"
+              "$ref": "#/definitions/enterpriseUserDetails"
             }
           },
           "default": {
@@ -1625,28 +2179,36 @@ func init() {
         }
       }
     },
-    "/collaborators/{collaboratorID}": {
+    "/explain/users/{userID}/repositories/{repositoryID}": {
       "get": {
-        "description": "Get collaborator and repos",
+        "description": "Explain all the paths granting access to a repository to a user, in the teams repository and in Github (including the grants not managed by Goliac)",
         "tags": [
           "app"
         ],
-        "operationId": "getCollaborator",
+        "operationId": "getAccessExplanation",
         "parameters": [
           {
             "minLength": 1,
             "type": "string",
-            "description": "collaborator name",
-            "name": "collaboratorID",
+            "description": "github login or Goliac username",
+            "name": "userID",
+            "in": "path",
+            "required": true
+          },
+          {
+            "minLength": 1,
+            "type": "string",
+            "description": "repository name",
+            "name": "repositoryID",
             "in": "path",
             "required": true
           }
         ],
         "responses": {
           "200": {
-            "description": "get collaborator details especially repositories",
+            "description": "get the access explanation",
             "schema": {
-              "$ref": "#/definitions/collaboratorDetails"
+              "$ref": "#/definitions/accessExplanation"
             }
           },
           "default": {
@@ -1788,6 +2350,57 @@ func init() {
         }
       }
     },
+    "/reports/access": {
+      "get": {
+        "description": "Get the effective permission of each user on each repository, and the reasons granting it (access review)",
+        "produces": [
+          "application/json",
+          "text/csv"
+        ],
+        "tags": [
+          "app"
+        ],
+        "operationId": "getAccessReport",
+        "parameters": [
+          {
+            "enum": [
+              "json",
+              "csv"
+            ],
+            "type": "string",
+            "default": "json",
+            "description": "output format",
+            "name": "format",
+            "in": "query"
+          },
+          {
+            "enum": [
+              "local",
+              "remote"
+            ],
+            "type": "string",
+            "default": "local",
+            "description": "local (the teams repository, desired state) or remote (the Github organization, actual state)",
+            "name": "source",
+            "in": "query"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "get the access report",
+            "schema": {
+              "$ref": "#/definitions/accessReport"
+            }
+          },
+          "default": {
+            "description": "generic error response",
+            "schema": {
+              "$ref": "#/definitions/error"
+            }
+          }
+        }
+      }
+    },
     "/repositories": {
       "get": {
         "description": "Get all repositories",
@@ -2199,6 +2812,143 @@ func init() {
         }
       }
     },
+    "accessBypass": {
+      "type": "object",
+      "properties": {
+        "description": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "kind": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "mode": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "name": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "team": {
+          "type": "string"
+        },
+        "via": {
+          "type": "string"
+        }
+      }
+    },
+    "accessExplanation": {
+      "type": "object",
+      "properties": {
+        "githubid": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "local": {
+          "$ref": "#/definitions/accessPaths"
+        },
+        "remote": {
+          "$ref": "#/definitions/accessPaths"
+        },
+        "repository": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "unmanaged": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/accessReason"
+          }
+        },
+        "unmanagedBypasses": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/accessBypass"
+          }
+        },
+        "username": {
+          "type": "string"
+        }
+      }
+    },
+    "accessGrant": {
+      "type": "object",
+      "properties": {
+        "githubid": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "permission": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "reasons": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/accessReason"
+          }
+        },
+        "repository": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "username": {
+          "type": "string"
+        }
+      }
+    },
+    "accessPaths": {
+      "type": "object",
+      "properties": {
+        "bypasses": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/accessBypass"
+          }
+        },
+        "permission": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "reasons": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/accessReason"
+          }
+        }
+      }
+    },
+    "accessReason": {
+      "type": "object",
+      "properties": {
+        "description": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "kind": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "permission": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "team": {
+          "type": "string"
+        },
+        "via": {
+          "type": "string"
+        }
+      }
+    },
+    "accessReport": {
+      "type": "array",
+      "items": {
+        "$ref": "#/definitions/accessGrant"
+      }
+    },
     "collaboratorDetails": {
       "type": "object",
       "properties": {
@@ -2214,6 +2964,32 @@ func init() {
         }
       }
     },
+    "enterpriseUserDetails": {
+      "type": "object",
+      "properties": {
+        "enterpriseMember": {
+          "type": "boolean",
+          "x-isnullable": false,
+          "x-omitempty": false
+        },
+        "enterpriseTeams": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "githubid": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "organizations": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/organizationAccess"
+          }
+        }
+      }
+    },
     "error": {
       "type": "object",
       "required": [
@@ -2247,6 +3023,36 @@ func init() {
         }
       }
     },
+    "organizationAccess": {
+      "type": "object",
+      "properties": {
+        "externalRepositories": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/repository"
+          }
+        },
+        "organization": {
+          "type": "string",
+          "x-isnullable": false
+        },
+        "repositories": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/repository"
+          }
+        },
+        "teams": {
+          "type": "array",
+          "items": {
+            "$ref": "#/definitions/team"
+          }
+        },
+        "username": {
+          "type": "string"
+        }
+      }
+    },
     "repositories": {
       "type": "array",
       "items": {
@@ -2434,6 +3240,9 @@ func init() {
             "type": "string"
           }
         },
+        "githubServerVersion": {
+          "type": "string"
+        },
         "lastSyncError": {
           "type": "string"
         },
@@ -2465,6 +3274,18 @@ func init() {
           "type": "string",
           "x-isnullable": false
         },
+        "organizations": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
+        "unsupportedFeatures": {
+          "type": "array",
+          "items": {
+            "type": "string"
+          }
+        },
         "version": {
           "type": "string"
         }
@@ -2589,10 +3410,25 @@ func init() {
     "userDetails": {
       "type": "object",
       "properties": {
+        "email": {
+          "type": "string"
+        },
+        "fullName": {
+          "type": "string"
+        },
         "githubid": {
           "type": "string",
           "x-isnullable": false
         },
+        "labels": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "string"
+          }
+        },
+        "manager": {
+          "type": "string"
+        },
         "repositories": {
           "type": "array",
           "items": {
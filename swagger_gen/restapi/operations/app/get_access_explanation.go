@@ -0,0 +1,54 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+)
+
+// GetAccessExplanationHandlerFunc turns a function with the right signature into a get access explanation handler
+type GetAccessExplanationHandlerFunc func(GetAccessExplanationParams) middleware.Responder
+
+// Handle executing the request and returning a response
+func (fn GetAccessExplanationHandlerFunc) Handle(params GetAccessExplanationParams) middleware.Responder {
+	return fn(params)
+}
+
+// GetAccessExplanationHandler interface for that can handle valid get access explanation params
+type GetAccessExplanationHandler interface {
+	Handle(GetAccessExplanationParams) middleware.Responder
+}
+
+// NewGetAccessExplanation creates a new http.Handler for the get access explanation operation
+func NewGetAccessExplanation(ctx *middleware.Context, handler GetAccessExplanationHandler) *GetAccessExplanation {
+	return &GetAccessExplanation{Context: ctx, Handler: handler}
+}
+
+/*
+	GetAccessExplanation swagger:route GET /explain/users/{userID}/repositories/{repositoryID} app getAccessExplanation
+
+Explain all the paths granting access to a repository to a user, in the teams repository and in Github (including the grants not managed by Goliac)
+*/
+type GetAccessExplanation struct {
+	Context *middleware.Context
+	Handler GetAccessExplanationHandler
+}
+
+func (o *GetAccessExplanation) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	route, rCtx, _ := o.Context.RouteInfo(r)
+	if rCtx != nil {
+		*r = *rCtx
+	}
+	var Params = NewGetAccessExplanationParams()
+	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+
+	res := o.Handler.Handle(Params) // actually handle the request
+
+	o.Context.Respond(rw, r, route.Produces, route, res)
+
+}
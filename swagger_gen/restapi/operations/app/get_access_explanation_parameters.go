@@ -0,0 +1,123 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+)
+
+// NewGetAccessExplanationParams creates a new GetAccessExplanationParams object
+//
+// There are no default values defined in the spec.
+func NewGetAccessExplanationParams() GetAccessExplanationParams {
+
+	return GetAccessExplanationParams{}
+}
+
+// GetAccessExplanationParams contains all the bound params for the get access explanation operation
+// typically these are obtained from a http.Request
+//
+// swagger:parameters getAccessExplanation
+type GetAccessExplanationParams struct {
+	// HTTP Request Object
+	HTTPRequest *http.Request `json:"-"`
+
+	/*repository name
+	  Required: true
+	  Min Length: 1
+	  In: path
+	*/
+	RepositoryID string
+
+	/*github login or Goliac username
+	  Required: true
+	  Min Length: 1
+	  In: path
+	*/
+	UserID string
+}
+
+// BindRequest both binds and validates a request, it assumes that complex things implement a Validatable(strfmt.Registry) error interface
+// for simple values it will use straight method calls.
+//
+// To ensure default values, the struct must have been initialized with NewGetAccessExplanationParams() beforehand.
+func (o *GetAccessExplanationParams) BindRequest(r *http.Request, route *middleware.MatchedRoute) error {
+	var res []error
+
+	o.HTTPRequest = r
+
+	rRepositoryID, rhkRepositoryID, _ := route.Params.GetOK("repositoryID")
+	if err := o.bindRepositoryID(rRepositoryID, rhkRepositoryID, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	rUserID, rhkUserID, _ := route.Params.GetOK("userID")
+	if err := o.bindUserID(rUserID, rhkUserID, route.Formats); err != nil {
+		res = append(res, err)
+	}
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+// bindRepositoryID binds and validates parameter RepositoryID from path.
+func (o *GetAccessExplanationParams) bindRepositoryID(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: true
+	// Parameter is provided by construction from the route
+	o.RepositoryID = raw
+
+	if err := o.validateRepositoryID(formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateRepositoryID carries out validations for parameter RepositoryID
+func (o *GetAccessExplanationParams) validateRepositoryID(formats strfmt.Registry) error {
+
+	if err := validate.MinLength("repositoryID", "path", o.RepositoryID, 1); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// bindUserID binds and validates parameter UserID from path.
+func (o *GetAccessExplanationParams) bindUserID(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: true
+	// Parameter is provided by construction from the route
+	o.UserID = raw
+
+	if err := o.validateUserID(formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateUserID carries out validations for parameter UserID
+func (o *GetAccessExplanationParams) validateUserID(formats strfmt.Registry) error {
+
+	if err := validate.MinLength("userID", "path", o.UserID, 1); err != nil {
+		return err
+	}
+
+	return nil
+}
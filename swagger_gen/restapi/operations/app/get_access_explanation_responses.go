@@ -0,0 +1,115 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+
+	"github.com/goliac-project/goliac/swagger_gen/models"
+)
+
+// GetAccessExplanationOKCode is the HTTP code returned for type GetAccessExplanationOK
+const GetAccessExplanationOKCode int = 200
+
+/*
+GetAccessExplanationOK get the access explanation
+
+swagger:response getAccessExplanationOK
+*/
+type GetAccessExplanationOK struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.AccessExplanation `json:"body,omitempty"`
+}
+
+// NewGetAccessExplanationOK creates GetAccessExplanationOK with default headers values
+func NewGetAccessExplanationOK() *GetAccessExplanationOK {
+
+	return &GetAccessExplanationOK{}
+}
+
+// WithPayload adds the payload to the get access explanation o k response
+func (o *GetAccessExplanationOK) WithPayload(payload *models.AccessExplanation) *GetAccessExplanationOK {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get access explanation o k response
+func (o *GetAccessExplanationOK) SetPayload(payload *models.AccessExplanation) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetAccessExplanationOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(200)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+/*
+GetAccessExplanationDefault generic error response
+
+swagger:response getAccessExplanationDefault
+*/
+type GetAccessExplanationDefault struct {
+	_statusCode int
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewGetAccessExplanationDefault creates GetAccessExplanationDefault with default headers values
+func NewGetAccessExplanationDefault(code int) *GetAccessExplanationDefault {
+	if code <= 0 {
+		code = 500
+	}
+
+	return &GetAccessExplanationDefault{
+		_statusCode: code,
+	}
+}
+
+// WithStatusCode adds the status to the get access explanation default response
+func (o *GetAccessExplanationDefault) WithStatusCode(code int) *GetAccessExplanationDefault {
+	o._statusCode = code
+	return o
+}
+
+// SetStatusCode sets the status to the get access explanation default response
+func (o *GetAccessExplanationDefault) SetStatusCode(code int) {
+	o._statusCode = code
+}
+
+// WithPayload adds the payload to the get access explanation default response
+func (o *GetAccessExplanationDefault) WithPayload(payload *models.Error) *GetAccessExplanationDefault {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get access explanation default response
+func (o *GetAccessExplanationDefault) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetAccessExplanationDefault) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(o._statusCode)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package app
+
+import (
+	"errors"
+	"net/url"
+	golangswaggerpaths "path"
+	"strings"
+)
+
+// GetAccessExplanationURL generates an URL for the get access explanation operation
+type GetAccessExplanationURL struct {
+	RepositoryID string
+	UserID       string
+
+	_basePath string
+	// avoid unkeyed usage
+	_ struct{}
+}
+
+// WithBasePath sets the base path for this url builder, only required when it's different from the
+// base path specified in the swagger spec.
+// When the value of the base path is an empty string
+func (o *GetAccessExplanationURL) WithBasePath(bp string) *GetAccessExplanationURL {
+	o.SetBasePath(bp)
+	return o
+}
+
+// SetBasePath sets the base path for this url builder, only required when it's different from the
+// base path specified in the swagger spec.
+// When the value of the base path is an empty string
+func (o *GetAccessExplanationURL) SetBasePath(bp string) {
+	o._basePath = bp
+}
+
+// Build a url path and query string
+func (o *GetAccessExplanationURL) Build() (*url.URL, error) {
+	var _result url.URL
+
+	var _path = "/explain/users/{userID}/repositories/{repositoryID}"
+
+	repositoryID := o.RepositoryID
+	if repositoryID != "" {
+		_path = strings.ReplaceAll(_path, "{repositoryID}", repositoryID)
+	} else {
+		return nil, errors.New("repositoryId is required on GetAccessExplanationURL")
+	}
+
+	userID := o.UserID
+	if userID != "" {
+		_path = strings.ReplaceAll(_path, "{userID}", userID)
+	} else {
+		return nil, errors.New("userId is required on GetAccessExplanationURL")
+	}
+
+	_basePath := o._basePath
+	if _basePath == "" {
+		_basePath = "/api/v1"
+	}
+	_result.Path = golangswaggerpaths.Join(_basePath, _path)
+
+	return &_result, nil
+}
+
+// Must is a helper function to panic when the url builder returns an error
+func (o *GetAccessExplanationURL) Must(u *url.URL, err error) *url.URL {
+	if err != nil {
+		panic(err)
+	}
+	if u == nil {
+		panic("url can't be nil")
+	}
+	return u
+}
+
+// String returns the string representation of the path with query string
+func (o *GetAccessExplanationURL) String() string {
+	return o.Must(o.Build()).String()
+}
+
+// BuildFull builds a full url with scheme, host, path and query string
+func (o *GetAccessExplanationURL) BuildFull(scheme, host string) (*url.URL, error) {
+	if scheme == "" {
+		return nil, errors.New("scheme is required for a full url on GetAccessExplanationURL")
+	}
+	if host == "" {
+		return nil, errors.New("host is required for a full url on GetAccessExplanationURL")
+	}
+
+	base, err := o.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	base.Scheme = scheme
+	base.Host = host
+	return base, nil
+}
+
+// StringFull returns the string representation of a complete url
+func (o *GetAccessExplanationURL) StringFull(scheme, host string) string {
+	return o.Must(o.BuildFull(scheme, host)).String()
+}
@@ -0,0 +1,54 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+)
+
+// GetAccessReportHandlerFunc turns a function with the right signature into a get access report handler
+type GetAccessReportHandlerFunc func(GetAccessReportParams) middleware.Responder
+
+// Handle executing the request and returning a response
+func (fn GetAccessReportHandlerFunc) Handle(params GetAccessReportParams) middleware.Responder {
+	return fn(params)
+}
+
+// GetAccessReportHandler interface for that can handle valid get access report params
+type GetAccessReportHandler interface {
+	Handle(GetAccessReportParams) middleware.Responder
+}
+
+// NewGetAccessReport creates a new http.Handler for the get access report operation
+func NewGetAccessReport(ctx *middleware.Context, handler GetAccessReportHandler) *GetAccessReport {
+	return &GetAccessReport{Context: ctx, Handler: handler}
+}
+
+/*
+	GetAccessReport swagger:route GET /reports/access app getAccessReport
+
+Get the effective permission of each user on each repository, and the reasons granting it (access review)
+*/
+type GetAccessReport struct {
+	Context *middleware.Context
+	Handler GetAccessReportHandler
+}
+
+func (o *GetAccessReport) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	route, rCtx, _ := o.Context.RouteInfo(r)
+	if rCtx != nil {
+		*r = *rCtx
+	}
+	var Params = NewGetAccessReportParams()
+	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+
+	res := o.Handler.Handle(Params) // actually handle the request
+
+	o.Context.Respond(rw, r, route.Produces, route, res)
+
+}
@@ -0,0 +1,143 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+)
+
+// NewGetAccessReportParams creates a new GetAccessReportParams object
+// with the default values initialized.
+func NewGetAccessReportParams() GetAccessReportParams {
+
+	var (
+		// initialize parameters with default values
+
+		formatDefault = string("json")
+		sourceDefault = string("local")
+	)
+
+	return GetAccessReportParams{
+		Format: &formatDefault,
+
+		Source: &sourceDefault,
+	}
+}
+
+// GetAccessReportParams contains all the bound params for the get access report operation
+// typically these are obtained from a http.Request
+//
+// swagger:parameters getAccessReport
+type GetAccessReportParams struct {
+	// HTTP Request Object
+	HTTPRequest *http.Request `json:"-"`
+
+	/*output format
+	  In: query
+	  Default: "json"
+	*/
+	Format *string
+
+	/*local (the teams repository, desired state) or remote (the Github organization, actual state)
+	  In: query
+	  Default: "local"
+	*/
+	Source *string
+}
+
+// BindRequest both binds and validates a request, it assumes that complex things implement a Validatable(strfmt.Registry) error interface
+// for simple values it will use straight method calls.
+//
+// To ensure default values, the struct must have been initialized with NewGetAccessReportParams() beforehand.
+func (o *GetAccessReportParams) BindRequest(r *http.Request, route *middleware.MatchedRoute) error {
+	var res []error
+
+	o.HTTPRequest = r
+	qs := runtime.Values(r.URL.Query())
+
+	qFormat, qhkFormat, _ := qs.GetOK("format")
+	if err := o.bindFormat(qFormat, qhkFormat, route.Formats); err != nil {
+		res = append(res, err)
+	}
+
+	qSource, qhkSource, _ := qs.GetOK("source")
+	if err := o.bindSource(qSource, qhkSource, route.Formats); err != nil {
+		res = append(res, err)
+	}
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+// bindFormat binds and validates parameter Format from query.
+func (o *GetAccessReportParams) bindFormat(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+
+	if raw == "" { // empty values pass all other validations
+		// Default values have been previously initialized by NewGetAccessReportParams()
+		return nil
+	}
+	o.Format = &raw
+
+	if err := o.validateFormat(formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateFormat carries out validations for parameter Format
+func (o *GetAccessReportParams) validateFormat(formats strfmt.Registry) error {
+
+	if err := validate.EnumCase("format", "query", *o.Format, []any{"json", "csv"}, true); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// bindSource binds and validates parameter Source from query.
+func (o *GetAccessReportParams) bindSource(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: false
+	// AllowEmptyValue: false
+
+	if raw == "" { // empty values pass all other validations
+		// Default values have been previously initialized by NewGetAccessReportParams()
+		return nil
+	}
+	o.Source = &raw
+
+	if err := o.validateSource(formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateSource carries out validations for parameter Source
+func (o *GetAccessReportParams) validateSource(formats strfmt.Registry) error {
+
+	if err := validate.EnumCase("source", "query", *o.Source, []any{"local", "remote"}, true); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+
+	"github.com/goliac-project/goliac/swagger_gen/models"
+)
+
+// GetAccessReportOKCode is the HTTP code returned for type GetAccessReportOK
+const GetAccessReportOKCode int = 200
+
+/*
+GetAccessReportOK get the access report
+
+swagger:response getAccessReportOK
+*/
+type GetAccessReportOK struct {
+
+	/*
+	  In: Body
+	*/
+	Payload models.AccessReport `json:"body,omitempty"`
+}
+
+// NewGetAccessReportOK creates GetAccessReportOK with default headers values
+func NewGetAccessReportOK() *GetAccessReportOK {
+
+	return &GetAccessReportOK{}
+}
+
+// WithPayload adds the payload to the get access report o k response
+func (o *GetAccessReportOK) WithPayload(payload models.AccessReport) *GetAccessReportOK {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get access report o k response
+func (o *GetAccessReportOK) SetPayload(payload models.AccessReport) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetAccessReportOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(200)
+	payload := o.Payload
+	if payload == nil {
+		// return empty array
+		payload = models.AccessReport{}
+	}
+
+	if err := producer.Produce(rw, payload); err != nil {
+		panic(err) // let the recovery middleware deal with this
+	}
+}
+
+/*
+GetAccessReportDefault generic error response
+
+swagger:response getAccessReportDefault
+*/
+type GetAccessReportDefault struct {
+	_statusCode int
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewGetAccessReportDefault creates GetAccessReportDefault with default headers values
+func NewGetAccessReportDefault(code int) *GetAccessReportDefault {
+	if code <= 0 {
+		code = 500
+	}
+
+	return &GetAccessReportDefault{
+		_statusCode: code,
+	}
+}
+
+// WithStatusCode adds the status to the get access report default response
+func (o *GetAccessReportDefault) WithStatusCode(code int) *GetAccessReportDefault {
+	o._statusCode = code
+	return o
+}
+
+// SetStatusCode sets the status to the get access report default response
+func (o *GetAccessReportDefault) SetStatusCode(code int) {
+	o._statusCode = code
+}
+
+// WithPayload adds the payload to the get access report default response
+func (o *GetAccessReportDefault) WithPayload(payload *models.Error) *GetAccessReportDefault {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get access report default response
+func (o *GetAccessReportDefault) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetAccessReportDefault) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(o._statusCode)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
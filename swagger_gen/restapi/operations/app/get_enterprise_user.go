@@ -0,0 +1,54 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime/middleware"
+)
+
+// GetEnterpriseUserHandlerFunc turns a function with the right signature into a get enterprise user handler
+type GetEnterpriseUserHandlerFunc func(GetEnterpriseUserParams) middleware.Responder
+
+// Handle executing the request and returning a response
+func (fn GetEnterpriseUserHandlerFunc) Handle(params GetEnterpriseUserParams) middleware.Responder {
+	return fn(params)
+}
+
+// GetEnterpriseUserHandler interface for that can handle valid get enterprise user params
+type GetEnterpriseUserHandler interface {
+	Handle(GetEnterpriseUserParams) middleware.Responder
+}
+
+// NewGetEnterpriseUser creates a new http.Handler for the get enterprise user operation
+func NewGetEnterpriseUser(ctx *middleware.Context, handler GetEnterpriseUserHandler) *GetEnterpriseUser {
+	return &GetEnterpriseUser{Context: ctx, Handler: handler}
+}
+
+/*
+	GetEnterpriseUser swagger:route GET /enterprise/users/{githubID} app getEnterpriseUser
+
+Get the organizations, teams and repositories a Github user has access to, across all the managed organizations
+*/
+type GetEnterpriseUser struct {
+	Context *middleware.Context
+	Handler GetEnterpriseUserHandler
+}
+
+func (o *GetEnterpriseUser) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	route, rCtx, _ := o.Context.RouteInfo(r)
+	if rCtx != nil {
+		*r = *rCtx
+	}
+	var Params = NewGetEnterpriseUserParams()
+	if err := o.Context.BindValidRequest(r, route, &Params); err != nil { // bind params
+		o.Context.Respond(rw, r, route.Produces, route, err)
+		return
+	}
+
+	res := o.Handler.Handle(Params) // actually handle the request
+
+	o.Context.Respond(rw, r, route.Produces, route, res)
+
+}
@@ -0,0 +1,83 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/errors"
+	"github.com/go-openapi/runtime/middleware"
+	"github.com/go-openapi/strfmt"
+	"github.com/go-openapi/validate"
+)
+
+// NewGetEnterpriseUserParams creates a new GetEnterpriseUserParams object
+//
+// There are no default values defined in the spec.
+func NewGetEnterpriseUserParams() GetEnterpriseUserParams {
+
+	return GetEnterpriseUserParams{}
+}
+
+// GetEnterpriseUserParams contains all the bound params for the get enterprise user operation
+// typically these are obtained from a http.Request
+//
+// swagger:parameters getEnterpriseUser
+type GetEnterpriseUserParams struct {
+	// HTTP Request Object
+	HTTPRequest *http.Request `json:"-"`
+
+	/*github login
+	  Required: true
+	  Min Length: 1
+	  In: path
+	*/
+	GithubID string
+}
+
+// BindRequest both binds and validates a request, it assumes that complex things implement a Validatable(strfmt.Registry) error interface
+// for simple values it will use straight method calls.
+//
+// To ensure default values, the struct must have been initialized with NewGetEnterpriseUserParams() beforehand.
+func (o *GetEnterpriseUserParams) BindRequest(r *http.Request, route *middleware.MatchedRoute) error {
+	var res []error
+
+	o.HTTPRequest = r
+
+	rGithubID, rhkGithubID, _ := route.Params.GetOK("githubID")
+	if err := o.bindGithubID(rGithubID, rhkGithubID, route.Formats); err != nil {
+		res = append(res, err)
+	}
+	if len(res) > 0 {
+		return errors.CompositeValidationError(res...)
+	}
+	return nil
+}
+
+// bindGithubID binds and validates parameter GithubID from path.
+func (o *GetEnterpriseUserParams) bindGithubID(rawData []string, hasKey bool, formats strfmt.Registry) error {
+	var raw string
+	if len(rawData) > 0 {
+		raw = rawData[len(rawData)-1]
+	}
+
+	// Required: true
+	// Parameter is provided by construction from the route
+	o.GithubID = raw
+
+	if err := o.validateGithubID(formats); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateGithubID carries out validations for parameter GithubID
+func (o *GetEnterpriseUserParams) validateGithubID(formats strfmt.Registry) error {
+
+	if err := validate.MinLength("githubID", "path", o.GithubID, 1); err != nil {
+		return err
+	}
+
+	return nil
+}
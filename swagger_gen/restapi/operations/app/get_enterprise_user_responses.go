@@ -0,0 +1,115 @@
+// Code generated by go-swagger; DO NOT EDIT.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+
+	"github.com/goliac-project/goliac/swagger_gen/models"
+)
+
+// GetEnterpriseUserOKCode is the HTTP code returned for type GetEnterpriseUserOK
+const GetEnterpriseUserOKCode int = 200
+
+/*
+GetEnterpriseUserOK get the user access per organization
+
+swagger:response getEnterpriseUserOK
+*/
+type GetEnterpriseUserOK struct {
+
+	/*
+	  In: Body
+	*/
+	Payload *models.EnterpriseUserDetails `json:"body,omitempty"`
+}
+
+// NewGetEnterpriseUserOK creates GetEnterpriseUserOK with default headers values
+func NewGetEnterpriseUserOK() *GetEnterpriseUserOK {
+
+	return &GetEnterpriseUserOK{}
+}
+
+// WithPayload adds the payload to the get enterprise user o k response
+func (o *GetEnterpriseUserOK) WithPayload(payload *models.EnterpriseUserDetails) *GetEnterpriseUserOK {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get enterprise user o k response
+func (o *GetEnterpriseUserOK) SetPayload(payload *models.EnterpriseUserDetails) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetEnterpriseUserOK) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(200)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
+
+/*
+GetEnterpriseUserDefault generic error response
+
+swagger:response getEnterpriseUserDefault
+*/
+type GetEnterpriseUserDefault struct {
+	_statusCode int
+
+	/*
+	  In: Body
+	*/
+	Payload *models.Error `json:"body,omitempty"`
+}
+
+// NewGetEnterpriseUserDefault creates GetEnterpriseUserDefault with default headers values
+func NewGetEnterpriseUserDefault(code int) *GetEnterpriseUserDefault {
+	if code <= 0 {
+		code = 500
+	}
+
+	return &GetEnterpriseUserDefault{
+		_statusCode: code,
+	}
+}
+
+// WithStatusCode adds the status to the get enterprise user default response
+func (o *GetEnterpriseUserDefault) WithStatusCode(code int) *GetEnterpriseUserDefault {
+	o._statusCode = code
+	return o
+}
+
+// SetStatusCode sets the status to the get enterprise user default response
+func (o *GetEnterpriseUserDefault) SetStatusCode(code int) {
+	o._statusCode = code
+}
+
+// WithPayload adds the payload to the get enterprise user default response
+func (o *GetEnterpriseUserDefault) WithPayload(payload *models.Error) *GetEnterpriseUserDefault {
+	o.Payload = payload
+	return o
+}
+
+// SetPayload sets the payload to the get enterprise user default response
+func (o *GetEnterpriseUserDefault) SetPayload(payload *models.Error) {
+	o.Payload = payload
+}
+
+// WriteResponse to the client
+func (o *GetEnterpriseUserDefault) WriteResponse(rw http.ResponseWriter, producer runtime.Producer) {
+
+	rw.WriteHeader(o._statusCode)
+	if o.Payload != nil {
+		payload := o.Payload
+		if err := producer.Produce(rw, payload); err != nil {
+			panic(err) // let the recovery middleware deal with this
+		}
+	}
+}
@@ -42,8 +42,21 @@ func NewGoliacAPI(spec *loads.Document) *GoliacAPI {
 
 		JSONConsumer: runtime.JSONConsumer(),
 
+		CsvProducer:  runtime.CSVProducer(),
 		JSONProducer: runtime.JSONProducer(),
 
+		AppGetAccessExplanationHandler: app.GetAccessExplanationHandlerFunc(func(params app.GetAccessExplanationParams) middleware.Responder {
+			_ = params
+
+			return middleware.NotImplemented("operation app.GetAccessExplanation has not yet been implemented")
+		}),
+
+		AppGetAccessReportHandler: app.GetAccessReportHandlerFunc(func(params app.GetAccessReportParams) middleware.Responder {
+			_ = params
+
+			return middleware.NotImplemented("operation app.GetAccessReport has not yet been implemented")
+		}),
+
 		AuthGetAuthenticationCallbackHandler: auth.GetAuthenticationCallbackHandlerFunc(func(params auth.GetAuthenticationCallbackParams) middleware.Responder {
 			_ = params
 
@@ -68,6 +81,12 @@ func NewGoliacAPI(spec *loads.Document) *GoliacAPI {
 			return middleware.NotImplemented("operation app.GetCollaborators has not yet been implemented")
 		}),
 
+		AppGetEnterpriseUserHandler: app.GetEnterpriseUserHandlerFunc(func(params app.GetEnterpriseUserParams) middleware.Responder {
+			_ = params
+
+			return middleware.NotImplemented("operation app.GetEnterpriseUser has not yet been implemented")
+		}),
+
 		AuthGetGithubUserHandler: auth.GetGithubUserHandlerFunc(func(params auth.GetGithubUserParams) middleware.Responder {
 			_ = params
 
@@ -152,6 +171,12 @@ func NewGoliacAPI(spec *loads.Document) *GoliacAPI {
 			return middleware.NotImplemented("operation auth.GetWorkflows has not yet been implemented")
 		}),
 
+		AuthPostAdoptHandler: auth.PostAdoptHandlerFunc(func(params auth.PostAdoptParams) middleware.Responder {
+			_ = params
+
+			return middleware.NotImplemented("operation auth.PostAdopt has not yet been implemented")
+		}),
+
 		ExternalPostExternalCreateRepositoryHandler: external.PostExternalCreateRepositoryHandlerFunc(func(params external.PostExternalCreateRepositoryParams) middleware.Responder {
 			_ = params
 
@@ -208,10 +233,17 @@ type GoliacAPI struct {
 	//   - application/json
 	JSONConsumer runtime.Consumer
 
+	// CsvProducer registers a producer for the following mime types:
+	//   - text/csv
+	CsvProducer runtime.Producer
 	// JSONProducer registers a producer for the following mime types:
 	//   - application/json
 	JSONProducer runtime.Producer
 
+	// AppGetAccessExplanationHandler sets the operation handler for the get access explanation operation
+	AppGetAccessExplanationHandler app.GetAccessExplanationHandler
+	// AppGetAccessReportHandler sets the operation handler for the get access report operation
+	AppGetAccessReportHandler app.GetAccessReportHandler
 	// AuthGetAuthenticationCallbackHandler sets the operation handler for the get authentication callback operation
 	AuthGetAuthenticationCallbackHandler auth.GetAuthenticationCallbackHandler
 	// AuthGetAuthenticationLoginHandler sets the operation handler for the get authentication login operation
@@ -220,6 +252,8 @@ type GoliacAPI struct {
 	AppGetCollaboratorHandler app.GetCollaboratorHandler
 	// AppGetCollaboratorsHandler sets the operation handler for the get collaborators operation
 	AppGetCollaboratorsHandler app.GetCollaboratorsHandler
+	// AppGetEnterpriseUserHandler sets the operation handler for the get enterprise user operation
+	AppGetEnterpriseUserHandler app.GetEnterpriseUserHandler
 	// AuthGetGithubUserHandler sets the operation handler for the get github user operation
 	AuthGetGithubUserHandler auth.GetGithubUserHandler
 	// HealthGetLivenessHandler sets the operation handler for the get liveness operation
@@ -248,6 +282,8 @@ type GoliacAPI struct {
 	AuthGetWorkflowHandler auth.GetWorkflowHandler
 	// AuthGetWorkflowsHandler sets the operation handler for the get workflows operation
 	AuthGetWorkflowsHandler auth.GetWorkflowsHandler
+	// AuthPostAdoptHandler sets the operation handler for the post adopt operation
+	AuthPostAdoptHandler auth.PostAdoptHandler
 	// ExternalPostExternalCreateRepositoryHandler sets the operation handler for the post external create repository operation
 	ExternalPostExternalCreateRepositoryHandler external.PostExternalCreateRepositoryHandler
 	// AppPostFlushCacheHandler sets the operation handler for the post flush cache operation
@@ -329,10 +365,19 @@ func (o *GoliacAPI) Validate() error {
 		unregistered = append(unregistered, "JSONConsumer")
 	}
 
+	if o.CsvProducer == nil {
+		unregistered = append(unregistered, "CsvProducer")
+	}
 	if o.JSONProducer == nil {
 		unregistered = append(unregistered, "JSONProducer")
 	}
 
+	if o.AppGetAccessExplanationHandler == nil {
+		unregistered = append(unregistered, "app.GetAccessExplanationHandler")
+	}
+	if o.AppGetAccessReportHandler == nil {
+		unregistered = append(unregistered, "app.GetAccessReportHandler")
+	}
 	if o.AuthGetAuthenticationCallbackHandler == nil {
 		unregistered = append(unregistered, "auth.GetAuthenticationCallbackHandler")
 	}
@@ -345,6 +390,9 @@ func (o *GoliacAPI) Validate() error {
 	if o.AppGetCollaboratorsHandler == nil {
 		unregistered = append(unregistered, "app.GetCollaboratorsHandler")
 	}
+	if o.AppGetEnterpriseUserHandler == nil {
+		unregistered = append(unregistered, "app.GetEnterpriseUserHandler")
+	}
 	if o.AuthGetGithubUserHandler == nil {
 		unregistered = append(unregistered, "auth.GetGithubUserHandler")
 	}
@@ -387,6 +435,9 @@ func (o *GoliacAPI) Validate() error {
 	if o.AuthGetWorkflowsHandler == nil {
 		unregistered = append(unregistered, "auth.GetWorkflowsHandler")
 	}
+	if o.AuthPostAdoptHandler == nil {
+		unregistered = append(unregistered, "auth.PostAdoptHandler")
+	}
 	if o.ExternalPostExternalCreateRepositoryHandler == nil {
 		unregistered = append(unregistered, "external.PostExternalCreateRepositoryHandler")
 	}
@@ -446,7 +497,10 @@ func (o *GoliacAPI) ConsumersFor(mediaTypes []string) map[string]runtime.Consume
 func (o *GoliacAPI) ProducersFor(mediaTypes []string) map[string]runtime.Producer {
 	result := make(map[string]runtime.Producer, len(mediaTypes))
 	for _, mt := range mediaTypes {
-		if mt == "application/json" {
+		switch mt {
+		case "text/csv":
+			result["text/csv"] = o.CsvProducer
+		case "application/json":
 			result["application/json"] = o.JSONProducer
 		}
 
@@ -489,6 +543,14 @@ func (o *GoliacAPI) initHandlerCache() {
 		o.handlers = make(map[string]map[string]http.Handler)
 	}
 
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/explain/users/{userID}/repositories/{repositoryID}"] = app.NewGetAccessExplanation(o.context, o.AppGetAccessExplanationHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
+	o.handlers["GET"]["/reports/access"] = app.NewGetAccessReport(o.context, o.AppGetAccessReportHandler)
 	if o.handlers["GET"] == nil {
 		o.handlers["GET"] = make(map[string]http.Handler)
 	}
@@ -508,6 +570,10 @@ func (o *GoliacAPI) initHandlerCache() {
 	if o.handlers["GET"] == nil {
 		o.handlers["GET"] = make(map[string]http.Handler)
 	}
+	o.handlers["GET"]["/enterprise/users/{githubID}"] = app.NewGetEnterpriseUser(o.context, o.AppGetEnterpriseUserHandler)
+	if o.handlers["GET"] == nil {
+		o.handlers["GET"] = make(map[string]http.Handler)
+	}
 	o.handlers["GET"]["/auth/githubuser"] = auth.NewGetGithubUser(o.context, o.AuthGetGithubUserHandler)
 	if o.handlers["GET"] == nil {
 		o.handlers["GET"] = make(map[string]http.Handler)
@@ -564,6 +630,10 @@ func (o *GoliacAPI) initHandlerCache() {
 	if o.handlers["POST"] == nil {
 		o.handlers["POST"] = make(map[string]http.Handler)
 	}
+	o.handlers["POST"]["/auth/adopt"] = auth.NewPostAdopt(o.context, o.AuthPostAdoptHandler)
+	if o.handlers["POST"] == nil {
+		o.handlers["POST"] = make(map[string]http.Handler)
+	}
 	o.handlers["POST"]["/external/createrepository"] = external.NewPostExternalCreateRepository(o.context, o.ExternalPostExternalCreateRepositoryHandler)
 	if o.handlers["POST"] == nil {
 		o.handlers["POST"] = make(map[string]http.Handler)